@@ -136,8 +136,17 @@ func scanFile(file string, info os.FileInfo, mustPrint bool) {
 	}
 
 	fmt.Printf("%s: %s\n", file, vers)
-	if *versionM && mod != "" {
-		fmt.Printf("\t%s\n", strings.Replace(mod[:len(mod)-1], "\n", "\n\t", -1))
+	if *versionM {
+		// The Go version, recorded in the first line of the module
+		// information by newer go commands, is reported above.
+		if strings.HasPrefix(mod, "go\t") {
+			mod = mod[strings.IndexByte(mod, '\n')+1:]
+		}
+		// Module information rewritten after linking
+		// may be padded with newlines.
+		if mod = strings.TrimRight(mod, "\n"); mod != "" {
+			fmt.Printf("\t%s\n", strings.Replace(mod, "\n", "\n\t", -1))
+		}
 	}
 }
 
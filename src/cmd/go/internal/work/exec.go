@@ -25,11 +25,13 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"cmd/go/internal/base"
 	"cmd/go/internal/cache"
 	"cmd/go/internal/cfg"
 	"cmd/go/internal/load"
+	"cmd/go/internal/par"
 	"cmd/go/internal/str"
 )
 
@@ -191,6 +193,134 @@ func (b *Builder) Do(root *Action) {
 	writeActionGraph()
 }
 
+// buildInfo returns the module information to embed in the main
+// package p, followed by build lines recording the settings of the
+// build, which runtime/debug.ReadBuildInfo reports as
+// BuildInfo.Settings. It returns the empty string if p carries no
+// module information.
+func buildInfo(p *load.Package) string {
+	if p.Internal.BuildInfo == "" {
+		return ""
+	}
+	var buf strings.Builder
+	// The toolchain building the binary is the one the go command
+	// itself was built with.
+	fmt.Fprintf(&buf, "go\t%s\n", runtime.Version())
+	buf.WriteString(p.Internal.BuildInfo)
+	notPrint := func(r rune) bool { return !strconv.IsPrint(r) }
+	add := func(key, value string) {
+		// Quote values the text format cannot otherwise hold,
+		// as runtime/debug.DefaultFieldEncoder does.
+		if strings.HasPrefix(value, `"`) || !utf8.ValidString(value) || strings.IndexFunc(value, notPrint) >= 0 {
+			value = strconv.Quote(value)
+		}
+		fmt.Fprintf(&buf, "build\t%s=%s\n", key, value)
+	}
+
+	buildmode := cfg.BuildBuildmode
+	if buildmode == "default" {
+		buildmode = "exe"
+	}
+	add("-buildmode", buildmode)
+	add("-compiler", cfg.BuildToolchainName)
+	// The linker flags may name files, as in -extldflags,
+	// so leave them out when asked to trim file system paths.
+	if ldflags := load.BuildLdflags.For(p); len(ldflags) > 0 && !cfg.BuildTrimpath {
+		quoted := make([]string, len(ldflags))
+		for i, f := range ldflags {
+			if strings.ContainsAny(f, " \t\r\n'\"") {
+				f = strconv.Quote(f)
+			}
+			quoted[i] = f
+		}
+		add("-ldflags", strings.Join(quoted, " "))
+	}
+	// Record how modules were loaded: from the module cache or a
+	// vendor directory, whose contents the go command does not verify.
+	if cfg.BuildMod != "" {
+		add("-mod", cfg.BuildMod)
+	}
+	// Leave out the goexperiment tags added by BuildInit,
+	// which describe the toolchain rather than the build.
+	var tags []string
+	for _, tag := range cfg.BuildContext.BuildTags {
+		if !strings.HasPrefix(tag, "goexperiment.") {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > 0 {
+		add("-tags", strings.Join(tags, ","))
+	}
+	if cfg.BuildTrimpath {
+		add("-trimpath", "true")
+	}
+	cgo := "0"
+	if cfg.BuildContext.CgoEnabled {
+		cgo = "1"
+	}
+	add("CGO_ENABLED", cgo)
+	add("GOARCH", cfg.BuildContext.GOARCH)
+	add("GOOS", cfg.BuildContext.GOOS)
+
+	// Record the commit the main module was built from, if it is
+	// checked out with Git. Test binaries and the standard library
+	// are not stamped.
+	if p.Module != nil && p.Module.Main && p.Module.Dir != "" && !p.Standard && p.Internal.TestmainGo == nil {
+		if st := gitStatus(p.Module.Dir); st.revision != "" {
+			add("vcs", "git")
+			add("vcs.revision", st.revision)
+			add("vcs.time", st.time)
+			add("vcs.modified", strconv.FormatBool(st.modified))
+		}
+	}
+	return buf.String()
+}
+
+// A vcsStatus describes the state of a version control checkout.
+type vcsStatus struct {
+	revision string // commit hash of the checked-out revision
+	time     string // commit time, in RFC 3339 format
+	modified bool   // whether the working tree has uncommitted changes
+}
+
+var gitStatusCache par.Cache
+
+// gitStatus returns the status of the Git checkout containing dir.
+// If dir is not in a Git checkout, or git cannot be run, the status
+// has an empty revision.
+func gitStatus(dir string) vcsStatus {
+	return gitStatusCache.Do(dir, func() interface{} {
+		var st vcsStatus
+		out, err := runGit(dir, "-c", "log.showsignature=false", "show", "-s", "--format=%H:%ct")
+		if err != nil {
+			return st
+		}
+		i := strings.IndexByte(out, ':')
+		if i < 0 {
+			return st
+		}
+		sec, err := strconv.ParseInt(strings.TrimSpace(out[i+1:]), 10, 64)
+		if err != nil {
+			return st
+		}
+		status, err := runGit(dir, "status", "--porcelain")
+		if err != nil {
+			return st
+		}
+		st.revision = out[:i]
+		st.time = time.Unix(sec, 0).UTC().Format(time.RFC3339)
+		st.modified = status != ""
+		return st
+	}).(vcsStatus)
+}
+
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	return string(out), err
+}
+
 // buildActionID computes the action ID for a build action.
 func (b *Builder) buildActionID(a *Action) cache.ActionID {
 	p := a.Package
@@ -241,7 +371,7 @@ func (b *Builder) buildActionID(a *Action) cache.ActionID {
 	if p.Internal.CoverMode != "" {
 		fmt.Fprintf(h, "cover %q %q\n", p.Internal.CoverMode, b.toolID("cover"))
 	}
-	fmt.Fprintf(h, "modinfo %q\n", p.Internal.BuildInfo)
+	fmt.Fprintf(h, "modinfo %q\n", buildInfo(p))
 
 	// Configuration specific to compiler toolchain.
 	switch cfg.BuildToolchainName {
@@ -675,7 +805,7 @@ func (b *Builder) build(a *Action) (err error) {
 	}
 
 	if p.Internal.BuildInfo != "" && cfg.ModulesEnabled {
-		if err := b.writeFile(objdir+"_gomod_.go", load.ModInfoProg(p.Internal.BuildInfo, cfg.BuildToolchainName == "gccgo")); err != nil {
+		if err := b.writeFile(objdir+"_gomod_.go", load.ModInfoProg(buildInfo(p), cfg.BuildToolchainName == "gccgo")); err != nil {
 			return err
 		}
 		gofiles = append(gofiles, objdir+"_gomod_.go")
@@ -81,6 +81,11 @@ func (d *deadcodePass) init() {
 		if d.ctxt.BuildMode == BuildModePlugin {
 			names = append(names, objabi.PathToPrefix(*flagPluginPath)+"..inittask", objabi.PathToPrefix(*flagPluginPath)+".main", "go.plugin.tabs")
 
+			// Keep the module information, which buildinfo does not
+			// refer to in a plugin, so that the plugin file records it
+			// for plugin.(*Plugin).BuildInfo.
+			names = append(names, "runtime.modinfo")
+
 			// We don't keep the go.plugin.exports symbol,
 			// but we do keep the symbols it refers to.
 			exportsIdx := d.ldr.Lookup("go.plugin.exports", 0)
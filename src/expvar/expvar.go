@@ -29,6 +29,7 @@ import (
 	"net/http"
 	"os"
 	"runtime"
+	"runtime/debug"
 	"sort"
 	"strconv"
 	"strings"
@@ -274,13 +275,22 @@ var (
 // package's init function when it creates its Vars. If the name is already
 // registered then this will log.Panic.
 func Publish(name string, v Var) {
-	if _, dup := vars.LoadOrStore(name, v); dup {
+	if !publish(name, v) {
 		log.Panicln("Reuse of exported var name:", name)
 	}
+}
+
+// publish declares a named exported variable,
+// reporting false if the name is already registered.
+func publish(name string, v Var) bool {
+	if _, dup := vars.LoadOrStore(name, v); dup {
+		return false
+	}
 	varKeysMu.Lock()
 	defer varKeysMu.Unlock()
 	varKeys = append(varKeys, name)
 	sort.Strings(varKeys)
+	return true
 }
 
 // Get retrieves a named exported variable. It returns nil if the name has
@@ -350,6 +360,25 @@ func Handler() http.Handler {
 	return http.HandlerFunc(expvarHandler)
 }
 
+// PublishBuildInfo publishes the build information of the running
+// binary, as reported by runtime/debug.ReadBuildInfo, as the variable
+// named "buildinfo", so that a server exposing /debug/vars reports the
+// versions of the modules it was built from. The variable's value is
+// the JSON object written by runtime/debug.BuildInfo.MarshalJSON, or
+// null if the binary has no build information. PublishBuildInfo does
+// nothing if a variable named "buildinfo" is already published, so it
+// is safe to call more than once.
+func PublishBuildInfo() {
+	publish("buildinfo", Func(buildinfo))
+}
+
+func buildinfo() interface{} {
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		return bi
+	}
+	return nil
+}
+
 func cmdline() interface{} {
 	return os.Args
 }
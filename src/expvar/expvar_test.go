@@ -13,6 +13,7 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"runtime"
+	"runtime/debug"
 	"strconv"
 	"sync"
 	"sync/atomic"
@@ -442,6 +443,27 @@ func TestFunc(t *testing.T) {
 	}
 }
 
+func TestPublishBuildInfo(t *testing.T) {
+	RemoveAll()
+	PublishBuildInfo()
+	PublishBuildInfo()
+	v := Get("buildinfo")
+	if v == nil {
+		t.Fatal("PublishBuildInfo did not publish buildinfo")
+	}
+	want := "null"
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		js, err := bi.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = string(js)
+	}
+	if got := v.String(); got != want {
+		t.Errorf("buildinfo = %s, want %s", got, want)
+	}
+}
+
 func TestHandler(t *testing.T) {
 	RemoveAll()
 	m := NewMap("map1")
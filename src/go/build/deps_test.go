@@ -184,7 +184,6 @@ var depsRules = `
 	  mime/quotedprintable,
 	  net/internal/socktest,
 	  net/url,
-	  runtime/debug,
 	  runtime/trace,
 	  text/scanner,
 	  text/tabwriter;
@@ -299,9 +298,6 @@ var depsRules = `
 	# The prohibition stops at net and os/user.
 	C !< fmt, go/types, CRYPTO-MATH;
 
-	CGO, OS
-	< plugin;
-
 	CGO, FMT
 	< os/user
 	< archive/tar;
@@ -365,6 +361,15 @@ var depsRules = `
 
 	CGO, fmt, net !< CRYPTO;
 
+	# runtime/debug needs crypto/sha256 for BuildInfo.Hash, whose
+	# digests must resist collisions crafted by untrusted binaries,
+	# and compress/gzip for WriteHeapDumpTo.
+	FMT, crypto/sha256, compress/gzip
+	< runtime/debug;
+
+	CGO, runtime/debug
+	< plugin;
+
 	# CRYPTO-MATH is core bignum-based crypto - no cgo, net; fmt now ok.
 	CRYPTO, FMT, math/big
 	< crypto/rand
@@ -429,7 +434,7 @@ var depsRules = `
 
 	# HTTP-aware packages
 
-	encoding/json, net/http
+	encoding/json, net/http, runtime/debug
 	< expvar;
 
 	net/http
@@ -446,12 +451,18 @@ var depsRules = `
 	FMT, compress/gzip, encoding/binary, text/tabwriter
 	< runtime/pprof;
 
+	archive/zip, runtime/debug, runtime/pprof
+	< runtime/debug/bundle;
+
 	OS, compress/gzip, regexp
 	< internal/profile;
 
 	html/template, internal/profile, net/http, runtime/pprof, runtime/trace
 	< net/http/pprof;
 
+	net/http, runtime/debug
+	< net/http/buildinfo;
+
 	# RPC
 	encoding/gob, encoding/json, go/token, html/template, net/http
 	< net/rpc
@@ -0,0 +1,113 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package buildinfo serves via its HTTP server the build information of
+// the running binary, as reported by runtime/debug.ReadBuildInfo: the
+// versions of the modules the binary was built from and the settings of
+// the build.
+//
+// The package is typically only imported for the side effect of
+// registering its HTTP handler. The handled path is /debug/buildinfo.
+//
+// To use buildinfo, link this package into your program:
+//	import _ "net/http/buildinfo"
+//
+// If you are not using DefaultServeMux, you will have to register the
+// handler returned by Handler with the mux you are using.
+//
+// The build information is served as the JSON object written by
+// runtime/debug.BuildInfo.MarshalJSON, or, to clients that prefer
+// text/plain in their Accept header, in the text form written by
+// runtime/debug.BuildInfo.MarshalText, which is that printed by
+// "go version -m":
+//
+//	curl -H 'Accept: text/plain' http://localhost:6060/debug/buildinfo
+//
+package buildinfo
+
+import (
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	http.Handle("/debug/buildinfo", Handler())
+}
+
+// Handler returns an HTTP handler that serves the build information
+// of the running binary. It responds with 404 Not Found if the binary
+// has no build information.
+func Handler() http.Handler {
+	return http.HandlerFunc(serveBuildInfo)
+}
+
+func serveBuildInfo(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		http.Error(w, "no build information available", http.StatusNotFound)
+		return
+	}
+	var (
+		data []byte
+		err  error
+	)
+	if prefersText(r.Header.Get("Accept")) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		data, err = bi.MarshalText()
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		data, err = bi.MarshalJSONIndent("", "\t")
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Write(data)
+}
+
+// prefersText reports whether the Accept header accept gives text/plain
+// a higher quality than application/json. Without a preference, JSON
+// is served.
+func prefersText(accept string) bool {
+	text, json := quality(accept, "text", "plain"), quality(accept, "application", "json")
+	return text > 0 && text > json
+}
+
+// quality returns the quality the Accept header accept gives to the
+// media type typ/subtype, taken, as RFC 7231 says, from the most
+// specific media range that matches it. It returns 0 if none does.
+func quality(accept, typ, subtype string) float64 {
+	best, q := -1, 0.0
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		media := strings.ToLower(strings.TrimSpace(params[0]))
+		var specificity int
+		switch media {
+		case typ + "/" + subtype:
+			specificity = 2
+		case typ + "/*":
+			specificity = 1
+		case "*/*":
+			specificity = 0
+		default:
+			continue
+		}
+		if specificity <= best {
+			continue
+		}
+		best, q = specificity, 1
+		for _, p := range params[1:] {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				if f, err := strconv.ParseFloat(p[len("q="):], 64); err == nil {
+					q = f
+				}
+			}
+		}
+	}
+	return q
+}
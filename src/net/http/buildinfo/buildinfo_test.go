@@ -0,0 +1,79 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package buildinfo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"runtime/debug"
+	"testing"
+)
+
+func TestPrefersText(t *testing.T) {
+	for _, tt := range []struct {
+		accept string
+		text   bool
+	}{
+		{"", false},
+		{"*/*", false},
+		{"application/json", false},
+		{"text/plain", true},
+		{"TEXT/PLAIN; charset=utf-8", true},
+		{"text/*", true},
+		{"text/plain, application/json", false},
+		{"text/plain, application/json;q=0.9", true},
+		{"text/plain;q=0.5, application/*;q=0.8", false},
+		{"text/plain;q=0, */*", false},
+		{"text/html, text/plain;q=0.8, */*;q=0.1", true},
+		{"text/html", false},
+		{"image/png", false},
+	} {
+		if got := prefersText(tt.accept); got != tt.text {
+			t.Errorf("prefersText(%q) = %v, want %v", tt.accept, got, tt.text)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	for _, tt := range []struct {
+		accept, contentType string
+	}{
+		{"", "application/json"},
+		{"text/plain", "text/plain; charset=utf-8"},
+	} {
+		req := httptest.NewRequest("GET", "/debug/buildinfo", nil)
+		req.Header.Set("Accept", tt.accept)
+		w := httptest.NewRecorder()
+		Handler().ServeHTTP(w, req)
+
+		bi, ok := debug.ReadBuildInfo()
+		if !ok {
+			// Tests of the standard library run without build information.
+			if w.Code != http.StatusNotFound {
+				t.Errorf("status without build information = %d, want %d", w.Code, http.StatusNotFound)
+			}
+			return
+		}
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+		}
+		if got := w.Header().Get("Content-Type"); got != tt.contentType {
+			t.Errorf("Accept %q: Content-Type = %q, want %q", tt.accept, got, tt.contentType)
+		}
+		var got debug.BuildInfo
+		var err error
+		if tt.contentType == "application/json" {
+			err = got.UnmarshalJSON(w.Body.Bytes())
+		} else {
+			err = got.UnmarshalText(w.Body.Bytes())
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.String() != bi.String() {
+			t.Errorf("Accept %q: served\n%s\nwant\n%s", tt.accept, got.String(), bi.String())
+		}
+	}
+}
@@ -17,9 +17,12 @@
 // Please report any issues.
 package plugin
 
+import "runtime/debug"
+
 // Plugin is a loaded Go plugin.
 type Plugin struct {
 	pluginpath string
+	file       string        // absolute path of the shared object file
 	err        string        // set if plugin failed to load
 	loaded     chan struct{} // closed when loaded
 	syms       map[string]interface{}
@@ -32,6 +35,16 @@ func Open(path string) (*Plugin, error) {
 	return open(path)
 }
 
+// BuildInfo returns the build information embedded in the shared
+// object file from which p was loaded, when it was built in module
+// mode, so that a program can report the module versions of each of
+// its plugins as well as its own, as returned by debug.ReadBuildInfo.
+// The file is read again by each call; if it has been replaced since
+// p was opened, BuildInfo reports the build information of the new file.
+func (p *Plugin) BuildInfo() (*debug.BuildInfo, error) {
+	return debug.ReadBuildInfoFromFile(p.file)
+}
+
 // Lookup searches for a symbol named symName in plugin p.
 // A symbol is any exported variable or function.
 // It reports an error if the symbol is not found.
@@ -87,6 +87,7 @@ func open(name string) (*Plugin, error) {
 	// Drop a placeholder in the map so subsequent opens can wait on it.
 	p := &Plugin{
 		pluginpath: pluginpath,
+		file:       filepath,
 		loaded:     make(chan struct{}),
 	}
 	plugins[filepath] = p
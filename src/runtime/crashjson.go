@@ -0,0 +1,170 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package runtime
+
+import (
+	"runtime/internal/atomic"
+	_ "unsafe" // for go:linkname
+)
+
+// When runtime/debug.SetTracebackEncoding selects JSON, a crashing
+// program prints its panics, fatal error, signal, goroutine tracebacks,
+// and registers as JSON objects, one to a line, rather than as text, so
+// that log pipelines need not parse tracebacks spread over many lines.
+// Each object has a "type" field:
+//
+//	{"type":"panic","value":"boom","recovered":false}
+//	{"type":"fatal error","error":"all goroutines are asleep - deadlock!"}
+//	{"type":"signal","signal":"SIGSEGV: segmentation violation","code":"0x1","addr":"0x0","pc":"0x4a1c2f","m":0}
+//	{"type":"goroutine","goroutine":1,"state":"running","frames":[
+//		{"func":"main.main","file":"/home/gopher/x.go","line":12,"args":["0x1"],"offset":"0x1d"}],
+//		"created_by":{"func":"main.init.0","file":"/home/gopher/x.go","line":8,"offset":"0x25"}}
+//	{"type":"registers","m":0,"rax":"0x0","rbx":"0xc000010000",...}
+//
+// (The goroutine is shown over three lines here, for legibility.)
+// A signal object always has these fields, with code, addr, and pc as
+// hexadecimal strings; for an exception on Windows, the signal is
+// "Exception" followed by the exception code, and code and addr are the
+// first two words of the exception information. A signal that arrived
+// during cgo execution adds "cgo":true, and a SIGILL adds
+// "instruction_bytes".
+// The format of the frames follows runtime/debug.SetStackFormat.
+// The messages the runtime prints about its own state before
+// throwing, such as "runtime: bad pointer in frame", remain text,
+// as does the output of tracebacks requested other than by a crash,
+// such as by runtime.Stack.
+//
+// Strings are printed as text is, with the output escaped by gwrite
+// while m.printjson is set, so that the printing of panic values and
+// the like need not be duplicated.
+
+// Values of tracebackEncoding.
+const (
+	tracebackEncodingText = iota
+	tracebackEncodingJSON
+)
+
+// tracebackEncoding is the encoding of crash output, as set by
+// runtime/debug.SetTracebackEncoding. It is accessed atomically.
+var tracebackEncoding uint32
+
+//go:linkname setTracebackEncoding runtime/debug.setTracebackEncoding
+func setTracebackEncoding(enc uint32) {
+	atomic.Store(&tracebackEncoding, enc)
+}
+
+// crashJSON reports whether crash output is being printed as JSON:
+// whether JSON has been selected and the current M is crashing.
+//go:nosplit
+func crashJSON() bool {
+	mp := getg().m
+	return atomic.Load(&tracebackEncoding) == tracebackEncodingJSON && (mp.throwing > 0 || mp.dying > 0)
+}
+
+// printJSONString prints s as a JSON string.
+func printJSONString(s string) {
+	mp := getg().m
+	print(`"`)
+	mp.printjson = true
+	print(s)
+	mp.printjson = false
+	print(`"`)
+}
+
+// printJSONValue prints v, as printany would, as a JSON string.
+func printJSONValue(v interface{}) {
+	mp := getg().m
+	print(`"`)
+	mp.printjson = true
+	printany(v)
+	mp.printjson = false
+	print(`"`)
+}
+
+// gwriteJSON writes b, escaped as the contents of a JSON string.
+// Invalid UTF-8 is written as is, for decoders to replace.
+func gwriteJSON(b []byte) {
+	const hexdigits = "0123456789abcdef"
+	mp := getg().m
+	mp.printjson = false
+	start := 0
+	for i, c := range b {
+		if c >= ' ' && c != '"' && c != '\\' {
+			continue
+		}
+		gwrite(b[start:i])
+		switch c {
+		case '"':
+			gwrite(bytes(`\"`))
+		case '\\':
+			gwrite(bytes(`\\`))
+		case '\n':
+			gwrite(bytes(`\n`))
+		case '\t':
+			gwrite(bytes(`\t`))
+		default:
+			gwrite(bytes(`\u00`))
+			gwrite(bytes(hexdigits[c>>4 : c>>4+1]))
+			gwrite(bytes(hexdigits[c&0xf : c&0xf+1]))
+		}
+		start = i + 1
+	}
+	gwrite(b[start:])
+	mp.printjson = true
+}
+
+// printFramesJSON begins the JSON array of the frames of a traceback,
+// as the "frames" field of the object being printed.
+func printFramesJSON() {
+	print(`,"frames":[`)
+	getg().m.printjsonsep = false
+}
+
+// printFrameJSON begins a JSON object for a frame, in the array begun
+// by printFramesJSON, leaving the caller to add fields and end it.
+// If file is empty, the object has neither file nor line.
+func printFrameJSON(name, file string, line int) {
+	mp := getg().m
+	if mp.printjsonsep {
+		print(",")
+	}
+	mp.printjsonsep = true
+	print(`{"func":`)
+	printJSONString(name)
+	if file != "" {
+		print(`,"file":`)
+		printJSONString(file)
+		print(`,"line":`, line)
+	}
+}
+
+// printpanicsJSON prints the object of each panic in the chain p,
+// the earliest first, as printpanics prints their lines.
+func printpanicsJSON(p *_panic) {
+	if p.link != nil {
+		printpanicsJSON(p.link)
+	}
+	if p.goexit {
+		return
+	}
+	print(`{"type":"panic","value":`)
+	printJSONValue(p.arg)
+	print(`,"recovered":`, p.recovered, "}\n")
+}
+
+// printreg prints a register for dumpregs: in text, name, which is
+// padded to align the values, then v and end; in JSON, as a field of
+// the registers object.
+func printreg(name string, v hex, end string) {
+	if crashJSON() {
+		n := 0
+		for n < len(name) && name[n] != ' ' {
+			n++
+		}
+		print(`,"`, name[:n], `":"`, v, `"`)
+		return
+	}
+	print(name, v, end)
+}
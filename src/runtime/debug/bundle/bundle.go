@@ -0,0 +1,232 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package bundle writes diagnostics bundles: zip archives of the state
+// of the running program, for a program's users to send to those
+// supporting it.
+//
+// A program typically writes a bundle on request, from a command-line
+// flag or an HTTP handler:
+//
+//	f, err := os.Create("diagnostics.zip")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	if err := bundle.Write(f, bundle.Options{CPUProfile: 10 * time.Second}); err != nil {
+//		log.Fatal(err)
+//	}
+//	if err := f.Close(); err != nil {
+//		log.Fatal(err)
+//	}
+//
+package bundle
+
+import (
+	"archive/zip"
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Options controls the contents of the bundle written by Write.
+type Options struct {
+	// CPUProfile is how long to profile the CPU for.
+	// If it is zero, the bundle holds no CPU profile.
+	CPUProfile time.Duration
+
+	// Stacks selects the goroutines whose stacks are written.
+	Stacks debug.StackOptions
+}
+
+// Write writes to w a zip archive of diagnostics of the running
+// program. The archive holds these files:
+//
+//	buildinfo.json  the build information, as debug.BuildInfo.MarshalJSON writes it
+//	goroutines.txt  the stacks of the goroutines selected by opts, as debug.AllStacks reports them
+//	heap.pb.gz      a heap profile, as pprof.WriteHeapProfile writes it
+//	cpu.pb.gz       a CPU profile covering opts.CPUProfile, if it is positive
+//	gcstats.txt     garbage collection statistics
+//	memstats.txt    memory statistics
+//	env.txt         the runtime's settings and the environment variables beginning with GO
+//
+// buildinfo.json is left out if the build information is not available.
+// Other environment variables are left out, as they may hold secrets.
+//
+// The CPU profile is taken first, so Write blocks for opts.CPUProfile
+// before writing anything else. If CPU profiling is already enabled, as
+// by pprof.StartCPUProfile, Write returns that error. Write returns the
+// first error writing to w, after which the archive is incomplete.
+func Write(w io.Writer, opts Options) error {
+	zw := zip.NewWriter(w)
+	now := time.Now()
+	create := func(name string) (io.Writer, error) {
+		return zw.CreateHeader(&zip.FileHeader{
+			Name:     name,
+			Method:   zip.Deflate,
+			Modified: now,
+		})
+	}
+	text := func(name string, write func(*bufio.Writer)) error {
+		f, err := create(name)
+		if err != nil {
+			return err
+		}
+		b := bufio.NewWriter(f)
+		write(b)
+		return b.Flush()
+	}
+
+	if opts.CPUProfile > 0 {
+		f, err := create("cpu.pb.gz")
+		if err != nil {
+			return err
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			return err
+		}
+		time.Sleep(opts.CPUProfile)
+		pprof.StopCPUProfile()
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		f, err := create("buildinfo.json")
+		if err != nil {
+			return err
+		}
+		js, err := bi.MarshalJSONIndent("", "\t")
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(append(js, '\n')); err != nil {
+			return err
+		}
+	}
+	if err := text("goroutines.txt", func(b *bufio.Writer) { writeGoroutines(b, opts.Stacks) }); err != nil {
+		return err
+	}
+	f, err := create("heap.pb.gz")
+	if err != nil {
+		return err
+	}
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		return err
+	}
+	if err := text("gcstats.txt", writeGCStats); err != nil {
+		return err
+	}
+	if err := text("memstats.txt", writeMemStats); err != nil {
+		return err
+	}
+	if err := text("env.txt", writeEnv); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// writeGoroutines writes to b the stacks of the goroutines selected
+// by opts, separated by blank lines, each in the form printed by
+// debug.FprintStack, with the goroutine's state and creator as
+// debug.Stack prints them.
+func writeGoroutines(b *bufio.Writer, opts debug.StackOptions) {
+	for i, g := range debug.AllStacks(opts) {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(b, "goroutine %d [%s", g.ID, g.State)
+		if min := int64(g.WaitDuration / time.Minute); min >= 1 {
+			fmt.Fprintf(b, ", %d minutes", min)
+		}
+		b.WriteString("]:\n")
+		for _, f := range g.Frames {
+			writeFrame(b, f)
+		}
+		if f := g.CreatedBy; f.Func != "" {
+			b.WriteString("created by ")
+			writeFrame(b, f)
+		}
+	}
+}
+
+func writeFrame(b *bufio.Writer, f debug.StackFrame) {
+	b.WriteString(f.Func)
+	b.WriteString("(...)\n\t")
+	b.WriteString(f.File)
+	b.WriteByte(':')
+	b.WriteString(strconv.Itoa(f.Line))
+	b.WriteByte('\n')
+}
+
+// writeGCStats writes to b the garbage collection statistics
+// and settings, one "name = value" line each.
+func writeGCStats(b *bufio.Writer) {
+	var stats debug.GCStats
+	debug.ReadGCStats(&stats)
+	fmt.Fprintf(b, "NumGC = %d\n", stats.NumGC)
+	if stats.NumGC > 0 {
+		fmt.Fprintf(b, "LastGC = %s\n", stats.LastGC.Format(time.RFC3339Nano))
+	}
+	fmt.Fprintf(b, "PauseTotal = %v\n", stats.PauseTotal)
+	fmt.Fprintf(b, "GCPercent = %d\n", debug.GCPercent())
+}
+
+// writeMemStats writes to b the principal memory statistics,
+// one "name = value" line each.
+func writeMemStats(b *bufio.Writer) {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	fmt.Fprintf(b, "Alloc = %d\n", m.Alloc)
+	fmt.Fprintf(b, "TotalAlloc = %d\n", m.TotalAlloc)
+	fmt.Fprintf(b, "Sys = %d\n", m.Sys)
+	fmt.Fprintf(b, "Mallocs = %d\n", m.Mallocs)
+	fmt.Fprintf(b, "Frees = %d\n", m.Frees)
+	fmt.Fprintf(b, "HeapAlloc = %d\n", m.HeapAlloc)
+	fmt.Fprintf(b, "HeapSys = %d\n", m.HeapSys)
+	fmt.Fprintf(b, "HeapIdle = %d\n", m.HeapIdle)
+	fmt.Fprintf(b, "HeapInuse = %d\n", m.HeapInuse)
+	fmt.Fprintf(b, "HeapReleased = %d\n", m.HeapReleased)
+	fmt.Fprintf(b, "HeapObjects = %d\n", m.HeapObjects)
+	fmt.Fprintf(b, "StackInuse = %d\n", m.StackInuse)
+	fmt.Fprintf(b, "StackSys = %d\n", m.StackSys)
+	fmt.Fprintf(b, "NextGC = %d\n", m.NextGC)
+	fmt.Fprintf(b, "NumGoroutine = %d\n", runtime.NumGoroutine())
+}
+
+// writeEnv writes to b the runtime's settings and the environment
+// variables beginning with GO, such as GOGC and GODEBUG, which
+// affect it.
+func writeEnv(b *bufio.Writer) {
+	fmt.Fprintf(b, "Version = %s\n", runtime.Version())
+	fmt.Fprintf(b, "GOOS = %s\n", runtime.GOOS)
+	fmt.Fprintf(b, "GOARCH = %s\n", runtime.GOARCH)
+	fmt.Fprintf(b, "NumCPU = %d\n", runtime.NumCPU())
+	fmt.Fprintf(b, "GOMAXPROCS = %d\n", runtime.GOMAXPROCS(0))
+	fmt.Fprintf(b, "GCPercent = %d\n", debug.GCPercent())
+	fmt.Fprintf(b, "MemoryLimit = %d\n", debug.SetMemoryLimit(-1))
+	fmt.Fprintf(b, "MaxStack = %d\n", debug.MaxStack())
+	fmt.Fprintf(b, "MaxThreads = %d\n", debug.MaxThreads())
+	if exe, err := os.Executable(); err == nil {
+		fmt.Fprintf(b, "Executable = %s\n", exe)
+	}
+
+	var env []string
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, "GO") {
+			env = append(env, kv)
+		}
+	}
+	sort.Strings(env)
+	b.WriteString("\n")
+	for _, kv := range env {
+		b.WriteString(kv)
+		b.WriteByte('\n')
+	}
+}
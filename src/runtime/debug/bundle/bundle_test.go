@@ -0,0 +1,76 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bundle_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"runtime/debug"
+	. "runtime/debug/bundle"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, Options{CPUProfile: 10 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("reading %s: %v", f.Name, err)
+		}
+		files[f.Name] = string(data)
+	}
+	for name, want := range map[string]string{
+		"goroutines.txt": "runtime/debug/bundle_test.TestWrite(...)\n\t",
+		"heap.pb.gz":     "\x1f\x8b",
+		"cpu.pb.gz":      "\x1f\x8b",
+		"gcstats.txt":    "NumGC = ",
+		"memstats.txt":   "HeapAlloc = ",
+		"env.txt":        "GOMAXPROCS = ",
+	} {
+		data, ok := files[name]
+		if !ok {
+			t.Errorf("bundle has no %s", name)
+		} else if !strings.Contains(data, want) {
+			t.Errorf("%s does not contain %q:\n%s", name, want, data)
+		}
+	}
+
+	// The test binary has no build information.
+	if _, ok := debug.ReadBuildInfo(); !ok {
+		if _, ok := files["buildinfo.json"]; ok {
+			t.Errorf("bundle has buildinfo.json without build information")
+		}
+	}
+
+	buf.Reset()
+	if err := Write(&buf, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	zr, err = zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range zr.File {
+		if f.Name == "cpu.pb.gz" {
+			t.Errorf("bundle has a CPU profile without Options.CPUProfile")
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"encoding/json"
+	"internal/testenv"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	. "runtime/debug"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// raiseAccessViolation raises an access violation outside Go code,
+// which the runtime reports as an exception rather than a panic.
+func raiseAccessViolation() {
+	const EXCEPTION_NONCONTINUABLE = 1
+	proc := syscall.MustLoadDLL("kernel32.dll").MustFindProc("RaiseException")
+	proc.Call(0xc0000005, EXCEPTION_NONCONTINUABLE, 0, 0)
+}
+
+func TestTracebackEncodingException(t *testing.T) {
+	if os.Getenv("GO_RUNTIME_DEBUG_EXCEPTION_JSON") != "" {
+		// In the child: die of an exception with JSON output.
+		SetTracebackEncoding(TracebackJSON)
+		raiseAccessViolation()
+		return
+	}
+	testenv.MustHaveExec(t)
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestTracebackEncodingException$")
+	cmd.Env = append(os.Environ(), "GO_RUNTIME_DEBUG_EXCEPTION_JSON=1")
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("child did not crash; output:\n%s", out)
+	}
+	var signal, registers bool
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if strings.HasPrefix(line, "Exception ") || strings.HasPrefix(line, "PC=") {
+			t.Errorf("text line %q; output:\n%s", line, out)
+		}
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+		var obj map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &obj); err != nil {
+			t.Fatalf("line %q is not JSON: %v; output:\n%s", line, err, out)
+		}
+		switch obj["type"] {
+		case "signal":
+			signal = true
+			if obj["signal"] != "Exception 0xc0000005" {
+				t.Errorf("signal object %v, want signal %q", obj, "Exception 0xc0000005")
+			}
+			for _, field := range []string{"code", "addr", "pc"} {
+				if s, _ := obj[field].(string); !strings.HasPrefix(s, "0x") {
+					t.Errorf("signal object %v: %s is not a hexadecimal string", obj, field)
+				}
+			}
+			if _, ok := obj["m"].(float64); !ok {
+				t.Errorf("signal object %v has no m", obj)
+			}
+		case "registers":
+			registers = true
+		}
+	}
+	if !signal || !registers {
+		t.Errorf("found signal object %v, registers object %v; output:\n%s", signal, registers, out)
+	}
+}
+
+func TestSetCrashOutputException(t *testing.T) {
+	if out := os.Getenv("GO_RUNTIME_DEBUG_EXCEPTION_OUTPUT"); out != "" {
+		// In the child: die of an exception with the output copied to the file.
+		f, err := os.Create(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := SetCrashOutput(f, CrashOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		f.Close() // SetCrashOutput holds its own descriptor
+		raiseAccessViolation()
+		return
+	}
+	testenv.MustHaveExec(t)
+
+	dir, err := ioutil.TempDir("", "crash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	out := filepath.Join(dir, "crash.txt")
+	cmd := exec.Command(os.Args[0], "-test.run=^TestSetCrashOutputException$")
+	cmd.Env = append(os.Environ(), "GO_RUNTIME_DEBUG_EXCEPTION_OUTPUT="+out)
+	stderr, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("child did not crash; output:\n%s", stderr)
+	}
+	crash, err := ioutil.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Exception 0xc0000005", "PC=", "goroutine ", "TestSetCrashOutputException"} {
+		if !strings.Contains(string(crash), want) {
+			t.Errorf("crash output does not contain %q:\n%s", want, crash)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DumpOptions controls the diagnostics dumps written by EnableSignalDump.
+// The zero DumpOptions writes the stacks of every goroutine.
+type DumpOptions struct {
+	// Stacks selects the goroutines whose stacks are written.
+	Stacks StackOptions
+}
+
+// dumpLock serializes the writing of dumps.
+var dumpLock sync.Mutex
+
+// EnableSignalDump arranges for the program, on each receipt of a
+// signal on c, to write a diagnostics dump to w and carry on running,
+// much as a Java virtual machine writes a thread dump on SIGQUIT. The
+// dump holds the stacks of the goroutines selected by opts, in the form
+// printed by FprintStack, followed by the program's build information,
+// garbage collection statistics, and memory statistics. Each dump is
+// written with a single call to w.Write; errors writing it are ignored.
+//
+// The caller asks package os/signal to deliver the signals on c, which
+// keeps this package from depending on os/signal:
+//
+//	c := make(chan os.Signal, 1)
+//	signal.Notify(c, syscall.SIGQUIT)
+//	debug.EnableSignalDump(c, os.Stderr, debug.DumpOptions{})
+//
+// Dumping stops when c is closed. Since the default behavior of SIGQUIT
+// is to print the stacks of all goroutines and exit, dumping on SIGQUIT
+// changes what "kill -QUIT" does, until the caller calls signal.Stop(c).
+func EnableSignalDump(c <-chan os.Signal, w io.Writer, opts DumpOptions) {
+	go func() {
+		for range c {
+			writeDump(w, opts)
+		}
+	}()
+}
+
+// writeDump writes a diagnostics dump to w.
+func writeDump(w io.Writer, opts DumpOptions) {
+	var buf bytes.Buffer
+	b := bufio.NewWriter(&buf)
+	fmt.Fprintf(b, "=== diagnostics dump of process %d at %s ===\n", os.Getpid(), time.Now().Format(time.RFC3339))
+
+	b.WriteString("\n--- goroutines ---\n")
+	writeGoroutines(b, opts.Stacks)
+
+	b.WriteString("\n--- build info ---\n")
+	if bi, ok := ReadBuildInfo(); ok {
+		b.WriteString(bi.String())
+	} else {
+		b.WriteString("not available\n")
+	}
+
+	b.WriteString("\n--- gc stats ---\n")
+	writeGCStats(b)
+
+	b.WriteString("\n--- mem stats ---\n")
+	writeMemStats(b)
+	b.Flush()
+
+	dumpLock.Lock()
+	defer dumpLock.Unlock()
+	w.Write(buf.Bytes())
+}
+
+// writeGoroutines writes to b the stacks of the goroutines selected
+// by opts, separated by blank lines.
+func writeGoroutines(b *bufio.Writer, opts StackOptions) {
+	for i, g := range allStacks(opts) {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		writeGoroutine(b, g)
+	}
+}
+
+// writeGoroutine writes the stack of g to b in the form printed by
+// fprintStack, with the goroutine's state and creator as Stack prints them.
+func writeGoroutine(b *bufio.Writer, g GoroutineStack) {
+	b.WriteString("goroutine ")
+	b.WriteString(strconv.FormatInt(g.ID, 10))
+	b.WriteString(" [")
+	b.WriteString(g.State)
+	if min := int64(g.WaitDuration / time.Minute); min >= 1 {
+		b.WriteString(", ")
+		b.WriteString(strconv.FormatInt(min, 10))
+		b.WriteString(" minutes")
+	}
+	b.WriteString("]:\n")
+	for _, f := range g.Frames {
+		writeFrame(b, f)
+	}
+	if f := g.CreatedBy; f.Func != "" {
+		b.WriteString("created by ")
+		writeFrame(b, f)
+	}
+}
+
+// writeGCStats writes to b the garbage collection statistics
+// and settings, one "name = value" line each.
+func writeGCStats(b *bufio.Writer) {
+	var stats GCStats
+	ReadGCStats(&stats)
+	fmt.Fprintf(b, "NumGC = %d\n", stats.NumGC)
+	if stats.NumGC > 0 {
+		fmt.Fprintf(b, "LastGC = %s\n", stats.LastGC.Format(time.RFC3339Nano))
+	}
+	fmt.Fprintf(b, "PauseTotal = %v\n", stats.PauseTotal)
+	fmt.Fprintf(b, "GCPercent = %d\n", GCPercent())
+}
+
+// writeMemStats writes to b the principal memory statistics,
+// one "name = value" line each.
+func writeMemStats(b *bufio.Writer) {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	fmt.Fprintf(b, "Alloc = %d\n", m.Alloc)
+	fmt.Fprintf(b, "TotalAlloc = %d\n", m.TotalAlloc)
+	fmt.Fprintf(b, "Sys = %d\n", m.Sys)
+	fmt.Fprintf(b, "Mallocs = %d\n", m.Mallocs)
+	fmt.Fprintf(b, "Frees = %d\n", m.Frees)
+	fmt.Fprintf(b, "HeapAlloc = %d\n", m.HeapAlloc)
+	fmt.Fprintf(b, "HeapSys = %d\n", m.HeapSys)
+	fmt.Fprintf(b, "HeapIdle = %d\n", m.HeapIdle)
+	fmt.Fprintf(b, "HeapInuse = %d\n", m.HeapInuse)
+	fmt.Fprintf(b, "HeapReleased = %d\n", m.HeapReleased)
+	fmt.Fprintf(b, "HeapObjects = %d\n", m.HeapObjects)
+	fmt.Fprintf(b, "StackInuse = %d\n", m.StackInuse)
+	fmt.Fprintf(b, "StackSys = %d\n", m.StackSys)
+	fmt.Fprintf(b, "NextGC = %d\n", m.NextGC)
+	fmt.Fprintf(b, "NumGoroutine = %d\n", runtime.NumGoroutine())
+}
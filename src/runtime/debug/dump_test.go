@@ -0,0 +1,62 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"os"
+	"os/signal"
+	"runtime"
+	. "runtime/debug"
+	"strings"
+	"testing"
+	"time"
+)
+
+// chanWriter sends each write to a channel.
+type chanWriter chan string
+
+func (w chanWriter) Write(b []byte) (int, error) {
+	w <- string(b)
+	return len(b), nil
+}
+
+func TestEnableSignalDump(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "plan9" {
+		t.Skipf("cannot send os.Interrupt to self on %s", runtime.GOOS)
+	}
+	w := make(chanWriter, 1)
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt)
+	defer close(c)
+	defer signal.Stop(c)
+	EnableSignalDump(c, w, DumpOptions{})
+
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Signal(os.Interrupt); err != nil {
+		t.Fatal(err)
+	}
+	var dump string
+	select {
+	case dump = <-w:
+	case <-time.After(30 * time.Second):
+		t.Fatal("no dump written after signal")
+	}
+	for _, want := range []string{
+		"=== diagnostics dump of process ",
+		"\n--- goroutines ---\ngoroutine ",
+		"runtime/debug_test.TestEnableSignalDump(...)\n\t",
+		"\ncreated by testing.(*T).Run(...)\n\t",
+		"\n--- build info ---\n",
+		"\n--- gc stats ---\nNumGC = ",
+		"\n--- mem stats ---\nAlloc = ",
+	} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("dump does not contain %q:\n%s", want, dump)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+var (
+	ReadBuildInfoData     = readBuildInfo
+	ReadBuildInfoErrData  = readBuildInfoErr
+	ReadMainModuleData    = readMainModule
+	ReadBuildInfoLazyData = readBuildInfoLazy
+	BuildInfoMetricOf     = buildInfoMetric
+	BuildInfoSummary      = buildInfoSummary
+	RawBuildInfoData      = rawBuildInfo
+	MappedImages          = mappedImages
+	ModuleSizesOf         = moduleSizes
+	SymbolModule          = symbolModule
+	EnforcePolicyOn       = enforcePolicy
+	ParseCgroupInt        = parseCgroupInt
+	WatchBuildInfoWith    = watchBuildInfo
+)
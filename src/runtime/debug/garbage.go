@@ -5,8 +5,16 @@
 package debug
 
 import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
 	"runtime"
 	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -92,6 +100,48 @@ func SetGCPercent(percent int) int {
 	return int(setGCPercent(int32(percent)))
 }
 
+// GCPercent returns the garbage collection target percentage set by
+// SetGCPercent, without changing it.
+func GCPercent() int {
+	return int(getGCPercent())
+}
+
+// SetMemoryLimit sets a soft limit on the amount of memory the runtime
+// uses, in bytes: the garbage collector runs as often as needed to keep
+// the heap, together with the memory the runtime maps for other
+// purposes, such as goroutine stacks and its own metadata, within the
+// limit. The limit complements SetGCPercent, which otherwise paces
+// collection; it lets a program running in a container target the
+// memory available to it, even with garbage collection disabled by a
+// negative percentage, in which case the limit alone triggers
+// collection. The limit is soft: the heap may grow past it if the live
+// heap itself approaches the limit, rather than slow the program to a
+// crawl with continuous collection. The limit does not cover memory
+// outside the runtime's control, such as that allocated by C code.
+//
+// SetMemoryLimit returns the previous setting. A negative limit leaves
+// the setting unchanged, so SetMemoryLimit(-1) reports the current limit.
+// The initial setting is math.MaxInt64, which means no limit.
+func SetMemoryLimit(limit int64) int64 {
+	return setMemoryLimit(limit)
+}
+
+// SetHeapTarget sets a minimum goal for the size of the heap, in bytes:
+// the garbage collector lets the heap grow to at least this size before
+// completing a collection, however small the live heap, so that a
+// program with a small live heap and a high rate of allocation does not
+// collect continuously. It replaces the common workaround of allocating
+// a large, never used ballast slice to inflate the live heap. Above the
+// target, SetGCPercent paces collection as usual. The target applies
+// only while garbage collection is enabled, and the memory limit set by
+// SetMemoryLimit takes precedence over it, so a target above the limit
+// has no effect. A target of zero, the initial setting, means no minimum.
+//
+// SetHeapTarget returns the previous setting.
+func SetHeapTarget(bytes uint64) uint64 {
+	return setHeapTarget(bytes)
+}
+
 // FreeOSMemory forces a garbage collection followed by an
 // attempt to return as much memory to the operating system
 // as possible. (Even if this is not called, the runtime gradually
@@ -100,6 +150,37 @@ func FreeOSMemory() {
 	freeOSMemory()
 }
 
+// FreeOSMemoryTo returns free memory to the operating system until the
+// memory the heap retains, as runtime.MemStats.HeapSys less HeapReleased,
+// is at most targetBytes, or no free memory remains to be returned.
+// Unlike FreeOSMemory, it does not force a garbage collection, so memory
+// held by unreachable objects not yet collected is not returned; call
+// runtime.GC first to return that too.
+func FreeOSMemoryTo(targetBytes uint64) {
+	freeOSMemoryTo(targetBytes, ^uintptr(0), true)
+}
+
+// The amount of memory FreeOSMemoryToContext returns to the operating
+// system at a time.
+const scavengeStep = 4 << 20
+
+// FreeOSMemoryToContext is like FreeOSMemoryTo but returns memory a
+// little at a time, letting other goroutines run in between, and stops
+// early if ctx is done, returning ctx.Err(). It is meant to run in a
+// goroutine of its own while the program goes about its work.
+func FreeOSMemoryToContext(ctx context.Context, targetBytes uint64) error {
+	for restart := true; ; restart = false {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		released, retained := freeOSMemoryTo(targetBytes, scavengeStep, restart)
+		if released == 0 || retained <= targetBytes {
+			return nil
+		}
+		runtime.Gosched()
+	}
+}
+
 // SetMaxStack sets the maximum amount of memory that
 // can be used by a single goroutine stack.
 // If any goroutine exceeds this limit while growing its stack,
@@ -114,6 +195,12 @@ func SetMaxStack(bytes int) int {
 	return setMaxStack(bytes)
 }
 
+// MaxStack returns the maximum stack size set by SetMaxStack,
+// without changing it.
+func MaxStack() int {
+	return getMaxStack()
+}
+
 // SetMaxThreads sets the maximum number of operating system
 // threads that the Go program can use. If it attempts to use more than
 // this many, the program crashes.
@@ -132,6 +219,83 @@ func SetMaxThreads(threads int) int {
 	return setMaxThreads(threads)
 }
 
+// MaxThreads returns the maximum number of threads set by
+// SetMaxThreads, without changing it.
+func MaxThreads() int {
+	return getMaxThreads()
+}
+
+// A LimitKind names a limit whose violation makes the program crash.
+type LimitKind int
+
+const (
+	StackLimit  LimitKind = 1 + iota // the limit set by SetMaxStack
+	ThreadLimit                      // the limit set by SetMaxThreads
+)
+
+// A LimitEvent describes the violation of a limit.
+type LimitEvent struct {
+	Kind LimitKind
+
+	// Limit is the limit: a number of bytes for StackLimit,
+	// and of threads for ThreadLimit.
+	Limit uint64
+
+	// Value is the size in bytes the stack needed to grow to,
+	// or the number of threads the program needed.
+	Value uint64
+
+	// GoroutineID is the ID of the goroutine whose stack
+	// exceeded the limit, or 0 for ThreadLimit.
+	GoroutineID int64
+}
+
+// SetLimitExceededHandler arranges for f to be called when the program
+// exceeds the limit set by SetMaxStack or SetMaxThreads, before the
+// runtime reports the violation and aborts, so that the program may
+// record why it died. A nil f removes the handler.
+//
+// The call is best effort. f runs on a goroutine of its own, which the
+// first call to SetLimitExceededHandler with a non-nil f starts and
+// which then waits, on a thread of its own, for a limit to be exceeded.
+// It runs only while another thread can run it: the goroutine that
+// exceeded the limit does not yield its processor, so with GOMAXPROCS
+// set to 1, f does not run. The runtime waits for f for at most a
+// second. f runs at most once, and must not itself grow its stack or
+// create threads without bound.
+func SetLimitExceededHandler(f func(LimitEvent)) {
+	limitHandler.Store(limitHandlerFunc{f})
+	if f == nil {
+		setLimitHandler(false)
+		return
+	}
+	limitHandlerOnce.Do(func() { go runLimitHandler() })
+	setLimitHandler(true)
+}
+
+var (
+	// limitHandler holds a limitHandlerFunc with the handler set by
+	// SetLimitExceededHandler.
+	limitHandler atomic.Value
+
+	// limitHandlerOnce starts runLimitHandler.
+	limitHandlerOnce sync.Once
+)
+
+// A limitHandlerFunc holds a handler, as an atomic.Value must hold
+// values of a single concrete type.
+type limitHandlerFunc struct{ f func(LimitEvent) }
+
+// runLimitHandler waits for the runtime to report that a limit has been
+// exceeded and calls the handler set by SetLimitExceededHandler.
+func runLimitHandler() {
+	kind, limit, value, goid := waitLimitEvent()
+	defer fatalHandlerDone()
+	if h, _ := limitHandler.Load().(limitHandlerFunc); h.f != nil {
+		h.f(LimitEvent{Kind: LimitKind(kind), Limit: limit, Value: value, GoroutineID: goid})
+	}
+}
+
 // SetPanicOnFault controls the runtime's behavior when a program faults
 // at an unexpected (non-nil) address. Such faults are typically caused by
 // bugs such as runtime memory corruption, so the default response is to crash
@@ -145,6 +309,19 @@ func SetPanicOnFault(enabled bool) bool {
 	return setPanicOnFault(enabled)
 }
 
+// InheritPanicOnFault controls whether the goroutines started by the
+// current goroutine inherit its SetPanicOnFault setting, as it is when
+// each starts. Such goroutines inherit the InheritPanicOnFault setting
+// too, and so pass the SetPanicOnFault setting on to those they start
+// in turn. A library working with memory-mapped files may thus protect
+// all its worker goroutines by calling SetPanicOnFault(true) and
+// InheritPanicOnFault(true) before starting them, and restoring both
+// settings afterward; goroutines already running are unaffected.
+// It returns the previous setting.
+func InheritPanicOnFault(enabled bool) bool {
+	return setInheritFault(enabled)
+}
+
 // WriteHeapDump writes a description of the heap and the objects in
 // it to the given file descriptor.
 //
@@ -156,6 +333,53 @@ func SetPanicOnFault(enabled bool) bool {
 // The heap dump format is defined at https://golang.org/s/go15heapdump.
 func WriteHeapDump(fd uintptr)
 
+// HeapDumpOptions holds options for WriteHeapDumpTo.
+type HeapDumpOptions struct {
+	Gzip bool // compress the dump with gzip
+}
+
+// heapDumpHeader begins every heap dump.
+const heapDumpHeader = "go1.7 heap dump\n"
+
+// WriteHeapDumpTo writes the heap dump written by WriteHeapDump to w,
+// compressed with gzip if opts.Gzip is set, and returns any error.
+// As the runtime cannot write to w with all goroutines suspended,
+// WriteHeapDumpTo first writes the dump to a temporary file, which
+// it removes when done, so the dump takes up space in the temporary
+// directory for a while, and w may be a pipe read by the same process.
+func WriteHeapDumpTo(w io.Writer, opts HeapDumpOptions) error {
+	f, err := ioutil.TempFile("", "heapdump")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	WriteHeapDump(f.Fd())
+	// The runtime does not report write errors, so check
+	// at least that the dump is there.
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	hdr := make([]byte, len(heapDumpHeader))
+	if _, err := io.ReadFull(f, hdr); err != nil || string(hdr) != heapDumpHeader {
+		return errors.New("runtime/debug: heap dump not written to temporary file " + f.Name())
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	if !opts.Gzip {
+		_, err = io.Copy(w, f)
+		return err
+	}
+	zw := gzip.NewWriter(w)
+	if _, err := io.Copy(zw, f); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
 // SetTraceback sets the amount of detail printed by the runtime in
 // the traceback it prints before exiting due to an unrecovered panic
 // or an internal runtime error.
@@ -166,3 +390,29 @@ func WriteHeapDump(fd uintptr)
 // If SetTraceback is called with a level lower than that of the
 // environment variable, the call is ignored.
 func SetTraceback(level string)
+
+// A TracebackEncoding is the encoding of the output of a crashing program.
+type TracebackEncoding int
+
+const (
+	// TracebackText prints the output as text, as initially.
+	TracebackText TracebackEncoding = iota
+
+	// TracebackJSON prints the panics, fatal error, signal, goroutine
+	// tracebacks, and registers of a crashing program as JSON
+	// objects, one to a line, each with a "type" field saying which
+	// of those it describes, such as "panic" or "goroutine". The
+	// frames of a goroutine are in an array of objects with "func",
+	// "file", and "line" fields, and, as SetStackFormat allows,
+	// "args" and "offset" fields.
+	TracebackJSON
+)
+
+// SetTracebackEncoding sets the encoding of the output the runtime
+// prints when the program crashes, so that log pipelines can index
+// crashes without parsing tracebacks spread over many lines. Messages
+// the runtime prints about its own state before crashing remain text,
+// as do the traces returned by Stack and runtime.Stack.
+func SetTracebackEncoding(enc TracebackEncoding) {
+	setTracebackEncoding(uint32(enc))
+}
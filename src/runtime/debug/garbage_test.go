@@ -5,9 +5,15 @@
 package debug_test
 
 import (
+	"context"
 	"internal/testenv"
+	"math"
+	"os"
+	"os/exec"
 	"runtime"
 	. "runtime/debug"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -105,6 +111,44 @@ func TestFreeOSMemory(t *testing.T) {
 	}
 }
 
+var freeOSMemoryToSink []byte
+
+// retainedFreeHeap frees 64 MB of heap and returns the bytes
+// the heap then retains.
+func retainedFreeHeap() uint64 {
+	freeOSMemoryToSink = make([]byte, 64<<20)
+	freeOSMemoryToSink = nil
+	runtime.GC()
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return ms.HeapSys - ms.HeapReleased
+}
+
+func TestFreeOSMemoryTo(t *testing.T) {
+	const slack = 16 << 20
+	for _, async := range []bool{false, true} {
+		target := retainedFreeHeap() - 32<<20
+		if async {
+			if err := FreeOSMemoryToContext(context.Background(), target); err != nil {
+				t.Errorf("FreeOSMemoryToContext: %v", err)
+			}
+		} else {
+			FreeOSMemoryTo(target)
+		}
+		var ms runtime.MemStats
+		runtime.ReadMemStats(&ms)
+		if r := ms.HeapSys - ms.HeapReleased; r > target || r+slack < target {
+			t.Errorf("async=%v: heap retains %d MB, want a little under the target of %d MB", async, r>>20, target>>20)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := FreeOSMemoryToContext(ctx, 0); err != context.Canceled {
+		t.Errorf("FreeOSMemoryToContext with a canceled context = %v, want context.Canceled", err)
+	}
+}
+
 var (
 	setGCPercentBallast interface{}
 	setGCPercentSink    interface{}
@@ -172,6 +216,95 @@ func TestSetGCPercent(t *testing.T) {
 	}
 }
 
+var setMemoryLimitSink []byte
+
+func TestSetMemoryLimit(t *testing.T) {
+	old := SetMemoryLimit(-1)
+	if old != math.MaxInt64 {
+		t.Errorf("initial SetMemoryLimit(-1) = %d, want math.MaxInt64", old)
+	}
+	// Restore the settings before anything can fail, so that later
+	// runs, as with -count, start from them.
+	defer SetGCPercent(SetGCPercent(100))
+	defer SetMemoryLimit(old)
+	SetMemoryLimit(1 << 40)
+	if got := SetMemoryLimit(old); got != 1<<40 {
+		t.Errorf("SetMemoryLimit(1<<40); SetMemoryLimit(x) = %d, want %d", got, int64(1<<40))
+	}
+
+	// With GC disabled, the limit alone keeps the heap in check.
+	// The limit lies a fixed distance above the live heap, rather
+	// than above the memory the process has mapped, which earlier
+	// allocations leave higher on each run.
+	const headroom = 64 << 20
+	SetGCPercent(-1)
+	runtime.GC()
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	limit := int64(ms.HeapAlloc) + headroom
+	ngc := ms.NumGC
+	SetMemoryLimit(limit)
+	runtime.ReadMemStats(&ms)
+	if int64(ms.NextGC) > limit {
+		t.Errorf("NextGC = %d MB, want at most the limit of %d MB", ms.NextGC>>20, limit>>20)
+	}
+	// Allocate 8 times the headroom in garbage, which would all stay
+	// in the heap without the limit. The limit is soft, and the heap
+	// grows past it while a cycle finishes, so check that collection
+	// kept the heap to twice the headroom on average, and that it is
+	// not left far above the limit.
+	for i := 0; i < 8*headroom; i += 64 << 10 {
+		setMemoryLimitSink = make([]byte, 64<<10)
+	}
+	setMemoryLimitSink = nil
+	runtime.ReadMemStats(&ms)
+	if n := ms.NumGC - ngc; n < 4 {
+		t.Errorf("%d GCs ran with GOGC=off and a memory limit, want at least 4", n)
+	}
+	if int64(ms.HeapAlloc) > limit+2*headroom {
+		t.Errorf("HeapAlloc = %d MB, want at most %d MB above the limit of %d MB", ms.HeapAlloc>>20, 2*headroom>>20, limit>>20)
+	}
+}
+
+func TestSetHeapTarget(t *testing.T) {
+	if old := SetHeapTarget(0); old != 0 {
+		t.Errorf("initial SetHeapTarget(0) = %d, want 0", old)
+	}
+	defer SetHeapTarget(0)
+	defer SetGCPercent(SetGCPercent(100))
+	runtime.GC()
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	target := ms.HeapAlloc + 256<<20
+	SetHeapTarget(target)
+	runtime.ReadMemStats(&ms)
+	if ms.NextGC < target {
+		t.Errorf("NextGC = %d MB, want at least the target of %d MB", ms.NextGC>>20, target>>20)
+	}
+	ngc := ms.NumGC
+	// Allocate 64 MB of garbage, which would take several
+	// collections with such a small live heap but for the target.
+	for i := 0; i < 64<<20; i += 64 << 10 {
+		setMemoryLimitSink = make([]byte, 64<<10)
+	}
+	setMemoryLimitSink = nil
+	runtime.ReadMemStats(&ms)
+	if ms.NumGC != ngc {
+		t.Errorf("%d GCs ran below the heap target", ms.NumGC-ngc)
+	}
+
+	// The memory limit takes precedence over the target.
+	defer SetMemoryLimit(SetMemoryLimit(int64(target / 2)))
+	runtime.ReadMemStats(&ms)
+	if ms.NextGC >= target {
+		t.Errorf("NextGC = %d MB with a memory limit below the target of %d MB", ms.NextGC>>20, target>>20)
+	}
+
+	if got := SetHeapTarget(0); got != target {
+		t.Errorf("SetHeapTarget(0) = %d, want %d", got, target)
+	}
+}
+
 func abs64(a int64) int64 {
 	if a < 0 {
 		return -a
@@ -191,3 +324,92 @@ func TestSetMaxThreadsOvf(t *testing.T) {
 	nt := SetMaxThreads(1 << (30 + ^uint(0)>>63))
 	SetMaxThreads(nt) // restore previous value
 }
+
+func TestSetLimitExceededHandler(t *testing.T) {
+	if limit := os.Getenv("GO_RUNTIME_DEBUG_LIMIT_HANDLER"); limit != "" {
+		runtime.GOMAXPROCS(2)
+		SetLimitExceededHandler(func(e LimitEvent) {
+			os.Stdout.WriteString("handler: kind " + strconv.Itoa(int(e.Kind)) +
+				" limit " + strconv.FormatUint(e.Limit, 10) +
+				" goroutine " + strconv.FormatInt(e.GoroutineID, 10) + "\n")
+		})
+		os.Stdout.WriteString("goroutine " + strconv.FormatInt(CapturedStack()[0].GoroutineID, 10) + "\n")
+		if limit == "stack" {
+			// In the child: overflow a 1 MB stack.
+			SetMaxStack(1 << 20)
+			overflow(0)
+		} else {
+			// In the child: fall below the threads already running.
+			SetMaxThreads(1)
+		}
+		return
+	}
+	testenv.MustHaveExec(t)
+
+	for _, tt := range []struct {
+		limit, want, msg string
+	}{
+		{"stack", "kind 1 limit 1048576 goroutine ", "stack overflow"},
+		{"threads", "kind 2 limit 1 goroutine 0", "thread exhaustion"},
+	} {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestSetLimitExceededHandler$")
+		cmd.Env = append(os.Environ(), "GO_RUNTIME_DEBUG_LIMIT_HANDLER="+tt.limit)
+		out, err := cmd.CombinedOutput()
+		if err == nil {
+			t.Errorf("%s: child did not crash; output:\n%s", tt.limit, out)
+			continue
+		}
+		want := "handler: " + tt.want
+		if tt.limit == "stack" {
+			var goid string
+			for _, line := range strings.Split(string(out), "\n") {
+				if strings.HasPrefix(line, "goroutine ") && !strings.Contains(line, "[") {
+					goid = strings.TrimPrefix(line, "goroutine ")
+				}
+			}
+			if goid == "" {
+				t.Errorf("%s: output does not report the goroutine:\n%s", tt.limit, out)
+			}
+			want += goid
+		}
+		if !strings.Contains(string(out), want+"\n") {
+			t.Errorf("%s: output does not contain %q:\n%s", tt.limit, want, out)
+		}
+		if !strings.Contains(string(out), tt.msg) {
+			t.Errorf("%s: output does not report the %s:\n%s", tt.limit, tt.msg, out)
+		}
+	}
+}
+
+//go:noinline
+func overflow(n int) int {
+	var buf [1024]byte
+	buf[n%len(buf)] = byte(n)
+	return overflow(n+1) + int(buf[0])
+}
+
+func TestSettingGetters(t *testing.T) {
+	old := SetGCPercent(123)
+	if got := GCPercent(); got != 123 {
+		t.Errorf("SetGCPercent(123); GCPercent() = %d, want 123", got)
+	}
+	SetGCPercent(old)
+	if got := GCPercent(); got != old {
+		t.Errorf("GCPercent() = %d after restoring %d", got, old)
+	}
+
+	oldStack := SetMaxStack(64 << 20)
+	if got := MaxStack(); got != 64<<20 {
+		t.Errorf("SetMaxStack(64<<20); MaxStack() = %d, want %d", got, 64<<20)
+	}
+	SetMaxStack(oldStack)
+
+	oldThreads := SetMaxThreads(5000)
+	if got := MaxThreads(); got != 5000 {
+		t.Errorf("SetMaxThreads(5000); MaxThreads() = %d, want 5000", got)
+	}
+	SetMaxThreads(oldThreads)
+	if got := MaxThreads(); got != oldThreads {
+		t.Errorf("MaxThreads() = %d after restoring %d", got, oldThreads)
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// GCCycleInfo describes a completed garbage collection cycle.
+type GCCycleInfo struct {
+	NumGC    int64         // number of the cycle, as GCStats.NumGC just after it
+	End      time.Time     // time the cycle ended
+	Pause    time.Duration // total stop-the-world pause of the cycle
+	HeapLive uint64        // bytes of heap the cycle found live
+	HeapGoal uint64        // heap size at which the next cycle aims to end
+
+	// The pause is made up of those at the start and end of marking:
+	// sweep termination, which finishes the previous cycle's sweep,
+	// and mark termination.
+	SweepTermPause time.Duration
+	MarkTermPause  time.Duration
+
+	// AssistTime is the time goroutines spent assisting the collector
+	// with marking when they allocated faster than it marked.
+	AssistTime time.Duration
+
+	// CPUFraction is the fraction of the available CPU time, as set by
+	// GOMAXPROCS, the cycle used from its start to its end, not counting
+	// marking by otherwise idle processors.
+	CPUFraction float64
+}
+
+// DetailedGCStats holds the statistics of GCStats together with
+// a breakdown of the most recent cycles.
+type DetailedGCStats struct {
+	GCStats
+	Cycles []GCCycleInfo // most recent first
+}
+
+// ReadGCStatsDetailed is like ReadGCStats but also reads into
+// stats.Cycles the breakdown of as many recent cycles as the runtime
+// records, currently 256. The stats.Cycles slice is reused if large
+// enough, and reallocated otherwise.
+func ReadGCStatsDetailed(stats *DetailedGCStats) {
+	ReadGCStats(&stats.GCStats)
+	recs := make([]gcCycleRecord, 256)
+	last, copied := readGCCycles(0, recs)
+	if cap(stats.Cycles) < copied {
+		stats.Cycles = make([]GCCycleInfo, copied)
+	}
+	stats.Cycles = stats.Cycles[:copied]
+	for i, r := range recs[:copied] {
+		// Most recent first.
+		stats.Cycles[copied-1-i] = r.info(int64(last) - int64(copied-1-i))
+	}
+}
+
+// RegisterGCCallback arranges for f to be called after each garbage
+// collection cycle that completes after RegisterGCCallback returns,
+// and returns a function that cancels the arrangement.
+//
+// The callbacks run one at a time, in the order registered, on a
+// goroutine started for the purpose, never during collection itself.
+// A callback should return promptly: the runtime keeps the records of
+// only the most recent 256 cycles, and a callback that falls further
+// behind misses the cycles whose records are gone, as does any other.
+// A callback may call cancel, its own included; once cancel returns,
+// f is not called again unless it is running or about to run on the
+// callback goroutine.
+func RegisterGCCallback(f func(info GCCycleInfo)) (cancel func()) {
+	last, _ := readGCCycles(0, nil)
+	c := &gcCallback{f: f, after: last}
+
+	gcCallbacks.mu.Lock()
+	gcCallbacks.list = append(gcCallbacks.list, c)
+	if !gcCallbacks.running {
+		gcCallbacks.running = true
+		go notifyGC(last)
+	}
+	gcCallbacks.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			atomic.StoreInt32(&c.canceled, 1)
+			gcCallbacks.mu.Lock()
+			defer gcCallbacks.mu.Unlock()
+			for i, c1 := range gcCallbacks.list {
+				if c1 == c {
+					gcCallbacks.list = append(gcCallbacks.list[:i:i], gcCallbacks.list[i+1:]...)
+					break
+				}
+			}
+		})
+	}
+}
+
+// A PauseEvent describes a stop-the-world pause of a garbage
+// collection cycle that took longer than the threshold given to
+// MonitorGCPauses.
+type PauseEvent struct {
+	Phase    string        // "sweep termination" or "mark termination"
+	Duration time.Duration // length of the pause
+	Cycle    GCCycleInfo   // the cycle, with its heap statistics
+}
+
+// MonitorGCPauses arranges for fn to be called for each stop-the-world
+// pause longer than threshold of the garbage collection cycles that
+// complete after MonitorGCPauses returns, and returns a function that
+// stops the monitoring. Each cycle stops the world twice, to begin and
+// to end marking, and fn is called for each of the two pauses that is
+// too long, with the statistics of the cycle, as soon as the cycle
+// completes. The calls are made as those of a callback registered with
+// RegisterGCCallback are, and stop does what the cancel function it
+// returns does. The world is also stopped outside garbage collection,
+// as by runtime.ReadMemStats, but MonitorGCPauses does not see those
+// pauses.
+func MonitorGCPauses(threshold time.Duration, fn func(PauseEvent)) (stop func()) {
+	return RegisterGCCallback(func(info GCCycleInfo) {
+		if info.SweepTermPause > threshold {
+			fn(PauseEvent{Phase: "sweep termination", Duration: info.SweepTermPause, Cycle: info})
+		}
+		if info.MarkTermPause > threshold {
+			fn(PauseEvent{Phase: "mark termination", Duration: info.MarkTermPause, Cycle: info})
+		}
+	})
+}
+
+// A gcCallback is a function registered with RegisterGCCallback.
+type gcCallback struct {
+	f        func(GCCycleInfo)
+	after    uint32 // NumGC when registered
+	canceled int32  // set atomically by cancel
+}
+
+var gcCallbacks struct {
+	mu      sync.Mutex
+	list    []*gcCallback
+	running bool // whether notifyGC is running
+}
+
+// A gcCycleRecord is the runtime's record of a GC cycle.
+// It must match runtime.gcCycleRecord.
+type gcCycleRecord struct {
+	end            int64
+	pause          int64
+	sweepTermPause int64
+	markTermPause  int64
+	assistTime     int64
+	cpuFraction    float64
+	heapLive       uint64
+	heapGoal       uint64
+}
+
+// info returns the GCCycleInfo of r, the record of cycle num.
+func (r *gcCycleRecord) info(num int64) GCCycleInfo {
+	return GCCycleInfo{
+		NumGC:          num,
+		End:            time.Unix(0, r.end),
+		Pause:          time.Duration(r.pause),
+		HeapLive:       r.heapLive,
+		HeapGoal:       r.heapGoal,
+		SweepTermPause: time.Duration(r.sweepTermPause),
+		MarkTermPause:  time.Duration(r.markTermPause),
+		AssistTime:     time.Duration(r.assistTime),
+		CPUFraction:    r.cpuFraction,
+	}
+}
+
+// notifyGC calls the registered callbacks for each GC cycle after
+// cycle n, until no callbacks remain.
+func notifyGC(n uint32) {
+	recs := make([]gcCycleRecord, 16)
+	for {
+		waitGCCycle(n + 1)
+		for {
+			last, copied := readGCCycles(n, recs)
+			if copied == 0 {
+				n = last
+				break
+			}
+			gcCallbacks.mu.Lock()
+			list := gcCallbacks.list
+			gcCallbacks.mu.Unlock()
+			first := last - uint32(copied) + 1
+			for i := range recs[:copied] {
+				info := recs[i].info(int64(first) + int64(i))
+				for _, c := range list {
+					if info.NumGC > int64(c.after) && atomic.LoadInt32(&c.canceled) == 0 {
+						c.f(info)
+					}
+				}
+			}
+			n = last
+		}
+
+		gcCallbacks.mu.Lock()
+		if len(gcCallbacks.list) == 0 {
+			gcCallbacks.running = false
+			gcCallbacks.mu.Unlock()
+			return
+		}
+		gcCallbacks.mu.Unlock()
+	}
+}
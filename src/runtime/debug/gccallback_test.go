@@ -0,0 +1,144 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"runtime"
+	. "runtime/debug"
+	"testing"
+	"time"
+)
+
+func TestRegisterGCCallback(t *testing.T) {
+	infos := make(chan GCCycleInfo, 100)
+	cancel := RegisterGCCallback(func(info GCCycleInfo) {
+		infos <- info
+	})
+	defer cancel()
+	others := make(chan GCCycleInfo, 100)
+	cancelOthers := RegisterGCCallback(func(info GCCycleInfo) {
+		others <- info
+	})
+
+	start := time.Now()
+	runtime.GC()
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	var info GCCycleInfo
+	for info.NumGC < int64(ms.NumGC) {
+		select {
+		case info = <-infos:
+		case <-time.After(10 * time.Second):
+			t.Fatalf("no callback for GC cycle %d", ms.NumGC)
+		}
+	}
+	if info.NumGC != int64(ms.NumGC) {
+		t.Errorf("callback for cycle %d, want %d", info.NumGC, ms.NumGC)
+	}
+	if info.End.Before(start.Add(-time.Second)) || info.End.After(time.Now().Add(time.Second)) {
+		t.Errorf("End = %v, want about %v", info.End, start)
+	}
+	if info.Pause <= 0 || info.HeapLive == 0 || info.HeapGoal < info.HeapLive {
+		t.Errorf("callback got %+v, want a positive pause and heap goal of at least the live heap", info)
+	}
+	if want := ms.PauseNs[(ms.NumGC+255)%256]; info.Pause != time.Duration(want) {
+		t.Errorf("Pause = %v, want %v as in MemStats.PauseNs", info.Pause, time.Duration(want))
+	}
+	if got := <-others; got.NumGC > info.NumGC {
+		t.Errorf("second callback first got cycle %d, after the first callback got %d", got.NumGC, info.NumGC)
+	}
+
+	// A canceled callback is not called for cycles after cancel returns.
+	cancelOthers()
+	cancelOthers()
+	runtime.GC()
+	runtime.ReadMemStats(&ms)
+	for info.NumGC < int64(ms.NumGC) {
+		info = <-infos
+	}
+	// The callbacks run in order, so the second callback
+	// has had its chance to run for the cycle.
+	for len(others) > 0 {
+		if got := <-others; got.NumGC >= int64(ms.NumGC) {
+			t.Errorf("canceled callback got cycle %d", got.NumGC)
+		}
+	}
+}
+
+func TestMonitorGCPauses(t *testing.T) {
+	events := make(chan PauseEvent, 100)
+	stop := MonitorGCPauses(0, func(e PauseEvent) {
+		events <- e
+	})
+	defer stop()
+	never := MonitorGCPauses(time.Hour, func(e PauseEvent) {
+		t.Errorf("pause of %v exceeds an hour", e.Duration)
+	})
+	defer never()
+
+	runtime.GC()
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	var phases []string
+	for {
+		var e PauseEvent
+		select {
+		case e = <-events:
+		case <-time.After(10 * time.Second):
+			t.Fatalf("no pause events for GC cycle %d", ms.NumGC)
+		}
+		if e.Cycle.NumGC < int64(ms.NumGC) {
+			continue
+		}
+		want := e.Cycle.SweepTermPause
+		if e.Phase == "mark termination" {
+			want = e.Cycle.MarkTermPause
+		}
+		if e.Duration != want || e.Duration <= 0 || e.Cycle.HeapLive == 0 {
+			t.Errorf("event %+v, want positive %s pause of cycle with heap stats", e, e.Phase)
+		}
+		phases = append(phases, e.Phase)
+		if len(phases) == 2 {
+			break
+		}
+	}
+	if phases[0] != "sweep termination" || phases[1] != "mark termination" {
+		t.Errorf("phases %q, want sweep termination then mark termination", phases)
+	}
+}
+
+func TestReadGCStatsDetailed(t *testing.T) {
+	runtime.GC()
+	var stats DetailedGCStats
+	ReadGCStatsDetailed(&stats)
+	if len(stats.Cycles) == 0 || len(stats.Cycles) > 256 || len(stats.Cycles) > int(stats.NumGC) {
+		t.Fatalf("ReadGCStatsDetailed read %d cycles after %d collections", len(stats.Cycles), stats.NumGC)
+	}
+	for i, c := range stats.Cycles {
+		if c.NumGC != stats.NumGC-int64(i) {
+			t.Errorf("Cycles[%d].NumGC = %d, want %d", i, c.NumGC, stats.NumGC-int64(i))
+		}
+	}
+	c := stats.Cycles[0]
+	if c.Pause != stats.Pause[0] {
+		t.Errorf("Cycles[0].Pause = %v, want %v as in Pause[0]", c.Pause, stats.Pause[0])
+	}
+	if c.SweepTermPause <= 0 || c.MarkTermPause <= 0 || c.SweepTermPause+c.MarkTermPause > c.Pause {
+		t.Errorf("pauses of %v and %v, want positive pauses adding up to at most %v", c.SweepTermPause, c.MarkTermPause, c.Pause)
+	}
+	if c.AssistTime < 0 {
+		t.Errorf("AssistTime = %v, want at least 0", c.AssistTime)
+	}
+	if !(c.CPUFraction > 0 && c.CPUFraction <= 1) {
+		t.Errorf("CPUFraction = %v, want in (0, 1]", c.CPUFraction)
+	}
+
+	// The slice is reused.
+	p := &stats.Cycles[0]
+	ReadGCStatsDetailed(&stats)
+	if &stats.Cycles[0] != p {
+		t.Errorf("ReadGCStatsDetailed reallocated a large enough Cycles slice")
+	}
+}
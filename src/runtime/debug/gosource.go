@@ -0,0 +1,105 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// GoSource returns the text of a Go source file for package pkg
+// declaring a variable named varName whose value is a
+// *debug.BuildInfo equal to bi. It lets code generators snapshot
+// parsed build information into a program. The result is formatted
+// as by gofmt.
+func (bi *BuildInfo) GoSource(pkg, varName string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by runtime/debug.BuildInfo.GoSource. DO NOT EDIT.\n\n")
+	buf.WriteString("package " + pkg + "\n\n")
+	buf.WriteString("import \"runtime/debug\"\n\n")
+	buf.WriteString("var " + varName + " = &debug.BuildInfo{\n")
+	if bi.GoVersion != "" {
+		buf.WriteString("\tGoVersion: " + strconv.Quote(bi.GoVersion) + ",\n")
+		buf.WriteString("\tPath:      " + strconv.Quote(bi.Path) + ",\n")
+	} else {
+		buf.WriteString("\tPath: " + strconv.Quote(bi.Path) + ",\n")
+	}
+	buf.WriteString("\tMain: debug.Module{\n")
+	writeModuleFields(&buf, "\t\t", &bi.Main)
+	buf.WriteString("\t},\n")
+	if len(bi.Deps) > 0 {
+		buf.WriteString("\tDeps: []*debug.Module{\n")
+		for _, dep := range bi.Deps {
+			if dep == nil {
+				continue
+			}
+			buf.WriteString("\t\t{\n")
+			writeModuleFields(&buf, "\t\t\t", dep)
+			buf.WriteString("\t\t},\n")
+		}
+		buf.WriteString("\t},\n")
+	}
+	if len(bi.Settings) > 0 {
+		buf.WriteString("\tSettings: []debug.BuildSetting{\n")
+		for _, s := range bi.Settings {
+			buf.WriteString("\t\t{Key: " + strconv.Quote(s.Key) + ", Value: " + strconv.Quote(s.Value) + "},\n")
+		}
+		buf.WriteString("\t},\n")
+	}
+	if len(bi.Raw) > 0 {
+		buf.WriteString("\tRaw: []string{\n")
+		for _, line := range bi.Raw {
+			buf.WriteString("\t\t" + strconv.Quote(line) + ",\n")
+		}
+		buf.WriteString("\t},\n")
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+// writeModuleFields writes the keyed fields of a debug.Module
+// composite literal for m, each line starting with indent.
+// Path and Version are always present, so the values line up
+// at the width of the longest key, as gofmt would align them.
+func writeModuleFields(buf *bytes.Buffer, indent string, m *Module) {
+	writeField := func(key, value string) {
+		buf.WriteString(indent)
+		buf.WriteString(key)
+		buf.WriteString(":         "[:len("Version: ")-len(key)])
+		buf.WriteString(value)
+		buf.WriteString(",\n")
+	}
+	writeField("Path", strconv.Quote(m.Path))
+	writeField("Version", strconv.Quote(m.Version))
+	if m.Sum != "" {
+		writeField("Sum", strconv.Quote(m.Sum))
+	}
+	if len(m.Extra) > 0 {
+		list := make([]string, len(m.Extra))
+		for i, x := range m.Extra {
+			list[i] = strconv.Quote(x)
+		}
+		writeField("Extra", "[]string{"+strings.Join(list, ", ")+"}")
+	}
+	if o := m.Origin; o != nil {
+		fields := []string{"VCS: " + strconv.Quote(o.VCS), "URL: " + strconv.Quote(o.URL)}
+		if o.Ref != "" {
+			fields = append(fields, "Ref: "+strconv.Quote(o.Ref))
+		}
+		if o.Hash != "" {
+			fields = append(fields, "Hash: "+strconv.Quote(o.Hash))
+		}
+		writeField("Origin", "&debug.Origin{"+strings.Join(fields, ", ")+"}")
+	}
+	if m.License != "" {
+		writeField("License", strconv.Quote(m.License))
+	}
+	if m.Replace != nil {
+		buf.WriteString(indent + "Replace: &debug.Module{\n")
+		writeModuleFields(buf, indent+"\t", m.Replace)
+		buf.WriteString(indent + "},\n")
+	}
+}
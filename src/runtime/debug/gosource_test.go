@@ -0,0 +1,57 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"bytes"
+	"go/format"
+	"go/parser"
+	"go/token"
+	. "runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestGoSource(t *testing.T) {
+	info, ok := ReadBuildInfoData(blob(testModinfo))
+	if !ok {
+		t.Fatal("ReadBuildInfo failed")
+	}
+	info.GoVersion = "go1.15"
+	info.Deps[0].Path = "example.com/\"quoted\"\t\u00e9"
+	info.Deps[1].Origin = &Origin{VCS: "git", URL: "https://github.com/rsc/quote", Hash: "0406d72"}
+	info.Deps[1].License = "MIT"
+	src := info.GoSource("buildinfo", "Info")
+
+	f, err := parser.ParseFile(token.NewFileSet(), "buildinfo.go", src, 0)
+	if err != nil {
+		t.Fatalf("generated source does not parse: %v\n%s", err, src)
+	}
+	if f.Name.Name != "buildinfo" {
+		t.Errorf("package name = %q, want %q", f.Name.Name, "buildinfo")
+	}
+	if obj := f.Scope.Lookup("Info"); obj == nil {
+		t.Errorf("generated source does not declare Info:\n%s", src)
+	}
+	for _, want := range []string{
+		`Path:    "example.com/\"quoted\"\té",`,
+		`Replace: &debug.Module{`,
+		`Sum:     "h1:beef=",`,
+		`Origin:  &debug.Origin{VCS: "git", URL: "https://github.com/rsc/quote", Hash: "0406d72"},`,
+		`License: "MIT",`,
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("generated source does not contain %q:\n%s", want, src)
+		}
+	}
+
+	fmtSrc, err := format.Source(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(fmtSrc, src) {
+		t.Errorf("generated source is not gofmt-formatted:\n%s", src)
+	}
+}
@@ -5,6 +5,9 @@
 package debug_test
 
 import (
+	"bytes"
+	"compress/gzip"
+	"errors"
 	"io/ioutil"
 	"os"
 	"runtime"
@@ -68,3 +71,44 @@ func TestWriteHeapDumpFinalizers(t *testing.T) {
 	WriteHeapDump(f.Fd())
 	println("done dump")
 }
+
+func TestWriteHeapDumpTo(t *testing.T) {
+	if runtime.GOOS == "js" {
+		t.Skipf("WriteHeapDump is not available on %s.", runtime.GOOS)
+	}
+	const hdr = "go1.7 heap dump\n"
+	var buf bytes.Buffer
+	if err := WriteHeapDumpTo(&buf, HeapDumpOptions{}); err != nil {
+		t.Fatalf("WriteHeapDumpTo: %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte(hdr)) {
+		t.Fatalf("heap dump begins %q, want %q", buf.Bytes()[:len(hdr)], hdr)
+	}
+
+	var zbuf bytes.Buffer
+	if err := WriteHeapDumpTo(&zbuf, HeapDumpOptions{Gzip: true}); err != nil {
+		t.Fatalf("WriteHeapDumpTo with gzip: %v", err)
+	}
+	zr, err := gzip.NewReader(&zbuf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dump, err := ioutil.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(dump, []byte(hdr)) {
+		t.Errorf("uncompressed heap dump does not begin with %q", hdr)
+	}
+
+	// Write errors are reported.
+	if err := WriteHeapDumpTo(errWriter{}, HeapDumpOptions{}); err != errWrite {
+		t.Errorf("WriteHeapDumpTo to a failing writer = %v, want %v", err, errWrite)
+	}
+}
+
+var errWrite = errors.New("write failed")
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) { return 0, errWrite }
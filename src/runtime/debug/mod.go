@@ -5,7 +5,15 @@
 package debug
 
 import (
+	"bytes"
+	"errors"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
 )
 
 // exported from runtime
@@ -14,101 +22,741 @@ func modinfo() string
 // ReadBuildInfo returns the build information embedded
 // in the running binary. The information is available only
 // in binaries built with module support.
+//
+// ReadBuildInfo is safe to call from multiple goroutines
+// simultaneously. It parses the data written by the linker once, on
+// the first call, and returns a new deep copy of the result on each
+// call, so callers may modify the BuildInfo they receive.
 func ReadBuildInfo() (info *BuildInfo, ok bool) {
-	return readBuildInfo(modinfo())
+	info, err := ReadBuildInfoErr()
+	return info, err == nil
+}
+
+// ReadBuildInfoErr is like ReadBuildInfo but reports why the build
+// information is unavailable: ErrNoBuildInfo if the binary has none,
+// as when it was built without module support, or an error describing
+// how the embedded information is corrupt. For a malformed line, the
+// error is a *TextError giving its position. Like ReadBuildInfo, it
+// returns a new deep copy of the build information on each call.
+func ReadBuildInfoErr() (*BuildInfo, error) {
+	buildInfoOnce.Do(func() {
+		buildInfo, buildInfoErr = readBuildInfoErr(modinfo())
+		if buildInfoErr == nil {
+			buildInfo.GoVersion = runtime.Version()
+		}
+	})
+	if buildInfoErr != nil {
+		return nil, buildInfoErr
+	}
+	return buildInfo.clone(), nil
+}
+
+// The build information of the running binary, parsed by ReadBuildInfoErr.
+// buildInfo must not be modified or returned to callers.
+var (
+	buildInfoOnce sync.Once
+	buildInfo     *BuildInfo
+	buildInfoErr  error
+)
+
+// ErrNoBuildInfo reports that a binary holds no build information, as
+// returned by ReadBuildInfoErr for the running binary and by
+// ReadBuildInfoFrom for another.
+var ErrNoBuildInfo = errors.New("no build information found")
+
+// ReadMainModule returns the main module recorded in the build
+// information embedded in the running binary. It is a cheaper
+// alternative to ReadBuildInfo for the common case of reporting
+// the binary's own version: it stops parsing at the main module's
+// line and ignores the dependency list and everything after it.
+// The information is available only in binaries built with module support.
+func ReadMainModule() (Module, bool) {
+	return readMainModule(modinfo())
+}
+
+// RawBuildInfo returns the build information embedded in the running
+// binary as the go command wrote it, without the sentinels that frame
+// it and without parsing it, so that it includes lines of kinds this
+// package does not understand. The information is available only in
+// binaries built with module support.
+func RawBuildInfo() (string, bool) {
+	return rawBuildInfo(modinfo())
+}
+
+// rawBuildInfo returns the text framed by the sentinels in data.
+func rawBuildInfo(data string) (string, bool) {
+	if len(data) < len(infoStart)+len(infoEnd) || !strings.HasPrefix(data, infoStart) || !strings.HasSuffix(data, infoEnd) {
+		return "", false
+	}
+	return data[len(infoStart) : len(data)-len(infoEnd)], true
 }
 
 // BuildInfo represents the build information read from
 // the running binary.
+//
+// GoVersion is the version of the toolchain that built the binary, as
+// reported by runtime.Version, such as go1.15.2. The go command always
+// builds with the toolchain it was itself built with, so GoVersion is
+// the effective toolchain of the build. It is distinct from the language
+// version set by the go directive of the main module's go.mod file,
+// which is not recorded.
 type BuildInfo struct {
-	Path string    // The main package path
-	Main Module    // The module containing the main package
-	Deps []*Module // Module dependencies
+	GoVersion string         // Version of Go that produced this binary
+	Path      string         // The main package path
+	Main      Module         // The module containing the main package
+	Deps      []*Module      // Module dependencies
+	Settings  []BuildSetting // Other information about the build
+	Raw       []string       // Lines of unknown kinds, kept by UnmarshalText
+
+	index atomic.Value        // *depIndex, built by Dep
+	graph map[string][]string // requirements by module path@version, set by SetModGraph
 }
 
 // Module represents a module.
+//
+// Path and Version have the meanings of the fields of the same names in
+// golang.org/x/mod/module.Version, to which a Module m converts as
+// module.Version{Path: m.Path, Version: m.Version}. The standard library
+// cannot depend on golang.org/x/mod, so no conversion method is provided.
+// Note that a Module with a replacement describes two module versions:
+// the one required and, in Replace, the one used.
 type Module struct {
-	Path    string  // module path
-	Version string  // module version
-	Sum     string  // checksum
-	Replace *Module // replaced by this module
+	Path    string   // module path
+	Version string   // module version
+	Sum     string   // checksum
+	Replace *Module  // replaced by this module
+	Extra   []string // columns following the checksum, added by custom tooling
+	Origin  *Origin  // provenance of the module's source, if recorded
+	License string   // SPDX license expression, such as "BSD-3-Clause", if recorded
 }
 
-func readBuildInfo(data string) (*BuildInfo, bool) {
-	if len(data) < 32 {
-		return nil, false
+// An Origin describes the version control repository and revision
+// from which the source code of a module was obtained. The go command
+// does not record origins itself; tools that know them, such as module
+// proxies and release pipelines, may add them to the text form as an
+// origin line following the line of the module they describe:
+//
+//	origin\tvcs\turl\tref\thash
+//
+// The ref and hash columns may be left out.
+type Origin struct {
+	VCS  string // version control system, such as "git"
+	URL  string // repository URL
+	Ref  string // tag or branch, such as "refs/tags/v1.2.3", if known
+	Hash string // revision identifier, such as a Git commit hash, if known
+}
+
+// Key returns the module path and version of m in the form path@version,
+// identifying the module version without regard to its checksum or
+// replacement. If m has no version, Key returns the path alone.
+func (m Module) Key() string {
+	if m.Version == "" {
+		return m.Path
 	}
-	data = data[16 : len(data)-16]
+	return m.Path + "@" + m.Version
+}
 
-	const (
-		pathLine = "path\t"
-		modLine  = "mod\t"
-		depLine  = "dep\t"
-		repLine  = "=>\t"
-	)
+// BuildSetting is a key-value pair describing one setting that
+// influenced a build, such as an environment variable like
+// CGO_ENABLED or a command-line flag like -ldflags. A flag
+// given without a value, such as -trimpath, has an empty Value.
+type BuildSetting struct {
+	Key, Value string
+}
+
+// TextFormatVersion is the version of the text format written by
+// MarshalText, recorded in its format line. Text without a format
+// line, as the go command embeds in binaries, is of version 1;
+// version 2 adds origin lines and version 3 license lines. A later
+// version may add kinds of lines, which UnmarshalText keeps in
+// BuildInfo.Raw.
+const TextFormatVersion = 3
+
+// MarshalText implements encoding.TextMarshaler. It returns bi in the
+// line-oriented text format that cmd/go embeds in binaries, headed by
+// a format line giving TextFormatVersion: a go line for the Go
+// version, a path line, a mod line for the main module, a dep line
+// for each dependency, each module line followed by a => line
+// if the module is replaced, and that by another if the replacement is
+// itself replaced, and so on along a chain of replacements, an origin
+// line after the line of each module with an Origin, a license line
+// giving the License of each module that has one, and a build line
+// for each setting, followed by the lines of Raw, unchanged.
+// The columns of a line are separated by tabs,
+// and the fields are encoded by DefaultFieldEncoder.
+// The go and path lines are omitted if empty.
+// Nil entries in Deps are skipped.
+func (bi *BuildInfo) MarshalText() ([]byte, error) {
+	return []byte(bi.String()), nil
+}
+
+// String returns bi in the text format written by MarshalText,
+// so that printing a BuildInfo shows its contents.
+func (bi *BuildInfo) String() string {
+	text, _ := bi.MarshalTextWith(defaultFieldEncoder)
+	return string(text)
+}
+
+// MarshalTextWith is like MarshalText but encodes each field with enc.
+func (bi *BuildInfo) MarshalTextWith(enc FieldEncoder) ([]byte, error) {
+	var buf bytes.Buffer
+	writeTextHeader(&buf, enc, bi)
+	for _, dep := range bi.Deps {
+		if dep != nil {
+			writeTextEntry(&buf, enc, depLine, dep)
+		}
+	}
+	writeTextTrailer(&buf, enc, bi)
+	return buf.Bytes(), nil
+}
+
+// writeTextHeader writes the lines of the text form of bi that precede
+// the dependencies: the format, go, path, and mod lines.
+func writeTextHeader(buf *bytes.Buffer, enc FieldEncoder, bi *BuildInfo) {
+	buf.WriteString(formatLine)
+	buf.WriteString(strconv.Itoa(TextFormatVersion))
+	buf.WriteByte('\n')
+	if bi.GoVersion != "" {
+		buf.WriteString(goLine)
+		buf.WriteString(enc.Encode(bi.GoVersion))
+		buf.WriteByte('\n')
+	}
+	if bi.Path != "" {
+		buf.WriteString(pathLine)
+		buf.WriteString(enc.Encode(bi.Path))
+		buf.WriteByte('\n')
+	}
+	if bi.Main.Path != "" || bi.Main.Version != "" {
+		writeTextEntry(buf, enc, modLine, &bi.Main)
+	}
+}
+
+// writeTextEntry writes the line of m, beginning with word,
+// and those of its chain of replacements.
+func writeTextEntry(buf *bytes.Buffer, enc FieldEncoder, word string, m *Module) {
+	writeTextModule(buf, enc, word, m)
+	for r := m.Replace; r != nil; r = r.Replace {
+		writeTextModule(buf, enc, repLine, r)
+	}
+}
+
+// writeTextModule writes the line of m, beginning with word,
+// followed by its origin and license lines.
+func writeTextModule(buf *bytes.Buffer, enc FieldEncoder, word string, m *Module) {
+	buf.WriteString(word)
+	buf.WriteString(enc.Encode(m.Path))
+	buf.WriteByte('\t')
+	buf.WriteString(enc.Encode(m.Version))
+	// The go command leaves out the checksum of a replaced
+	// module, but a => line always has a checksum column.
+	if m.Replace == nil || len(m.Extra) > 0 || word == repLine {
+		buf.WriteByte('\t')
+		buf.WriteString(enc.Encode(m.Sum))
+	}
+	for _, x := range m.Extra {
+		buf.WriteByte('\t')
+		buf.WriteString(enc.Encode(x))
+	}
+	buf.WriteByte('\n')
+	if o := m.Origin; o != nil {
+		buf.WriteString(originLine)
+		buf.WriteString(enc.Encode(o.VCS))
+		buf.WriteByte('\t')
+		buf.WriteString(enc.Encode(o.URL))
+		if o.Ref != "" || o.Hash != "" {
+			buf.WriteByte('\t')
+			buf.WriteString(enc.Encode(o.Ref))
+			buf.WriteByte('\t')
+			buf.WriteString(enc.Encode(o.Hash))
+		}
+		buf.WriteByte('\n')
+	}
+	if m.License != "" {
+		buf.WriteString(licenseLine)
+		buf.WriteString(enc.Encode(m.License))
+		buf.WriteByte('\n')
+	}
+}
+
+// writeTextTrailer writes the lines of the text form of bi that follow
+// the dependencies: the build lines and the lines of bi.Raw.
+func writeTextTrailer(buf *bytes.Buffer, enc FieldEncoder, bi *BuildInfo) {
+	for _, s := range bi.Settings {
+		buf.WriteString(buildLine)
+		buf.WriteString(enc.Encode(s.Key))
+		if s.Value != "" {
+			buf.WriteByte('=')
+			buf.WriteString(enc.Encode(s.Value))
+		}
+		buf.WriteByte('\n')
+	}
+	for _, line := range bi.Raw {
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+}
+
+// MarshalTextCanonical is like MarshalText but returns the same text
+// for any two BuildInfos that describe the same build, whatever the
+// order of their dependencies and settings, so that the text can be
+// signed or compared byte for byte. It sorts the dependencies by path,
+// and then by semantic version, and the settings by key, keeping the order of
+// settings with the same key. It also normalizes replacements: a chain
+// of replacements is written as a single => line naming the module at
+// its end, the one actually used, and a replacement by the very same
+// module path and version is left out.
+func (bi *BuildInfo) MarshalTextCanonical() ([]byte, error) {
+	c := bi.clone()
+	normalize := func(m *Module) {
+		r := m.Replace
+		for r != nil && r.Replace != nil {
+			r = r.Replace
+		}
+		if r != nil && r.Path == m.Path && r.Version == m.Version {
+			r = nil
+		}
+		m.Replace = r
+	}
+	normalize(&c.Main)
+	deps := c.Deps[:0]
+	for _, dep := range c.Deps {
+		if dep != nil {
+			normalize(dep)
+			deps = append(deps, dep)
+		}
+	}
+	// Dependencies with the same path and version are ordered by
+	// their text, so that even they come out the same way each time.
+	text := func(m *Module) string { return (&BuildInfo{Deps: []*Module{m}}).String() }
+	sort.Slice(deps, func(i, j int) bool {
+		m, n := deps[i], deps[j]
+		if m.Path != n.Path {
+			return m.Path < n.Path
+		}
+		if c := compareSemver(m.Version, n.Version); c != 0 {
+			return c < 0
+		}
+		if m.Version != n.Version {
+			return m.Version < n.Version
+		}
+		return text(m) < text(n)
+	})
+	c.Deps = deps
+	sort.SliceStable(c.Settings, func(i, j int) bool { return c.Settings[i].Key < c.Settings[j].Key })
+	return c.MarshalText()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It parses the
+// text format produced by MarshalText, of any version, and that
+// embedded in binaries by the go command of any release. Lines with
+// unrecognized prefixes, such as those of kinds added by later
+// versions of the format, are kept in Raw so that MarshalText writes
+// them back; blank lines are ignored. Columns following the checksum
+// in a module line, which some tools add to carry their own metadata,
+// are kept in Module.Extra.
+// The fields are decoded by DefaultFieldEncoder.
+func (bi *BuildInfo) UnmarshalText(data []byte) error {
+	return bi.UnmarshalTextWith(data, defaultFieldEncoder)
+}
+
+// UnmarshalTextStrict is like UnmarshalText but rejects input that
+// UnmarshalText accepts or skips: lines with unrecognized prefixes,
+// including blank lines, unless a format line declares a version later
+// than TextFormatVersion; more than one format, go, mod, or path line;
+// mod, dep, and => lines with columns after the checksum, which
+// UnmarshalText keeps in Module.Extra; and input that does not end in a
+// newline, as when it has been cut short.
+// Its errors are of type *TextError and wrap ErrSyntax, ErrTruncated, or
+// ErrLimitExceeded.
+func (bi *BuildInfo) UnmarshalTextStrict(data []byte) error {
+	info, err := parseText(string(data), defaultFieldEncoder, true)
+	if err != nil {
+		return err
+	}
+	*bi = *info
+	return nil
+}
+
+// UnmarshalTextWith is like UnmarshalText but decodes each field with enc.
+func (bi *BuildInfo) UnmarshalTextWith(data []byte, enc FieldEncoder) error {
+	info, err := parseBuildInfoWith(string(data), enc)
+	if err != nil {
+		return err
+	}
+	*bi = *info
+	return nil
+}
+
+const (
+	formatLine  = "format\t"
+	goLine      = "go\t"
+	pathLine    = "path\t"
+	modLine     = "mod\t"
+	depLine     = "dep\t"
+	repLine     = "=>\t"
+	buildLine   = "build\t"
+	originLine  = "origin\t"
+	licenseLine = "license\t"
+)
+
+// splitColumns appends the tab-separated columns of s to buf and
+// returns the result, which uses the storage of buf unless s has more
+// columns than fit in it.
+func splitColumns(s string, buf []string) []string {
+	for {
+		i := strings.IndexByte(s, '\t')
+		if i < 0 {
+			return append(buf, s)
+		}
+		buf = append(buf, s[:i])
+		s = s[i+1:]
+	}
+}
+
+// extraColumn returns the byte column, starting at 1, of the first
+// column after the checksum in a module line whose columns elem follow
+// a prefix of length n. The column is where strict parsing rejects the
+// line, although UnmarshalText keeps such columns in Module.Extra.
+func extraColumn(n int, elem []string) int {
+	return n + len(elem[0]) + len(elem[1]) + len(elem[2]) + 3 + 1
+}
 
-	readEntryFirstLine := func(elem []string) (Module, bool) {
-		if len(elem) != 2 && len(elem) != 3 {
+// readEntryFirstLine parses the tab-separated columns
+// of a module line: path, version, and optional checksum,
+// followed by any extra columns, each decoded by enc.
+func readEntryFirstLine(elem []string, enc FieldEncoder) (Module, bool) {
+	if len(elem) < 2 {
+		return Module{}, false
+	}
+	for i, e := range elem {
+		d, err := enc.Decode(e)
+		if err != nil {
 			return Module{}, false
 		}
-		sum := ""
-		if len(elem) == 3 {
-			sum = elem[2]
+		elem[i] = d
+	}
+	m := Module{
+		Path:    elem[0],
+		Version: elem[1],
+	}
+	if len(elem) >= 3 {
+		m.Sum = elem[2]
+	}
+	if len(elem) > 3 {
+		// elem may be the caller's scratch space; copy.
+		m.Extra = append([]string(nil), elem[3:]...)
+	}
+	return m, true
+}
+
+// ValidateBlob checks that data is a well-framed build information
+// blob as embedded in binaries by cmd/go: at least 32 bytes long,
+// beginning and ending with the 16-byte sentinels that cmd/go places
+// around the text, and holding valid UTF-8 text between them.
+// It does not check the text itself.
+func ValidateBlob(data []byte) error {
+	return validateBlob(string(data))
+}
+
+func validateBlob(data string) error {
+	switch {
+	case len(data) < len(infoStart)+len(infoEnd):
+		return errors.New("build information blob too short: " + strconv.Itoa(len(data)) + " bytes")
+	case !strings.HasPrefix(data, infoStart):
+		return errors.New("build information blob does not begin with start sentinel")
+	case !strings.HasSuffix(data, infoEnd):
+		return errors.New("build information blob does not end with end sentinel")
+	case !utf8.ValidString(data[len(infoStart) : len(data)-len(infoEnd)]):
+		return errors.New("build information blob is not valid UTF-8")
+	}
+	return nil
+}
+
+// readBuildInfo parses the module information returned by modinfo,
+// which is framed by 16-byte sentinels.
+func readBuildInfo(data string) (*BuildInfo, bool) {
+	info, err := readBuildInfoErr(data)
+	return info, err == nil
+}
+
+// readBuildInfoErr is like readBuildInfo but returns the error
+// described at ReadBuildInfoErr.
+func readBuildInfoErr(data string) (*BuildInfo, error) {
+	if data == "" {
+		return nil, ErrNoBuildInfo
+	}
+	if err := validateBlob(data); err != nil {
+		return nil, err
+	}
+	info, err := parseBuildInfo(data[len(infoStart) : len(data)-len(infoEnd)])
+	if err != nil {
+		if le, ok := err.(*lenientError); ok {
+			return nil, &le.pos
 		}
-		return Module{
-			Path:    elem[0],
-			Version: elem[1],
-			Sum:     sum,
-		}, true
+		return nil, err
 	}
+	return info, nil
+}
+
+// parseBuildInfo parses the text format of build information.
+// It is the reverse of cmd/go/internal/modload.PackageBuildInfo.
+func parseBuildInfo(data string) (*BuildInfo, error) {
+	return parseBuildInfoWith(data, defaultFieldEncoder)
+}
 
+// parseBuildInfoWith parses the text format of build information,
+// decoding its fields with enc.
+func parseBuildInfoWith(data string, enc FieldEncoder) (*BuildInfo, error) {
+	return parseText(data, enc, false)
+}
+
+// parseText parses the text format of build information, decoding its
+// fields with enc. In strict mode, as described at UnmarshalTextStrict,
+// it rejects more malformed input and reports errors as *TextError.
+//
+// The fields of the result are substrings of data, not copies, and the
+// modules and settings are allocated together, so that parsing takes a
+// handful of allocations however long the dependency list is.
+func parseText(data string, enc FieldEncoder, strict bool) (*BuildInfo, error) {
 	var (
-		info = &BuildInfo{}
-		last *Module
-		line string
-		ok   bool
+		info    = &BuildInfo{}
+		limits  = currentTextLimits()
+		mods    []Module // backing store for Deps and replacements
+		cols    [4]string
+		last    *Module
+		line    string
+		ok      bool
+		lineno  int
+		format  int // version declared by the format line, if any
+		sawGo   bool
+		sawMod  bool
+		sawPath bool
 	)
-	// Reverse of cmd/go/internal/modload.PackageBuildInfo
+	// fail returns the error for the current line, with the problem
+	// starting at the given byte column.
+	fail := func(err error, col int, msg string) error {
+		pos := TextError{Line: lineno, Column: col, Text: line, Msg: msg, Err: ErrSyntax}
+		if !strict {
+			if err == nil {
+				err = errBadLine(line)
+			}
+			return &lenientError{pos: pos, err: err}
+		}
+		return &pos
+	}
+	// Size Deps, Settings, and the Modules of dep and => lines up front
+	// by counting their lines. Lines are counted after a newline, which
+	// misses the first line, so allow for one more.
+	// When limits are set, the counts are capped by them, and the loop
+	// below rejects the input before going past them.
+	capped := func(n, limit int) int {
+		if limit > 0 && n > limit {
+			return limit
+		}
+		return n
+	}
+	if n := strings.Count(data, "\n"+depLine); n > 0 || strings.HasPrefix(data, depLine) {
+		info.Deps = make([]*Module, 0, capped(n+1, limits.MaxDeps))
+	}
+	if n := strings.Count(data, "\n"+buildLine); n > 0 || strings.HasPrefix(data, buildLine) {
+		info.Settings = make([]BuildSetting, 0, capped(n+1, limits.MaxLines))
+	}
+	mods = make([]Module, 0, cap(info.Deps)+capped(strings.Count(data, "\n"+repLine), limits.MaxLines))
+	// newModule returns the next Module from mods.
+	newModule := func() *Module {
+		if len(mods) == cap(mods) {
+			mods = make([]Module, 0, 8)
+		}
+		mods = mods[:len(mods)+1]
+		return &mods[len(mods)-1]
+	}
 	for len(data) > 0 {
+		lineno++
 		i := strings.IndexByte(data, '\n')
 		if i < 0 {
-			break
+			if strict {
+				return nil, &TextError{Line: lineno, Column: len(data) + 1, Text: data, Msg: "missing newline at end of input", Err: ErrTruncated}
+			}
+			// Accept a final line without a newline.
+			line, data = data, ""
+		} else {
+			line, data = data[:i], data[i+1:]
+		}
+		if limits.MaxLines > 0 && lineno > limits.MaxLines {
+			return nil, limitError(lineno, line, limits, limitMsg("too many lines", limits.MaxLines))
+		}
+		if limits.MaxLineLength > 0 && len(line) > limits.MaxLineLength {
+			return nil, limitError(lineno, line, limits, limitMsg("line too long", limits.MaxLineLength))
 		}
-		line, data = data[:i], data[i+1:]
 		switch {
+		case strings.HasPrefix(line, formatLine):
+			v := line[len(formatLine):]
+			if !isNum(v) || v == "0" || len(v) > 9 {
+				return nil, fail(nil, len(formatLine)+1, "invalid format version")
+			}
+			if strict && format != 0 {
+				return nil, fail(nil, 1, "duplicate format line")
+			}
+			format, _ = strconv.Atoi(v)
+		case strings.HasPrefix(line, goLine):
+			elem, err := enc.Decode(line[len(goLine):])
+			if err != nil {
+				return nil, fail(nil, len(goLine)+1, "invalid go version")
+			}
+			if strict && sawGo {
+				return nil, fail(nil, 1, "duplicate go line")
+			}
+			sawGo = true
+			info.GoVersion = elem
 		case strings.HasPrefix(line, pathLine):
-			elem := line[len(pathLine):]
+			elem, err := enc.Decode(line[len(pathLine):])
+			if err != nil {
+				return nil, fail(nil, len(pathLine)+1, "invalid path")
+			}
+			if strict && sawPath {
+				return nil, fail(nil, 1, "duplicate path line")
+			}
+			sawPath = true
 			info.Path = elem
 		case strings.HasPrefix(line, modLine):
-			elem := strings.Split(line[len(modLine):], "\t")
+			if strict && sawMod {
+				return nil, fail(nil, 1, "duplicate mod line")
+			}
+			sawMod = true
+			elem := splitColumns(line[len(modLine):], cols[:0])
+			if strict && len(elem) > 3 {
+				return nil, fail(nil, extraColumn(len(modLine), elem), "too many columns")
+			}
 			last = &info.Main
-			*last, ok = readEntryFirstLine(elem)
+			*last, ok = readEntryFirstLine(elem, enc)
 			if !ok {
-				return nil, false
+				return nil, fail(nil, len(modLine)+1, "invalid module")
 			}
 		case strings.HasPrefix(line, depLine):
-			elem := strings.Split(line[len(depLine):], "\t")
-			last = new(Module)
+			if limits.MaxDeps > 0 && len(info.Deps) == limits.MaxDeps {
+				return nil, limitError(lineno, line, limits, limitMsg("too many dependencies", limits.MaxDeps))
+			}
+			elem := splitColumns(line[len(depLine):], cols[:0])
+			if strict && len(elem) > 3 {
+				return nil, fail(nil, extraColumn(len(depLine), elem), "too many columns")
+			}
+			last = newModule()
 			info.Deps = append(info.Deps, last)
-			*last, ok = readEntryFirstLine(elem)
+			*last, ok = readEntryFirstLine(elem, enc)
 			if !ok {
-				return nil, false
+				return nil, fail(nil, len(depLine)+1, "invalid module")
 			}
 		case strings.HasPrefix(line, repLine):
-			elem := strings.Split(line[len(repLine):], "\t")
-			if len(elem) != 3 {
-				return nil, false
+			elem := splitColumns(line[len(repLine):], cols[:0])
+			if len(elem) < 3 {
+				return nil, fail(nil, len(repLine)+1, "invalid replacement")
+			}
+			if last == nil {
+				return nil, fail(errors.New("replacement without module: "+strconv.Quote(line)), 1, "replacement without module")
+			}
+			if strict && len(elem) > 3 {
+				return nil, fail(nil, extraColumn(len(repLine), elem), "too many columns")
+			}
+			repl, ok := readEntryFirstLine(elem, enc)
+			if !ok {
+				return nil, fail(nil, len(repLine)+1, "invalid replacement")
+			}
+			// A further => line replaces the replacement.
+			last.Replace = newModule()
+			last = last.Replace
+			*last = repl
+		case strings.HasPrefix(line, originLine):
+			elem := splitColumns(line[len(originLine):], cols[:0])
+			if len(elem) < 2 || len(elem) > 4 {
+				return nil, fail(nil, len(originLine)+1, "invalid origin")
+			}
+			if last == nil {
+				return nil, fail(errors.New("origin without module: "+strconv.Quote(line)), 1, "origin without module")
+			}
+			for i, e := range elem {
+				d, err := enc.Decode(e)
+				if err != nil {
+					return nil, fail(nil, len(originLine)+1, "invalid origin")
+				}
+				elem[i] = d
+			}
+			o := &Origin{VCS: elem[0], URL: elem[1]}
+			if len(elem) > 2 {
+				o.Ref = elem[2]
+			}
+			if len(elem) > 3 {
+				o.Hash = elem[3]
+			}
+			last.Origin = o
+		case strings.HasPrefix(line, licenseLine):
+			elem, err := enc.Decode(line[len(licenseLine):])
+			if err != nil || elem == "" || strings.IndexByte(elem, '\t') >= 0 {
+				return nil, fail(nil, len(licenseLine)+1, "invalid license")
 			}
 			if last == nil {
-				return nil, false
+				return nil, fail(errors.New("license without module: "+strconv.Quote(line)), 1, "license without module")
 			}
-			last.Replace = &Module{
-				Path:    elem[0],
-				Version: elem[1],
-				Sum:     elem[2],
+			last.License = elem
+		case strings.HasPrefix(line, buildLine):
+			elem := line[len(buildLine):]
+			var s BuildSetting
+			if i := strings.IndexByte(elem, '='); i >= 0 {
+				s.Key, s.Value = elem[:i], elem[i+1:]
+			} else {
+				s.Key = elem
 			}
-			last = nil
+			var err1, err2 error
+			s.Key, err1 = enc.Decode(s.Key)
+			s.Value, err2 = enc.Decode(s.Value)
+			if s.Key == "" || err1 != nil || err2 != nil {
+				return nil, fail(nil, len(buildLine)+1, "invalid setting")
+			}
+			info.Settings = append(info.Settings, s)
+		case line == "":
+			if strict {
+				return nil, fail(nil, 1, "blank line")
+			}
+		default:
+			if strict && format <= TextFormatVersion {
+				return nil, fail(nil, 1, "unknown line prefix")
+			}
+			info.Raw = append(info.Raw, line)
+		}
+	}
+	return info, nil
+}
+
+// A lenientError is an error from parseText outside strict mode. It
+// reads as the plain error that such parsing reports, but records the
+// position of the problem, for ReadBuildInfoErr.
+type lenientError struct {
+	pos TextError
+	err error
+}
+
+func (e *lenientError) Error() string { return e.err.Error() }
+func (e *lenientError) Unwrap() error { return e.err }
+
+func errBadLine(line string) error {
+	return errors.New("invalid build information line: " + strconv.Quote(line))
+}
+
+func readMainModule(data string) (Module, bool) {
+	if validateBlob(data) != nil {
+		return Module{}, false
+	}
+	data = data[len(infoStart) : len(data)-len(infoEnd)]
+	for len(data) > 0 {
+		i := strings.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		var line string
+		line, data = data[:i], data[i+1:]
+		if strings.HasPrefix(line, modLine) {
+			var cols [4]string
+			return readEntryFirstLine(splitColumns(line[len(modLine):], cols[:0]), defaultFieldEncoder)
 		}
 	}
-	return info, true
+	return Module{}, false
 }
@@ -0,0 +1,510 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	. "runtime/debug"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// The sentinels framing the module information embedded by cmd/go.
+const (
+	infoStart = "\x30\x77\xaf\x0c\x92\x74\x08\x02\x41\xe1\xc1\x07\xe6\xd6\x18\xe6"
+	infoEnd   = "\xf9\x32\x43\x31\x86\x18\x20\x72\x00\x82\x42\x10\x41\x16\xd8\xf2"
+)
+
+const testModinfo = "path\texample.com/cmd/hello\n" +
+	"mod\texample.com/hello\tv1.2.3\th1:c0ffee=\n" +
+	"dep\tgolang.org/x/text\tv0.3.3\th1:cafe=\n" +
+	"dep\trsc.io/quote\tv1.5.2\n" +
+	"=>\trsc.io/quote\tv1.0.0\th1:beef=\n" +
+	"build\t-compiler=gc\n" +
+	"build\tCGO_ENABLED=1\n"
+
+// formatHeader is the format line that begins the text form written by
+// MarshalText, but not that written by cmd/go.
+const formatHeader = "format\t3\n"
+
+func blob(text string) string {
+	return infoStart + text + infoEnd
+}
+
+// bigModinfo returns module information listing n dependencies.
+func bigModinfo(n int) string {
+	var b strings.Builder
+	b.WriteString("path\texample.com/cmd/hello\n")
+	b.WriteString("mod\texample.com/hello\tv1.2.3\th1:c0ffee=\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "dep\texample.com/dep%d\tv1.0.%d\th1:cafe=\n", i, i)
+	}
+	return b.String()
+}
+
+func TestReadMainModule(t *testing.T) {
+	m, ok := ReadMainModuleData(blob(testModinfo))
+	if !ok {
+		t.Fatal("ReadMainModule failed")
+	}
+	want := Module{Path: "example.com/hello", Version: "v1.2.3", Sum: "h1:c0ffee="}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("ReadMainModule = %+v, want %+v", m, want)
+	}
+
+	info, ok := ReadBuildInfoData(blob(testModinfo))
+	if !ok {
+		t.Fatal("ReadBuildInfo failed")
+	}
+	if !reflect.DeepEqual(info.Main, m) {
+		t.Errorf("ReadBuildInfo().Main = %+v, want %+v", info.Main, m)
+	}
+
+	for _, data := range []string{"", "short", blob("path\tx\n")} {
+		if m, ok := ReadMainModuleData(data); ok {
+			t.Errorf("ReadMainModule(%q) = %+v, true, want false", data, m)
+		}
+	}
+}
+
+func TestReadBuildInfoErr(t *testing.T) {
+	if _, err := ReadBuildInfoErrData(""); err != ErrNoBuildInfo {
+		t.Errorf("ReadBuildInfoErr of no data: %v, want ErrNoBuildInfo", err)
+	}
+	if _, err := ReadBuildInfoErrData("short"); err == nil || err == ErrNoBuildInfo {
+		t.Errorf("ReadBuildInfoErr of short blob: %v, want a corruption error", err)
+	}
+
+	_, err := ReadBuildInfoErrData(blob("path\texample.com/hello\nmod\texample.com/hello\n"))
+	te, ok := err.(*TextError)
+	if !ok {
+		t.Fatalf("ReadBuildInfoErr of bad mod line: %v, want *TextError", err)
+	}
+	if te.Line != 2 || te.Column != 5 || te.Text != "mod\texample.com/hello" || te.Err != ErrSyntax {
+		t.Errorf("ReadBuildInfoErr of bad mod line: %+v", te)
+	}
+
+	info, err := ReadBuildInfoErrData(blob(testModinfo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := ReadBuildInfoData(blob(testModinfo)); !reflect.DeepEqual(info, want) {
+		t.Errorf("ReadBuildInfoErr = %+v, want %+v", info, want)
+	}
+}
+
+func BenchmarkReadMainModule(b *testing.B) {
+	data := blob(bigModinfo(500))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, ok := ReadMainModuleData(data); !ok {
+			b.Fatal("ReadMainModule failed")
+		}
+	}
+}
+
+func BenchmarkReadBuildInfo(b *testing.B) {
+	data := blob(bigModinfo(500))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, ok := ReadBuildInfoData(data); !ok {
+			b.Fatal("ReadBuildInfo failed")
+		}
+	}
+}
+
+func TestUnmarshalTextAllocs(t *testing.T) {
+	// The first line and long chains of replacements are not counted
+	// when the parser sizes its storage in advance.
+	text := "dep\ta\tv1.0.0\t\n=>\tb\tv1.0.0\t\n=>\tc\tv1.0.0\t\n" +
+		"dep\td\tv1.0.0\th1:x=\te1\te2\te3\n"
+	var info BuildInfo
+	if err := info.UnmarshalText([]byte(text)); err != nil {
+		t.Fatal(err)
+	}
+	want := &BuildInfo{Deps: []*Module{
+		{Path: "a", Version: "v1.0.0", Replace: &Module{Path: "b", Version: "v1.0.0",
+			Replace: &Module{Path: "c", Version: "v1.0.0"}}},
+		{Path: "d", Version: "v1.0.0", Sum: "h1:x=", Extra: []string{"e1", "e2", "e3"}},
+	}}
+	if !reflect.DeepEqual(&info, want) {
+		t.Errorf("UnmarshalText(%q) = %+v, want %+v", text, &info, want)
+	}
+
+	data := blob(bigModinfo(500))
+	if n := testing.AllocsPerRun(10, func() { ReadBuildInfoData(data) }); n > 5 {
+		t.Errorf("parsing 500 dependencies took %v allocations, want at most 5", n)
+	}
+}
+
+func BenchmarkUnmarshalText(b *testing.B) {
+	text := []byte(bigModinfo(500))
+	b.ReportAllocs()
+	b.SetBytes(int64(len(text)))
+	for i := 0; i < b.N; i++ {
+		var info BuildInfo
+		if err := info.UnmarshalText(text); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestReadBuildInfoConcurrent(t *testing.T) {
+	data := blob(testModinfo)
+	want, wantOK := ReadBuildInfoData(data)
+	self, selfOK := ReadBuildInfo()
+
+	const n = 200
+	var wg sync.WaitGroup
+	errc := make(chan string, 2*n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if info, ok := ReadBuildInfoData(data); ok != wantOK || !reflect.DeepEqual(info, want) {
+				errc <- fmt.Sprintf("ReadBuildInfoData = %+v, %v, want %+v, %v", info, ok, want, wantOK)
+			}
+			if info, ok := ReadBuildInfo(); ok != selfOK || !reflect.DeepEqual(info, self) {
+				errc <- fmt.Sprintf("ReadBuildInfo = %+v, %v, want %+v, %v", info, ok, self, selfOK)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errc)
+	for err := range errc {
+		t.Error(err)
+	}
+}
+
+func TestReplaceChain(t *testing.T) {
+	text := formatHeader +
+		"mod\texample.com/hello\t(devel)\t\n" +
+		"dep\texample.com/a\tv1.0.0\n" +
+		"=>\texample.com/b\tv1.1.0\t\n" +
+		"=>\texample.com/c\tv1.2.0\t\n" +
+		"=>\t../c\t\t\n" +
+		"dep\texample.com/d\tv1.0.0\th1:d=\n"
+	var info BuildInfo
+	if err := info.UnmarshalTextStrict([]byte(text)); err != nil {
+		t.Fatal(err)
+	}
+	want := &BuildInfo{
+		Main: Module{Path: "example.com/hello", Version: "(devel)"},
+		Deps: []*Module{
+			{Path: "example.com/a", Version: "v1.0.0", Replace: &Module{
+				Path: "example.com/b", Version: "v1.1.0", Replace: &Module{
+					Path: "example.com/c", Version: "v1.2.0", Replace: &Module{
+						Path: "../c"}}}},
+			{Path: "example.com/d", Version: "v1.0.0", Sum: "h1:d="},
+		},
+	}
+	if !reflect.DeepEqual(&info, want) {
+		t.Errorf("UnmarshalText =\n%+v\nwant\n%+v", &info, want)
+	}
+	if out, _ := want.MarshalText(); string(out) != text {
+		t.Errorf("MarshalText:\n%s\nwant:\n%s", out, text)
+	}
+}
+
+func TestRawBuildInfo(t *testing.T) {
+	text := testModinfo + "future\tline\xff\n"
+	if got, ok := RawBuildInfoData(blob(text)); !ok || got != text {
+		t.Errorf("RawBuildInfo = %q, %v, want %q", got, ok, text)
+	}
+	for _, bad := range []string{"", infoStart, infoStart + testModinfo, testModinfo + infoEnd} {
+		if got, ok := RawBuildInfoData(bad); ok {
+			t.Errorf("RawBuildInfo(%q) = %q, true, want false", bad, got)
+		}
+	}
+	if got, ok := RawBuildInfoData(blob("")); !ok || got != "" {
+		t.Errorf("RawBuildInfo(empty) = %q, %v, want \"\", true", got, ok)
+	}
+}
+
+func TestTextRoundTrip(t *testing.T) {
+	var info BuildInfo
+	if err := info.UnmarshalText([]byte(testModinfo)); err != nil {
+		t.Fatal(err)
+	}
+	text, err := info.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := formatHeader + testModinfo; string(text) != want {
+		t.Errorf("MarshalText:\n%s\nwant:\n%s", text, want)
+	}
+	if got, want := fmt.Sprint(&info), formatHeader+testModinfo; got != want {
+		t.Errorf("fmt.Sprint:\n%s\nwant:\n%s", got, want)
+	}
+
+	for _, bad := range []string{
+		"mod\texample.com/hello\n",
+		"=>\texample.com/hello\tv1.0.0\th1:x=\n",
+		"dep\texample.com/a\tv1.0.0\n=>\texample.com/b\n",
+		"build\t=x\n",
+	} {
+		if err := new(BuildInfo).UnmarshalText([]byte(bad)); err == nil {
+			t.Errorf("UnmarshalText(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestTextOrigin(t *testing.T) {
+	text := formatHeader +
+		"path\texample.com/cmd/hello\n" +
+		"mod\texample.com/hello\tv1.2.3\th1:c0ffee=\n" +
+		"origin\tgit\thttps://example.com/hello.git\trefs/tags/v1.2.3\tabcdef\n" +
+		"dep\trsc.io/quote\tv1.5.2\n" +
+		"=>\texample.com/quote\tv1.0.0\th1:beef=\n" +
+		"origin\tgit\thttps://example.com/quote.git\n"
+	var info BuildInfo
+	if err := info.UnmarshalTextStrict([]byte(text)); err != nil {
+		t.Fatal(err)
+	}
+	want := &BuildInfo{
+		Path: "example.com/cmd/hello",
+		Main: Module{Path: "example.com/hello", Version: "v1.2.3", Sum: "h1:c0ffee=",
+			Origin: &Origin{VCS: "git", URL: "https://example.com/hello.git", Ref: "refs/tags/v1.2.3", Hash: "abcdef"}},
+		Deps: []*Module{{Path: "rsc.io/quote", Version: "v1.5.2",
+			Replace: &Module{Path: "example.com/quote", Version: "v1.0.0", Sum: "h1:beef=",
+				Origin: &Origin{VCS: "git", URL: "https://example.com/quote.git"}}}},
+	}
+	if !reflect.DeepEqual(&info, want) {
+		t.Errorf("UnmarshalText:\n%+v\nwant:\n%+v", &info, want)
+	}
+	if got := info.String(); got != text {
+		t.Errorf("String:\n%s\nwant:\n%s", got, text)
+	}
+	if c := info.Clone(); !reflect.DeepEqual(c, want) || c.Main.Origin == info.Main.Origin {
+		t.Errorf("Clone does not copy origins")
+	}
+
+	js, err := info.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromJSON BuildInfo
+	if err := fromJSON.UnmarshalJSON(js); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&fromJSON, want) {
+		t.Errorf("JSON round trip:\n%+v\nwant:\n%+v\nJSON: %s", &fromJSON, want, js)
+	}
+
+	for _, bad := range []string{
+		"origin\tgit\thttps://example.com/x.git\n",
+		"mod\tx\tv1.0.0\t\norigin\tgit\n",
+		"mod\tx\tv1.0.0\t\norigin\tgit\tu\tr\th\textra\n",
+	} {
+		if err := new(BuildInfo).UnmarshalText([]byte(bad)); err == nil {
+			t.Errorf("UnmarshalText(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestTextFormat(t *testing.T) {
+	// A later version of the format may add kinds of lines,
+	// which are kept and written back after the settings.
+	text := "format\t4\n" +
+		"path\texample.com/cmd/hello\n" +
+		"future\tline\n" +
+		"mod\texample.com/hello\tv1.2.3\t\n" +
+		"build\t-compiler=gc\n"
+	var info BuildInfo
+	if err := info.UnmarshalTextStrict([]byte(text)); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"future\tline"}; !reflect.DeepEqual(info.Raw, want) {
+		t.Errorf("Raw = %q, want %q", info.Raw, want)
+	}
+	want := formatHeader +
+		"path\texample.com/cmd/hello\n" +
+		"mod\texample.com/hello\tv1.2.3\t\n" +
+		"build\t-compiler=gc\n" +
+		"future\tline\n"
+	if got := info.String(); got != want {
+		t.Errorf("String:\n%s\nwant:\n%s", got, want)
+	}
+	js, err := info.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromJSON BuildInfo
+	if err := fromJSON.UnmarshalJSON(js); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromJSON.Raw, info.Raw) {
+		t.Errorf("JSON round trip: Raw = %q, want %q", fromJSON.Raw, info.Raw)
+	}
+	if c := info.Clone(); !reflect.DeepEqual(c.Raw, info.Raw) || &c.Raw[0] == &info.Raw[0] {
+		t.Errorf("Clone does not copy Raw")
+	}
+
+	// Text without a format line, as written by cmd/go, is version 1,
+	// in which unknown lines are rejected by strict parsing.
+	for _, legacy := range []string{testModinfo, formatHeader + testModinfo} {
+		if err := new(BuildInfo).UnmarshalTextStrict([]byte(legacy + "future\tline\n")); err == nil {
+			t.Errorf("UnmarshalTextStrict accepted unknown line in %q", legacy)
+		}
+		if err := new(BuildInfo).UnmarshalText([]byte(legacy + "future\tline\n")); err != nil {
+			t.Errorf("UnmarshalText: %v", err)
+		}
+	}
+	for _, bad := range []string{"format\t\n", "format\t0\n", "format\tv2\n", "format\t02\n"} {
+		if err := new(BuildInfo).UnmarshalText([]byte(bad)); err == nil {
+			t.Errorf("UnmarshalText(%q) succeeded, want error", bad)
+		}
+	}
+	if err := new(BuildInfo).UnmarshalTextStrict([]byte(formatHeader + formatHeader)); err == nil {
+		t.Errorf("UnmarshalTextStrict accepted duplicate format line")
+	}
+}
+
+func TestTextGoVersion(t *testing.T) {
+	text := "go\tgo1.15.2\n" + testModinfo
+	var info BuildInfo
+	if err := info.UnmarshalText([]byte(text)); err != nil {
+		t.Fatal(err)
+	}
+	if info.GoVersion != "go1.15.2" || info.Path != "example.com/cmd/hello" {
+		t.Errorf("UnmarshalText: GoVersion = %q, Path = %q, want go1.15.2, example.com/cmd/hello", info.GoVersion, info.Path)
+	}
+	if got, want := info.String(), formatHeader+text; got != want {
+		t.Errorf("String:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Module information written by cmd/go begins with the go line,
+	// and may be read from a file.
+	dir, err := ioutil.TempDir("", "modtext")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	f, err := ReadBuildInfoFromFile(writeExe(t, dir, text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.GoVersion != "go1.15.2" {
+		t.Errorf("ReadBuildInfoFromFile: GoVersion = %q, want go1.15.2", f.GoVersion)
+	}
+}
+
+func TestTextExtraColumns(t *testing.T) {
+	const text = formatHeader +
+		"path\texample.com/cmd/hello\n" +
+		"mod\texample.com/hello\tv1.2.3\th1:c0ffee=\n" +
+		"dep\tgolang.org/x/text\tv0.3.3\th1:cafe=\t2020-07-01T12:00:00Z\tmirror-a\n" +
+		"dep\trsc.io/quote\tv1.5.2\t\t2020-07-02T12:00:00Z\n" +
+		"=>\trsc.io/quote\tv1.0.0\th1:beef=\t2020-07-03T12:00:00Z\n"
+	var info BuildInfo
+	if err := info.UnmarshalText([]byte(text)); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"2020-07-01T12:00:00Z", "mirror-a"}; !reflect.DeepEqual(info.Deps[0].Extra, want) {
+		t.Errorf("Deps[0].Extra = %q, want %q", info.Deps[0].Extra, want)
+	}
+	if info.Deps[1].Sum != "" || len(info.Deps[1].Extra) != 1 {
+		t.Errorf("Deps[1] = %+v, want empty Sum and one extra column", info.Deps[1])
+	}
+	if r := info.Deps[1].Replace; r == nil || len(r.Extra) != 1 {
+		t.Errorf("Deps[1].Replace = %+v, want one extra column", r)
+	}
+	if info.Main.Extra != nil {
+		t.Errorf("Main.Extra = %q, want nil", info.Main.Extra)
+	}
+	out, err := info.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != text {
+		t.Errorf("MarshalText:\n%s\nwant:\n%s", out, text)
+	}
+}
+
+func TestValidateBlob(t *testing.T) {
+	for _, tt := range []struct {
+		data string
+		err  string
+	}{
+		{blob(testModinfo), ""},
+		{blob(""), ""},
+		{"path\tx\n", "too short"},
+		{"x" + blob(testModinfo)[1:], "start sentinel"},
+		{blob(testModinfo)[:len(blob(testModinfo))-1] + "x", "end sentinel"},
+		{blob("path\t\xff\n"), "UTF-8"},
+	} {
+		err := ValidateBlob([]byte(tt.data))
+		if tt.err == "" {
+			if err != nil {
+				t.Errorf("ValidateBlob(%q) = %v, want nil", tt.data, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("ValidateBlob(%q) = %v, want error containing %q", tt.data, err, tt.err)
+		}
+		if _, ok := ReadBuildInfoData(tt.data); ok {
+			t.Errorf("ReadBuildInfo(%q) succeeded on invalid blob", tt.data)
+		}
+	}
+}
+
+func TestMarshalTextCanonical(t *testing.T) {
+	a := &BuildInfo{
+		Path: "example.com/cmd/hello",
+		Main: Module{Path: "example.com/hello", Version: "v1.2.3"},
+		Deps: []*Module{
+			{Path: "rsc.io/quote", Version: "v1.5.2", Replace: &Module{Path: "rsc.io/quote", Version: "v1.0.0",
+				Replace: &Module{Path: "../quote"}}},
+			nil,
+			{Path: "golang.org/x/text", Version: "v0.3.10"},
+			{Path: "golang.org/x/text", Version: "v0.3.3", Replace: &Module{Path: "golang.org/x/text", Version: "v0.3.3", Sum: "h1:x="}},
+			{Path: "example.com/a", Version: "v1.0.0", Sum: "h1:b="},
+			{Path: "example.com/a", Version: "v1.0.0", Sum: "h1:a="},
+		},
+		Settings: []BuildSetting{{Key: "GOOS", Value: "linux"}, {Key: "-tags", Value: "a"}, {Key: "-tags", Value: "b"}},
+	}
+	want := formatHeader +
+		"path\texample.com/cmd/hello\n" +
+		"mod\texample.com/hello\tv1.2.3\t\n" +
+		"dep\texample.com/a\tv1.0.0\th1:a=\n" +
+		"dep\texample.com/a\tv1.0.0\th1:b=\n" +
+		"dep\tgolang.org/x/text\tv0.3.3\t\n" +
+		"dep\tgolang.org/x/text\tv0.3.10\t\n" +
+		"dep\trsc.io/quote\tv1.5.2\n" +
+		"=>\t../quote\t\t\n" +
+		"build\t-tags=a\n" +
+		"build\t-tags=b\n" +
+		"build\tGOOS=linux\n"
+	text, err := a.MarshalTextCanonical()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(text) != want {
+		t.Errorf("MarshalTextCanonical:\n%s\nwant:\n%s", text, want)
+	}
+
+	// Reordering the dependencies and settings changes nothing,
+	// and a itself is left untouched.
+	b := a.Clone()
+	b.Deps[0], b.Deps[5] = b.Deps[5], b.Deps[0]
+	b.Deps[2], b.Deps[3] = b.Deps[3], b.Deps[2]
+	b.Settings[0], b.Settings[2] = b.Settings[2], b.Settings[0]
+	b.Settings[0], b.Settings[1] = b.Settings[1], b.Settings[0]
+	text, err = b.MarshalTextCanonical()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(text) != want {
+		t.Errorf("MarshalTextCanonical after reordering:\n%s\nwant:\n%s", text, want)
+	}
+	if a.Deps[0].Replace.Replace == nil || a.Deps[1] != nil {
+		t.Errorf("MarshalTextCanonical modified its receiver")
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"sort"
+	"time"
+)
+
+// AgeStats summarizes the ages of the dependencies of a build,
+// as reported by BuildInfo.AgeReport.
+type AgeStats struct {
+	Dated         int           // number of dependencies with a known commit time
+	Undated       int           // number of dependencies without one
+	Median        time.Duration // median age of the dated dependencies
+	Max           time.Duration // age of the oldest dated dependency
+	OlderThanYear int           // number of dated dependencies older than 365 days
+}
+
+// AgeReport reports how long before now the dependencies of bi were
+// committed, as a measure of how stale they are. The commit time of
+// a dependency is known only if its resolved version, that of the module
+// it resolves to as Module.Effective reports it, is a pseudo-version,
+// which records the time of the commit it names. Tagged versions carry
+// no date: determining when they were tagged would require consulting
+// the module's repository or proxy. Such dependencies, and those replaced
+// by local directories, are counted as Undated and do not contribute
+// to the other statistics, which are zero if no dependency is dated.
+// Dependencies committed after now count as zero age.
+func (bi *BuildInfo) AgeReport(now time.Time) AgeStats {
+	var stats AgeStats
+	var ages []time.Duration
+	for _, dep := range bi.Deps {
+		if dep == nil {
+			continue
+		}
+		v := dep.effective().Version
+		t, ok := pseudoVersionTime(v)
+		if !ok || v == zeroPseudoVersion {
+			stats.Undated++
+			continue
+		}
+		age := now.Sub(t)
+		if age < 0 {
+			age = 0
+		}
+		ages = append(ages, age)
+	}
+	stats.Dated = len(ages)
+	if len(ages) == 0 {
+		return stats
+	}
+	sort.Slice(ages, func(i, j int) bool { return ages[i] < ages[j] })
+	if n := len(ages); n%2 == 1 {
+		stats.Median = ages[n/2]
+	} else {
+		stats.Median = ages[n/2-1] + (ages[n/2]-ages[n/2-1])/2
+	}
+	stats.Max = ages[len(ages)-1]
+	for _, age := range ages {
+		if age > 365*24*time.Hour {
+			stats.OlderThanYear++
+		}
+	}
+	return stats
+}
@@ -0,0 +1,50 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	. "runtime/debug"
+	"testing"
+	"time"
+)
+
+func TestAgeReport(t *testing.T) {
+	now := time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	info := &BuildInfo{
+		Deps: []*Module{
+			{Path: "example.com/week", Version: "v0.0.0-20200624000000-abcdefabcdef"},
+			{Path: "example.com/month", Version: "v1.2.4-0.20200601000000-abcdefabcdef"},
+			{Path: "example.com/old", Version: "v1.0.0", Replace: &Module{Path: "example.com/fork", Version: "v1.0.1-pre.0.20190101000000-abcdefabcdef"}},
+			{Path: "example.com/older", Version: "v2.0.0-20180701000000-abcdefabcdef+incompatible"},
+			{Path: "example.com/tagged", Version: "v1.5.2"},
+			{Path: "example.com/local", Version: "v0.0.0-00010101000000-000000000000", Replace: &Module{Path: "../local"}},
+			{Path: "example.com/zero", Version: "v0.0.0-00010101000000-000000000000"},
+			nil,
+		},
+	}
+	want := AgeStats{
+		Dated:         4,
+		Undated:       3,
+		Median:        (30*day + 547*day) / 2,
+		Max:           731 * day,
+		OlderThanYear: 2,
+	}
+	if got := info.AgeReport(now); got != want {
+		t.Errorf("AgeReport() = %+v, want %+v", got, want)
+	}
+
+	info.Deps = info.Deps[:1]
+	want = AgeStats{Dated: 1, Median: 7 * day, Max: 7 * day}
+	if got := info.AgeReport(now); got != want {
+		t.Errorf("AgeReport() with one dependency = %+v, want %+v", got, want)
+	}
+	if got := info.AgeReport(now.Add(-30 * day)); got.Median != 0 || got.Max != 0 {
+		t.Errorf("AgeReport() before commit = %+v, want zero ages", got)
+	}
+	if got := new(BuildInfo).AgeReport(now); got != (AgeStats{}) {
+		t.Errorf("AgeReport() without dependencies = %+v, want zero", got)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// ReadBuildInfoFromAr returns the build information carried by a member
+// of the ar archive read from r, such as the go.o member of a static
+// library built with -buildmode=c-archive. If member is not empty, only
+// the member with that name is examined; otherwise each member is
+// examined in turn and the first one carrying build information is
+// used. Member names are read in the common System V (GNU) form,
+// including long names stored in the "//" member, and in the BSD form
+// "#1/n"; the symbol table members "/" and "__.SYMDEF" are skipped.
+// ReadBuildInfoFromAr returns nil, false if r is not an ar archive or
+// no examined member carries build information.
+func ReadBuildInfoFromAr(r io.Reader, member string) (*BuildInfo, bool) {
+	ar := &arReader{r: r}
+	if !ar.init() {
+		return nil, false
+	}
+	for {
+		name, data, err := ar.next()
+		if err != nil {
+			return nil, false
+		}
+		if member != "" && name != member {
+			continue
+		}
+		if blob, err := findModinfo(bytes.NewReader(data), int64(len(data))); err == nil {
+			if info, ok := readBuildInfo(blob); ok {
+				return info, true
+			}
+		}
+		if member != "" {
+			return nil, false
+		}
+	}
+}
+
+const (
+	arMagic      = "!<arch>\n"
+	arHeaderSize = 60
+)
+
+var errBadAr = errors.New("malformed ar archive")
+
+// An arReader reads the members of an ar archive.
+type arReader struct {
+	r         io.Reader
+	longNames string // contents of the "//" member
+}
+
+func (ar *arReader) init() bool {
+	var magic [len(arMagic)]byte
+	_, err := io.ReadFull(ar.r, magic[:])
+	return err == nil && string(magic[:]) == arMagic
+}
+
+// next returns the name and contents of the next member other than a
+// symbol table or long name table. It returns io.EOF at the end of
+// the archive.
+func (ar *arReader) next() (name string, data []byte, err error) {
+	for {
+		var hdr [arHeaderSize]byte
+		if _, err := io.ReadFull(ar.r, hdr[:]); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				err = errBadAr
+			}
+			return "", nil, err
+		}
+		if string(hdr[58:60]) != "`\n" {
+			return "", nil, errBadAr
+		}
+		size, err := strconv.ParseInt(strings.TrimSpace(string(hdr[48:58])), 10, 64)
+		if err != nil || size < 0 || size > 1<<40 {
+			return "", nil, errBadAr
+		}
+		data, err := ioutil.ReadAll(io.LimitReader(ar.r, size))
+		if err != nil {
+			return "", nil, err
+		}
+		if int64(len(data)) != size {
+			return "", nil, errBadAr
+		}
+		if size%2 == 1 {
+			// Members are padded to an even offset, except perhaps the last.
+			if _, err := io.ReadFull(ar.r, make([]byte, 1)); err != nil && err != io.EOF {
+				return "", nil, err
+			}
+		}
+
+		name := strings.TrimRight(string(hdr[:16]), " ")
+		switch {
+		case name == "/" || name == "/SYM64/" || name == "__.SYMDEF" || name == "__.SYMDEF SORTED":
+			continue
+		case name == "//":
+			ar.longNames = string(data)
+			continue
+		case strings.HasPrefix(name, "#1/"):
+			// BSD: the name is stored at the start of the data.
+			n, err := strconv.Atoi(name[len("#1/"):])
+			if err != nil || n < 0 || n > len(data) {
+				return "", nil, errBadAr
+			}
+			name, data = strings.TrimRight(string(data[:n]), "\x00"), data[n:]
+		case len(name) > 1 && name[0] == '/':
+			// GNU: an offset into the long name table.
+			off, err := strconv.Atoi(name[1:])
+			if err != nil || off < 0 || off > len(ar.longNames) {
+				return "", nil, errBadAr
+			}
+			name = ar.longNames[off:]
+			if i := strings.IndexByte(name, '\n'); i >= 0 {
+				name = name[:i]
+			}
+			name = strings.TrimSuffix(name, "/")
+		default:
+			name = strings.TrimSuffix(name, "/")
+		}
+		return name, data, nil
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"bytes"
+	"fmt"
+	. "runtime/debug"
+	"testing"
+)
+
+// arArchive returns an ar archive holding the given members,
+// each a name, the name to record in its header, and contents.
+func arArchive(members ...[3]string) []byte {
+	var b bytes.Buffer
+	b.WriteString("!<arch>\n")
+	for _, m := range members {
+		fmt.Fprintf(&b, "%-16s%-12s%-6s%-6s%-8s%-10d`\n", m[1], "0", "0", "0", "644", len(m[2]))
+		b.WriteString(m[2])
+		if len(m[2])%2 == 1 {
+			b.WriteByte('\n')
+		}
+	}
+	return b.Bytes()
+}
+
+func TestReadBuildInfoFromAr(t *testing.T) {
+	withInfo := "\x00\x01object code\x00" + blob(testModinfo) + "more code"
+	gnu := arArchive(
+		[3]string{"", "/", "\x00\x00\x00\x00"},
+		[3]string{"", "//", "a_rather_long_member_name.o/\n"},
+		[3]string{"__.PKGDEF", "__.PKGDEF/", "go object linux amd64\n"},
+		[3]string{"other.o", "other.o/", "odd"},
+		[3]string{"a_rather_long_member_name.o", "/0", withInfo},
+	)
+	bsd := arArchive(
+		[3]string{"", "__.SYMDEF", "symbols."},
+		[3]string{"go.o", "#1/4", "go.o" + withInfo},
+	)
+	for _, tt := range []struct {
+		name    string
+		archive []byte
+		member  string
+		ok      bool
+	}{
+		{"gnu scan", gnu, "", true},
+		{"gnu long name", gnu, "a_rather_long_member_name.o", true},
+		{"gnu member without info", gnu, "other.o", false},
+		{"gnu missing member", gnu, "missing.o", false},
+		{"bsd scan", bsd, "", true},
+		{"bsd member", bsd, "go.o", true},
+		{"no info", arArchive([3]string{"a.o", "a.o/", "code"}), "", false},
+		{"not an archive", []byte(withInfo), "", false},
+		{"truncated", gnu[:len(gnu)-len(withInfo)/2], "", false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			info, ok := ReadBuildInfoFromAr(bytes.NewReader(tt.archive), tt.member)
+			if ok != tt.ok {
+				t.Fatalf("ReadBuildInfoFromAr(%q) ok = %v, want %v", tt.member, ok, tt.ok)
+			}
+			if ok && info.Main.Path != "example.com/hello" {
+				t.Errorf("ReadBuildInfoFromAr(%q) main module = %q", tt.member, info.Main.Path)
+			}
+		})
+	}
+}
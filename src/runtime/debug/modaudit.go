@@ -0,0 +1,199 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// A Severity grades the findings of an audit.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+var severityNames = []string{"info", "warning", "error"}
+
+// String returns the name of s: "info", "warning", or "error".
+func (s Severity) String() string {
+	if s < 0 || int(s) >= len(severityNames) {
+		return "Severity(" + strconv.Itoa(int(s)) + ")"
+	}
+	return severityNames[s]
+}
+
+// MarshalText implements encoding.TextMarshaler, so that severities
+// appear by name in reports encoded as JSON.
+func (s Severity) MarshalText() ([]byte, error) {
+	if s < 0 || int(s) >= len(severityNames) {
+		return nil, errors.New("invalid severity " + s.String())
+	}
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *Severity) UnmarshalText(text []byte) error {
+	for i, name := range severityNames {
+		if string(text) == name {
+			*s = Severity(i)
+			return nil
+		}
+	}
+	return errors.New("unknown severity " + string(text))
+}
+
+// A Rule of an audit matches the dependencies meeting all of its
+// criteria; a Rule with none matches every dependency. Each dependency
+// is checked using the module it resolves to, as Module.Effective
+// reports it, except that Path matches the path the module is required
+// by as well.
+type Rule struct {
+	ID       string   // identifier of the rule, reported in findings
+	Message  string   // description of the problem, reported in findings
+	Severity Severity // severity of the findings
+
+	// Allow makes the rule an exception to the rules after it: a
+	// dependency it matches is not checked against them.
+	Allow bool
+
+	// Path, if not empty, is a pattern of the module paths matched,
+	// as for BuildInfo.DepsMatching.
+	Path string
+
+	// Versions, if not empty, matches the dependencies whose versions
+	// lie in one of the ranges. Dependencies without a valid semantic
+	// version, such as those replaced by local directories, do not.
+	Versions []VersionRange
+
+	// Replaced matches only replaced dependencies.
+	Replaced bool
+
+	// MissingSum matches only dependencies recorded without a
+	// checksum, as local directories always are.
+	MissingSum bool
+}
+
+// An AuditFinding reports a dependency matched by a rule of an audit.
+type AuditFinding struct {
+	Rule     string     // ID of the rule
+	Severity Severity   // severity of the rule
+	Dep      ModulePath // the dependency, as required
+	Resolved ModulePath // the module it resolves to
+	Message  string     // the rule's message, or why the dependency matched
+}
+
+// A Report is the result of an audit: the findings, in the order of
+// BuildInfo.Deps and then of the rules, about the dependencies of the
+// main module. A Report encodes as JSON, to be kept as the record of
+// the audit of a binary.
+type Report struct {
+	Main     ModulePath
+	Findings []AuditFinding
+}
+
+// HasSeverity reports whether r has a finding of severity s or higher,
+// so that a release process can fail on a report with errors.
+func (r *Report) HasSeverity(s Severity) bool {
+	for _, f := range r.Findings {
+		if f.Severity >= s {
+			return true
+		}
+	}
+	return false
+}
+
+// Audit checks each dependency of bi against rules, in order, and
+// returns a report of the dependencies matched by rules other than
+// those allowing them. A dependency matched by an Allow rule is not
+// checked against the rules after it, so that a list of rules can
+// exempt some modules from a rule applying to all the others:
+//
+//	rules := []debug.Rule{
+//		{Allow: true, Path: "example.com/internal/..."},
+//		{ID: "no-replace", Severity: debug.SeverityError, Replaced: true},
+//	}
+func Audit(bi *BuildInfo, rules []Rule) Report {
+	r := Report{Main: ModulePath{bi.Main.Path, bi.Main.Version}}
+	for _, dep := range bi.Deps {
+		if dep == nil {
+			continue
+		}
+		m := dep.effective()
+		for i := range rules {
+			rule := &rules[i]
+			reasons, ok := rule.match(dep, m)
+			if !ok {
+				continue
+			}
+			if rule.Allow {
+				break
+			}
+			msg := rule.Message
+			if msg == "" {
+				msg = strings.Join(reasons, "; ")
+			}
+			r.Findings = append(r.Findings, AuditFinding{
+				Rule:     rule.ID,
+				Severity: rule.Severity,
+				Dep:      ModulePath{dep.Path, dep.Version},
+				Resolved: ModulePath{m.Path, m.Version},
+				Message:  msg,
+			})
+		}
+	}
+	return r
+}
+
+// match reports whether the rule matches dep, which resolves to m,
+// and describes what matched.
+func (rule *Rule) match(dep, m *Module) (reasons []string, ok bool) {
+	if rule.Path != "" {
+		if !matchPattern(rule.Path, dep.Path) && !matchPattern(rule.Path, m.Path) {
+			return nil, false
+		}
+		reasons = append(reasons, "path matches "+rule.Path)
+	}
+	if len(rule.Versions) > 0 {
+		if !isValidSemver(m.Version) {
+			return nil, false
+		}
+		var in *VersionRange
+		for i, vr := range rule.Versions {
+			if versionInRange(m.Version, vr.Introduced, vr.Fixed) {
+				in = &rule.Versions[i]
+				break
+			}
+		}
+		if in == nil {
+			return nil, false
+		}
+		reasons = append(reasons, "version "+m.Version+" in range ["+in.Introduced+", "+in.Fixed+")")
+	}
+	if rule.Replaced {
+		if m == dep {
+			return nil, false
+		}
+		to := m.Path
+		if m.Version != "" {
+			to += "@" + m.Version
+		}
+		reasons = append(reasons, "replaced by "+to)
+	}
+	if rule.MissingSum {
+		if m.Sum != "" {
+			return nil, false
+		}
+		reasons = append(reasons, "no checksum")
+	}
+	if len(reasons) == 0 {
+		reasons = append(reasons, "matches all dependencies")
+	}
+	return reasons, true
+}
@@ -0,0 +1,72 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"encoding/json"
+	"reflect"
+	. "runtime/debug"
+	"testing"
+)
+
+func TestAudit(t *testing.T) {
+	bi := &BuildInfo{
+		Main: Module{Path: "example.com/main", Version: "v1.0.0"},
+		Deps: []*Module{
+			{Path: "golang.org/x/text", Version: "v0.3.2", Sum: "h1:a"},
+			{Path: "example.com/internal/x", Version: "v1.0.0", Replace: &Module{Path: "../x"}},
+			{Path: "example.com/a", Version: "v1.0.0", Replace: &Module{Path: "example.com/fork", Version: "v1.1.0", Sum: "h1:b"}},
+		},
+	}
+	rules := []Rule{
+		{ID: "text-cve", Severity: SeverityError, Message: "vulnerable", Path: "golang.org/x/text", Versions: []VersionRange{{"", "v0.3.3"}}},
+		{Allow: true, Path: "example.com/internal/..."},
+		{ID: "no-replace", Severity: SeverityWarning, Replaced: true},
+		{ID: "sums", Severity: SeverityInfo, MissingSum: true},
+		{ID: "fork-range", Severity: SeverityInfo, Path: "example.com/fork", Versions: []VersionRange{{"v1.1.0", ""}}},
+	}
+	r := Audit(bi, rules)
+	want := Report{
+		Main: ModulePath{"example.com/main", "v1.0.0"},
+		Findings: []AuditFinding{
+			{"text-cve", SeverityError, ModulePath{"golang.org/x/text", "v0.3.2"}, ModulePath{"golang.org/x/text", "v0.3.2"}, "vulnerable"},
+			{"no-replace", SeverityWarning, ModulePath{"example.com/a", "v1.0.0"}, ModulePath{"example.com/fork", "v1.1.0"}, "replaced by example.com/fork@v1.1.0"},
+			{"fork-range", SeverityInfo, ModulePath{"example.com/a", "v1.0.0"}, ModulePath{"example.com/fork", "v1.1.0"},
+				"path matches example.com/fork; version v1.1.0 in range [v1.1.0, )"},
+		},
+	}
+	if !reflect.DeepEqual(r, want) {
+		t.Errorf("Audit =\n%+v\nwant\n%+v", r, want)
+	}
+	if !r.HasSeverity(SeverityError) {
+		t.Errorf("HasSeverity(SeverityError) = false")
+	}
+
+	// Without the exception, the local replacement is reported too.
+	r = Audit(bi, append(rules[:1:1], rules[2:]...))
+	if len(r.Findings) != 5 || r.Findings[1].Dep.Path != "example.com/internal/x" || r.Findings[2].Message != "no checksum" {
+		t.Errorf("Audit without exception = %+v", r.Findings)
+	}
+	if r := Audit(bi, rules[3:4]); r.HasSeverity(SeverityWarning) || !r.HasSeverity(SeverityInfo) {
+		t.Errorf("Audit with info rule: HasSeverity wrong for %+v", r.Findings)
+	}
+
+	data, err := json.Marshal(want.Findings[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	const wantJSON = `{"Rule":"text-cve","Severity":"error","Dep":{"Path":"golang.org/x/text","Version":"v0.3.2"},` +
+		`"Resolved":{"Path":"golang.org/x/text","Version":"v0.3.2"},"Message":"vulnerable"}`
+	if string(data) != wantJSON {
+		t.Errorf("JSON = %s, want %s", data, wantJSON)
+	}
+	var f AuditFinding
+	if err := json.Unmarshal(data, &f); err != nil || !reflect.DeepEqual(f, want.Findings[0]) {
+		t.Errorf("JSON round trip = %+v, %v", f, err)
+	}
+	if s := Severity(7).String(); s != "Severity(7)" {
+		t.Errorf("Severity(7).String() = %q", s)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SuspiciousVersions returns the dependencies whose recorded versions
+// match patterns that often indicate a problem with the build.
+// It is a best-effort hygiene check: a reported dependency is not
+// necessarily wrong, only worth a second look. The patterns are:
+//
+//	- the zero pseudo-version v0.0.0-00010101000000-000000000000,
+//	  which cmd/go records for a requirement that exists only
+//	  to be replaced by a local directory;
+//	- a pseudo-version, of the dependency or of its replacement,
+//	  whose commit timestamp lies in the future;
+//	- the placeholder versions "", "(devel)", and v0.0.0, which
+//	  do not identify any published version of a dependency.
+func (bi *BuildInfo) SuspiciousVersions() []*Module {
+	now := time.Now()
+	var list []*Module
+	for _, dep := range bi.Deps {
+		if dep == nil {
+			continue
+		}
+		if isSuspiciousVersion(dep.Version, now) ||
+			dep.Replace != nil && dep.Replace.Version != "" && isSuspiciousVersion(dep.Replace.Version, now) {
+			list = append(list, dep)
+		}
+	}
+	return list
+}
+
+func isSuspiciousVersion(v string, now time.Time) bool {
+	switch v {
+	case "", "(devel)", "v0.0.0", zeroPseudoVersion:
+		return true
+	}
+	t, ok := pseudoVersionTime(v)
+	return ok && t.After(now)
+}
+
+// RequireTaggedMain returns an error if the main module of bi has no
+// version or the development version "(devel)", as when a binary is
+// built with "go build" in a module's working tree. It is meant as a
+// release gate, to catch shipping a binary that cannot identify the
+// version of the code it was built from.
+func (bi *BuildInfo) RequireTaggedMain() error {
+	switch v := bi.Main.Version; v {
+	case "", "(devel)":
+		desc := "no version"
+		if v != "" {
+			desc = "version " + v
+		}
+		return errors.New("main module " + strconv.Quote(bi.Main.Path) + " has " + desc +
+			"; build a tagged release with \"go install module@version\"" +
+			" or stamp a version into the binary with -ldflags=-X")
+	}
+	return nil
+}
+
+// ChecksumCoverage returns, for each host serving the dependencies of
+// bi, the fraction of those dependencies recorded with a checksum, from
+// 0 to 1. The host is the first element of the module path, such as
+// "github.com" or "golang.org". A replaced dependency counts under the
+// host of its original path but is covered only if its replacement has
+// a checksum, so that directory replacements, which never do, lower
+// the coverage of the module they replace.
+func (bi *BuildInfo) ChecksumCoverage() map[string]float64 {
+	total, summed := make(map[string]int), make(map[string]int)
+	for _, dep := range bi.Deps {
+		if dep == nil {
+			continue
+		}
+		host := dep.Path
+		if i := strings.IndexByte(host, '/'); i >= 0 {
+			host = host[:i]
+		}
+		m := dep.effective()
+		total[host]++
+		if m.Sum != "" {
+			summed[host]++
+		}
+	}
+	coverage := make(map[string]float64, len(total))
+	for host, n := range total {
+		coverage[host] = float64(summed[host]) / float64(n)
+	}
+	return coverage
+}
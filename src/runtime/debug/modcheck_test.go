@@ -0,0 +1,82 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"reflect"
+	. "runtime/debug"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSuspiciousVersions(t *testing.T) {
+	future := time.Now().Add(48 * time.Hour).UTC().Format("20060102150405")
+	info := &BuildInfo{
+		Deps: []*Module{
+			{Path: "example.com/ok", Version: "v1.2.3"},
+			{Path: "example.com/pseudo", Version: "v0.0.0-20200101120000-abcdefabcdef"},
+			{Path: "example.com/local", Version: "v0.0.0-00010101000000-000000000000",
+				Replace: &Module{Path: "../local"}},
+			{Path: "example.com/future", Version: "v1.2.4-0." + future + "-abcdefabcdef"},
+			{Path: "example.com/repfuture", Version: "v1.0.0",
+				Replace: &Module{Path: "example.com/fork", Version: "v0.0.0-" + future + "-abcdefabcdef"}},
+			{Path: "example.com/placeholder", Version: "v0.0.0"},
+		},
+	}
+	want := []string{"example.com/local", "example.com/future", "example.com/repfuture", "example.com/placeholder"}
+	got := info.SuspiciousVersions()
+	if len(got) != len(want) {
+		t.Fatalf("SuspiciousVersions returned %d modules, want %d", len(got), len(want))
+	}
+	for i, m := range got {
+		if m.Path != want[i] {
+			t.Errorf("SuspiciousVersions()[%d] = %s, want %s", i, m.Path, want[i])
+		}
+	}
+}
+
+func TestRequireTaggedMain(t *testing.T) {
+	for _, tt := range []struct {
+		version string
+		ok      bool
+	}{
+		{"", false},
+		{"(devel)", false},
+		{"v1.2.3", true},
+		{"v0.0.0-20200101000000-abcdefabcdef", true},
+	} {
+		info := &BuildInfo{Main: Module{Path: "example.com/hello", Version: tt.version}}
+		err := info.RequireTaggedMain()
+		if (err == nil) != tt.ok {
+			t.Errorf("RequireTaggedMain() with version %q = %v, want ok=%v", tt.version, err, tt.ok)
+		}
+		if err != nil && !strings.Contains(err.Error(), "go install module@version") {
+			t.Errorf("RequireTaggedMain() error %q does not suggest go install", err)
+		}
+	}
+}
+
+func TestChecksumCoverage(t *testing.T) {
+	info := &BuildInfo{
+		Deps: []*Module{
+			{Path: "github.com/a/one", Version: "v1.0.0", Sum: "h1:1="},
+			{Path: "github.com/a/two", Version: "v1.0.0", Sum: "h1:2="},
+			{Path: "github.com/b/three", Version: "v1.0.0", Replace: &Module{Path: "../three"}},
+			{Path: "github.com/b/four", Version: "v1.0.0", Sum: "h1:4=", Replace: &Module{Path: "github.com/c/four", Version: "v1.0.1"}},
+			{Path: "corp.example.com/x", Version: "v0.1.0"},
+			nil,
+			{Path: "golang.org/x/text", Version: "v0.3.3", Replace: &Module{Path: "golang.org/x/text", Version: "v0.3.2", Sum: "h1:t="}},
+		},
+	}
+	want := map[string]float64{
+		"github.com":       0.5,
+		"corp.example.com": 0,
+		"golang.org":       1,
+	}
+	if got := info.ChecksumCoverage(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ChecksumCoverage() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,27 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+// Columns returns the dependencies of bi as three parallel slices,
+// for loading into column-oriented stores: the i'th elements of
+// paths, versions, and sums give the module path, version, and
+// checksum of the same dependency, so the slices always have equal
+// lengths. A replaced dependency is described by the last module in its
+// chain of replacements. Nil entries in Deps are skipped.
+func (bi *BuildInfo) Columns() (paths, versions, sums []string) {
+	paths = make([]string, 0, len(bi.Deps))
+	versions = make([]string, 0, len(bi.Deps))
+	sums = make([]string, 0, len(bi.Deps))
+	for _, dep := range bi.Deps {
+		if dep == nil {
+			continue
+		}
+		m := dep.effective()
+		paths = append(paths, m.Path)
+		versions = append(versions, m.Version)
+		sums = append(sums, m.Sum)
+	}
+	return paths, versions, sums
+}
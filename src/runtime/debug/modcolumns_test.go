@@ -0,0 +1,29 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"reflect"
+	. "runtime/debug"
+	"testing"
+)
+
+func TestColumns(t *testing.T) {
+	info, ok := ReadBuildInfoData(blob(testModinfo))
+	if !ok {
+		t.Fatal("ReadBuildInfo failed")
+	}
+	info.Deps = append(info.Deps, nil, &Module{Path: "example.com/local", Version: "v1.0.0", Replace: &Module{Path: "../local"}})
+	paths, versions, sums := info.Columns()
+	if len(paths) != len(versions) || len(paths) != len(sums) {
+		t.Fatalf("Columns() lengths = %d, %d, %d, want equal", len(paths), len(versions), len(sums))
+	}
+	wantPaths := []string{"golang.org/x/text", "rsc.io/quote", "../local"}
+	wantVersions := []string{"v0.3.3", "v1.0.0", ""}
+	wantSums := []string{"h1:cafe=", "h1:beef=", ""}
+	if !reflect.DeepEqual(paths, wantPaths) || !reflect.DeepEqual(versions, wantVersions) || !reflect.DeepEqual(sums, wantSums) {
+		t.Errorf("Columns() = %q, %q, %q, want %q, %q, %q", paths, versions, sums, wantPaths, wantVersions, wantSums)
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+// ReadBuildInfoFromCore returns the build information of the program
+// that produced the ELF core dump named by corePath. The build
+// information is read from the program's executable, named by exePath.
+// If exePath is empty, ReadBuildInfoFromCore uses the executable path
+// recorded in the core's NT_FILE note, as written by Linux, which lists
+// the files mapped into the process starting with the executable.
+// It returns nil, false if the executable cannot be resolved or
+// carries no build information.
+func ReadBuildInfoFromCore(corePath, exePath string) (*BuildInfo, bool) {
+	if exePath == "" {
+		f, err := os.Open(corePath)
+		if err != nil {
+			return nil, false
+		}
+		exePath, err = coreExecutable(f)
+		f.Close()
+		if err != nil {
+			return nil, false
+		}
+	}
+	info, err := ReadBuildInfoFromFile(exePath)
+	if err != nil {
+		return nil, false
+	}
+	return info, true
+}
+
+// ReadBuildInfoFromCoreImage returns the build information of the
+// program whose crashed process image is read from core. The build
+// information is a string in the program's read-only data, so it is
+// found in core if the dump includes the pages holding it, as dumps
+// taken by gcore or with all mappings in /proc/self/coredump_filter do;
+// the search, as by ReadBuildInfoFrom, works for a dump in any format.
+// Otherwise the build information is read from exe, the program's
+// executable, if it is not nil. Unlike ReadBuildInfoFromCore,
+// ReadBuildInfoFromCoreImage does not need the executable when the
+// dump holds the build information, so it works when the original
+// executable is gone.
+func ReadBuildInfoFromCoreImage(core, exe io.ReaderAt) (*BuildInfo, error) {
+	info, err := ReadBuildInfoFrom(core)
+	if err == nil {
+		return info, nil
+	}
+	if exe == nil {
+		return nil, errors.New("core file holds no build information: " + err.Error())
+	}
+	return ReadBuildInfoFrom(exe)
+}
+
+var errBadCore = errors.New("not an ELF core file")
+
+const (
+	elfTypeCore = 4          // ET_CORE
+	elfPTNote   = 4          // PT_NOTE
+	elfNTFile   = 0x46494c45 // NT_FILE
+)
+
+// coreExecutable returns the path of the executable recorded in the
+// NT_FILE note of the ELF core file r.
+func coreExecutable(r io.ReaderAt) (string, error) {
+	var ident [64]byte
+	if _, err := r.ReadAt(ident[:], 0); err != nil {
+		return "", errBadCore
+	}
+	if string(ident[:4]) != "\x7fELF" {
+		return "", errBadCore
+	}
+	var e elfReader
+	switch ident[4] {
+	case 1:
+		e.wordSize = 4
+	case 2:
+		e.wordSize = 8
+	default:
+		return "", errBadCore
+	}
+	switch ident[5] {
+	case 1:
+	case 2:
+		e.bigEndian = true
+	default:
+		return "", errBadCore
+	}
+	if e.uint16(ident[16:]) != elfTypeCore {
+		return "", errBadCore
+	}
+
+	var phoff uint64
+	var phentsize, phnum int
+	if e.wordSize == 8 {
+		phoff = e.uint64(ident[32:])
+		phentsize, phnum = int(e.uint16(ident[54:])), int(e.uint16(ident[56:]))
+	} else {
+		phoff = uint64(e.uint32(ident[28:]))
+		phentsize, phnum = int(e.uint16(ident[42:])), int(e.uint16(ident[44:]))
+	}
+	if phentsize < 8+4*e.wordSize {
+		return "", errBadCore
+	}
+	ph := make([]byte, phentsize)
+	for i := 0; i < phnum; i++ {
+		if _, err := r.ReadAt(ph, int64(phoff)+int64(i*phentsize)); err != nil {
+			return "", errBadCore
+		}
+		if e.uint32(ph) != elfPTNote {
+			continue
+		}
+		var off, size uint64
+		if e.wordSize == 8 {
+			off, size = e.uint64(ph[8:]), e.uint64(ph[32:])
+		} else {
+			off, size = uint64(e.uint32(ph[4:])), uint64(e.uint32(ph[16:]))
+		}
+		if size > 64<<20 {
+			return "", errBadCore
+		}
+		notes := make([]byte, size)
+		if _, err := r.ReadAt(notes, int64(off)); err != nil {
+			return "", errBadCore
+		}
+		if exe, ok := e.ntFileExecutable(notes); ok {
+			return exe, nil
+		}
+	}
+	return "", errors.New("core file does not record its executable")
+}
+
+// ntFileExecutable scans the notes in a PT_NOTE segment for an
+// NT_FILE note and returns the first file name it lists.
+func (e *elfReader) ntFileExecutable(notes []byte) (string, bool) {
+	// The sizes are aligned in 64 bits, as a crafted size near
+	// 1<<32 would wrap to 0 in 32.
+	align := func(n uint32) uint64 { return (uint64(n) + 3) &^ 3 }
+	for len(notes) >= 12 {
+		namesz, descsz, typ := e.uint32(notes), e.uint32(notes[4:]), e.uint32(notes[8:])
+		notes = notes[12:]
+		if align(namesz) > uint64(len(notes)) {
+			return "", false
+		}
+		name := notes[:namesz]
+		notes = notes[align(namesz):]
+		if align(descsz) > uint64(len(notes)) {
+			return "", false
+		}
+		desc := notes[:descsz]
+		notes = notes[align(descsz):]
+		if typ != elfNTFile || string(name) != "CORE\x00" {
+			continue
+		}
+
+		// The NT_FILE descriptor holds the number of mapped files
+		// and the page size, then a start, end, and file offset for
+		// each mapping, then the NUL-terminated file names.
+		w := e.wordSize
+		if len(desc) < 2*w {
+			return "", false
+		}
+		count := e.word(desc)
+		if count == 0 || count > uint64(len(desc)) {
+			return "", false
+		}
+		names := uint64(2*w) + count*uint64(3*w)
+		if names > uint64(len(desc)) {
+			return "", false
+		}
+		desc = desc[names:]
+		for i, c := range desc {
+			if c == 0 {
+				return string(desc[:i]), i > 0
+			}
+		}
+		return "", false
+	}
+	return "", false
+}
+
+// An elfReader decodes the fields of an ELF file of a given class
+// and byte order.
+type elfReader struct {
+	wordSize  int // 4 for ELFCLASS32, 8 for ELFCLASS64
+	bigEndian bool
+}
+
+func (e *elfReader) uint16(b []byte) uint16 {
+	if e.bigEndian {
+		return uint16(b[1]) | uint16(b[0])<<8
+	}
+	return uint16(b[0]) | uint16(b[1])<<8
+}
+
+func (e *elfReader) uint32(b []byte) uint32 {
+	if e.bigEndian {
+		return uint32(b[3]) | uint32(b[2])<<8 | uint32(b[1])<<16 | uint32(b[0])<<24
+	}
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16 | uint32(b[3])<<24
+}
+
+func (e *elfReader) uint64(b []byte) uint64 {
+	if e.bigEndian {
+		return uint64(e.uint32(b[4:])) | uint64(e.uint32(b))<<32
+	}
+	return uint64(e.uint32(b)) | uint64(e.uint32(b[4:]))<<32
+}
+
+// word decodes a target-sized unsigned word.
+func (e *elfReader) word(b []byte) uint64 {
+	if e.wordSize == 8 {
+		return e.uint64(b)
+	}
+	return uint64(e.uint32(b))
+}
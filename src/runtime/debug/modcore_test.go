@@ -0,0 +1,168 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	. "runtime/debug"
+	"testing"
+)
+
+// writeExe writes a fake executable containing the module information
+// text amid other data and returns its path.
+func writeExe(t *testing.T, dir, text string) string {
+	t.Helper()
+	var b bytes.Buffer
+	b.WriteString("\x7fELF fake executable\x00")
+	b.Write(make([]byte, 4096))
+	b.WriteString(blob(text))
+	b.Write(make([]byte, 4096))
+	exe := filepath.Join(dir, "exe")
+	if err := ioutil.WriteFile(exe, b.Bytes(), 0777); err != nil {
+		t.Fatal(err)
+	}
+	return exe
+}
+
+// writeCore writes a minimal little-endian ELF64 core file whose
+// NT_FILE note lists exe as the first mapped file, and returns its path.
+func writeCore(t *testing.T, dir, exe string) string {
+	t.Helper()
+	le := binary.LittleEndian
+
+	var desc bytes.Buffer
+	for _, v := range []uint64{2, 4096, 0x400000, 0x401000, 0, 0x7f0000, 0x7f1000, 0} {
+		binary.Write(&desc, le, v)
+	}
+	desc.WriteString(exe + "\x00/lib/libc.so.6\x00")
+	for desc.Len()%4 != 0 {
+		desc.WriteByte(0)
+	}
+
+	var note bytes.Buffer
+	binary.Write(&note, le, []uint32{5, uint32(desc.Len()), 0x46494c45})
+	note.WriteString("CORE\x00\x00\x00\x00")
+	note.Write(desc.Bytes())
+	return writeCoreNotes(t, dir, note.Bytes())
+}
+
+// writeCoreNotes writes a minimal little-endian ELF64 core file whose
+// PT_NOTE segment holds notes, and returns its path.
+func writeCoreNotes(t *testing.T, dir string, notes []byte) string {
+	t.Helper()
+	le := binary.LittleEndian
+
+	const ehsize, phentsize = 64, 56
+	var f bytes.Buffer
+	f.WriteString("\x7fELF\x02\x01\x01")
+	f.Write(make([]byte, 9))
+	binary.Write(&f, le, uint16(4))      // e_type = ET_CORE
+	binary.Write(&f, le, uint16(62))     // e_machine
+	binary.Write(&f, le, uint32(1))      // e_version
+	binary.Write(&f, le, uint64(0))      // e_entry
+	binary.Write(&f, le, uint64(ehsize)) // e_phoff
+	binary.Write(&f, le, uint64(0))      // e_shoff
+	binary.Write(&f, le, uint32(0))      // e_flags
+	binary.Write(&f, le, []uint16{ehsize, phentsize, 1, 0, 0, 0})
+	binary.Write(&f, le, []uint32{4, 0}) // p_type = PT_NOTE, p_flags
+	binary.Write(&f, le, []uint64{ehsize + phentsize, 0, 0, uint64(len(notes)), 0, 4})
+	f.Write(notes)
+
+	core := filepath.Join(dir, "core")
+	if err := ioutil.WriteFile(core, f.Bytes(), 0666); err != nil {
+		t.Fatal(err)
+	}
+	return core
+}
+
+func TestReadBuildInfoFromCore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "debugcore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	exe := writeExe(t, dir, testModinfo)
+	core := writeCore(t, dir, exe)
+
+	info, ok := ReadBuildInfoFromCore(core, "")
+	if !ok {
+		t.Fatal("ReadBuildInfoFromCore(core, \"\") failed")
+	}
+	if info.Main.Path != "example.com/hello" || len(info.Deps) != 2 {
+		t.Errorf("ReadBuildInfoFromCore(core, \"\") = %+v", info)
+	}
+
+	if _, ok := ReadBuildInfoFromCore(filepath.Join(dir, "missing"), exe); !ok {
+		t.Error("ReadBuildInfoFromCore with explicit executable failed")
+	}
+	if _, ok := ReadBuildInfoFromCore(exe, ""); ok {
+		t.Error("ReadBuildInfoFromCore succeeded on a file that is not a core")
+	}
+	if _, ok := ReadBuildInfoFromCore(core, core); ok {
+		t.Error("ReadBuildInfoFromCore succeeded with an executable lacking build information")
+	}
+}
+
+func TestReadBuildInfoFromCoreBadNote(t *testing.T) {
+	dir, err := ioutil.TempDir("", "debugcore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Sizes that wrap to 0 when aligned in 32 bits.
+	for _, sizes := range [][2]uint32{{0xFFFFFFFF, 0}, {5, 0xFFFFFFFD}} {
+		var note bytes.Buffer
+		binary.Write(&note, binary.LittleEndian, []uint32{sizes[0], sizes[1], 0x46494c45})
+		note.WriteString("CORE\x00\x00\x00\x00")
+		core := writeCoreNotes(t, dir, note.Bytes())
+		if _, ok := ReadBuildInfoFromCore(core, ""); ok {
+			t.Errorf("ReadBuildInfoFromCore succeeded with note sizes %#x", sizes)
+		}
+	}
+}
+
+func TestReadBuildInfoFromCoreImage(t *testing.T) {
+	exe := bytes.NewReader([]byte("\x7fELF\x00" + blob(testModinfo)))
+	// A core that includes the read-only data of the program.
+	core := bytes.NewReader([]byte("\x7fELF core\x00" + blob(testModinfo) + "\x00stack"))
+	other := bytes.NewReader([]byte("\x7fELF core without build information"))
+
+	for _, tt := range []struct {
+		name      string
+		core, exe *bytes.Reader
+		ok        bool
+	}{
+		{"core alone", core, nil, true},
+		{"core and exe", core, exe, true},
+		{"exe fallback", other, exe, true},
+		{"neither", other, nil, false},
+		{"truncated core", bytes.NewReader([]byte(blob(testModinfo)[:40])), exe, true},
+	} {
+		var exeR io.ReaderAt
+		if tt.exe != nil {
+			exeR = tt.exe
+		}
+		info, err := ReadBuildInfoFromCoreImage(tt.core, exeR)
+		if !tt.ok {
+			if err == nil {
+				t.Errorf("%s: ReadBuildInfoFromCoreImage succeeded", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if info.Main.Path != "example.com/hello" || len(info.Deps) != 2 {
+			t.Errorf("%s: ReadBuildInfoFromCoreImage = %+v", tt.name, info)
+		}
+	}
+}
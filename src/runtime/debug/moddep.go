@@ -0,0 +1,155 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import "strings"
+
+// Dep returns the dependency with the given module path, and reports
+// whether there is one. A dependency that is replaced is found by its
+// own path and also by the path of its replacement, so that a program
+// built with a fork of a module can look up either name; when both
+// match different dependencies, the one with that path of its own wins.
+// The returned Module is an element of bi.Deps; its Replace field
+// says which module was actually used.
+//
+// Dep looks the path up in an index that it builds on first use and
+// rebuilds when bi.Deps is assigned a different slice. Callers that
+// modify the elements of bi.Deps in place must not rely on Dep
+// afterward. Dep may be called by multiple goroutines simultaneously.
+func (bi *BuildInfo) Dep(path string) (*Module, bool) {
+	idx, _ := bi.index.Load().(*depIndex)
+	if idx == nil || !idx.current(bi.Deps) {
+		idx = newDepIndex(bi.Deps)
+		bi.index.Store(idx)
+	}
+	m, ok := idx.byPath[path]
+	return m, ok
+}
+
+// A depIndex maps module paths to the dependencies of a BuildInfo.
+type depIndex struct {
+	deps   []*Module // the Deps slice indexed
+	byPath map[string]*Module
+}
+
+func newDepIndex(deps []*Module) *depIndex {
+	idx := &depIndex{deps: deps, byPath: make(map[string]*Module, len(deps))}
+	for _, dep := range deps {
+		if dep != nil && dep.Replace != nil && dep.Replace.Path != "" {
+			idx.byPath[dep.Replace.Path] = dep
+		}
+	}
+	// Index the dependencies' own paths last,
+	// so that they take precedence over replacements.
+	for _, dep := range deps {
+		if dep != nil {
+			idx.byPath[dep.Path] = dep
+		}
+	}
+	return idx
+}
+
+// current reports whether idx indexes the slice deps.
+func (idx *depIndex) current(deps []*Module) bool {
+	if len(idx.deps) != len(deps) {
+		return false
+	}
+	return len(deps) == 0 || &idx.deps[0] == &deps[0]
+}
+
+// Modules calls yield for the main module, each dependency, and the
+// module that finally replaces each replaced module, following chains
+// of Replace fields to their end, in that order. Each distinct Module
+// is passed to yield once. Modules stops if yield returns false.
+//
+// Modules has the form of a range function, for use by callers
+// that do not want to walk the Replace fields themselves:
+//
+//	bi.Modules(func(m *debug.Module) bool {
+//		fmt.Println(m.Path, m.Version)
+//		return true
+//	})
+func (bi *BuildInfo) Modules(yield func(*Module) bool) {
+	seen := make(map[*Module]bool)
+	visit := func(m *Module) bool {
+		if m == nil || seen[m] {
+			return true
+		}
+		seen[m] = true
+		if !yield(m) {
+			return false
+		}
+		r := m.Replace
+		for r != nil && r.Replace != nil && !seen[r] {
+			seen[r] = true
+			r = r.Replace
+		}
+		if r != nil && !seen[r] {
+			seen[r] = true
+			return yield(r)
+		}
+		return true
+	}
+	if !visit(&bi.Main) {
+		return
+	}
+	for _, dep := range bi.Deps {
+		if !visit(dep) {
+			return
+		}
+	}
+}
+
+// DepsMatching returns the dependencies, in the order of bi.Deps, whose
+// module path, or the path of a module in their replacement chain,
+// matches pattern. A pattern is a module path in which each "..." is a
+// wildcard matching any string, including the empty string and strings
+// containing slashes, as in the package patterns of the go command.
+// As there, a pattern ending in "/..." also matches its prefix alone, so
+// that github.com/org/... matches github.com/org as well as every module
+// path below it.
+func (bi *BuildInfo) DepsMatching(pattern string) []*Module {
+	var list []*Module
+	for _, dep := range bi.Deps {
+		if dep == nil {
+			continue
+		}
+		for m := dep; m != nil; m = m.Replace {
+			if matchPattern(pattern, m.Path) {
+				list = append(list, dep)
+				break
+			}
+		}
+	}
+	return list
+}
+
+// matchPattern reports whether path matches the ... pattern.
+func matchPattern(pattern, path string) bool {
+	if prefix := strings.TrimSuffix(pattern, "/..."); prefix != pattern && prefix == path {
+		return true
+	}
+	parts := strings.Split(pattern, "...")
+	if len(parts) == 1 {
+		return pattern == path
+	}
+	// The first part is anchored at the start of path and the last at
+	// its end; each part in between matches at the leftmost position
+	// after the previous one, which is always as good as any other
+	// since the wildcards match anything.
+	first, last := parts[0], parts[len(parts)-1]
+	if len(path) < len(first)+len(last) || !strings.HasPrefix(path, first) || !strings.HasSuffix(path, last) {
+		return false
+	}
+	rest := path[len(first) : len(path)-len(last)]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(rest, part)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(part):]
+	}
+	return true
+}
@@ -0,0 +1,120 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"reflect"
+	. "runtime/debug"
+	"sync"
+	"testing"
+)
+
+func TestDep(t *testing.T) {
+	info, ok := ReadBuildInfoData(blob(testModinfo))
+	if !ok {
+		t.Fatal("ReadBuildInfo failed")
+	}
+	for _, tt := range []struct {
+		path, want string
+	}{
+		{"golang.org/x/text", "golang.org/x/text"},
+		{"rsc.io/quote", "rsc.io/quote"},
+		{"example.com/hello", ""},
+		{"example.com/missing", ""},
+	} {
+		m, ok := info.Dep(tt.path)
+		if ok != (tt.want != "") || ok && m.Path != tt.want {
+			t.Errorf("Dep(%q) = %+v, %v, want %q", tt.path, m, ok, tt.want)
+		}
+	}
+
+	// A dependency is found by the path of its replacement,
+	// unless another dependency has that path.
+	info.Deps = append(info.Deps, &Module{Path: "example.com/orig", Version: "v1.0.0", Replace: &Module{Path: "example.com/fork", Version: "v1.0.1"}})
+	if m, ok := info.Dep("example.com/fork"); !ok || m.Path != "example.com/orig" {
+		t.Errorf("Dep(fork) = %+v, %v, want example.com/orig", m, ok)
+	}
+	info.Deps = append(info.Deps, &Module{Path: "example.com/fork", Version: "v2.0.0"})
+	if m, ok := info.Dep("example.com/fork"); !ok || m.Version != "v2.0.0" {
+		t.Errorf("Dep(fork) with fork also required = %+v, %v, want v2.0.0", m, ok)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, ok := info.Dep("rsc.io/quote"); !ok {
+				t.Error("concurrent Dep(rsc.io/quote) not found")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestModules(t *testing.T) {
+	info, ok := ReadBuildInfoData(blob(testModinfo))
+	if !ok {
+		t.Fatal("ReadBuildInfo failed")
+	}
+	// Chain a second replacement onto rsc.io/quote.
+	info.Deps[1].Replace.Replace = &Module{Path: "../quote"}
+	info.Deps = append(info.Deps, nil, info.Deps[0])
+
+	var got []string
+	info.Modules(func(m *Module) bool {
+		got = append(got, m.Key())
+		return true
+	})
+	want := []string{"example.com/hello@v1.2.3", "golang.org/x/text@v0.3.3", "rsc.io/quote@v1.5.2", "../quote"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Modules yielded %q, want %q", got, want)
+	}
+
+	got = nil
+	info.Modules(func(m *Module) bool {
+		got = append(got, m.Path)
+		return len(got) < 2
+	})
+	if len(got) != 2 {
+		t.Errorf("Modules yielded %q after yield returned false", got)
+	}
+}
+
+func TestDepsMatching(t *testing.T) {
+	info := &BuildInfo{
+		Deps: []*Module{
+			{Path: "github.com/org", Version: "v1.0.0"},
+			{Path: "github.com/org/a", Version: "v1.0.0"},
+			{Path: "github.com/org/a/v2", Version: "v2.0.0"},
+			{Path: "github.com/orgx/b", Version: "v1.0.0"},
+			{Path: "golang.org/x/text", Version: "v0.3.3"},
+			nil,
+			{Path: "rsc.io/quote", Version: "v1.5.2", Replace: &Module{Path: "github.com/org/quote", Version: "v1.5.3"}},
+		},
+	}
+	for _, tt := range []struct {
+		pattern string
+		want    []string
+	}{
+		{"github.com/org/...", []string{"github.com/org", "github.com/org/a", "github.com/org/a/v2", "rsc.io/quote"}},
+		{"github.com/org...", []string{"github.com/org", "github.com/org/a", "github.com/org/a/v2", "github.com/orgx/b", "rsc.io/quote"}},
+		{"github.com/.../v2", []string{"github.com/org/a/v2"}},
+		{".../a...", []string{"github.com/org/a", "github.com/org/a/v2"}},
+		{"golang.org/x/text", []string{"golang.org/x/text"}},
+		{"golang.org/x", nil},
+		{"...", []string{"github.com/org", "github.com/org/a", "github.com/org/a/v2", "github.com/orgx/b", "golang.org/x/text", "rsc.io/quote"}},
+		{"...x...x...", []string{"golang.org/x/text"}},
+		{"example.com/...", nil},
+	} {
+		var got []string
+		for _, m := range info.DepsMatching(tt.pattern) {
+			got = append(got, m.Path)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("DepsMatching(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+}
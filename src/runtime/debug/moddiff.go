@@ -0,0 +1,442 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GoVersionDelta returns a human-readable description of how the Go
+// toolchain changed between the builds old and new, such as
+// "go1.20.5 → go1.21.3 (minor upgrade)". The change is classified as
+// a major, minor, patch, or prerelease upgrade or downgrade by
+// comparing the components of the two versions. GoVersionDelta
+// returns "unchanged" when the versions are equal. A missing version
+// is reported as "unknown", and a change involving a version that
+// cannot be parsed, such as a development build, is reported
+// without a classification.
+func GoVersionDelta(old, new *BuildInfo) string {
+	var ov, nv string
+	if old != nil {
+		ov = old.GoVersion
+	}
+	if new != nil {
+		nv = new.GoVersion
+	}
+	if ov == nv {
+		return "unchanged"
+	}
+	desc := orUnknown(ov) + " → " + orUnknown(nv)
+	o, ok1 := parseGoVersion(ov)
+	n, ok2 := parseGoVersion(nv)
+	if !ok1 || !ok2 {
+		return desc
+	}
+	var kind string
+	switch {
+	case o.major != n.major:
+		kind = "major"
+	case o.minor != n.minor:
+		kind = "minor"
+	case o.patch != n.patch:
+		kind = "patch"
+	case o.preKind != n.preKind || o.preNum != n.preNum:
+		kind = "prerelease"
+	default:
+		// Same release spelled differently, as in go1.21 and go1.21.0.
+		return desc
+	}
+	if o.less(n) {
+		return desc + " (" + kind + " upgrade)"
+	}
+	return desc + " (" + kind + " downgrade)"
+}
+
+func orUnknown(v string) string {
+	if v == "" {
+		return "unknown"
+	}
+	return v
+}
+
+// A goVersion is a parsed Go release version such as go1.21.3 or go1.21rc1.
+type goVersion struct {
+	major, minor, patch int
+	preKind             string // kind of prerelease, "beta" or "rc"; empty for a release
+	preNum              int    // number of the prerelease, as 1 in "rc1"
+}
+
+// parseGoVersion parses a Go version as reported by runtime.Version.
+func parseGoVersion(v string) (goVersion, bool) {
+	if !strings.HasPrefix(v, "go") {
+		return goVersion{}, false
+	}
+	var gv goVersion
+	parts := strings.Split(v[len("go"):], ".")
+	if len(parts) > 3 {
+		return goVersion{}, false
+	}
+	// The prerelease suffix, if any, follows the last number.
+	last := parts[len(parts)-1]
+	i := 0
+	for i < len(last) && '0' <= last[i] && last[i] <= '9' {
+		i++
+	}
+	parts[len(parts)-1] = last[:i]
+	if pre := last[i:]; pre != "" {
+		j := 0
+		for j < len(pre) && 'a' <= pre[j] && pre[j] <= 'z' {
+			j++
+		}
+		n, err := strconv.Atoi(pre[j:])
+		if j == 0 || err != nil || n < 0 {
+			return goVersion{}, false
+		}
+		gv.preKind, gv.preNum = pre[:j], n
+	}
+	nums := []*int{&gv.major, &gv.minor, &gv.patch}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return goVersion{}, false
+		}
+		*nums[i] = n
+	}
+	return gv, true
+}
+
+// less reports whether v is an earlier release than w.
+// A prerelease precedes the release it anticipates.
+func (v goVersion) less(w goVersion) bool {
+	if v.major != w.major {
+		return v.major < w.major
+	}
+	if v.minor != w.minor {
+		return v.minor < w.minor
+	}
+	if v.patch != w.patch {
+		return v.patch < w.patch
+	}
+	if v.preKind == "" || w.preKind == "" {
+		return w.preKind == "" && v.preKind != ""
+	}
+	if v.preKind != w.preKind {
+		return v.preKind < w.preKind // beta before rc
+	}
+	return v.preNum < w.preNum
+}
+
+// ReproducibleCandidates reports whether the builds a and b should
+// produce byte-for-byte identical binaries, judging by their build
+// information. If not, it returns the reasons, which cover differences
+// in the Go version, the main module, the versions, sums, and
+// replacements of dependencies, and the build settings, as well as a
+// build made from a modified VCS working tree. The commit time recorded
+// in vcs.time is ignored when both builds used -trimpath and record
+// the same vcs.revision, since it then carries no extra information.
+func ReproducibleCandidates(a, b *BuildInfo) (bool, []string) {
+	var reasons []string
+	if a.GoVersion != b.GoVersion {
+		reasons = append(reasons, "Go version differs: "+orUnknown(a.GoVersion)+" vs "+orUnknown(b.GoVersion))
+	}
+	if a.Path != b.Path {
+		reasons = append(reasons, "main package differs: "+a.Path+" vs "+b.Path)
+	}
+	if !sameModule(&a.Main, &b.Main) {
+		reasons = append(reasons, "main module differs: "+modString(&a.Main)+" vs "+modString(&b.Main))
+	}
+
+	adeps, bdeps := depsByPath(a), depsByPath(b)
+	for _, path := range depPaths(adeps, bdeps) {
+		am, bm := adeps[path], bdeps[path]
+		switch {
+		case bm == nil:
+			reasons = append(reasons, "dependency "+path+" only in first build")
+		case am == nil:
+			reasons = append(reasons, "dependency "+path+" only in second build")
+		case !sameModule(am, bm):
+			reasons = append(reasons, "dependency "+path+" differs: "+modString(am)+" vs "+modString(bm))
+		}
+	}
+
+	as, bs := a.settingsMap(), b.settingsMap()
+	ignoreTime := a.trimpath() && b.trimpath() && as["vcs.revision"] != "" && as["vcs.revision"] == bs["vcs.revision"]
+	for _, key := range settingKeys(as, bs) {
+		if key == "vcs.time" && ignoreTime {
+			continue
+		}
+		av, aok := as[key]
+		bv, bok := bs[key]
+		if aok != bok || av != bv {
+			reasons = append(reasons, "setting "+key+" differs: "+settingString(av, aok)+" vs "+settingString(bv, bok))
+		}
+	}
+	if as["vcs.modified"] == "true" {
+		reasons = append(reasons, "first build is from a modified working tree")
+	}
+	if bs["vcs.modified"] == "true" {
+		reasons = append(reasons, "second build is from a modified working tree")
+	}
+	return len(reasons) == 0, reasons
+}
+
+// A BuildInfoDiff describes how the dependencies of two builds differ.
+// Each list is sorted by module path.
+type BuildInfoDiff struct {
+	Added   []*Module   // dependencies only in the new build
+	Removed []*Module   // dependencies only in the old build
+	Changed []DepChange // dependencies whose version, checksum, or replacement changed
+}
+
+// A DepChange describes a dependency present in two builds
+// with different versions, checksums, or replacements.
+type DepChange struct {
+	Path     string
+	Old, New *Module
+}
+
+// Diff compares the dependencies of the builds old and new by module
+// path, reporting those added, removed, or changed between them.
+func Diff(old, new *BuildInfo) BuildInfoDiff {
+	return diffDeps(depsByPath(old), depsByPath(new))
+}
+
+func diffDeps(olddeps, newdeps map[string]*Module) BuildInfoDiff {
+	var d BuildInfoDiff
+	for _, path := range depPaths(olddeps, newdeps) {
+		o, n := olddeps[path], newdeps[path]
+		switch {
+		case o == nil:
+			d.Added = append(d.Added, n)
+		case n == nil:
+			d.Removed = append(d.Removed, o)
+		case !sameModule(o, n):
+			d.Changed = append(d.Changed, DepChange{Path: path, Old: o, New: n})
+		}
+	}
+	return d
+}
+
+// A BuildDiff classifies how the dependencies of two builds differ.
+// Each list is sorted by module path.
+type BuildDiff struct {
+	Added      []*Module   // dependencies only in the new build
+	Removed    []*Module   // dependencies only in the old build
+	Upgraded   []DepChange // dependencies required at a later version
+	Downgraded []DepChange // dependencies required at an earlier version
+	Replaced   []DepChange // dependencies at the same version whose replacement changed
+	Changed    []DepChange // other changes, such as in a checksum
+}
+
+// CompareBuildInfo compares the dependencies of the builds a and b by
+// module path, as Diff does, and classifies each change. Versions
+// are ordered by semantic version precedence, as in the module system.
+// A dependency whose required version is unchanged but that is
+// replaced differently, or replaced in only one build, is reported in
+// Replaced.
+func CompareBuildInfo(a, b *BuildInfo) BuildDiff {
+	d := Diff(a, b)
+	bd := BuildDiff{Added: d.Added, Removed: d.Removed}
+	for _, c := range d.Changed {
+		switch {
+		case compareSemver(c.Old.Version, c.New.Version) < 0:
+			bd.Upgraded = append(bd.Upgraded, c)
+		case compareSemver(c.Old.Version, c.New.Version) > 0:
+			bd.Downgraded = append(bd.Downgraded, c)
+		case !sameModule(c.Old.Replace, c.New.Replace):
+			bd.Replaced = append(bd.Replaced, c)
+		default:
+			bd.Changed = append(bd.Changed, c)
+		}
+	}
+	return bd
+}
+
+// HostDiff is like Diff but considers only the dependencies whose
+// module paths have host as their first element, such as "golang.org"
+// for golang.org/x/text.
+func HostDiff(old, new *BuildInfo, host string) BuildInfoDiff {
+	return diffDeps(hostDeps(depsByPath(old), host), hostDeps(depsByPath(new), host))
+}
+
+// hostDeps removes from deps the modules not served by host.
+func hostDeps(deps map[string]*Module, host string) map[string]*Module {
+	for path := range deps {
+		if path != host && !strings.HasPrefix(path, host+"/") {
+			delete(deps, path)
+		}
+	}
+	return deps
+}
+
+// SettingsDiff compares the build settings of old and new, returning
+// for each key whose value differs its old and new values, in that
+// order. A setting missing from a build has the empty value. If a key
+// is repeated, its last value is compared.
+func SettingsDiff(old, new *BuildInfo) map[string][2]string {
+	olds, news := old.settingsMap(), new.settingsMap()
+	diff := make(map[string][2]string)
+	for _, key := range settingKeys(olds, news) {
+		if ov, nv := olds[key], news[key]; ov != nv {
+			diff[key] = [2]string{ov, nv}
+		}
+	}
+	return diff
+}
+
+// A FullBuildDiff describes how two builds differ.
+type FullBuildDiff struct {
+	Deps             BuildInfoDiff        // as reported by Diff
+	Settings         map[string][2]string // as reported by SettingsDiff
+	GoVersionChanged [2]string            // old and new Go versions, if they differ
+}
+
+// FullDiff compares the builds old and new, reporting the changes in
+// their dependencies, build settings, and Go version. It is the
+// combination of Diff and SettingsDiff, for tools such as release-note
+// generators that need a complete account of how a build changed.
+func FullDiff(old, new *BuildInfo) FullBuildDiff {
+	d := FullBuildDiff{
+		Deps:     Diff(old, new),
+		Settings: SettingsDiff(old, new),
+	}
+	if old.GoVersion != new.GoVersion {
+		d.GoVersionChanged = [2]string{old.GoVersion, new.GoVersion}
+	}
+	return d
+}
+
+// SumDrift returns the dependencies of new that are also dependencies
+// of old at the same module path and version, but with a different
+// checksum, either for the module itself or for replacements with the
+// same path and version. A module whose content changed without a
+// change of version may have been tampered with, or served
+// inconsistently by a module proxy. Checksums missing from either
+// build are not compared. The result is in the order of new.Deps.
+func SumDrift(old, new *BuildInfo) []*Module {
+	olddeps := depsByPath(old)
+	var drift []*Module
+	for _, dep := range new.Deps {
+		if dep == nil {
+			continue
+		}
+		for m, o := dep, olddeps[dep.Path]; m != nil && o != nil; m, o = m.Replace, o.Replace {
+			if m.Path != o.Path || m.Version != o.Version {
+				break
+			}
+			if m.Sum != "" && o.Sum != "" && m.Sum != o.Sum {
+				drift = append(drift, dep)
+				break
+			}
+		}
+	}
+	return drift
+}
+
+// CommonDeps returns the dependencies present, with the same module path
+// and version as reported by Module.Key, in all of the given builds.
+// The modules are returned as recorded in the first build and in its
+// order. CommonDeps returns an empty slice if fewer than two builds
+// are given or they share no dependencies.
+func CommonDeps(infos ...*BuildInfo) []Module {
+	common := []Module{}
+	if len(infos) < 2 {
+		return common
+	}
+	count := make(map[string]int)
+	for _, bi := range infos {
+		seen := make(map[string]bool)
+		for _, dep := range bi.Deps {
+			if dep != nil && !seen[dep.Key()] {
+				seen[dep.Key()] = true
+				count[dep.Key()]++
+			}
+		}
+	}
+	for _, dep := range infos[0].Deps {
+		if dep != nil && count[dep.Key()] == len(infos) {
+			common = append(common, *dep)
+			count[dep.Key()] = 0 // report each module once
+		}
+	}
+	return common
+}
+
+// sameModule reports whether m and n record the same module
+// version, checksum, and chain of replacements.
+func sameModule(m, n *Module) bool {
+	for m != nil && n != nil {
+		if m.Path != n.Path || m.Version != n.Version || m.Sum != n.Sum {
+			return false
+		}
+		m, n = m.Replace, n.Replace
+	}
+	return m == nil && n == nil
+}
+
+// modString formats m as path@version, followed by its replacement, if any.
+func modString(m *Module) string {
+	s := m.Path
+	if m.Version != "" {
+		s += "@" + m.Version
+	}
+	if m.Replace != nil {
+		s += " => " + modString(m.Replace)
+	}
+	return s
+}
+
+func settingString(v string, ok bool) string {
+	if !ok {
+		return "(unset)"
+	}
+	return strconv.Quote(v)
+}
+
+// depsByPath indexes the dependencies of bi by module path.
+func depsByPath(bi *BuildInfo) map[string]*Module {
+	m := make(map[string]*Module, len(bi.Deps))
+	for _, dep := range bi.Deps {
+		if dep != nil {
+			m[dep.Path] = dep
+		}
+	}
+	return m
+}
+
+// depPaths returns the sorted union of the module paths in a and b.
+func depPaths(a, b map[string]*Module) []string {
+	seen := make(map[string]bool)
+	for k := range a {
+		seen[k] = true
+	}
+	for k := range b {
+		seen[k] = true
+	}
+	return sortedKeys(seen)
+}
+
+// settingKeys returns the sorted union of the setting keys in a and b.
+func settingKeys(a, b map[string]string) []string {
+	seen := make(map[string]bool)
+	for k := range a {
+		seen[k] = true
+	}
+	for k := range b {
+		seen[k] = true
+	}
+	return sortedKeys(seen)
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,282 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"reflect"
+	. "runtime/debug"
+	"testing"
+)
+
+func TestGoVersionDelta(t *testing.T) {
+	for _, tt := range []struct {
+		old, new string
+		want     string
+	}{
+		{"go1.20.5", "go1.20.5", "unchanged"},
+		{"", "", "unchanged"},
+		{"go1.20.5", "go1.21.3", "go1.20.5 → go1.21.3 (minor upgrade)"},
+		{"go1.21.3", "go1.21.1", "go1.21.3 → go1.21.1 (patch downgrade)"},
+		{"go1.21", "go1.21.1", "go1.21 → go1.21.1 (patch upgrade)"},
+		{"go1.21rc1", "go1.21", "go1.21rc1 → go1.21 (prerelease upgrade)"},
+		{"go1.21beta1", "go1.21rc2", "go1.21beta1 → go1.21rc2 (prerelease upgrade)"},
+		{"go1.21rc2", "go1.21rc10", "go1.21rc2 → go1.21rc10 (prerelease upgrade)"},
+		{"go1.21rc10", "go1.21rc2", "go1.21rc10 → go1.21rc2 (prerelease downgrade)"},
+		{"go1.21.0", "go2.0", "go1.21.0 → go2.0 (major upgrade)"},
+		{"go1.21", "go1.21.0", "go1.21 → go1.21.0"},
+		{"", "go1.21.3", "unknown → go1.21.3"},
+		{"go1.21.3", "", "go1.21.3 → unknown"},
+		{"devel +abcdef", "go1.21.3", "devel +abcdef → go1.21.3"},
+	} {
+		got := GoVersionDelta(&BuildInfo{GoVersion: tt.old}, &BuildInfo{GoVersion: tt.new})
+		if got != tt.want {
+			t.Errorf("GoVersionDelta(%q, %q) = %q, want %q", tt.old, tt.new, got, tt.want)
+		}
+	}
+	if got := GoVersionDelta(nil, &BuildInfo{GoVersion: "go1.15"}); got != "unknown → go1.15" {
+		t.Errorf("GoVersionDelta(nil, go1.15) = %q, want %q", got, "unknown → go1.15")
+	}
+}
+
+func reproBuild() *BuildInfo {
+	return &BuildInfo{
+		GoVersion: "go1.15",
+		Path:      "example.com/cmd/hello",
+		Main:      Module{Path: "example.com/hello", Version: "v1.2.3"},
+		Deps: []*Module{
+			{Path: "golang.org/x/text", Version: "v0.3.3", Sum: "h1:cafe="},
+			{Path: "rsc.io/quote", Version: "v1.5.2", Replace: &Module{Path: "rsc.io/quote", Version: "v1.0.0", Sum: "h1:beef="}},
+		},
+		Settings: []BuildSetting{
+			{Key: "-trimpath", Value: "true"},
+			{Key: "GOOS", Value: "linux"},
+			{Key: "vcs.revision", Value: "abcdef"},
+			{Key: "vcs.time", Value: "2020-01-01T00:00:00Z"},
+			{Key: "vcs.modified", Value: "false"},
+		},
+	}
+}
+
+func TestReproducibleCandidates(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		change func(*BuildInfo)
+		want   []string
+	}{
+		{"identical", func(*BuildInfo) {}, nil},
+		{"vcs.time ignored", func(b *BuildInfo) { b.Settings[3].Value = "2020-02-02T00:00:00Z" }, nil},
+		{"vcs.time without trimpath", func(b *BuildInfo) {
+			b.Settings[0].Value = "false"
+			b.Settings[3].Value = "2020-02-02T00:00:00Z"
+		}, []string{
+			`setting -trimpath differs: "true" vs "false"`,
+			`setting vcs.time differs: "2020-01-01T00:00:00Z" vs "2020-02-02T00:00:00Z"`,
+		}},
+		{"go version", func(b *BuildInfo) { b.GoVersion = "go1.16" }, []string{"Go version differs: go1.15 vs go1.16"}},
+		{"dep version", func(b *BuildInfo) { b.Deps[0].Version = "v0.3.4" }, []string{
+			"dependency golang.org/x/text differs: golang.org/x/text@v0.3.3 vs golang.org/x/text@v0.3.4",
+		}},
+		{"dep replacement", func(b *BuildInfo) { b.Deps[1].Replace = nil }, []string{
+			"dependency rsc.io/quote differs: rsc.io/quote@v1.5.2 => rsc.io/quote@v1.0.0 vs rsc.io/quote@v1.5.2",
+		}},
+		{"dep added", func(b *BuildInfo) { b.Deps = append(b.Deps, &Module{Path: "example.com/new", Version: "v1.0.0"}) }, []string{
+			"dependency example.com/new only in second build",
+		}},
+		{"setting", func(b *BuildInfo) { b.Settings = append(b.Settings, BuildSetting{Key: "CGO_ENABLED", Value: "0"}) }, []string{
+			`setting CGO_ENABLED differs: (unset) vs "0"`,
+		}},
+		{"dirty", func(b *BuildInfo) { b.Settings[4].Value = "true" }, []string{
+			`setting vcs.modified differs: "false" vs "true"`,
+			"second build is from a modified working tree",
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := reproBuild(), reproBuild()
+			tt.change(b)
+			ok, reasons := ReproducibleCandidates(a, b)
+			if ok != (len(tt.want) == 0) || !reflect.DeepEqual(reasons, tt.want) {
+				t.Errorf("ReproducibleCandidates = %v, %q, want %v, %q", ok, reasons, len(tt.want) == 0, tt.want)
+			}
+		})
+	}
+}
+
+func TestSumDrift(t *testing.T) {
+	old := &BuildInfo{Deps: []*Module{
+		{Path: "golang.org/x/text", Version: "v0.3.3", Sum: "h1:cafe="},
+		{Path: "rsc.io/quote", Version: "v1.5.2", Sum: "h1:beef="},
+		{Path: "rsc.io/sampler", Version: "v1.3.0", Sum: "h1:aaaa="},
+		{Path: "example.com/fork", Version: "v1.0.0", Replace: &Module{Path: "example.com/fork2", Version: "v1.0.1", Sum: "h1:1111="}},
+		{Path: "example.com/local", Replace: &Module{Path: "../local"}},
+	}}
+	new := &BuildInfo{Deps: []*Module{
+		{Path: "golang.org/x/text", Version: "v0.3.3", Sum: "h1:f00d="},
+		{Path: "rsc.io/quote", Version: "v1.5.3", Sum: "h1:d00d="},
+		{Path: "rsc.io/sampler", Version: "v1.3.0", Sum: "h1:aaaa="},
+		{Path: "example.com/fork", Version: "v1.0.0", Replace: &Module{Path: "example.com/fork2", Version: "v1.0.1", Sum: "h1:2222="}},
+		{Path: "example.com/local", Replace: &Module{Path: "../local"}},
+		{Path: "example.com/new", Version: "v1.0.0", Sum: "h1:3333="},
+	}}
+	var got []string
+	for _, m := range SumDrift(old, new) {
+		got = append(got, m.Path)
+	}
+	want := []string{"golang.org/x/text", "example.com/fork"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SumDrift = %q, want %q", got, want)
+	}
+	if d := SumDrift(old, old); len(d) != 0 {
+		t.Errorf("SumDrift(old, old) = %v, want none", d)
+	}
+}
+
+func TestCommonDeps(t *testing.T) {
+	text := &Module{Path: "golang.org/x/text", Version: "v0.3.3", Sum: "h1:cafe="}
+	quote := &Module{Path: "rsc.io/quote", Version: "v1.5.2"}
+	sampler := &Module{Path: "rsc.io/sampler", Version: "v1.3.0"}
+	a := &BuildInfo{Deps: []*Module{sampler, text, quote, {Path: "example.com/a", Version: "v1.0.0"}}}
+	b := &BuildInfo{Deps: []*Module{quote, text, {Path: "rsc.io/sampler", Version: "v1.3.1"}}}
+	c := &BuildInfo{Deps: []*Module{nil, {Path: "golang.org/x/text", Version: "v0.3.3"}, sampler, quote}}
+
+	var got []string
+	for _, m := range CommonDeps(a, b, c) {
+		got = append(got, m.Key())
+	}
+	want := []string{"golang.org/x/text@v0.3.3", "rsc.io/quote@v1.5.2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CommonDeps(a, b, c) = %q, want %q", got, want)
+	}
+	if m := CommonDeps(a, b, c)[0]; m.Sum != "h1:cafe=" {
+		t.Errorf("CommonDeps(a, b, c)[0].Sum = %q, want the first build's %q", m.Sum, "h1:cafe=")
+	}
+
+	for _, infos := range [][]*BuildInfo{nil, {a}, {a, {}}} {
+		if common := CommonDeps(infos...); common == nil || len(common) != 0 {
+			t.Errorf("CommonDeps(%d infos) = %#v, want empty slice", len(infos), common)
+		}
+	}
+}
+
+func TestFullDiff(t *testing.T) {
+	old := &BuildInfo{
+		GoVersion: "go1.14.4",
+		Deps: []*Module{
+			{Path: "golang.org/x/text", Version: "v0.3.2"},
+			{Path: "rsc.io/quote", Version: "v1.5.2"},
+			{Path: "rsc.io/sampler", Version: "v1.3.0"},
+		},
+		Settings: []BuildSetting{
+			{Key: "-compiler", Value: "gc"},
+			{Key: "CGO_ENABLED", Value: "1"},
+			{Key: "GOOS", Value: "linux"},
+		},
+	}
+	new := &BuildInfo{
+		GoVersion: "go1.15",
+		Deps: []*Module{
+			{Path: "rsc.io/sampler", Version: "v1.3.0"},
+			{Path: "rsc.io/quote", Version: "v1.5.2", Replace: &Module{Path: "../quote"}},
+			{Path: "golang.org/x/text", Version: "v0.3.3"},
+			{Path: "example.com/new", Version: "v1.0.0"},
+		},
+		Settings: []BuildSetting{
+			{Key: "-compiler", Value: "gc"},
+			{Key: "CGO_ENABLED", Value: "0"},
+			{Key: "-trimpath", Value: "true"},
+		},
+	}
+	want := FullBuildDiff{
+		Deps: BuildInfoDiff{
+			Added: []*Module{new.Deps[3]},
+			Changed: []DepChange{
+				{Path: "golang.org/x/text", Old: old.Deps[0], New: new.Deps[2]},
+				{Path: "rsc.io/quote", Old: old.Deps[1], New: new.Deps[1]},
+			},
+		},
+		Settings: map[string][2]string{
+			"-trimpath":   {"", "true"},
+			"CGO_ENABLED": {"1", "0"},
+			"GOOS":        {"linux", ""},
+		},
+		GoVersionChanged: [2]string{"go1.14.4", "go1.15"},
+	}
+	for i := 0; i < 3; i++ {
+		if got := FullDiff(old, new); !reflect.DeepEqual(got, want) {
+			t.Fatalf("FullDiff =\n%+v\nwant\n%+v", got, want)
+		}
+	}
+
+	d := FullDiff(new, new)
+	if !reflect.DeepEqual(d.Deps, BuildInfoDiff{}) || len(d.Settings) != 0 || d.GoVersionChanged != [2]string{} {
+		t.Errorf("FullDiff(new, new) = %+v, want no changes", d)
+	}
+	if r := Diff(new, old); len(r.Removed) != 1 || r.Removed[0].Path != "example.com/new" {
+		t.Errorf("Diff(new, old).Removed = %v, want example.com/new", r.Removed)
+	}
+}
+
+func TestHostDiff(t *testing.T) {
+	old := &BuildInfo{Deps: []*Module{
+		{Path: "golang.org/x/text", Version: "v0.3.2"},
+		{Path: "golang.org/x/sys", Version: "v0.0.0-20200101000000-abcdefabcdef"},
+		{Path: "github.com/pkg/errors", Version: "v0.8.1"},
+		{Path: "golang.org.example.com/fake", Version: "v1.0.0"},
+	}}
+	new := &BuildInfo{Deps: []*Module{
+		{Path: "golang.org/x/text", Version: "v0.3.3"},
+		{Path: "golang.org/x/net", Version: "v0.0.0-20200202000000-abcdefabcdef"},
+		{Path: "github.com/pkg/errors", Version: "v0.9.1"},
+		{Path: "github.com/google/uuid", Version: "v1.1.1"},
+	}}
+	want := BuildInfoDiff{
+		Added:   []*Module{new.Deps[1]},
+		Removed: []*Module{old.Deps[1]},
+		Changed: []DepChange{{Path: "golang.org/x/text", Old: old.Deps[0], New: new.Deps[0]}},
+	}
+	if got := HostDiff(old, new, "golang.org"); !reflect.DeepEqual(got, want) {
+		t.Errorf("HostDiff(golang.org) =\n%+v\nwant\n%+v", got, want)
+	}
+	gh := HostDiff(old, new, "github.com")
+	if len(gh.Added) != 1 || gh.Added[0].Path != "github.com/google/uuid" || len(gh.Removed) != 0 ||
+		len(gh.Changed) != 1 || gh.Changed[0].Path != "github.com/pkg/errors" {
+		t.Errorf("HostDiff(github.com) = %+v", gh)
+	}
+	if d := HostDiff(old, new, "example.com"); !reflect.DeepEqual(d, BuildInfoDiff{}) {
+		t.Errorf("HostDiff(example.com) = %+v, want empty", d)
+	}
+}
+
+func TestCompareBuildInfo(t *testing.T) {
+	old := &BuildInfo{Deps: []*Module{
+		{Path: "example.com/down", Version: "v1.2.0"},
+		{Path: "example.com/gone", Version: "v1.0.0"},
+		{Path: "example.com/rerepl", Version: "v1.0.0", Replace: &Module{Path: "../a"}},
+		{Path: "example.com/same", Version: "v1.0.0", Sum: "h1:a="},
+		{Path: "example.com/sum", Version: "v1.0.0", Sum: "h1:a="},
+		{Path: "example.com/up", Version: "v1.2.0-pre"},
+	}}
+	new := &BuildInfo{Deps: []*Module{
+		{Path: "example.com/added", Version: "v0.1.0"},
+		{Path: "example.com/down", Version: "v1.1.9"},
+		{Path: "example.com/rerepl", Version: "v1.0.0", Replace: &Module{Path: "../b"}},
+		{Path: "example.com/same", Version: "v1.0.0", Sum: "h1:a="},
+		{Path: "example.com/sum", Version: "v1.0.0", Sum: "h1:b="},
+		{Path: "example.com/up", Version: "v1.2.0"},
+	}}
+	want := BuildDiff{
+		Added:      []*Module{new.Deps[0]},
+		Removed:    []*Module{old.Deps[1]},
+		Upgraded:   []DepChange{{Path: "example.com/up", Old: old.Deps[5], New: new.Deps[5]}},
+		Downgraded: []DepChange{{Path: "example.com/down", Old: old.Deps[0], New: new.Deps[1]}},
+		Replaced:   []DepChange{{Path: "example.com/rerepl", Old: old.Deps[2], New: new.Deps[2]}},
+		Changed:    []DepChange{{Path: "example.com/sum", Old: old.Deps[4], New: new.Deps[4]}},
+	}
+	if got := CompareBuildInfo(old, new); !reflect.DeepEqual(got, want) {
+		t.Errorf("CompareBuildInfo =\n%+v\nwant\n%+v", got, want)
+	}
+	if got := CompareBuildInfo(new, new); !reflect.DeepEqual(got, BuildDiff{}) {
+		t.Errorf("CompareBuildInfo(new, new) = %+v, want empty", got)
+	}
+}
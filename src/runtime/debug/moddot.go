@@ -0,0 +1,59 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+// GraphOptions controls the graph written by BuildInfo.WriteDOT.
+type GraphOptions struct {
+	Name         string // name of the graph; "buildinfo" if empty
+	OmitVersions bool   // name modules by path alone, not path@version
+}
+
+// WriteDOT writes to w the dependency set of bi as a graph in the DOT
+// language of Graphviz: an edge from the main module, drawn as a box,
+// to each dependency, and a dashed edge labeled "replace" from each
+// module to its replacement. Modules are named path@version, as
+// returned by Module.Key, so that graphs of different builds can be
+// compared; the nodes and edges are written in the order of bi.Deps.
+func (bi *BuildInfo) WriteDOT(w io.Writer, opts GraphOptions) error {
+	name := opts.Name
+	if name == "" {
+		name = "buildinfo"
+	}
+	node := func(m *Module) string {
+		if opts.OmitVersions {
+			return dotQuote(m.Path)
+		}
+		return dotQuote(m.Key())
+	}
+	var buf bytes.Buffer
+	buf.WriteString("digraph " + dotQuote(name) + " {\n")
+	main := node(&bi.Main)
+	buf.WriteString("\t" + main + " [shape=box];\n")
+	for _, dep := range bi.Deps {
+		if dep == nil {
+			continue
+		}
+		buf.WriteString("\t" + main + " -> " + node(dep) + ";\n")
+		for m := dep; m.Replace != nil; m = m.Replace {
+			buf.WriteString("\t" + node(m) + " -> " + node(m.Replace) + " [style=dashed, label=\"replace\"];\n")
+		}
+	}
+	buf.WriteString("}\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// dotQuote returns s as a DOT quoted string.
+func dotQuote(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
@@ -0,0 +1,54 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"bytes"
+	. "runtime/debug"
+	"testing"
+)
+
+func TestWriteDOT(t *testing.T) {
+	info := &BuildInfo{
+		Main: Module{Path: "example.com/hello", Version: "(devel)"},
+		Deps: []*Module{
+			{Path: "golang.org/x/text", Version: "v0.3.3"},
+			nil,
+			{Path: "rsc.io/quote", Version: "v1.5.2", Replace: &Module{Path: "example.com/\"quote\"", Version: "v1.0.0",
+				Replace: &Module{Path: `C:\quote`}}},
+		},
+	}
+	var buf bytes.Buffer
+	if err := info.WriteDOT(&buf, GraphOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	want := `digraph "buildinfo" {
+	"example.com/hello@(devel)" [shape=box];
+	"example.com/hello@(devel)" -> "golang.org/x/text@v0.3.3";
+	"example.com/hello@(devel)" -> "rsc.io/quote@v1.5.2";
+	"rsc.io/quote@v1.5.2" -> "example.com/\"quote\"@v1.0.0" [style=dashed, label="replace"];
+	"example.com/\"quote\"@v1.0.0" -> "C:\\quote" [style=dashed, label="replace"];
+}
+`
+	if buf.String() != want {
+		t.Errorf("WriteDOT:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := info.WriteDOT(&buf, GraphOptions{Name: "deps", OmitVersions: true}); err != nil {
+		t.Fatal(err)
+	}
+	want = `digraph "deps" {
+	"example.com/hello" [shape=box];
+	"example.com/hello" -> "golang.org/x/text";
+	"example.com/hello" -> "rsc.io/quote";
+	"rsc.io/quote" -> "example.com/\"quote\"" [style=dashed, label="replace"];
+	"example.com/\"quote\"" -> "C:\\quote" [style=dashed, label="replace"];
+}
+`
+	if buf.String() != want {
+		t.Errorf("WriteDOT with options:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
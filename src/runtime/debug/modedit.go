@@ -0,0 +1,127 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import "errors"
+
+// Exclude returns a copy of bi with the dependencies whose module
+// paths exactly match one of paths, and their replacements, removed
+// from Deps. It is useful for leaving known modules out of reports.
+// bi itself is not modified.
+func (bi *BuildInfo) Exclude(paths ...string) *BuildInfo {
+	drop := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		drop[p] = true
+	}
+	c := bi.clone()
+	deps := c.Deps[:0]
+	for _, dep := range c.Deps {
+		if dep != nil && !drop[dep.Path] {
+			deps = append(deps, dep)
+		}
+	}
+	c.Deps = deps
+	return c
+}
+
+// Compact removes nil entries from bi.Deps in place,
+// preserving the order of the remaining dependencies.
+func (bi *BuildInfo) Compact() {
+	deps := bi.Deps[:0]
+	for _, dep := range bi.Deps {
+		if dep != nil {
+			deps = append(deps, dep)
+		}
+	}
+	for i := len(deps); i < len(bi.Deps); i++ {
+		bi.Deps[i] = nil
+	}
+	bi.Deps = deps
+}
+
+// DepsInRange returns the dependencies of bi whose resolved versions,
+// the versions of the modules they resolve to as Module.Effective
+// reports them, lie within the inclusive semantic version range
+// [minVer, maxVer]. Dependencies
+// without a valid semantic version, such as those replaced by local
+// directories, are ignored. DepsInRange returns an error if either
+// bound is not a valid semantic version or minVer is greater than maxVer.
+func (bi *BuildInfo) DepsInRange(minVer, maxVer string) ([]*Module, error) {
+	if !isValidSemver(minVer) || !isValidSemver(maxVer) {
+		return nil, errors.New("invalid version range [" + minVer + ", " + maxVer + "]")
+	}
+	if compareSemver(minVer, maxVer) > 0 {
+		return nil, errors.New("empty version range [" + minVer + ", " + maxVer + "]")
+	}
+	var deps []*Module
+	for _, dep := range bi.Deps {
+		if dep == nil {
+			continue
+		}
+		v := dep.effective().Version
+		if isValidSemver(v) && compareSemver(minVer, v) <= 0 && compareSemver(v, maxVer) <= 0 {
+			deps = append(deps, dep)
+		}
+	}
+	return deps, nil
+}
+
+// Clone returns a deep copy of bi that shares no memory with it,
+// so that either can be modified without affecting the other: each
+// dependency, each module in a chain of replacements, and each origin
+// is copied, as are the slices holding them. Dependencies that are the
+// same *Module in bi are distinct copies in the result. Clone of a nil
+// BuildInfo returns nil.
+func (bi *BuildInfo) Clone() *BuildInfo {
+	if bi == nil {
+		return nil
+	}
+	return bi.clone()
+}
+
+// clone returns a deep copy of bi that shares no memory with it.
+func (bi *BuildInfo) clone() *BuildInfo {
+	c := BuildInfo{
+		GoVersion: bi.GoVersion,
+		Path:      bi.Path,
+	}
+	c.Main = *cloneModule(&bi.Main)
+	if bi.Deps != nil {
+		c.Deps = make([]*Module, len(bi.Deps))
+		for i, dep := range bi.Deps {
+			c.Deps[i] = cloneModule(dep)
+		}
+	}
+	if bi.Settings != nil {
+		c.Settings = append([]BuildSetting(nil), bi.Settings...)
+	}
+	if bi.Raw != nil {
+		c.Raw = append([]string(nil), bi.Raw...)
+	}
+	if bi.graph != nil {
+		c.graph = make(map[string][]string, len(bi.graph))
+		for from, to := range bi.graph {
+			c.graph[from] = append([]string(nil), to...)
+		}
+	}
+	return &c
+}
+
+// cloneModule returns a deep copy of m and its chain of replacements.
+func cloneModule(m *Module) *Module {
+	if m == nil {
+		return nil
+	}
+	c := *m
+	c.Replace = cloneModule(m.Replace)
+	if m.Origin != nil {
+		o := *m.Origin
+		c.Origin = &o
+	}
+	if m.Extra != nil {
+		c.Extra = append([]string(nil), m.Extra...)
+	}
+	return &c
+}
@@ -0,0 +1,149 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"reflect"
+	. "runtime/debug"
+	"testing"
+)
+
+func depPaths(info *BuildInfo) []string {
+	var paths []string
+	for _, dep := range info.Deps {
+		paths = append(paths, dep.Path)
+	}
+	return paths
+}
+
+func TestExclude(t *testing.T) {
+	info := &BuildInfo{
+		Main: Module{Path: "example.com/hello", Version: "v1.0.0"},
+		Deps: []*Module{
+			{Path: "example.com/a", Version: "v1.0.0"},
+			{Path: "example.com/b", Version: "v1.0.0", Replace: &Module{Path: "example.com/c", Version: "v1.1.0"}},
+			{Path: "example.com/c", Version: "v1.0.0"},
+			{Path: "example.com/d", Version: "v1.0.0"},
+		},
+	}
+	got := info.Exclude("example.com/b", "example.com/d", "example.com/missing")
+	if paths := depPaths(got); len(paths) != 2 || paths[0] != "example.com/a" || paths[1] != "example.com/c" {
+		t.Errorf("Exclude deps = %v, want [example.com/a example.com/c]", paths)
+	}
+	if n := len(info.Deps); n != 4 {
+		t.Errorf("Exclude modified the original: %d deps, want 4", n)
+	}
+	got.Deps[0].Version = "v9.9.9"
+	if info.Deps[0].Version != "v1.0.0" {
+		t.Error("Exclude result shares modules with the original")
+	}
+}
+
+func TestDepsInRange(t *testing.T) {
+	info := &BuildInfo{
+		Deps: []*Module{
+			{Path: "example.com/low", Version: "v0.9.9"},
+			{Path: "example.com/min", Version: "v1.0.0"},
+			{Path: "example.com/mid", Version: "v1.5.0-rc.1"},
+			{Path: "example.com/max", Version: "v2.0.0"},
+			{Path: "example.com/high", Version: "v2.0.1"},
+			{Path: "example.com/pre", Version: "v2.0.0-pre"},
+			{Path: "example.com/replaced", Version: "v3.0.0", Replace: &Module{Path: "example.com/fork", Version: "v1.2.0"}},
+			{Path: "example.com/local", Version: "v1.0.0", Replace: &Module{Path: "../local"}},
+			{Path: "example.com/devel", Version: "(devel)"},
+			{Path: "example.com/empty"},
+		},
+	}
+	deps, err := info.DepsInRange("v1.0.0", "v2.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := depPaths(&BuildInfo{Deps: deps})
+	want := []string{"example.com/min", "example.com/mid", "example.com/max", "example.com/pre", "example.com/replaced"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DepsInRange(v1.0.0, v2.0.0) = %q, want %q", got, want)
+	}
+
+	for _, r := range [][2]string{{"1.0.0", "v2.0.0"}, {"v1.0.0", ""}, {"v2.0.0", "v1.0.0"}} {
+		if deps, err := info.DepsInRange(r[0], r[1]); err == nil {
+			t.Errorf("DepsInRange(%q, %q) = %v, nil, want error", r[0], r[1], deps)
+		}
+	}
+}
+
+func TestCompact(t *testing.T) {
+	info := &BuildInfo{
+		Main: Module{Path: "example.com/hello", Version: "v1.0.0"},
+		Deps: []*Module{
+			nil,
+			{Path: "example.com/a", Version: "v1.0.0"},
+			nil,
+			{Path: "example.com/b", Version: "v1.0.0"},
+			nil,
+		},
+	}
+	text, err := info.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := formatHeader +
+		"mod\texample.com/hello\tv1.0.0\t\n" +
+		"dep\texample.com/a\tv1.0.0\t\n" +
+		"dep\texample.com/b\tv1.0.0\t\n"
+	if string(text) != want {
+		t.Errorf("MarshalText with nil deps =\n%s\nwant:\n%s", text, want)
+	}
+
+	info.Compact()
+	if got, want := depPaths(info), []string{"example.com/a", "example.com/b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after Compact, deps = %q, want %q", got, want)
+	}
+	new(BuildInfo).Compact()
+}
+
+func TestClone(t *testing.T) {
+	info, ok := ReadBuildInfoData(blob(testModinfo))
+	if !ok {
+		t.Fatal("ReadBuildInfo failed")
+	}
+	info.Deps[0].Extra = []string{"x"}
+	c := info.Clone()
+	if !reflect.DeepEqual(c, info) {
+		t.Fatalf("Clone() = %+v, want %+v", c, info)
+	}
+	c.Main.Path = "changed"
+	c.Deps[0].Version = "v0.0.0"
+	c.Deps[0].Extra[0] = "y"
+	c.Deps[1].Replace.Path = "changed"
+	c.Settings[0].Value = "changed"
+	want, _ := ReadBuildInfoData(blob(testModinfo))
+	want.Deps[0].Extra = []string{"x"}
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("modifying clone changed original to %+v", info)
+	}
+
+	// Dependencies shared within a BuildInfo are copied separately.
+	info.Deps[1] = info.Deps[0]
+	c = info.Clone()
+	c.Deps[0].Path = "changed"
+	if c.Deps[1].Path == "changed" || info.Deps[0].Path == "changed" {
+		t.Error("modifying a shared dependency of a clone changed another")
+	}
+
+	if (*BuildInfo)(nil).Clone() != nil {
+		t.Error("Clone of nil BuildInfo is not nil")
+	}
+
+	// ReadBuildInfo returns an independent copy on each call.
+	if a, ok := ReadBuildInfo(); ok {
+		a.Main.Path = "changed"
+		if len(a.Deps) > 0 {
+			a.Deps[0].Path = "changed"
+		}
+		if b, _ := ReadBuildInfo(); b.Main.Path == "changed" || len(b.Deps) > 0 && b.Deps[0].Path == "changed" {
+			t.Error("modifying ReadBuildInfo result changed later results")
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"bytes"
+	"strings"
+)
+
+// DotEnv returns bi in the syntax of an environment file (.env),
+// one KEY=value line per variable, so that it can be sourced by
+// shells and container entrypoints. The variables are APP_VERSION,
+// the version of the main module; APP_REVISION, the vcs.revision
+// setting; GO_VERSION; and BUILD_<KEY> for each setting, in order,
+// where <KEY> is the setting key upper-cased, with any leading '-'
+// removed and other characters not valid in a variable name replaced
+// by '_'. Values containing spaces or characters special to the shell
+// are double-quoted.
+func (bi *BuildInfo) DotEnv() []byte {
+	var buf bytes.Buffer
+	write := func(name, value string) {
+		buf.WriteString(name)
+		buf.WriteByte('=')
+		buf.WriteString(envValue(value))
+		buf.WriteByte('\n')
+	}
+	rev, _ := bi.setting("vcs.revision")
+	write("APP_VERSION", bi.Main.Version)
+	write("APP_REVISION", rev)
+	write("GO_VERSION", bi.GoVersion)
+	for _, s := range bi.Settings {
+		write("BUILD_"+envName(s.Key), s.Value)
+	}
+	return buf.Bytes()
+}
+
+// envName converts a setting key to the form used in a variable name.
+func envName(key string) string {
+	key = strings.TrimLeft(key, "-")
+	return strings.Map(func(r rune) rune {
+		switch {
+		case 'A' <= r && r <= 'Z', '0' <= r && r <= '9', r == '_':
+			return r
+		case 'a' <= r && r <= 'z':
+			return r - 'a' + 'A'
+		}
+		return '_'
+	}, key)
+}
+
+// envValue returns v, double-quoted if needed to be read back verbatim.
+func envValue(v string) string {
+	if !strings.ContainsAny(v, " \t\n\r\"'\\$`#;&|<>()*?[]{}~!") {
+		return v
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(v); i++ {
+		switch c := v[i]; c {
+		case '"', '\\', '$', '`':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\n':
+			b.WriteString(`\n`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,40 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	. "runtime/debug"
+	"testing"
+)
+
+func TestDotEnv(t *testing.T) {
+	info := &BuildInfo{
+		GoVersion: "go1.15",
+		Main:      Module{Path: "example.com/hello", Version: "v1.2.3"},
+		Settings: []BuildSetting{
+			{Key: "-compiler", Value: "gc"},
+			{Key: "-ldflags", Value: "-s -X main.version=1"},
+			{Key: "-tags", Value: `a,"b"`},
+			{Key: "-trimpath"},
+			{Key: "CGO_ENABLED", Value: "1"},
+			{Key: "vcs.revision", Value: "abc123"},
+			{Key: "vcs.time", Value: "$HOME"},
+		},
+	}
+	want := `APP_VERSION=v1.2.3
+APP_REVISION=abc123
+GO_VERSION=go1.15
+BUILD_COMPILER=gc
+BUILD_LDFLAGS="-s -X main.version=1"
+BUILD_TAGS="a,\"b\""
+BUILD_TRIMPATH=
+BUILD_CGO_ENABLED=1
+BUILD_VCS_REVISION=abc123
+BUILD_VCS_TIME="\$HOME"
+`
+	if got := string(info.DotEnv()); got != want {
+		t.Errorf("DotEnv() =\n%s\nwant:\n%s", got, want)
+	}
+}
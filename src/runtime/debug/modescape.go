@@ -0,0 +1,70 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"errors"
+	"strconv"
+)
+
+// EscapePath returns the escaped form of the module path path, as used
+// for the module's directory in the module cache and in module proxy
+// URLs: each upper-case letter is replaced by an exclamation mark
+// followed by the letter's lower-case form, so that the result is safe
+// on case-insensitive file systems. For example, the escaped form of
+// github.com/Azure/azure-sdk-for-go is github.com/!azure/azure-sdk-for-go.
+// EscapePath fails if path is not a well-formed module path, as checked
+// by Validate. It is the inverse of UnescapePath.
+func EscapePath(path string) (string, error) {
+	if err := checkModulePath(path); err != nil {
+		return "", err
+	}
+	// A valid module path has only ASCII characters and no
+	// exclamation marks, so the escaping cannot be ambiguous.
+	var buf []byte
+	for i := 0; i < len(path); i++ {
+		if c := path[i]; 'A' <= c && c <= 'Z' {
+			if buf == nil {
+				buf = append(make([]byte, 0, len(path)+4), path[:i]...)
+			}
+			buf = append(buf, '!', c+'a'-'A')
+		} else if buf != nil {
+			buf = append(buf, c)
+		}
+	}
+	if buf == nil {
+		return path, nil
+	}
+	return string(buf), nil
+}
+
+// UnescapePath returns the module path whose escaped form, as returned
+// by EscapePath, is escaped. It fails if escaped is not a valid
+// escaped form, because it contains an upper-case letter or an
+// exclamation mark not followed by a lower-case letter, or if it
+// describes a malformed module path.
+func UnescapePath(escaped string) (string, error) {
+	buf := make([]byte, 0, len(escaped))
+	for i := 0; i < len(escaped); i++ {
+		c := escaped[i]
+		switch {
+		case c == '!':
+			if i+1 == len(escaped) || escaped[i+1] < 'a' || 'z' < escaped[i+1] {
+				return "", errors.New("invalid escaped module path " + strconv.Quote(escaped))
+			}
+			i++
+			buf = append(buf, escaped[i]-'a'+'A')
+		case 'A' <= c && c <= 'Z':
+			return "", errors.New("invalid escaped module path " + strconv.Quote(escaped))
+		default:
+			buf = append(buf, c)
+		}
+	}
+	path := string(buf)
+	if err := checkModulePath(path); err != nil {
+		return "", errors.New("invalid escaped module path " + strconv.Quote(escaped) + ": " + err.Error())
+	}
+	return path, nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	. "runtime/debug"
+	"testing"
+)
+
+var escapeTests = []struct {
+	path, escaped string
+}{
+	{"example.com/hello", "example.com/hello"},
+	{"github.com/Azure/azure-sdk-for-go", "github.com/!azure/azure-sdk-for-go"},
+	{"github.com/GoogleCloudPlatform/cloudsql-proxy", "github.com/!google!cloud!platform/cloudsql-proxy"},
+	{"Example.COM/x", "!example.!c!o!m/x"},
+}
+
+func TestEscapePath(t *testing.T) {
+	for _, tt := range escapeTests {
+		escaped, err := EscapePath(tt.path)
+		if err != nil || escaped != tt.escaped {
+			t.Errorf("EscapePath(%q) = %q, %v, want %q, nil", tt.path, escaped, err, tt.escaped)
+		}
+		path, err := UnescapePath(tt.escaped)
+		if err != nil || path != tt.path {
+			t.Errorf("UnescapePath(%q) = %q, %v, want %q, nil", tt.escaped, path, err, tt.path)
+		}
+	}
+}
+
+func TestEscapePathErrors(t *testing.T) {
+	for _, path := range []string{"", "example.com//x", "example.com/x!y", "example.com/é", "example.com/x."} {
+		if escaped, err := EscapePath(path); err == nil {
+			t.Errorf("EscapePath(%q) = %q, nil, want error", path, escaped)
+		}
+	}
+	for _, escaped := range []string{"", "github.com/Azure/x", "github.com/!", "github.com/!1x", "github.com/!!x", "example.com//x"} {
+		if path, err := UnescapePath(escaped); err == nil {
+			t.Errorf("UnescapePath(%q) = %q, nil, want error", escaped, path)
+		}
+	}
+}
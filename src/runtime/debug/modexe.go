@@ -0,0 +1,203 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+)
+
+// The module information embedded by cmd/go is a string literal
+// framed by these sentinels, so it appears verbatim in the data of
+// the executable whatever its object file format.
+// See cmd/go/internal/modload.ModInfoProg.
+const (
+	infoStart = "\x30\x77\xaf\x0c\x92\x74\x08\x02\x41\xe1\xc1\x07\xe6\xd6\x18\xe6"
+	infoEnd   = "\xf9\x32\x43\x31\x86\x18\x20\x72\x00\x82\x42\x10\x41\x16\xd8\xf2"
+)
+
+// ErrTruncatedBuildInfo reports that a file holds the start of build
+// information but ends before its end, as happens with a binary that
+// was truncated, for example by an interrupted copy. The errors
+// returned for such files wrap ErrTruncatedBuildInfo and say how many
+// bytes of build information were found.
+var ErrTruncatedBuildInfo = errors.New("truncated build information")
+
+type truncatedError struct {
+	n int64 // bytes found, starting with the start sentinel
+}
+
+func (e *truncatedError) Error() string {
+	return ErrTruncatedBuildInfo.Error() + ": file ends " + strconv.FormatInt(e.n, 10) + " bytes after its start"
+}
+
+func (e *truncatedError) Unwrap() error { return ErrTruncatedBuildInfo }
+
+// scanChunk is the size of the reads used to search executables.
+const scanChunk = 1 << 20
+
+// findModinfo searches the first size bytes of r for the embedded
+// module information and returns it, sentinels included, in the form
+// returned by the runtime's modinfo.
+//
+// Binaries that use this package also hold copies of the sentinels
+// themselves, as string constants, so the search skips framed text that
+// does not begin with a format, go, path, or mod line, as cmd/go's
+// always does.
+func findModinfo(r io.ReaderAt, size int64) (string, error) {
+	_, data, err := locateModinfo(r, size)
+	return data, err
+}
+
+// locateModinfo is like findModinfo but also returns the offset in r
+// of the module information.
+func locateModinfo(r io.ReaderAt, size int64) (int64, string, error) {
+	for off := int64(0); ; {
+		start, err := indexAt(r, off, size, infoStart)
+		if err != nil {
+			return 0, "", err
+		}
+		end, err := indexAt(r, start+int64(len(infoStart)), size, infoEnd)
+		if err == ErrNoBuildInfo {
+			// Report truncation only if the start sentinel
+			// is followed by the beginning of the text.
+			head := make([]byte, len(infoStart)+len(pathLine))
+			n, _ := r.ReadAt(head, start)
+			if beginsModinfo(head[len(infoStart):n], true) {
+				return 0, "", &truncatedError{size - start}
+			}
+			off = start + int64(len(infoStart))
+			continue
+		}
+		if err != nil {
+			return 0, "", err
+		}
+		// The text begins after the last start sentinel before the end.
+		for {
+			next, err := indexAt(r, start+int64(len(infoStart)), end, infoStart)
+			if err == ErrNoBuildInfo {
+				break
+			}
+			if err != nil {
+				return 0, "", err
+			}
+			start = next
+		}
+		n := end - start - int64(len(infoStart))
+		if max := currentTextLimits().maxBytes(); max > 0 && n > max {
+			return 0, "", &sizeLimitError{n, max}
+		}
+		data := make([]byte, end+int64(len(infoEnd))-start)
+		if _, err := r.ReadAt(data, start); err != nil && err != io.EOF {
+			return 0, "", err
+		}
+		if beginsModinfo(data[len(infoStart):], false) {
+			return start, string(data), nil
+		}
+		off = end + int64(len(infoEnd))
+	}
+}
+
+// beginsModinfo reports whether text begins with a format, go, path, or
+// mod line, as the text of module information written by cmd/go or
+// WriteBuildInfo does.
+// If partial is set, text may also be cut short within such a line.
+func beginsModinfo(text []byte, partial bool) bool {
+	for _, line := range []string{formatLine, goLine, pathLine, modLine} {
+		if bytes.HasPrefix(text, []byte(line)) || partial && bytes.HasPrefix([]byte(line), text) {
+			return true
+		}
+	}
+	return false
+}
+
+// indexAt returns the offset of the first instance of sep
+// in r between offsets off and size.
+func indexAt(r io.ReaderAt, off, size int64, sep string) (int64, error) {
+	// Consecutive reads overlap by len(sep)-1 bytes
+	// so that a sentinel straddling two chunks is found.
+	buf := make([]byte, scanChunk+len(sep)-1)
+	for ; off < size; off += scanChunk {
+		if rem := size - off; rem < int64(len(buf)) {
+			buf = buf[:rem]
+		}
+		n, err := r.ReadAt(buf, off)
+		if i := bytes.Index(buf[:n], []byte(sep)); i >= 0 {
+			return off + int64(i), nil
+		}
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		if n < len(buf) {
+			break
+		}
+	}
+	return 0, ErrNoBuildInfo
+}
+
+// ReadBuildInfoFrom returns the build information embedded in the Go
+// executable read from r, which need not be the running binary nor
+// built for the running system. The module information cmd/go embeds
+// is framed by fixed sentinels wherever the linker placed it, so r may
+// hold an ELF, PE, or Mach-O file, or indeed any file containing a Go
+// binary. If r has a Size or Stat method, as *os.File,
+// *io.SectionReader, and *bytes.Reader do, it determines how much of
+// r is searched; otherwise r is read until io.EOF. If r ends partway
+// through the build information, the error wraps ErrTruncatedBuildInfo,
+// and if the build information exceeds the limits set by SetTextLimits,
+// the error wraps ErrLimitExceeded. If r holds no build information,
+// the error is ErrNoBuildInfo. Otherwise the errors are those of
+// ReadBuildInfoErr; for a malformed line, the error is a *TextError.
+func ReadBuildInfoFrom(r io.ReaderAt) (*BuildInfo, error) {
+	size, err := readerSize(r)
+	if err != nil {
+		return nil, err
+	}
+	data, err := findModinfo(r, size)
+	if err != nil {
+		return nil, err
+	}
+	return readBuildInfoErr(data)
+}
+
+// ReadBuildInfoFromFile returns the build information embedded in the
+// Go executable named by path, as read by ReadBuildInfoFrom.
+func ReadBuildInfoFromFile(path string) (*BuildInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadBuildInfoFrom(f)
+}
+
+// readerSize returns the size of the data in r.
+func readerSize(r io.ReaderAt) (int64, error) {
+	switch r := r.(type) {
+	case interface{ Size() int64 }:
+		return r.Size(), nil
+	case interface{ Stat() (os.FileInfo, error) }:
+		fi, err := r.Stat()
+		if err != nil {
+			return 0, err
+		}
+		return fi.Size(), nil
+	}
+	buf := make([]byte, scanChunk)
+	var size int64
+	for {
+		n, err := r.ReadAt(buf, size)
+		size += int64(n)
+		if err == io.EOF {
+			return size, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+}
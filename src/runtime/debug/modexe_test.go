@@ -0,0 +1,124 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	. "runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestReadBuildInfoFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "modexe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	exe := writeExe(t, dir, testModinfo)
+	info, err := ReadBuildInfoFromFile(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Main.Path != "example.com/hello" || len(info.Deps) != 2 {
+		t.Errorf("ReadBuildInfoFromFile = %+v", info)
+	}
+
+	// Cut the file off partway through the build information.
+	data, err := ioutil.ReadFile(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := strings.Index(string(data), infoStart)
+	truncated := filepath.Join(dir, "truncated")
+	if err := ioutil.WriteFile(truncated, data[:start+40], 0777); err != nil {
+		t.Fatal(err)
+	}
+	_, err = ReadBuildInfoFromFile(truncated)
+	if !errors.Is(err, ErrTruncatedBuildInfo) {
+		t.Fatalf("ReadBuildInfoFromFile(truncated) error = %v, want ErrTruncatedBuildInfo", err)
+	}
+	if !strings.Contains(err.Error(), " 40 bytes") {
+		t.Errorf("truncation error %q does not report the 40 bytes found", err)
+	}
+
+	none := filepath.Join(dir, "none")
+	if err := ioutil.WriteFile(none, data[:start], 0777); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadBuildInfoFromFile(none); err != ErrNoBuildInfo {
+		t.Errorf("ReadBuildInfoFromFile(none) error = %v, want ErrNoBuildInfo", err)
+	}
+}
+
+// onlyReaderAt hides the Size method of the reader it wraps.
+type onlyReaderAt struct{ r io.ReaderAt }
+
+func (r onlyReaderAt) ReadAt(p []byte, off int64) (int, error) { return r.r.ReadAt(p, off) }
+
+func TestReadBuildInfoFrom(t *testing.T) {
+	data := []byte("\x7fELF padding" + blob(testModinfo) + "trailer")
+	for _, r := range []io.ReaderAt{
+		bytes.NewReader(data),
+		io.NewSectionReader(bytes.NewReader(data), 0, int64(len(data))),
+		onlyReaderAt{bytes.NewReader(data)},
+	} {
+		info, err := ReadBuildInfoFrom(r)
+		if err != nil {
+			t.Fatalf("ReadBuildInfoFrom(%T): %v", r, err)
+		}
+		if info.Main.Path != "example.com/hello" || len(info.Deps) != 2 {
+			t.Errorf("ReadBuildInfoFrom(%T) = %+v", r, info)
+		}
+	}
+
+	cut := data[:bytes.Index(data, []byte(infoStart))+20]
+	for _, r := range []io.ReaderAt{bytes.NewReader(cut), onlyReaderAt{bytes.NewReader(cut)}} {
+		if _, err := ReadBuildInfoFrom(r); !errors.Is(err, ErrTruncatedBuildInfo) || !strings.Contains(err.Error(), " 20 bytes") {
+			t.Errorf("ReadBuildInfoFrom(%T) on truncated data: error = %v, want 20 bytes of ErrTruncatedBuildInfo", r, err)
+		}
+	}
+
+	// A corrupt line is reported with its position.
+	corrupt := strings.Replace(testModinfo, "\tv1.2.3\th1:c0ffee=", "", 1)
+	_, err := ReadBuildInfoFrom(strings.NewReader("\x7fELF padding" + blob(corrupt) + "trailer"))
+	var te *TextError
+	if !errors.As(err, &te) {
+		t.Fatalf("ReadBuildInfoFrom(corrupt) error = %v, want a *TextError", err)
+	}
+	if te.Line != 2 || te.Err != ErrSyntax {
+		t.Errorf("ReadBuildInfoFrom(corrupt) error = %+v, want ErrSyntax at line 2", te)
+	}
+}
+
+func TestReadBuildInfoFromDecoys(t *testing.T) {
+	// A binary that uses this package holds the sentinels as
+	// string constants, in either order, which must not be
+	// mistaken for the module information.
+	for _, data := range []string{
+		"\x00" + infoStart + "\x00constants\x00" + infoEnd + "\x00" + blob(testModinfo),
+		infoEnd + "\x00" + infoStart + "\x00" + blob(testModinfo),
+		blob(testModinfo) + "\x00" + infoEnd + "\x00" + infoStart + "\x00",
+	} {
+		info, err := ReadBuildInfoFrom(strings.NewReader(data))
+		if err != nil {
+			t.Errorf("ReadBuildInfoFrom(%q): %v", data, err)
+			continue
+		}
+		if info.Main.Path != "example.com/hello" {
+			t.Errorf("ReadBuildInfoFrom(%q) = %+v", data, info)
+		}
+	}
+	_, err := ReadBuildInfoFrom(strings.NewReader(infoEnd + "\x00" + infoStart + "\x00constants"))
+	if err == nil || errors.Is(err, ErrTruncatedBuildInfo) {
+		t.Errorf("ReadBuildInfoFrom(constants only) error = %v, want non-truncation error", err)
+	}
+}
@@ -0,0 +1,399 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Format formats bi according to tmpl, a template in a small subset of
+// the syntax of text/template, for such uses as the output of a
+// -version flag:
+//
+//	{{.Main.Version}}
+//
+// Text outside actions, which are delimited by {{ and }}, is copied
+// unchanged. An action is one of
+//
+//	{{X}}             the value of X
+//	{{if X}}...{{end}}, {{if X}}...{{else}}...{{end}}
+//	                  the first part if X is not the zero value of its
+//	                  type, such as the empty string or a nil Replace,
+//	                  or an empty slice, and otherwise the second
+//	{{range X}}...{{end}}
+//	                  the part for each element of X, a slice such as
+//	                  .Deps or .Settings, with dot set to the element
+//
+// where X is a field, such as .Path, .Main.Replace.Version, or . for
+// dot, which is initially bi, or a function applied to one:
+//
+//	path M      the path of the module used for M: that of the last
+//	            replacement in its chain of replacements, or its own
+//	version M   the version of the module used for M, which is empty
+//	            for a local directory
+//	key M       the path@version of the module used for M, as Module.Key
+//	kind M      the kind of M's replacement, as Module.ReplacementKind
+//	setting S   the value of the build setting with the key S, a quoted
+//	            string, or the empty string if there is none
+//
+// Strings, numbers, and booleans print as text/template prints them, a
+// Module as its Key, a BuildSetting as key=value, a nil pointer as
+// nothing, and a slice as its elements in brackets. As in text/template,
+// a - after {{ or before }}, separated by a space from the rest of the
+// action, trims the white space before or after the action.
+//
+// Format returns an error if tmpl is malformed or refers to a field
+// that does not exist.
+func (bi *BuildInfo) Format(tmpl string) (string, error) {
+	nodes, err := parseFormat(tmpl)
+	if err != nil {
+		return "", err
+	}
+	var buf strings.Builder
+	st := formatState{bi: bi, buf: &buf}
+	if err := st.walk(nodes, reflect.ValueOf(bi)); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Kinds of formatNode.
+const (
+	formatText = iota
+	formatValue
+	formatIf
+	formatRange
+)
+
+// A formatNode is a piece of a parsed Format template.
+type formatNode struct {
+	kind     int
+	text     string       // for formatText
+	expr     formatExpr   // for the others
+	list     []formatNode // body of if or range
+	elseList []formatNode // else part of if
+}
+
+// A formatExpr is the expression of an action: a field chain,
+// optionally the argument of a function.
+type formatExpr struct {
+	fn     string   // function name, or "" for the field alone
+	fields []string // field chain applied to dot; empty for dot
+	str    string   // string argument, for setting
+}
+
+func errFormat(msg string) error {
+	return errors.New("build information format: " + msg)
+}
+
+// parseFormat parses a Format template.
+func parseFormat(tmpl string) ([]formatNode, error) {
+	type frame struct {
+		node   formatNode
+		inElse bool
+	}
+	var (
+		stack []frame
+		top   []formatNode
+	)
+	// add appends n to the innermost list.
+	add := func(n formatNode) {
+		if len(stack) == 0 {
+			top = append(top, n)
+			return
+		}
+		f := &stack[len(stack)-1]
+		if f.inElse {
+			f.node.elseList = append(f.node.elseList, n)
+		} else {
+			f.node.list = append(f.node.list, n)
+		}
+	}
+	trimNext := false
+	for len(tmpl) > 0 {
+		i := strings.Index(tmpl, "{{")
+		if i < 0 {
+			i = len(tmpl)
+		}
+		text := tmpl[:i]
+		tmpl = tmpl[i:]
+		if trimNext {
+			text = strings.TrimLeft(text, " \t\r\n")
+		}
+		trimNext = false
+		if strings.HasPrefix(tmpl, "{{- ") {
+			text = strings.TrimRight(text, " \t\r\n")
+		}
+		if text != "" {
+			add(formatNode{kind: formatText, text: text})
+		}
+		if tmpl == "" {
+			break
+		}
+		j := strings.Index(tmpl, "}}")
+		if j < 0 {
+			return nil, errFormat("unclosed action")
+		}
+		action := tmpl[2:j]
+		tmpl = tmpl[j+2:]
+		if strings.HasPrefix(action, "- ") {
+			action = action[2:]
+		}
+		if strings.HasSuffix(action, " -") {
+			action = action[:len(action)-2]
+			trimNext = true
+		}
+		words, err := formatWords(action)
+		if err != nil {
+			return nil, err
+		}
+		if len(words) == 0 {
+			return nil, errFormat("missing value for action")
+		}
+		switch words[0] {
+		case "if", "range":
+			expr, err := parseFormatExpr(words[1:])
+			if err != nil {
+				return nil, err
+			}
+			kind := formatIf
+			if words[0] == "range" {
+				kind = formatRange
+			}
+			stack = append(stack, frame{node: formatNode{kind: kind, expr: expr}})
+		case "else":
+			if len(words) != 1 || len(stack) == 0 || stack[len(stack)-1].node.kind != formatIf || stack[len(stack)-1].inElse {
+				return nil, errFormat("unexpected {{else}}")
+			}
+			stack[len(stack)-1].inElse = true
+		case "end":
+			if len(words) != 1 || len(stack) == 0 {
+				return nil, errFormat("unexpected {{end}}")
+			}
+			n := stack[len(stack)-1].node
+			stack = stack[:len(stack)-1]
+			add(n)
+		default:
+			expr, err := parseFormatExpr(words)
+			if err != nil {
+				return nil, err
+			}
+			add(formatNode{kind: formatValue, expr: expr})
+		}
+	}
+	if len(stack) > 0 {
+		return nil, errFormat("missing {{end}}")
+	}
+	return top, nil
+}
+
+// formatWords splits an action into words: fields, identifiers, and
+// quoted strings, which are returned with their quotes.
+func formatWords(action string) ([]string, error) {
+	var words []string
+	for {
+		action = strings.TrimLeft(action, " \t")
+		if action == "" {
+			return words, nil
+		}
+		if action[0] == '"' {
+			i := 1
+			for i < len(action) && action[i] != '"' {
+				if action[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= len(action) {
+				return nil, errFormat("unterminated quoted string")
+			}
+			words = append(words, action[:i+1])
+			action = action[i+1:]
+			continue
+		}
+		i := strings.IndexAny(action, " \t")
+		if i < 0 {
+			i = len(action)
+		}
+		words = append(words, action[:i])
+		action = action[i:]
+	}
+}
+
+// parseFormatExpr parses the words of an expression.
+func parseFormatExpr(words []string) (formatExpr, error) {
+	var e formatExpr
+	switch len(words) {
+	case 0:
+		return e, errFormat("missing value")
+	case 1:
+	case 2:
+		e.fn = words[0]
+		words = words[1:]
+		switch e.fn {
+		case "path", "version", "key", "kind":
+		case "setting":
+			s, err := strconv.Unquote(words[0])
+			if err != nil {
+				return e, errFormat("setting requires a quoted string, not " + words[0])
+			}
+			e.str = s
+			return e, nil
+		default:
+			return e, errFormat("unknown function " + strconv.Quote(e.fn))
+		}
+	default:
+		return e, errFormat("too many words in " + strconv.Quote(strings.Join(words, " ")))
+	}
+	w := words[0]
+	if !strings.HasPrefix(w, ".") {
+		return e, errFormat("bad value " + strconv.Quote(w))
+	}
+	if w != "." {
+		e.fields = strings.Split(w[1:], ".")
+		for _, f := range e.fields {
+			if f == "" {
+				return e, errFormat("bad field in " + strconv.Quote(w))
+			}
+		}
+	}
+	return e, nil
+}
+
+// formatState is the state of executing a Format template.
+type formatState struct {
+	bi  *BuildInfo
+	buf *strings.Builder
+}
+
+var moduleType = reflect.TypeOf(Module{})
+
+func (st *formatState) walk(nodes []formatNode, dot reflect.Value) error {
+	for _, n := range nodes {
+		if n.kind == formatText {
+			st.buf.WriteString(n.text)
+			continue
+		}
+		v, err := st.eval(n.expr, dot)
+		if err != nil {
+			return err
+		}
+		switch n.kind {
+		case formatValue:
+			st.print(v)
+		case formatIf:
+			list := n.list
+			if !v.IsValid() || v.IsZero() || (v.Kind() == reflect.Slice && v.Len() == 0) {
+				list = n.elseList
+			}
+			if err := st.walk(list, dot); err != nil {
+				return err
+			}
+		case formatRange:
+			if v.Kind() != reflect.Slice {
+				return errFormat("range over " + v.Type().String())
+			}
+			for i := 0; i < v.Len(); i++ {
+				if err := st.walk(n.list, v.Index(i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// eval evaluates e with dot.
+func (st *formatState) eval(e formatExpr, dot reflect.Value) (reflect.Value, error) {
+	if e.fn == "setting" {
+		v, _ := st.bi.Setting(e.str)
+		return reflect.ValueOf(v), nil
+	}
+	v := dot
+	for _, f := range e.fields {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, errFormat("nil pointer evaluating ." + strings.Join(e.fields, "."))
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, errFormat("can't evaluate field " + f + " in type " + v.Type().String())
+		}
+		sf, ok := v.Type().FieldByName(f)
+		if !ok || sf.PkgPath != "" {
+			return reflect.Value{}, errFormat("can't evaluate field " + f + " in type " + v.Type().String())
+		}
+		v = v.FieldByIndex(sf.Index)
+	}
+	if e.fn == "" {
+		return v, nil
+	}
+	var m *Module
+	switch {
+	case v.Type() == moduleType:
+		mv := v.Interface().(Module)
+		m = &mv
+	case v.Type() == reflect.PtrTo(moduleType):
+		m = v.Interface().(*Module)
+	default:
+		return reflect.Value{}, errFormat(e.fn + " of " + v.Type().String() + ", not a module")
+	}
+	if m == nil {
+		return reflect.ValueOf(""), nil
+	}
+	if e.fn == "kind" {
+		return reflect.ValueOf(m.ReplacementKind()), nil
+	}
+	m = m.effective()
+	switch e.fn {
+	case "path":
+		return reflect.ValueOf(m.Path), nil
+	case "version":
+		return reflect.ValueOf(m.Version), nil
+	}
+	return reflect.ValueOf(m.Key()), nil
+}
+
+// print prints v.
+func (st *formatState) print(v reflect.Value) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		st.buf.WriteString(v.String())
+		return
+	case reflect.Bool:
+		st.buf.WriteString(strconv.FormatBool(v.Bool()))
+		return
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		st.buf.WriteString(strconv.FormatInt(v.Int(), 10))
+		return
+	}
+	switch x := v.Interface().(type) {
+	case Module:
+		st.buf.WriteString(x.Key())
+	case BuildSetting:
+		st.buf.WriteString(x.Key + "=" + x.Value)
+	default:
+		if v.Kind() == reflect.Slice {
+			st.buf.WriteByte('[')
+			for i := 0; i < v.Len(); i++ {
+				if i > 0 {
+					st.buf.WriteByte(' ')
+				}
+				st.print(v.Index(i))
+			}
+			st.buf.WriteByte(']')
+		}
+	}
+}
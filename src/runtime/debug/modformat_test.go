@@ -0,0 +1,67 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	. "runtime/debug"
+	"testing"
+)
+
+var formatTests = []struct {
+	tmpl string
+	want string
+}{
+	{"plain text", "plain text"},
+	{"{{.Main.Version}}", "v1.2.3"},
+	{"{{.Path}} {{.Main}}", "example.com/cmd/hello example.com/hello@v1.2.3"},
+	{"{{range .Deps}}{{.Path}} {{version .}}\n{{end}}", "golang.org/x/text v0.3.3\nrsc.io/quote v1.0.0\n"},
+	{"{{range .Deps}}{{if .Replace}}{{.Path}} => {{key .}} ({{kind .}}){{end}}{{end}}", "rsc.io/quote => rsc.io/quote@v1.0.0 (version)"},
+	{"{{range .Deps}}{{if .Replace}}r{{else}}-{{end}}{{end}}", "-r"},
+	{"{{path .Main}} {{kind .Main}}.", "example.com/hello ."},
+	{"{{setting \"CGO_ENABLED\"}}/{{setting \"GOOS\"}}/", "1//"},
+	{"{{range .Settings -}}\n  {{.}}\n{{- end}}", "-compiler=gcCGO_ENABLED=1"},
+	{"{{.Main.Replace}}|{{if .Main.Replace}}x{{end}}", "|"},
+	{"{{if .Deps}}deps{{end}}{{if .Raw}}raw{{end}}", "deps"},
+}
+
+var formatErrorTests = []string{
+	"{{.Main.Version",
+	"{{}}",
+	"{{.NoSuchField}}",
+	"{{.Main.Replace.Path}}",
+	"{{range .Path}}{{end}}",
+	"{{if .Path}}",
+	"{{end}}",
+	"{{else}}",
+	"{{range .Deps}}{{else}}{{end}}",
+	"{{nosuchfunc .Main}}",
+	"{{version .Path}}",
+	"{{setting CGO_ENABLED}}",
+	"{{.Main .Path .GoVersion}}",
+	"{{Main}}",
+	"{{.Main..Path}}",
+	"{{.index}}",
+	`{{setting "x}}`,
+}
+
+func TestFormat(t *testing.T) {
+	bi, ok := ReadBuildInfoData(blob(testModinfo))
+	if !ok {
+		t.Fatal("ReadBuildInfo failed")
+	}
+	for _, tt := range formatTests {
+		got, err := bi.Format(tt.tmpl)
+		if err != nil {
+			t.Errorf("Format(%q): %v", tt.tmpl, err)
+		} else if got != tt.want {
+			t.Errorf("Format(%q) = %q, want %q", tt.tmpl, got, tt.want)
+		}
+	}
+	for _, tmpl := range formatErrorTests {
+		if got, err := bi.Format(tmpl); err == nil {
+			t.Errorf("Format(%q) = %q, want error", tmpl, got)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+// GitHubSnapshot returns a snapshot of the dependencies of bi in the
+// JSON format accepted by the GitHub dependency submission API,
+// attributed to the workflow job jobID and the commit sha, the full
+// hexadecimal object name. The snapshot has a single manifest, named
+// by the main package path, whose resolved dependencies are the
+// modules bi.Deps resolve to, after replacement, each identified by
+// its package URL as returned by Module.PURL. Dependencies replaced
+// by local directories are omitted, having no published identity.
+//
+// The submission API also requires the Git reference of the commit,
+// such as refs/heads/main, in a "ref" member that GitHubSnapshot
+// cannot know; callers must add it before submitting the snapshot.
+func (bi *BuildInfo) GitHubSnapshot(jobID, sha string) ([]byte, error) {
+	if jobID == "" {
+		return nil, errors.New("GitHub snapshot requires a job ID")
+	}
+	if !isHexSHA(sha) {
+		return nil, errors.New("GitHub snapshot commit " + strconv.Quote(sha) + " is not a hexadecimal object name")
+	}
+	b := []byte(`{"version":0,"sha":`)
+	b = appendJSONString(b, sha)
+	b = append(b, `,"job":{"correlator":`...)
+	b = appendJSONString(b, jobID+" "+bi.Path)
+	b = append(b, `,"id":`...)
+	b = appendJSONString(b, jobID)
+	b = append(b, `},"detector":{"name":"runtime/debug","version":`...)
+	b = appendJSONString(b, orUnknown(bi.GoVersion))
+	b = append(b, `,"url":"https://golang.org/pkg/runtime/debug/"},"scanned":`...)
+	b = appendJSONString(b, time.Now().UTC().Format(time.RFC3339))
+	b = append(b, `,"manifests":{`...)
+	b = appendJSONString(b, bi.Path)
+	b = append(b, `:{"name":`...)
+	b = appendJSONString(b, bi.Path)
+	b = append(b, `,"resolved":{`...)
+	seen := make(map[string]bool)
+	for _, dep := range bi.Deps {
+		if dep == nil || dep.IsLocalReplace() {
+			continue
+		}
+		m := dep.effective()
+		if seen[m.Path] {
+			continue
+		}
+		first := len(seen) == 0
+		seen[m.Path] = true
+		if !first {
+			b = append(b, ',')
+		}
+		b = appendJSONString(b, m.Path)
+		b = append(b, `:{"package_url":`...)
+		b = appendJSONString(b, m.PURL())
+		b = append(b, `,"scope":"runtime"}`...)
+	}
+	b = append(b, "}}}}"...)
+	return b, nil
+}
+
+// isHexSHA reports whether s is a full SHA-1 or SHA-256 object name.
+func isHexSHA(s string) bool {
+	if len(s) != 40 && len(s) != 64 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"encoding/json"
+	"reflect"
+	. "runtime/debug"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPURL(t *testing.T) {
+	for _, tt := range []struct {
+		m    Module
+		want string
+	}{
+		{Module{Path: "golang.org/x/text", Version: "v0.3.3"}, "pkg:golang/golang.org/x/text@v0.3.3"},
+		{Module{Path: "github.com/Azure/go-autorest", Version: "v14.2.0+incompatible"}, "pkg:golang/github.com/Azure/go-autorest@v14.2.0%2Bincompatible"},
+		{Module{Path: "example.com/a b", Version: "v1.0.0", Replace: &Module{Path: "../local"}}, "pkg:golang/example.com/a%20b@v1.0.0"},
+		{Module{Path: "example.com/nover"}, "pkg:golang/example.com/nover"},
+	} {
+		if got := tt.m.PURL(); got != tt.want {
+			t.Errorf("%+v.PURL() = %q, want %q", tt.m, got, tt.want)
+		}
+	}
+}
+
+func TestGitHubSnapshot(t *testing.T) {
+	const sha = "0123456789abcdef0123456789abcdef01234567"
+	info := &BuildInfo{
+		GoVersion: "go1.15",
+		Path:      "example.com/cmd/hello",
+		Main:      Module{Path: "example.com/hello", Version: "v1.2.3"},
+		Deps: []*Module{
+			{Path: "golang.org/x/text", Version: "v0.3.3", Sum: "h1:cafe="},
+			{Path: "rsc.io/quote", Version: "v1.5.2", Replace: &Module{Path: "example.com/quote", Version: "v1.5.3"}},
+			{Path: "example.com/local", Version: "v0.0.0-00010101000000-000000000000", Replace: &Module{Path: "../local"}},
+			nil,
+		},
+	}
+	data, err := info.GitHubSnapshot("build-42", sha)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The fields of the snapshot schema documented at
+	// https://docs.github.com/en/rest/dependency-graph/dependency-submission.
+	var snap struct {
+		Version  int
+		Sha      string
+		Job      struct{ Correlator, ID string }
+		Detector struct {
+			Name, Version, URL string
+		}
+		Scanned   string
+		Manifests map[string]struct {
+			Name     string
+			Resolved map[string]struct {
+				PackageURL string `json:"package_url"`
+				Scope      string
+			}
+		}
+	}
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&snap); err != nil {
+		t.Fatalf("decoding snapshot: %v\n%s", err, data)
+	}
+	if snap.Version != 0 || snap.Sha != sha || snap.Job.ID != "build-42" || snap.Job.Correlator == "" {
+		t.Errorf("snapshot header = %+v", snap)
+	}
+	if snap.Detector.Name == "" || snap.Detector.Version != "go1.15" || snap.Detector.URL == "" {
+		t.Errorf("snapshot detector = %+v", snap.Detector)
+	}
+	if _, err := time.Parse(time.RFC3339, snap.Scanned); err != nil {
+		t.Errorf("snapshot scanned time: %v", err)
+	}
+	m, ok := snap.Manifests["example.com/cmd/hello"]
+	if !ok || len(snap.Manifests) != 1 || m.Name != "example.com/cmd/hello" {
+		t.Fatalf("snapshot manifests = %+v", snap.Manifests)
+	}
+	var got []string
+	for path, r := range m.Resolved {
+		if r.Scope != "runtime" {
+			t.Errorf("resolved %s has scope %q, want runtime", path, r.Scope)
+		}
+		got = append(got, path+" "+r.PackageURL)
+	}
+	want := []string{
+		"example.com/quote pkg:golang/example.com/quote@v1.5.3",
+		"golang.org/x/text pkg:golang/golang.org/x/text@v0.3.3",
+	}
+	if sort.Strings(got); !reflect.DeepEqual(got, want) {
+		t.Errorf("resolved = %q, want %q", got, want)
+	}
+
+	for _, args := range [][2]string{{"", sha}, {"build-42", "main"}, {"build-42", strings.ToUpper(sha)}} {
+		if _, err := info.GitHubSnapshot(args[0], args[1]); err == nil {
+			t.Errorf("GitHubSnapshot(%q, %q) succeeded, want error", args[0], args[1])
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// A Drift reports a dependency built at a different version, or with a
+// different replacement, than a go.mod file requires.
+type Drift struct {
+	Path   string
+	GoMod  *Module // the requirement in go.mod, with its replacement, if any
+	Binary *Module // the dependency as listed in BuildInfo.Deps
+}
+
+// CompareWithGoMod compares the dependencies recorded in bi with the
+// requirements and replacements of gomod, the contents of a go.mod file,
+// and returns the dependencies that differ, in the order of bi.Deps.
+// It is meant to catch stale builds: binaries built before a change to
+// go.mod, or from a go.mod other than the one checked in.
+//
+// Only modules both required by gomod and listed in bi are compared.
+// A go.mod file need not list every module in the build, and minimal
+// version selection may build a dependency at a later version than the
+// main module requires; such a dependency is reported too, as the
+// go.mod file does not then describe the build exactly.
+// CompareWithGoMod returns an error if gomod cannot be parsed or
+// declares a module path other than that of bi's main module.
+func CompareWithGoMod(bi *BuildInfo, gomod []byte) ([]Drift, error) {
+	f, err := parseGoMod(gomod)
+	if err != nil {
+		return nil, err
+	}
+	if bi.Main.Path != "" && f.module != "" && f.module != bi.Main.Path {
+		return nil, errors.New("go.mod declares module " + f.module + ", but the binary's main module is " + bi.Main.Path)
+	}
+	var drifts []Drift
+	for _, dep := range bi.Deps {
+		if dep == nil {
+			continue
+		}
+		v, ok := f.require[dep.Path]
+		if !ok {
+			continue
+		}
+		want := &Module{Path: dep.Path, Version: v}
+		if r, ok := f.replace[dep.Path+"@"+v]; ok {
+			want.Replace = &Module{Path: r.Path, Version: r.Version}
+		} else if r, ok := f.replace[dep.Path]; ok {
+			want.Replace = &Module{Path: r.Path, Version: r.Version}
+		}
+		if want.Version != dep.Version || !sameReplacement(want.Replace, dep.Replace) {
+			drifts = append(drifts, Drift{Path: dep.Path, GoMod: want, Binary: dep})
+		}
+	}
+	return drifts, nil
+}
+
+// sameReplacement reports whether a and b name the same module version,
+// or are both nil.
+func sameReplacement(a, b *Module) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Path == b.Path && a.Version == b.Version
+}
+
+// A goModFile holds the parts of a go.mod file CompareWithGoMod uses.
+type goModFile struct {
+	module  string
+	require map[string]string // module path to required version
+	replace map[string]Module // path or path@version to replacement
+}
+
+// parseGoMod parses the module, require, and replace directives of the
+// go.mod file data. Other directives are checked only for their syntax.
+func parseGoMod(data []byte) (*goModFile, error) {
+	f := &goModFile{require: make(map[string]string), replace: make(map[string]Module)}
+	block := ""
+	for i, line := range strings.Split(string(data), "\n") {
+		lineno := i + 1
+		args, err := goModFields(line)
+		if err != nil {
+			return nil, goModError(lineno, err.Error())
+		}
+		if len(args) == 0 {
+			continue
+		}
+		if block != "" {
+			if len(args) == 1 && args[0] == ")" {
+				block = ""
+				continue
+			}
+			if err := f.directive(block, args); err != nil {
+				return nil, goModError(lineno, err.Error())
+			}
+			continue
+		}
+		if len(args) == 2 && args[1] == "(" {
+			block = args[0]
+			continue
+		}
+		if err := f.directive(args[0], args[1:]); err != nil {
+			return nil, goModError(lineno, err.Error())
+		}
+	}
+	if block != "" {
+		return nil, errors.New("go.mod: unterminated " + block + " block")
+	}
+	return f, nil
+}
+
+func goModError(lineno int, msg string) error {
+	return errors.New("go.mod:" + strconv.Itoa(lineno) + ": " + msg)
+}
+
+// directive records the directive verb with the arguments args.
+func (f *goModFile) directive(verb string, args []string) error {
+	switch verb {
+	case "module":
+		if len(args) != 1 {
+			return errors.New("usage: module module/path")
+		}
+		f.module = args[0]
+	case "require":
+		if len(args) != 2 {
+			return errors.New("usage: require module/path v1.2.3")
+		}
+		f.require[args[0]] = args[1]
+	case "replace":
+		arrow := 2
+		if len(args) >= 2 && args[1] == "=>" {
+			arrow = 1
+		}
+		if len(args) < arrow+2 || len(args) > arrow+3 || args[arrow] != "=>" {
+			return errors.New("usage: replace module/path [v1.2.3] => other/module v1.4\n\t or replace module/path [v1.2.3] => ../local/directory")
+		}
+		old := args[0]
+		if arrow == 2 {
+			old += "@" + args[1]
+		}
+		r := Module{Path: args[arrow+1]}
+		if len(args) == arrow+3 {
+			r.Version = args[arrow+2]
+		}
+		f.replace[old] = r
+	case "go", "exclude", "retract":
+		// Not used.
+	default:
+		return errors.New("unknown directive: " + verb)
+	}
+	return nil
+}
+
+// goModFields splits a go.mod line into its fields, dropping any
+// comment and unquoting quoted fields.
+func goModFields(line string) ([]string, error) {
+	var args []string
+	for {
+		line = strings.TrimLeft(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "//") {
+			return args, nil
+		}
+		switch line[0] {
+		case '"', '`':
+			n := -1
+			if line[0] == '"' {
+				n = quotedPrefixLen(line)
+			} else if j := strings.IndexByte(line[1:], '`'); j >= 0 {
+				n = j + 2
+			}
+			if n < 0 {
+				return nil, errors.New("unterminated quoted string")
+			}
+			s, err := strconv.Unquote(line[:n])
+			if err != nil {
+				return nil, errors.New("invalid quoted string " + line[:n])
+			}
+			args = append(args, s)
+			line = line[n:]
+		default:
+			n := strings.IndexAny(line, " \t\r")
+			if n < 0 {
+				n = len(line)
+			}
+			if j := strings.Index(line[:n], "//"); j >= 0 {
+				n = j
+			}
+			args = append(args, line[:n])
+			line = line[n:]
+		}
+	}
+}
+
+// quotedPrefixLen returns the length of the double-quoted string at the
+// start of s, or -1 if it is not terminated.
+func quotedPrefixLen(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return -1
+}
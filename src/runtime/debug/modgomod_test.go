@@ -0,0 +1,91 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	. "runtime/debug"
+	"strings"
+	"testing"
+)
+
+const testGoMod = `module example.com/hello // the main module
+
+go 1.15
+
+require (
+	example.com/same v1.0.0
+	example.com/stale v1.1.0 // indirect
+	"example.com/quoted" v1.2.0
+	example.com/replaced v1.0.0
+	example.com/unreplaced v1.0.0
+	example.com/versioned v1.0.0
+)
+
+require example.com/notbuilt v1.0.0
+
+replace example.com/replaced => example.com/fork v1.0.1
+
+replace (
+	example.com/versioned v1.0.0 => ../versioned
+	example.com/versioned v0.9.0 => ../old
+)
+
+exclude example.com/same v0.9.0
+`
+
+func TestCompareWithGoMod(t *testing.T) {
+	info := &BuildInfo{
+		Main: Module{Path: "example.com/hello", Version: "(devel)"},
+		Deps: []*Module{
+			{Path: "example.com/same", Version: "v1.0.0"},
+			{Path: "example.com/stale", Version: "v1.0.0"},
+			{Path: "example.com/quoted", Version: "v1.2.0"},
+			{Path: "example.com/replaced", Version: "v1.0.0", Replace: &Module{Path: "example.com/fork", Version: "v1.0.0"}},
+			{Path: "example.com/unreplaced", Version: "v1.0.0", Replace: &Module{Path: "../local"}},
+			{Path: "example.com/versioned", Version: "v1.0.0", Replace: &Module{Path: "../versioned"}},
+			{Path: "example.com/transitive", Version: "v1.0.0"},
+		},
+	}
+	drifts, err := CompareWithGoMod(info, []byte(testGoMod))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"example.com/stale: go.mod example.com/stale@v1.1.0, binary example.com/stale@v1.0.0",
+		"example.com/replaced: go.mod example.com/replaced@v1.0.0 => example.com/fork@v1.0.1, binary example.com/replaced@v1.0.0 => example.com/fork@v1.0.0",
+		"example.com/unreplaced: go.mod example.com/unreplaced@v1.0.0, binary example.com/unreplaced@v1.0.0 => ../local",
+	}
+	var got []string
+	for _, d := range drifts {
+		got = append(got, d.Path+": go.mod "+describe(d.GoMod)+", binary "+describe(d.Binary))
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("CompareWithGoMod:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	other := &BuildInfo{Main: Module{Path: "example.com/other"}}
+	if _, err := CompareWithGoMod(other, []byte(testGoMod)); err == nil {
+		t.Errorf("CompareWithGoMod with mismatched main module succeeded")
+	}
+	for _, bad := range []string{
+		"require (\n\texample.com/x v1.0.0\n",
+		"require example.com/x\n",
+		"replace example.com/x v1.0.0\n",
+		"bogus example.com/x\n",
+		"module \"example.com/x\n",
+	} {
+		if _, err := CompareWithGoMod(info, []byte(bad)); err == nil {
+			t.Errorf("CompareWithGoMod(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func describe(m *Module) string {
+	s := m.Key()
+	if m.Replace != nil {
+		s += " => " + m.Replace.Key()
+	}
+	return s
+}
@@ -0,0 +1,67 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"crypto/sha256"
+	"sort"
+	"strconv"
+)
+
+// Hash returns a SHA-256 digest of the modules bi was built from: the
+// path, version, and checksum of the main module, of each dependency,
+// and of each module in a dependency's replacement chain. The digest
+// does not depend on the order of bi.Deps, so two binaries built from
+// the same set of module versions have the same Hash, whichever build
+// settings and Go version they were built with. It is meant as a cheap
+// equality key; tools that need to know how two builds differ should
+// use CompareBuildInfo.
+//
+// The digest is cryptographic, rather than a checksum such as FNV, as
+// the key may be matched against binaries that are not trusted, such as
+// when checking a binary against a list of approved builds: it must not
+// be feasible to craft a set of modules with the Hash of another.
+func (bi *BuildInfo) Hash() [32]byte {
+	// Each module contributes one record, with length-prefixed fields
+	// so that no two different module sets encode the same; the records
+	// are sorted to make the digest independent of the order of Deps.
+	records := make([]string, 0, 1+len(bi.Deps))
+	records = append(records, hashRecord(nil, "mod", &bi.Main))
+	for _, dep := range bi.Deps {
+		if dep != nil {
+			records = append(records, hashRecord(nil, "dep", dep))
+		}
+	}
+	sort.Strings(records[1:])
+
+	h := sha256.New()
+	for _, r := range records {
+		h.Write([]byte(r))
+	}
+	var sum [32]byte
+	h.Sum(sum[:0])
+	return sum
+}
+
+// hashRecord appends to b the record of m and its replacements hashed
+// by Hash, under the given kind, and returns it as a string.
+func hashRecord(b []byte, kind string, m *Module) string {
+	b = appendHashField(b, kind)
+	for r := m; r != nil; r = r.Replace {
+		if r != m {
+			b = appendHashField(b, "=>")
+		}
+		b = appendHashField(b, r.Path)
+		b = appendHashField(b, r.Version)
+		b = appendHashField(b, r.Sum)
+	}
+	return string(append(b, '\n'))
+}
+
+func appendHashField(b []byte, s string) []byte {
+	b = strconv.AppendInt(b, int64(len(s)), 10)
+	b = append(b, ':')
+	return append(b, s...)
+}
@@ -0,0 +1,46 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	. "runtime/debug"
+	"testing"
+)
+
+func TestHash(t *testing.T) {
+	info, ok := ReadBuildInfoData(blob(testModinfo))
+	if !ok {
+		t.Fatal("ReadBuildInfoData failed")
+	}
+	h := info.Hash()
+
+	same := info.Clone()
+	same.Deps[0], same.Deps[1] = same.Deps[1], same.Deps[0]
+	same.Settings = nil
+	same.GoVersion = "go1.99"
+	if same.Hash() != h {
+		t.Errorf("Hash changed with dependency order, settings, or Go version")
+	}
+
+	for _, tt := range []struct {
+		name string
+		edit func(bi *BuildInfo)
+	}{
+		{"main version", func(bi *BuildInfo) { bi.Main.Version = "v1.2.4" }},
+		{"dep version", func(bi *BuildInfo) { bi.Deps[0].Version = "v0.3.4" }},
+		{"dep sum", func(bi *BuildInfo) { bi.Deps[0].Sum = "" }},
+		{"replacement", func(bi *BuildInfo) { bi.Deps[1].Replace.Version = "v1.0.1" }},
+		{"unreplaced", func(bi *BuildInfo) { bi.Deps[1].Replace = nil }},
+		{"extra dep", func(bi *BuildInfo) { bi.Deps = append(bi.Deps, &Module{Path: "example.com/x", Version: "v1.0.0"}) }},
+		{"dep as main", func(bi *BuildInfo) { bi.Main, *bi.Deps[0] = *bi.Deps[0], bi.Main }},
+		{"field boundary", func(bi *BuildInfo) { bi.Deps[0].Path, bi.Deps[0].Version = "golang.org/x/tex", "tv0.3.3" }},
+	} {
+		bi := info.Clone()
+		tt.edit(bi)
+		if bi.Hash() == h {
+			t.Errorf("%s: Hash did not change", tt.name)
+		}
+	}
+}
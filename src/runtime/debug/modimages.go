@@ -0,0 +1,192 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AllBuildInfo returns the build information of each Go image loaded
+// in the process that records it: first the running binary, as
+// returned by ReadBuildInfo, then, in the order they are mapped, the
+// shared objects loaded by the process, such as Go shared libraries
+// used with -linkshared, plugins, and libraries built with
+// -buildmode=c-shared. Shared objects are found only on Linux and
+// Android, by reading /proc/self/maps; elsewhere AllBuildInfo returns
+// at most the running binary's build information. The build
+// information of a shared object is read from its file, as by
+// ReadBuildInfoFromFile.
+func AllBuildInfo() []*BuildInfo {
+	var infos []*BuildInfo
+	if info, ok := ReadBuildInfo(); ok {
+		infos = append(infos, info)
+	}
+	if runtime.GOOS != "linux" && runtime.GOOS != "android" {
+		return infos
+	}
+	maps, err := ioutil.ReadFile("/proc/self/maps")
+	if err != nil {
+		return infos
+	}
+	exe, _ := os.Executable()
+	if p, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = p
+	}
+	for _, file := range mappedImages(string(maps)) {
+		if file == exe {
+			continue
+		}
+		if info, err := ReadBuildInfoFromFile(file); err == nil {
+			infos = append(infos, info)
+		}
+	}
+	return infos
+}
+
+// A BuildInfoEvent reports the loading or unloading of a Go image
+// carrying build information, as seen by WatchBuildInfo.
+type BuildInfoEvent struct {
+	File   string     // the file of the image
+	Loaded bool       // whether the image was loaded, rather than unloaded
+	Info   *BuildInfo // the build information of the image
+}
+
+// buildInfoWatchInterval is how often WatchBuildInfo looks for
+// images loaded or unloaded.
+const buildInfoWatchInterval = time.Second
+
+// WatchBuildInfo returns a channel of events reporting the Go images
+// loaded in the process, as AllBuildInfo lists them: first the running
+// binary and the shared objects loaded already, then the shared
+// objects loaded and unloaded afterward, such as plugins opened with
+// plugin.Open, each with its build information. Images without build
+// information, such as C libraries, are not reported. The channel is
+// closed once ctx is done.
+//
+// The images are found, as by AllBuildInfo, only on Linux and Android,
+// by reading /proc/self/maps, which WatchBuildInfo rereads every
+// second, so the events of an image follow its loading or unloading
+// by up to a second, and an image loaded and unloaded in between is
+// not seen. Elsewhere the only event is that of the running binary.
+// The events are sent without buffering, and the next look for images
+// waits until the receiver has taken them.
+func WatchBuildInfo(ctx context.Context) <-chan BuildInfoEvent {
+	readMaps := func() (string, bool) {
+		if runtime.GOOS != "linux" && runtime.GOOS != "android" {
+			return "", false
+		}
+		maps, err := ioutil.ReadFile("/proc/self/maps")
+		return string(maps), err == nil
+	}
+	return watchBuildInfo(ctx, readMaps, buildInfoWatchInterval)
+}
+
+// watchBuildInfo implements WatchBuildInfo, reading the memory map of
+// the process with readMaps every interval.
+func watchBuildInfo(ctx context.Context, readMaps func() (string, bool), interval time.Duration) <-chan BuildInfoEvent {
+	ch := make(chan BuildInfoEvent)
+	go func() {
+		defer close(ch)
+		send := func(e BuildInfoEvent) bool {
+			select {
+			case ch <- e:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		exe, _ := os.Executable()
+		if p, err := filepath.EvalSymlinks(exe); err == nil {
+			exe = p
+		}
+		if info, ok := ReadBuildInfo(); ok {
+			if !send(BuildInfoEvent{File: exe, Loaded: true, Info: info}) {
+				return
+			}
+		}
+
+		// known holds the images mapped at the last look, with their
+		// build information, or nil for those without.
+		known := make(map[string]*BuildInfo)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if maps, ok := readMaps(); ok {
+				// A file replaced on disk, as a plugin may be when
+				// rebuilt, stays mapped as a deleted file.
+				maps = strings.Replace(maps, " (deleted)\n", "\n", -1)
+				mapped := make(map[string]bool)
+				for _, file := range mappedImages(maps) {
+					if file == exe {
+						continue
+					}
+					mapped[file] = true
+					if _, ok := known[file]; ok {
+						continue
+					}
+					info, err := ReadBuildInfoFromFile(file)
+					if err != nil {
+						info = nil
+					}
+					known[file] = info
+					if info != nil && !send(BuildInfoEvent{File: file, Loaded: true, Info: info}) {
+						return
+					}
+				}
+				var gone []string
+				for file := range known {
+					if !mapped[file] {
+						gone = append(gone, file)
+					}
+				}
+				sort.Strings(gone)
+				for _, file := range gone {
+					info := known[file]
+					delete(known, file)
+					if info != nil && !send(BuildInfoEvent{File: file, Info: info}) {
+						return
+					}
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// mappedImages returns the files that have executable mappings in the
+// Linux memory map listing maps, in the format of /proc/self/maps,
+// once each in order of first appearance.
+func mappedImages(maps string) []string {
+	var files []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(maps, "\n") {
+		// address perms offset dev inode pathname
+		f := strings.Fields(line)
+		if len(f) < 6 || len(f[1]) < 3 || f[1][2] != 'x' || !strings.HasPrefix(f[5], "/") {
+			continue
+		}
+		// The path name is the rest of the line, and may contain spaces.
+		i := strings.Index(line, f[5])
+		file := line[i:]
+		if strings.HasSuffix(file, " (deleted)") || seen[file] {
+			continue
+		}
+		seen[file] = true
+		files = append(files, file)
+	}
+	return files
+}
@@ -0,0 +1,121 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	. "runtime/debug"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMappedImages(t *testing.T) {
+	const maps = `5589f0250000-5589f0252000 r--p 00000000 fe:00 301775                     /usr/bin/prog
+5589f0252000-5589f0254000 r-xp 00002000 fe:00 301775                     /usr/bin/prog
+5589f0254000-5589f0256000 rw-p 00004000 fe:00 301775                     /usr/bin/prog
+7f1c2a000000-7f1c2a100000 r-xp 00000000 fe:00 412345                     /usr/lib/libgo plugin.so
+7f1c2a100000-7f1c2a200000 r--p 00000000 fe:00 412346                     /var/db/data.bin
+7f1c2a200000-7f1c2a300000 r-xp 00000000 fe:00 412347                     /tmp/gone.so (deleted)
+7f1c2a300000-7f1c2a400000 rw-p 00000000 00:00 0 
+7f1c2a400000-7f1c2a500000 r-xp 00100000 fe:00 412345                     /usr/lib/libgo plugin.so
+7ffd3c5f1000-7ffd3c5f3000 r-xp 00000000 00:00 0                          [vdso]
+`
+	got := MappedImages(maps)
+	want := []string{"/usr/bin/prog", "/usr/lib/libgo plugin.so"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MappedImages = %q, want %q", got, want)
+	}
+}
+
+func TestAllBuildInfo(t *testing.T) {
+	infos := AllBuildInfo()
+	main, ok := ReadBuildInfo()
+	if !ok {
+		if len(infos) != 0 {
+			t.Errorf("AllBuildInfo returned %d entries for a binary without build information", len(infos))
+		}
+		return
+	}
+	if len(infos) == 0 || !reflect.DeepEqual(infos[0], main) {
+		t.Errorf("AllBuildInfo()[0] is not the result of ReadBuildInfo")
+	}
+}
+
+func TestWatchBuildInfo(t *testing.T) {
+	dir, err := ioutil.TempDir("", "modwatch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var files []string
+	for _, name := range []string{"a", "b"} {
+		if err := os.Mkdir(filepath.Join(dir, name), 0777); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, writeExe(t, filepath.Join(dir, name), testModinfo))
+	}
+	lib := filepath.Join(dir, "libc.so")
+	if err := ioutil.WriteFile(lib, []byte("\x7fELF no build information"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	line := func(file string, deleted bool) string {
+		l := "7f1c2a000000-7f1c2a100000 r-xp 00000000 fe:00 412345                     " + file
+		if deleted {
+			l += " (deleted)"
+		}
+		return l + "\n"
+	}
+	// The maps shown at each look; the last is shown from then on.
+	steps := []string{
+		line(files[0], false) + line(lib, false),
+		line(files[0], false) + line(lib, false) + line(files[1], false),
+		line(lib, false) + line(files[1], true),
+	}
+	var mu sync.Mutex
+	readMaps := func() (string, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		maps := steps[0]
+		if len(steps) > 1 {
+			steps = steps[1:]
+		}
+		return maps, true
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := WatchBuildInfoWith(ctx, readMaps, time.Millisecond)
+	var got []string
+	for len(got) < 3 {
+		var e BuildInfoEvent
+		select {
+		case e = <-ch:
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out after events %q", got)
+		}
+		if !strings.HasPrefix(e.File, dir) {
+			continue // the running binary
+		}
+		if e.Info == nil || e.Info.Main.Path != "example.com/hello" {
+			t.Errorf("event for %s has build information %v", e.File, e.Info)
+		}
+		got = append(got, strings.TrimPrefix(e.File, dir)+" "+map[bool]string{true: "loaded", false: "unloaded"}[e.Loaded])
+	}
+	want := []string{"/a/exe loaded", "/b/exe loaded", "/a/exe unloaded"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("events %q, want %q", got, want)
+	}
+	cancel()
+	for e := range ch {
+		if strings.HasPrefix(e.File, dir) {
+			t.Errorf("event %+v after the last change", e)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// An Inventory aggregates the build information of many binaries, such
+// as those of the services deployed in a fleet, and answers questions
+// about the modules they were built from. Each binary contributes the
+// modules it actually uses: its main module and the module each of its
+// dependencies resolves to, which is the dependency's replacement, if it
+// has one, and the replacement's replacement, if that is replaced, and
+// so on. A dependency replaced by a local directory, with no version, is
+// recorded under its replacement's path with no version.
+//
+// The zero value is an empty Inventory ready to use.
+// An Inventory must not be used by multiple goroutines simultaneously.
+type Inventory struct {
+	uses map[string][]inventoryUse // by module path
+}
+
+// An inventoryUse records that a binary uses a version of a module.
+type inventoryUse struct {
+	bi      *BuildInfo
+	version string
+}
+
+// Add adds the build information of a binary to inv. The Inventory
+// keeps bi and returns it from BinariesUsing, so bi must not be
+// modified afterward.
+func (inv *Inventory) Add(bi *BuildInfo) {
+	if inv.uses == nil {
+		inv.uses = make(map[string][]inventoryUse)
+	}
+	add := func(m *Module) {
+		m = m.effective()
+		if m.Path != "" {
+			inv.uses[m.Path] = append(inv.uses[m.Path], inventoryUse{bi, m.Version})
+		}
+	}
+	add(&bi.Main)
+	for _, dep := range bi.Deps {
+		if dep != nil {
+			add(dep)
+		}
+	}
+}
+
+// VersionsOf returns the distinct versions of the module with the given
+// path used by the binaries in inv, in increasing semantic version
+// order. Versions that are not semantic versions, such as "(devel)" or
+// the empty version of a local directory, sort first, in string order.
+func (inv *Inventory) VersionsOf(path string) []string {
+	seen := make(map[string]bool)
+	var versions []string
+	for _, u := range inv.uses[path] {
+		if !seen[u.version] {
+			seen[u.version] = true
+			versions = append(versions, u.version)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		if c := compareSemver(versions[i], versions[j]); c != 0 {
+			return c < 0
+		}
+		return versions[i] < versions[j]
+	})
+	return versions
+}
+
+// BinariesUsing returns the build information of the binaries in inv
+// that use a version of the module with the given path in versionRange,
+// in the order in which they were added. The range is a space-separated
+// list of comparisons, each an operator, one of <, <=, >, >=, or =,
+// followed by a semantic version, and a version is in the range if it
+// satisfies all of them: ">=v1.2.0 <v1.5.0" is the range of versions
+// from v1.2.0 up to but not including v1.5.0. An empty range includes
+// every version, even those that are not semantic versions; otherwise
+// such versions are in no range. BinariesUsing returns an error if
+// versionRange is malformed.
+func (inv *Inventory) BinariesUsing(path, versionRange string) ([]*BuildInfo, error) {
+	match, err := parseVersionRange(versionRange)
+	if err != nil {
+		return nil, err
+	}
+	var list []*BuildInfo
+	var last *BuildInfo
+	for _, u := range inv.uses[path] {
+		// The uses of a binary are consecutive, as Add records them.
+		if u.bi != last && match(u.version) {
+			list = append(list, u.bi)
+			last = u.bi
+		}
+	}
+	return list, nil
+}
+
+// parseVersionRange parses the version range syntax of BinariesUsing
+// and returns a function reporting whether a version is in the range.
+func parseVersionRange(r string) (func(v string) bool, error) {
+	type comparison struct {
+		op string
+		v  string
+	}
+	var list []comparison
+	for _, f := range strings.Fields(r) {
+		i := strings.IndexByte(f, 'v')
+		if i < 0 {
+			i = len(f)
+		}
+		op, v := f[:i], f[i:]
+		switch op {
+		case "<", "<=", ">", ">=", "=":
+		default:
+			return nil, errors.New("invalid version range " + strconv.Quote(r) + ": bad operator in " + strconv.Quote(f))
+		}
+		if !isValidSemver(v) {
+			return nil, errors.New("invalid version range " + strconv.Quote(r) + ": bad version in " + strconv.Quote(f))
+		}
+		list = append(list, comparison{op, v})
+	}
+	if len(list) == 0 {
+		return func(string) bool { return true }, nil
+	}
+	return func(v string) bool {
+		if !isValidSemver(v) {
+			return false
+		}
+		for _, c := range list {
+			cmp := compareSemver(v, c.v)
+			var ok bool
+			switch c.op {
+			case "<":
+				ok = cmp < 0
+			case "<=":
+				ok = cmp <= 0
+			case ">":
+				ok = cmp > 0
+			case ">=":
+				ok = cmp >= 0
+			case "=":
+				ok = cmp == 0
+			}
+			if !ok {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
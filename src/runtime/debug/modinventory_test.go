@@ -0,0 +1,90 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"reflect"
+	. "runtime/debug"
+	"testing"
+)
+
+func TestInventory(t *testing.T) {
+	a := &BuildInfo{
+		Path: "example.com/a",
+		Main: Module{Path: "example.com/a", Version: "(devel)"},
+		Deps: []*Module{
+			{Path: "golang.org/x/text", Version: "v0.3.3"},
+			{Path: "rsc.io/quote", Version: "v1.5.2"},
+		},
+	}
+	b := &BuildInfo{
+		Path: "example.com/b",
+		Main: Module{Path: "example.com/b", Version: "v1.0.0"},
+		Deps: []*Module{
+			{Path: "golang.org/x/text", Version: "v0.3.0"},
+			{Path: "rsc.io/quote", Version: "v1.5.2", Replace: &Module{Path: "rsc.io/quote", Version: "v1.0.0"}},
+			nil,
+		},
+	}
+	c := &BuildInfo{
+		Path: "example.com/c",
+		Main: Module{Path: "example.com/c", Version: "v0.1.0"},
+		Deps: []*Module{
+			{Path: "golang.org/x/text", Version: "v0.3.3"},
+			{Path: "example.com/local", Version: "v0.0.0-00010101000000-000000000000", Replace: &Module{Path: "../local"}},
+			{Path: "rsc.io/quote", Version: "v1.5.2", Replace: &Module{Path: "github.com/fork/quote", Version: "v1.5.3"}},
+		},
+	}
+	var inv Inventory
+	for _, bi := range []*BuildInfo{a, b, c} {
+		inv.Add(bi)
+	}
+
+	for _, tt := range []struct {
+		path string
+		want []string
+	}{
+		{"golang.org/x/text", []string{"v0.3.0", "v0.3.3"}},
+		{"rsc.io/quote", []string{"v1.0.0", "v1.5.2"}},
+		{"github.com/fork/quote", []string{"v1.5.3"}},
+		{"example.com/a", []string{"(devel)"}},
+		{"../local", []string{""}},
+		{"example.com/local", nil},
+	} {
+		if got := inv.VersionsOf(tt.path); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("VersionsOf(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	for _, tt := range []struct {
+		path, versionRange string
+		want               []*BuildInfo
+	}{
+		{"golang.org/x/text", "", []*BuildInfo{a, b, c}},
+		{"golang.org/x/text", ">=v0.3.3", []*BuildInfo{a, c}},
+		{"golang.org/x/text", "<v0.3.3", []*BuildInfo{b}},
+		{"golang.org/x/text", ">v0.3.0 <=v0.3.3", []*BuildInfo{a, c}},
+		{"golang.org/x/text", "=v0.3.1", nil},
+		{"rsc.io/quote", "<v1.5.0", []*BuildInfo{b}},
+		{"example.com/a", "", []*BuildInfo{a}},
+		{"example.com/a", ">=v0.0.0", nil},
+		{"example.com/none", "", nil},
+	} {
+		got, err := inv.BinariesUsing(tt.path, tt.versionRange)
+		if err != nil {
+			t.Errorf("BinariesUsing(%q, %q): %v", tt.path, tt.versionRange, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("BinariesUsing(%q, %q) = %d binaries, want %d", tt.path, tt.versionRange, len(got), len(tt.want))
+		}
+	}
+
+	for _, bad := range []string{"v1.0.0", "~v1.0.0", ">=1.0.0", "<", ">=v1.x"} {
+		if _, err := inv.BinariesUsing("golang.org/x/text", bad); err == nil {
+			t.Errorf("BinariesUsing with range %q succeeded, want error", bad)
+		}
+	}
+}
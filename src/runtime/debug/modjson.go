@@ -0,0 +1,738 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// This file encodes and decodes BuildInfo as JSON by hand, rather than
+// with encoding/json, to keep runtime/debug low in the package layering
+// checked by go/build's deps_test, so that testing and the other
+// packages importing runtime/debug do not depend on encoding/json.
+
+// MarshalJSON implements json.Marshaler.
+// The object has the members GoVersion, Path, Main, Deps, Settings, and
+// Raw, mirroring the fields of BuildInfo. Raw is omitted if empty.
+func (bi *BuildInfo) MarshalJSON() ([]byte, error) {
+	return bi.appendJSON(nil), nil
+}
+
+// MarshalJSONIndent is like MarshalJSON but applies indentation
+// to format the output, as json.MarshalIndent does.
+// Each JSON element begins on a new line beginning with prefix
+// followed by one or more copies of indent according to the nesting.
+func (bi *BuildInfo) MarshalJSONIndent(prefix, indent string) ([]byte, error) {
+	return indentJSON(bi.appendJSON(nil), prefix, indent), nil
+}
+
+// MarshalJSON implements json.Marshaler. The object has the members
+// Path, Version, Sum, Replace, Extra, Origin, and License, mirroring
+// the fields of Module. Sum, Extra, and License are omitted when
+// empty and Replace and Origin when nil. Origin is an object with the
+// members VCS, URL, Ref, and Hash, of which Ref and Hash are omitted
+// when empty.
+func (m Module) MarshalJSON() ([]byte, error) {
+	return m.appendJSON(nil), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, decoding the form
+// written by MarshalJSON. As with encoding/json, member names match
+// field names case-insensitively and unknown members are ignored.
+// The JSON null value leaves bi unchanged.
+func (bi *BuildInfo) UnmarshalJSON(data []byte) error {
+	v, err := decodeJSON(string(data))
+	if err != nil || v == nil {
+		return err
+	}
+	info, err := buildInfoFromJSON(v)
+	if err != nil {
+		return err
+	}
+	*bi = *info
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, decoding the form
+// written by MarshalJSON. The JSON null value leaves m unchanged.
+func (m *Module) UnmarshalJSON(data []byte) error {
+	v, err := decodeJSON(string(data))
+	if err != nil || v == nil {
+		return err
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return errors.New("JSON module is not an object")
+	}
+	var mod Module
+	if err := jsonModule(&mod, obj); err != nil {
+		return err
+	}
+	*m = mod
+	return nil
+}
+
+func (bi *BuildInfo) appendJSON(b []byte) []byte {
+	b = append(b, `{"GoVersion":`...)
+	b = appendJSONString(b, bi.GoVersion)
+	b = append(b, `,"Path":`...)
+	b = appendJSONString(b, bi.Path)
+	b = append(b, `,"Main":`...)
+	b = bi.Main.appendJSON(b)
+	b = append(b, `,"Deps":[`...)
+	first := true
+	for _, dep := range bi.Deps {
+		if dep == nil {
+			continue
+		}
+		if !first {
+			b = append(b, ',')
+		}
+		first = false
+		b = dep.appendJSON(b)
+	}
+	b = append(b, `],"Settings":[`...)
+	for i, s := range bi.Settings {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = append(b, `{"Key":`...)
+		b = appendJSONString(b, s.Key)
+		b = append(b, `,"Value":`...)
+		b = appendJSONString(b, s.Value)
+		b = append(b, '}')
+	}
+	b = append(b, ']')
+	if len(bi.Raw) > 0 {
+		b = append(b, `,"Raw":[`...)
+		for i, line := range bi.Raw {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = appendJSONString(b, line)
+		}
+		b = append(b, ']')
+	}
+	b = append(b, '}')
+	return b
+}
+
+func (m *Module) appendJSON(b []byte) []byte {
+	b = append(b, `{"Path":`...)
+	b = appendJSONString(b, m.Path)
+	b = append(b, `,"Version":`...)
+	b = appendJSONString(b, m.Version)
+	if m.Sum != "" {
+		b = append(b, `,"Sum":`...)
+		b = appendJSONString(b, m.Sum)
+	}
+	if m.Replace != nil {
+		b = append(b, `,"Replace":`...)
+		b = m.Replace.appendJSON(b)
+	}
+	if len(m.Extra) > 0 {
+		b = append(b, `,"Extra":[`...)
+		for i, x := range m.Extra {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = appendJSONString(b, x)
+		}
+		b = append(b, ']')
+	}
+	if o := m.Origin; o != nil {
+		b = append(b, `,"Origin":{"VCS":`...)
+		b = appendJSONString(b, o.VCS)
+		b = append(b, `,"URL":`...)
+		b = appendJSONString(b, o.URL)
+		if o.Ref != "" {
+			b = append(b, `,"Ref":`...)
+			b = appendJSONString(b, o.Ref)
+		}
+		if o.Hash != "" {
+			b = append(b, `,"Hash":`...)
+			b = appendJSONString(b, o.Hash)
+		}
+		b = append(b, '}')
+	}
+	if m.License != "" {
+		b = append(b, `,"License":`...)
+		b = appendJSONString(b, m.License)
+	}
+	b = append(b, '}')
+	return b
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString appends the JSON string literal for s to b,
+// escaping exactly as encoding/json does by default.
+func appendJSONString(b []byte, s string) []byte {
+	b = append(b, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+			b = append(b, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				b = append(b, '\\', c)
+			case '\n':
+				b = append(b, '\\', 'n')
+			case '\r':
+				b = append(b, '\\', 'r')
+			case '\t':
+				b = append(b, '\\', 't')
+			default:
+				b = append(b, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b = append(b, s[start:i]...)
+			b = append(b, `\ufffd`...)
+			i += size
+			start = i
+			continue
+		}
+		// U+2028 and U+2029 are valid JSON but not valid JavaScript.
+		if r == '\u2028' || r == '\u2029' {
+			b = append(b, s[start:i]...)
+			b = append(b, '\\', 'u', '2', '0', '2', hexDigits[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	b = append(b, s[start:]...)
+	b = append(b, '"')
+	return b
+}
+
+// indentJSON returns an indented form of the compact JSON src,
+// following the conventions of json.Indent.
+func indentJSON(src []byte, prefix, indent string) []byte {
+	var dst bytes.Buffer
+	newline := func(depth int) {
+		dst.WriteByte('\n')
+		dst.WriteString(prefix)
+		for i := 0; i < depth; i++ {
+			dst.WriteString(indent)
+		}
+	}
+	depth := 0
+	inString, escaped := false, false
+	for i, c := range src {
+		if inString {
+			dst.WriteByte(c)
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+			dst.WriteByte(c)
+		case '{', '[':
+			dst.WriteByte(c)
+			if i+1 < len(src) && (src[i+1] == '}' || src[i+1] == ']') {
+				// Leave empty objects and arrays on one line.
+				continue
+			}
+			depth++
+			newline(depth)
+		case '}', ']':
+			if i > 0 && src[i-1] != '{' && src[i-1] != '[' {
+				depth--
+				newline(depth)
+			}
+			dst.WriteByte(c)
+		case ',':
+			dst.WriteByte(c)
+			newline(depth)
+		case ':':
+			dst.WriteByte(c)
+			dst.WriteByte(' ')
+		default:
+			dst.WriteByte(c)
+		}
+	}
+	return dst.Bytes()
+}
+
+// parseBuildInfoJSON decodes build information from the JSON form
+// written by BuildInfo.MarshalJSON. As with encoding/json, member names
+// match field names case-insensitively, null leaves a field unset,
+// and unknown members are ignored.
+func parseBuildInfoJSON(data string) (*BuildInfo, error) {
+	v, err := decodeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return buildInfoFromJSON(v)
+}
+
+// decodeJSON decodes data, which must hold a single JSON value.
+func decodeJSON(data string) (interface{}, error) {
+	d := &jsonDecoder{data: data}
+	v, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+	if d.skipSpace(); d.pos < len(d.data) {
+		return nil, d.syntaxError()
+	}
+	return v, nil
+}
+
+// ParseGoVersionJSON decodes the build information of executables as
+// printed in JSON by "go version -m -json", either a JSON array of
+// objects or a sequence of objects, one per executable. Each object
+// has the members GoVersion, Path, Main, Deps, and Settings, decoded
+// as by the JSON form of BuildInfo.
+func ParseGoVersionJSON(data []byte) ([]*BuildInfo, error) {
+	d := &jsonDecoder{data: string(data)}
+	var infos []*BuildInfo
+	for d.skipSpace(); d.pos < len(d.data); d.skipSpace() {
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		list, ok := v.([]interface{})
+		if !ok {
+			list = []interface{}{v}
+		}
+		for _, v := range list {
+			bi, err := buildInfoFromJSON(v)
+			if err != nil {
+				return nil, err
+			}
+			infos = append(infos, bi)
+		}
+	}
+	return infos, nil
+}
+
+// buildInfoFromJSON converts a decoded JSON object to a BuildInfo.
+func buildInfoFromJSON(v interface{}) (*BuildInfo, error) {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("JSON build information is not an object")
+	}
+	var err error
+	bi := new(BuildInfo)
+	for key, v := range obj {
+		switch {
+		case strings.EqualFold(key, "GoVersion"):
+			err = jsonString(&bi.GoVersion, key, v)
+		case strings.EqualFold(key, "Path"):
+			err = jsonString(&bi.Path, key, v)
+		case strings.EqualFold(key, "Main"):
+			if o, ok := v.(map[string]interface{}); ok {
+				err = jsonModule(&bi.Main, o)
+			} else if v != nil {
+				err = errJSONType(key)
+			}
+		case strings.EqualFold(key, "Deps"):
+			err = jsonDeps(bi, key, v)
+		case strings.EqualFold(key, "Settings"):
+			err = jsonSettings(bi, key, v)
+		case strings.EqualFold(key, "Raw"):
+			err = jsonStrings(&bi.Raw, key, v)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return bi, nil
+}
+
+func errJSONType(key string) error {
+	return errors.New("JSON build information: unexpected type for " + key)
+}
+
+func jsonString(dst *string, key string, v interface{}) error {
+	switch v := v.(type) {
+	case string:
+		*dst = v
+	case nil:
+	default:
+		return errJSONType(key)
+	}
+	return nil
+}
+
+// jsonStrings stores in dst the JSON array of strings v.
+func jsonStrings(dst *[]string, key string, v interface{}) error {
+	list, ok := v.([]interface{})
+	if !ok && v != nil {
+		return errJSONType(key)
+	}
+	*dst = nil
+	for _, x := range list {
+		s, ok := x.(string)
+		if !ok {
+			return errJSONType(key)
+		}
+		*dst = append(*dst, s)
+	}
+	return nil
+}
+
+func jsonModule(m *Module, obj map[string]interface{}) error {
+	for key, v := range obj {
+		var err error
+		switch {
+		case strings.EqualFold(key, "Path"):
+			err = jsonString(&m.Path, key, v)
+		case strings.EqualFold(key, "Version"):
+			err = jsonString(&m.Version, key, v)
+		case strings.EqualFold(key, "Sum"):
+			err = jsonString(&m.Sum, key, v)
+		case strings.EqualFold(key, "Replace"):
+			if o, ok := v.(map[string]interface{}); ok {
+				m.Replace = new(Module)
+				err = jsonModule(m.Replace, o)
+			} else if v != nil {
+				err = errJSONType(key)
+			}
+		case strings.EqualFold(key, "Extra"):
+			err = jsonStrings(&m.Extra, key, v)
+		case strings.EqualFold(key, "License"):
+			err = jsonString(&m.License, key, v)
+		case strings.EqualFold(key, "Origin"):
+			if o, ok := v.(map[string]interface{}); ok {
+				m.Origin = new(Origin)
+				err = jsonOrigin(m.Origin, o)
+			} else if v != nil {
+				err = errJSONType(key)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func jsonOrigin(o *Origin, obj map[string]interface{}) error {
+	for key, v := range obj {
+		var err error
+		switch {
+		case strings.EqualFold(key, "VCS"):
+			err = jsonString(&o.VCS, key, v)
+		case strings.EqualFold(key, "URL"):
+			err = jsonString(&o.URL, key, v)
+		case strings.EqualFold(key, "Ref"):
+			err = jsonString(&o.Ref, key, v)
+		case strings.EqualFold(key, "Hash"):
+			err = jsonString(&o.Hash, key, v)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func jsonDeps(bi *BuildInfo, key string, v interface{}) error {
+	list, ok := v.([]interface{})
+	if !ok && v != nil {
+		return errJSONType(key)
+	}
+	bi.Deps = nil
+	for _, x := range list {
+		o, ok := x.(map[string]interface{})
+		if !ok {
+			return errJSONType(key)
+		}
+		dep := new(Module)
+		if err := jsonModule(dep, o); err != nil {
+			return err
+		}
+		bi.Deps = append(bi.Deps, dep)
+	}
+	return nil
+}
+
+func jsonSettings(bi *BuildInfo, key string, v interface{}) error {
+	list, ok := v.([]interface{})
+	if !ok && v != nil {
+		return errJSONType(key)
+	}
+	bi.Settings = nil
+	for _, x := range list {
+		o, ok := x.(map[string]interface{})
+		if !ok {
+			return errJSONType(key)
+		}
+		var s BuildSetting
+		for k, v := range o {
+			var err error
+			switch {
+			case strings.EqualFold(k, "Key"):
+				err = jsonString(&s.Key, k, v)
+			case strings.EqualFold(k, "Value"):
+				err = jsonString(&s.Value, k, v)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		bi.Settings = append(bi.Settings, s)
+	}
+	return nil
+}
+
+// A jsonDecoder decodes a JSON value into the types used by
+// encoding/json for an interface{}, except that numbers are returned
+// undecoded, as a jsonNumber, since BuildInfo has no numeric fields.
+type jsonDecoder struct {
+	data  string
+	pos   int
+	depth int // nesting of the objects and arrays being decoded
+}
+
+// maxJSONDepth is the deepest nesting of objects and arrays that
+// jsonDecoder accepts, as encoding/json does, so that crafted input
+// cannot exhaust the stack.
+const maxJSONDepth = 10000
+
+// A jsonNumber is the text of a JSON number.
+type jsonNumber string
+
+func (d *jsonDecoder) syntaxError() error {
+	return errors.New("invalid JSON at offset " + strconv.Itoa(d.pos))
+}
+
+func (d *jsonDecoder) skipSpace() {
+	for d.pos < len(d.data) {
+		switch d.data[d.pos] {
+		case ' ', '\t', '\n', '\r':
+			d.pos++
+		default:
+			return
+		}
+	}
+}
+
+// value decodes the JSON value at d.pos.
+func (d *jsonDecoder) value() (interface{}, error) {
+	d.skipSpace()
+	if d.pos >= len(d.data) {
+		return nil, d.syntaxError()
+	}
+	switch c := d.data[d.pos]; {
+	case c == '{' || c == '[':
+		if d.depth++; d.depth > maxJSONDepth {
+			return nil, d.syntaxError()
+		}
+		v, err := d.container(c)
+		d.depth--
+		return v, err
+	case c == '"':
+		return d.string()
+	case c == '-' || '0' <= c && c <= '9':
+		return d.number()
+	}
+	for _, lit := range []struct {
+		s string
+		v interface{}
+	}{{"true", true}, {"false", false}, {"null", nil}} {
+		if strings.HasPrefix(d.data[d.pos:], lit.s) {
+			d.pos += len(lit.s)
+			return lit.v, nil
+		}
+	}
+	return nil, d.syntaxError()
+}
+
+// container decodes the JSON object or array at d.pos, which begins
+// with c.
+func (d *jsonDecoder) container(c byte) (interface{}, error) {
+	switch c {
+	case '{':
+		d.pos++
+		obj := make(map[string]interface{})
+		if d.skipSpace(); d.pos < len(d.data) && d.data[d.pos] == '}' {
+			d.pos++
+			return obj, nil
+		}
+		for {
+			if d.skipSpace(); d.pos >= len(d.data) || d.data[d.pos] != '"' {
+				return nil, d.syntaxError()
+			}
+			key, err := d.string()
+			if err != nil {
+				return nil, err
+			}
+			if d.skipSpace(); d.pos >= len(d.data) || d.data[d.pos] != ':' {
+				return nil, d.syntaxError()
+			}
+			d.pos++
+			if obj[key], err = d.value(); err != nil {
+				return nil, err
+			}
+			if more, err := d.next('}'); err != nil || !more {
+				return obj, err
+			}
+		}
+	default:
+		d.pos++
+		list := []interface{}{}
+		if d.skipSpace(); d.pos < len(d.data) && d.data[d.pos] == ']' {
+			d.pos++
+			return list, nil
+		}
+		for {
+			v, err := d.value()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+			if more, err := d.next(']'); err != nil || !more {
+				return list, err
+			}
+		}
+	}
+}
+
+// number decodes the JSON number at d.pos, checking it against the
+// grammar of RFC 8259: an optional minus sign, an integer without
+// leading zeros, an optional fraction, and an optional exponent.
+func (d *jsonDecoder) number() (interface{}, error) {
+	start := d.pos
+	digits := func() bool {
+		i := d.pos
+		for d.pos < len(d.data) && '0' <= d.data[d.pos] && d.data[d.pos] <= '9' {
+			d.pos++
+		}
+		return d.pos > i
+	}
+	if d.data[d.pos] == '-' {
+		d.pos++
+	}
+	if d.pos < len(d.data) && d.data[d.pos] == '0' {
+		d.pos++
+	} else if !digits() {
+		return nil, d.syntaxError()
+	}
+	if d.pos < len(d.data) && d.data[d.pos] == '.' {
+		if d.pos++; !digits() {
+			return nil, d.syntaxError()
+		}
+	}
+	if d.pos < len(d.data) && (d.data[d.pos] == 'e' || d.data[d.pos] == 'E') {
+		d.pos++
+		if d.pos < len(d.data) && (d.data[d.pos] == '+' || d.data[d.pos] == '-') {
+			d.pos++
+		}
+		if !digits() {
+			return nil, d.syntaxError()
+		}
+	}
+	return jsonNumber(d.data[start:d.pos]), nil
+}
+
+// next consumes the comma separating elements of an object or array,
+// or the closing delimiter, reporting whether more elements follow.
+func (d *jsonDecoder) next(end byte) (bool, error) {
+	if d.skipSpace(); d.pos < len(d.data) {
+		switch d.data[d.pos] {
+		case ',':
+			d.pos++
+			return true, nil
+		case end:
+			d.pos++
+			return false, nil
+		}
+	}
+	return false, d.syntaxError()
+}
+
+// string decodes the JSON string literal at d.pos.
+func (d *jsonDecoder) string() (string, error) {
+	d.pos++ // opening quote
+	var b []byte
+	for start := d.pos; d.pos < len(d.data); {
+		switch c := d.data[d.pos]; {
+		case c == '"':
+			b = append(b, d.data[start:d.pos]...)
+			d.pos++
+			return string(b), nil
+		case c < 0x20:
+			return "", d.syntaxError()
+		case c == '\\':
+			b = append(b, d.data[start:d.pos]...)
+			if d.pos+1 >= len(d.data) {
+				return "", d.syntaxError()
+			}
+			switch e := d.data[d.pos+1]; e {
+			case '"', '\\', '/':
+				b = append(b, e)
+			case 'b':
+				b = append(b, '\b')
+			case 'f':
+				b = append(b, '\f')
+			case 'n':
+				b = append(b, '\n')
+			case 'r':
+				b = append(b, '\r')
+			case 't':
+				b = append(b, '\t')
+			case 'u':
+				r, ok := d.hex4(d.pos + 2)
+				if !ok {
+					return "", d.syntaxError()
+				}
+				d.pos += 4
+				if utf16.IsSurrogate(r) {
+					r2, ok := d.hex4(d.pos + 4)
+					if dec := utf16.DecodeRune(r, r2); ok && d.data[d.pos+2:d.pos+4] == `\u` && dec != utf8.RuneError {
+						r = dec
+						d.pos += 6
+					} else {
+						r = utf8.RuneError
+					}
+				}
+				b = append(b, string(r)...)
+			default:
+				return "", d.syntaxError()
+			}
+			d.pos += 2
+			start = d.pos
+		default:
+			d.pos++
+		}
+	}
+	return "", d.syntaxError()
+}
+
+// hex4 decodes the four hexadecimal digits at offset i.
+func (d *jsonDecoder) hex4(i int) (rune, bool) {
+	if i+4 > len(d.data) {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(d.data[i:i+4], 16, 16)
+	return rune(n), err == nil
+}
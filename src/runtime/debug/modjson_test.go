@@ -0,0 +1,198 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	. "runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestMarshalJSONIndent(t *testing.T) {
+	info, ok := ReadBuildInfoData(blob(testModinfo))
+	if !ok {
+		t.Fatal("ReadBuildInfo failed")
+	}
+	compact, err := info.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !json.Valid(compact) {
+		t.Fatalf("MarshalJSON returned invalid JSON:\n%s", compact)
+	}
+	if std, err := json.Marshal(info); err != nil || !bytes.Equal(std, compact) {
+		t.Errorf("json.Marshal = %s, %v, want %s", std, err, compact)
+	}
+
+	indented, err := info.MarshalJSONIndent("", "\t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden, err := ioutil.ReadFile(filepath.Join("testdata", "buildinfo.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(indented, bytes.TrimSuffix(golden, []byte("\n"))) {
+		t.Errorf("MarshalJSONIndent:\n%s\nwant:\n%s", indented, golden)
+	}
+
+	for _, tt := range []struct{ prefix, indent string }{
+		{"", "\t"},
+		{"    ", "  "},
+	} {
+		got, err := info.MarshalJSONIndent(tt.prefix, tt.indent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var want bytes.Buffer
+		json.Indent(&want, compact, tt.prefix, tt.indent)
+		if !bytes.Equal(got, want.Bytes()) {
+			t.Errorf("MarshalJSONIndent(%q, %q):\n%s\nwant:\n%s", tt.prefix, tt.indent, got, want.Bytes())
+		}
+		var back bytes.Buffer
+		if err := json.Compact(&back, got); err != nil || !bytes.Equal(back.Bytes(), compact) {
+			t.Errorf("compacted MarshalJSONIndent(%q, %q) = %s, %v, want %s", tt.prefix, tt.indent, back.Bytes(), err, compact)
+		}
+	}
+}
+
+func TestMarshalJSONEscape(t *testing.T) {
+	m := Module{Path: "a\"b\\c\n<&> \xff"}
+	got, err := m.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := json.Marshal(struct{ Path, Version string }{m.Path, ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("MarshalJSON = %s, want %s", got, want)
+	}
+}
+
+func TestParseGoVersionJSON(t *testing.T) {
+	// testdata/goversion.json is the output of
+	// "go version -m -json hello gofmt".
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "goversion.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	infos, err := ParseGoVersionJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 2 {
+		t.Fatalf("ParseGoVersionJSON returned %d build infos, want 2", len(infos))
+	}
+	hello, gofmt := infos[0], infos[1]
+	want := &BuildInfo{
+		GoVersion: "go1.22.0",
+		Path:      "example.com/hello",
+		Main:      Module{Path: "example.com/hello", Version: "(devel)"},
+		Deps: []*Module{
+			{Path: "example.com/dep", Version: "v1.0.0", Replace: &Module{Path: "./dep", Version: "(devel)"}},
+		},
+		Settings: []BuildSetting{
+			{Key: "-buildmode", Value: "exe"},
+			{Key: "-compiler", Value: "gc"},
+			{Key: "CGO_ENABLED", Value: "1"},
+			{Key: "CGO_CFLAGS"},
+			{Key: "CGO_CPPFLAGS"},
+			{Key: "CGO_CXXFLAGS"},
+			{Key: "CGO_LDFLAGS"},
+			{Key: "GOARCH", Value: "amd64"},
+			{Key: "GOOS", Value: "linux"},
+			{Key: "GOAMD64", Value: "v1"},
+		},
+	}
+	if !reflect.DeepEqual(hello, want) {
+		t.Errorf("hello =\n%+v\nwant\n%+v", hello, want)
+	}
+	if gofmt.Path != "cmd/gofmt" || !reflect.DeepEqual(gofmt.Main, Module{}) || gofmt.Deps != nil || len(gofmt.Settings) != 8 {
+		t.Errorf("gofmt = %+v, want cmd/gofmt with no modules and 8 settings", gofmt)
+	}
+
+	// The elements of a JSON array are decoded in turn.
+	array := append(append([]byte("[\n"), bytes.Replace(data, []byte("}\n{"), []byte("},\n{"), 1)...), ']')
+	fromArray, err := ParseGoVersionJSON(array)
+	if err != nil || !reflect.DeepEqual(fromArray, infos) {
+		t.Errorf("ParseGoVersionJSON(array) = %v, %v, want %v", fromArray, err, infos)
+	}
+
+	for _, bad := range []string{`{"Path": "x"} 1`, `[{"Path": "x"}, 2]`, `{"Path": "x"`} {
+		if infos, err := ParseGoVersionJSON([]byte(bad)); err == nil {
+			t.Errorf("ParseGoVersionJSON(%q) = %v, want error", bad, infos)
+		}
+	}
+}
+
+func TestParseGoVersionJSONSyntax(t *testing.T) {
+	// Unknown members are skipped, but must still be valid JSON.
+	for _, num := range []string{"0", "-0", "12", "-1.5", "1e3", "1E+3", "2.5e-10"} {
+		data := `{"Path": "x", "Extra": ` + num + `}`
+		if infos, err := ParseGoVersionJSON([]byte(data)); err != nil || len(infos) != 1 || infos[0].Path != "x" {
+			t.Errorf("ParseGoVersionJSON(%q) = %v, %v, want x", data, infos, err)
+		}
+	}
+	for _, num := range []string{"-", "01", "1.", ".5", "1e", "1e+", "+1", "1.2.3", "1-2", "0x10"} {
+		data := `{"Path": "x", "Extra": ` + num + `}`
+		if infos, err := ParseGoVersionJSON([]byte(data)); err == nil {
+			t.Errorf("ParseGoVersionJSON(%q) = %v, want error", data, infos)
+		}
+	}
+
+	// Nesting is limited to the depth encoding/json accepts.
+	nest := func(n int) []byte {
+		return []byte(`{"Path": "x", "Extra": ` + strings.Repeat("[", n) + strings.Repeat("]", n) + `}`)
+	}
+	if _, err := ParseGoVersionJSON(nest(9999)); err != nil {
+		t.Errorf("ParseGoVersionJSON(9999 nested arrays): %v", err)
+	}
+	if _, err := ParseGoVersionJSON(nest(1000000)); err == nil || !strings.Contains(err.Error(), "invalid JSON") {
+		t.Errorf("ParseGoVersionJSON(1000000 nested arrays) error = %v, want invalid JSON", err)
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	info, ok := ReadBuildInfoData(blob(testModinfo))
+	if !ok {
+		t.Fatal("ReadBuildInfo failed")
+	}
+	data, err := json.Marshal(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back BuildInfo
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&back, info) {
+		t.Errorf("json.Unmarshal(%s) = %+v, want %+v", data, &back, info)
+	}
+
+	var m Module
+	if err := json.Unmarshal([]byte(`{"path":"a","Version":"v1.0.0","Replace":{"Path":"../a"}}`), &m); err != nil {
+		t.Fatal(err)
+	}
+	want := Module{Path: "a", Version: "v1.0.0", Replace: &Module{Path: "../a"}}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("json.Unmarshal module = %+v, want %+v", m, want)
+	}
+	if data, _ := json.Marshal(want); !bytes.Contains(data, []byte(`"Replace":{"Path":"../a","Version":""}`)) || bytes.Contains(data, []byte("Sum")) {
+		t.Errorf("json.Marshal(%+v) = %s, want nested Replace and no Sum", want, data)
+	}
+
+	for _, bad := range []string{`[]`, `{"Deps":{}}`, `{"Main":{"Path":1}}`} {
+		if err := json.Unmarshal([]byte(bad), new(BuildInfo)); err == nil {
+			t.Errorf("json.Unmarshal(%s) succeeded, want error", bad)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// A LazyBuildInfo holds the build information of the running binary,
+// as returned by ReadBuildInfo, parsing it in two steps: the header,
+// which describes the binary and its main module, when the LazyBuildInfo
+// is created, and the rest, with the dependencies and build settings,
+// on first use. A program that only reports its own version, even one
+// with thousands of dependencies, need not parse them.
+//
+// The methods of a LazyBuildInfo may be called by multiple goroutines
+// simultaneously.
+type LazyBuildInfo struct {
+	GoVersion string // Version of Go that produced this binary
+	Path      string // The main package path
+	Main      Module // The module containing the main package
+
+	once sync.Once
+	load func() (*BuildInfo, error)
+	info *BuildInfo
+	err  error
+}
+
+// ReadBuildInfoLazy returns the build information embedded in the
+// running binary, with its dependencies left to be parsed on demand.
+// Like ReadBuildInfo, it reports whether the binary has build
+// information, and returns a new copy, which the caller may modify,
+// on each call.
+//
+// ReadBuildInfoLazy checks only the header for errors. If the rest of
+// the build information is corrupt, as it is most unlikely to be in a
+// binary built by the go command, the methods that would parse it
+// report no dependencies or settings, and Err reports the problem.
+func ReadBuildInfoLazy() (*LazyBuildInfo, bool) {
+	lb, ok := readBuildInfoLazy(modinfo(), ReadBuildInfoErr)
+	if ok {
+		lb.GoVersion = runtime.Version()
+	}
+	return lb, ok
+}
+
+// readBuildInfoLazy returns a LazyBuildInfo for the module information
+// data, framed by 16-byte sentinels as modinfo returns it, parsing its
+// header now and calling load to parse all of it on first use.
+func readBuildInfoLazy(data string, load func() (*BuildInfo, error)) (*LazyBuildInfo, bool) {
+	if validateBlob(data) != nil {
+		return nil, false
+	}
+	data = data[len(infoStart) : len(data)-len(infoEnd)]
+	// The header ends at the first dep or build line.
+	end := len(data)
+	for _, prefix := range []string{depLine, buildLine} {
+		if strings.HasPrefix(data, prefix) {
+			end = 0
+		} else if i := strings.Index(data, "\n"+prefix); i >= 0 && i+1 < end {
+			end = i + 1
+		}
+	}
+	header, err := parseBuildInfo(data[:end])
+	if err != nil {
+		return nil, false
+	}
+	return &LazyBuildInfo{
+		GoVersion: header.GoVersion,
+		Path:      header.Path,
+		Main:      header.Main,
+		load:      load,
+	}, true
+}
+
+// BuildInfo returns the complete build information, parsing it on the
+// first call. It returns nil if the build information is corrupt. The
+// result belongs to lb: it is the same on each call, and modifying it
+// affects the other methods, but not the fields of lb, which are
+// separate copies of the header.
+func (lb *LazyBuildInfo) BuildInfo() *BuildInfo {
+	lb.once.Do(func() {
+		lb.info, lb.err = lb.load()
+	})
+	return lb.info
+}
+
+// Deps returns the module dependencies, as in BuildInfo.Deps, parsing
+// them on the first call of Deps, Dep, Settings, BuildInfo, or Err.
+func (lb *LazyBuildInfo) Deps() []*Module {
+	if bi := lb.BuildInfo(); bi != nil {
+		return bi.Deps
+	}
+	return nil
+}
+
+// Dep returns the dependency with the given module path, as
+// BuildInfo.Dep does, and reports whether there is one.
+func (lb *LazyBuildInfo) Dep(path string) (*Module, bool) {
+	if bi := lb.BuildInfo(); bi != nil {
+		return bi.Dep(path)
+	}
+	return nil, false
+}
+
+// Settings returns the other information about the build,
+// as in BuildInfo.Settings.
+func (lb *LazyBuildInfo) Settings() []BuildSetting {
+	if bi := lb.BuildInfo(); bi != nil {
+		return bi.Settings
+	}
+	return nil
+}
+
+// Err returns the error, if any, from parsing the complete build
+// information, parsing it if it has not been parsed.
+func (lb *LazyBuildInfo) Err() error {
+	lb.BuildInfo()
+	return lb.err
+}
@@ -0,0 +1,96 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"reflect"
+	. "runtime/debug"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestReadBuildInfoLazy(t *testing.T) {
+	data := blob(testModinfo)
+	want, ok := ReadBuildInfoData(data)
+	if !ok {
+		t.Fatal("ReadBuildInfo failed")
+	}
+	var loads int32
+	load := func() (*BuildInfo, error) {
+		atomic.AddInt32(&loads, 1)
+		return ReadBuildInfoErrData(data)
+	}
+	lb, ok := ReadBuildInfoLazyData(data, load)
+	if !ok {
+		t.Fatal("ReadBuildInfoLazy failed")
+	}
+	if lb.Path != want.Path || !reflect.DeepEqual(lb.Main, want.Main) {
+		t.Errorf("ReadBuildInfoLazy header = %q, %+v, want %q, %+v", lb.Path, lb.Main, want.Path, want.Main)
+	}
+	if loads != 0 {
+		t.Errorf("ReadBuildInfoLazy parsed the dependencies")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if deps := lb.Deps(); !reflect.DeepEqual(deps, want.Deps) {
+				t.Errorf("Deps() = %v, want %v", deps, want.Deps)
+			}
+		}()
+	}
+	wg.Wait()
+	if m, ok := lb.Dep("rsc.io/quote"); !ok || m.Replace == nil {
+		t.Errorf("Dep(rsc.io/quote) = %+v, %v", m, ok)
+	}
+	if s := lb.Settings(); !reflect.DeepEqual(s, want.Settings) {
+		t.Errorf("Settings() = %v, want %v", s, want.Settings)
+	}
+	if err := lb.Err(); err != nil {
+		t.Errorf("Err() = %v", err)
+	}
+	if loads != 1 {
+		t.Errorf("dependencies parsed %d times, want once", loads)
+	}
+
+	// A corrupt dependency is found only on use.
+	data = blob("path\texample.com/cmd/hello\nmod\texample.com/hello\tv1.2.3\ndep\tx\n")
+	lb, ok = ReadBuildInfoLazyData(data, func() (*BuildInfo, error) { return ReadBuildInfoErrData(data) })
+	if !ok || lb.Main.Version != "v1.2.3" {
+		t.Fatalf("ReadBuildInfoLazy with corrupt dependency = %+v, %v", lb, ok)
+	}
+	if lb.Deps() != nil || lb.BuildInfo() != nil || lb.Err() == nil {
+		t.Errorf("corrupt dependency: Deps() = %v, BuildInfo() = %v, Err() = %v", lb.Deps(), lb.BuildInfo(), lb.Err())
+	}
+
+	for _, data := range []string{"", "short", blob("mod\tx\n")} {
+		if _, ok := ReadBuildInfoLazyData(data, nil); ok {
+			t.Errorf("ReadBuildInfoLazy(%q) succeeded", data)
+		}
+	}
+
+	// The running binary.
+	self, selfOK := ReadBuildInfo()
+	lb, ok = ReadBuildInfoLazy()
+	if ok != selfOK {
+		t.Fatalf("ReadBuildInfoLazy() ok = %v, ReadBuildInfo() ok = %v", ok, selfOK)
+	}
+	if ok && (lb.GoVersion != self.GoVersion || lb.Path != self.Path || !reflect.DeepEqual(lb.Main, self.Main) || !reflect.DeepEqual(lb.BuildInfo(), self)) {
+		t.Errorf("ReadBuildInfoLazy() = %+v, want %+v", lb, self)
+	}
+}
+
+func BenchmarkReadBuildInfoLazy(b *testing.B) {
+	data := blob(bigModinfo(500))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, ok := ReadBuildInfoLazyData(data, nil); !ok {
+			b.Fatal("ReadBuildInfoLazy failed")
+		}
+	}
+}
@@ -0,0 +1,24 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+// Licenses groups the dependencies of bi by the license recorded for
+// them, as Module.License, for license compliance checks. The license
+// of a dependency is that of the module it resolves to: the last in
+// its chain of replacements, as Module.Effective reports it.
+// Dependencies with no recorded license are listed under the empty
+// string, so that they can be reviewed. The dependencies with each
+// license are in the order of bi.Deps.
+func (bi *BuildInfo) Licenses() map[string][]*Module {
+	licenses := make(map[string][]*Module)
+	for _, dep := range bi.Deps {
+		if dep == nil {
+			continue
+		}
+		m := dep.effective()
+		licenses[m.License] = append(licenses[m.License], dep)
+	}
+	return licenses
+}
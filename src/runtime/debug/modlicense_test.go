@@ -0,0 +1,80 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"reflect"
+	. "runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestLicense(t *testing.T) {
+	text := formatHeader +
+		"mod\texample.com/hello\tv1.2.3\t\n" +
+		"license\tBSD-3-Clause\n" +
+		"dep\tgolang.org/x/text\tv0.3.3\t\n" +
+		"license\tBSD-3-Clause\n" +
+		"dep\trsc.io/quote\tv1.5.2\n" +
+		"=>\texample.com/quote\tv1.0.0\t\n" +
+		"origin\tgit\thttps://example.com/quote.git\n" +
+		"license\tMIT OR Apache-2.0\n" +
+		"dep\trsc.io/sampler\tv1.3.0\t\n"
+	var info BuildInfo
+	if err := info.UnmarshalTextStrict([]byte(text)); err != nil {
+		t.Fatal(err)
+	}
+	if info.Main.License != "BSD-3-Clause" || info.Deps[1].Replace.License != "MIT OR Apache-2.0" || info.Deps[1].License != "" {
+		t.Errorf("UnmarshalText: licenses %q, %q, %q", info.Main.License, info.Deps[1].License, info.Deps[1].Replace.License)
+	}
+	if got := info.String(); got != text {
+		t.Errorf("String:\n%s\nwant:\n%s", got, text)
+	}
+	js, err := info.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromJSON BuildInfo
+	if err := fromJSON.UnmarshalJSON(js); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&fromJSON, &info) {
+		t.Errorf("JSON round trip:\n%+v\nwant:\n%+v", &fromJSON, &info)
+	}
+	for _, bad := range []string{"license\tMIT\n", "mod\tx\tv1.0.0\t\nlicense\t\n"} {
+		if err := new(BuildInfo).UnmarshalText([]byte(bad)); err == nil {
+			t.Errorf("UnmarshalText(%q) succeeded, want error", bad)
+		}
+	}
+
+	licenses := info.Licenses()
+	for license, want := range map[string][]string{
+		"BSD-3-Clause":      {"golang.org/x/text"},
+		"MIT OR Apache-2.0": {"rsc.io/quote"},
+		"":                  {"rsc.io/sampler"},
+	} {
+		var got []string
+		for _, m := range licenses[license] {
+			got = append(got, m.Path)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Licenses()[%q] = %q, want %q", license, got, want)
+		}
+	}
+	if len(licenses) != 3 {
+		t.Errorf("Licenses() has %d entries, want 3", len(licenses))
+	}
+
+	cdx, _ := info.MarshalCycloneDX()
+	spdx, _ := info.MarshalSPDX()
+	for _, s := range []string{`"licenses": [`, `"expression": "MIT OR Apache-2.0"`} {
+		if !strings.Contains(string(cdx), s) {
+			t.Errorf("MarshalCycloneDX does not contain %s", s)
+		}
+	}
+	if !strings.Contains(string(spdx), `"licenseDeclared": "BSD-3-Clause"`) {
+		t.Errorf("MarshalSPDX does not contain licenseDeclared")
+	}
+}
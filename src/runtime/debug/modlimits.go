@@ -0,0 +1,83 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"errors"
+	"strconv"
+	"sync/atomic"
+)
+
+// ErrLimitExceeded reports build information larger than the limits
+// set by SetTextLimits.
+var ErrLimitExceeded = errors.New("build information exceeds limit")
+
+// TextLimits bounds the size of the text form of build information
+// accepted by the parsers in this package. A zero field means no limit.
+type TextLimits struct {
+	MaxLines      int // maximum number of lines
+	MaxLineLength int // maximum length of a line in bytes, without its newline
+	MaxDeps       int // maximum number of dependencies, that is, of dep lines
+}
+
+var textLimits atomic.Value // TextLimits
+
+// SetTextLimits sets the limits enforced when parsing the text form of
+// build information, by UnmarshalText and its variants and by the
+// functions that read build information from files and other binaries,
+// and returns the previous setting. Input exceeding a limit is rejected
+// with a *TextError wrapping ErrLimitExceeded, before the parser
+// allocates memory in proportion to it. When both MaxLines and
+// MaxLineLength are set, the functions reading executables also refuse
+// to read build information longer than they allow.
+// The initial setting has no limits.
+//
+// SetTextLimits is useful mainly for services that parse build
+// information from untrusted binaries, which could otherwise make them
+// allocate memory in proportion to a crafted, arbitrarily large payload.
+func SetTextLimits(limits TextLimits) TextLimits {
+	old := currentTextLimits()
+	textLimits.Store(limits)
+	return old
+}
+
+func currentTextLimits() TextLimits {
+	l, _ := textLimits.Load().(TextLimits)
+	return l
+}
+
+// maxBytes returns the length of the longest text l admits,
+// or 0 if that is unlimited.
+func (l TextLimits) maxBytes() int64 {
+	if l.MaxLines <= 0 || l.MaxLineLength <= 0 {
+		return 0
+	}
+	return int64(l.MaxLines) * (int64(l.MaxLineLength) + 1)
+}
+
+// A sizeLimitError reports build information in an executable
+// longer than the limits allow.
+type sizeLimitError struct {
+	n, max int64
+}
+
+func (e *sizeLimitError) Error() string {
+	return ErrLimitExceeded.Error() + ": " + strconv.FormatInt(e.n, 10) + " bytes, limit " + strconv.FormatInt(e.max, 10)
+}
+
+func (e *sizeLimitError) Unwrap() error { return ErrLimitExceeded }
+
+// limitError returns the error reporting that line lineno, text,
+// exceeds the limit described by msg.
+func limitError(lineno int, text string, limits TextLimits, msg string) error {
+	if limits.MaxLineLength > 0 && len(text) > limits.MaxLineLength {
+		text = text[:limits.MaxLineLength]
+	}
+	return &TextError{Line: lineno, Column: 1, Text: text, Msg: msg, Err: ErrLimitExceeded}
+}
+
+func limitMsg(what string, n int) string {
+	return what + " (limit " + strconv.Itoa(n) + ")"
+}
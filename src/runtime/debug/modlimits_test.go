@@ -0,0 +1,60 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"bytes"
+	"errors"
+	. "runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestTextLimits(t *testing.T) {
+	defer SetTextLimits(SetTextLimits(TextLimits{MaxLines: 8, MaxLineLength: 64, MaxDeps: 2}))
+
+	// testModinfo has 7 lines, the longest of 42 bytes, and 2 deps.
+	var info BuildInfo
+	if err := info.UnmarshalText([]byte(testModinfo)); err != nil {
+		t.Fatalf("UnmarshalText within limits: %v", err)
+	}
+	for _, tt := range []struct {
+		name, text string
+		line       int
+	}{
+		{"lines", testModinfo + "build\ta=1\nbuild\tb=2\n", 9},
+		{"line length", "path\t" + strings.Repeat("x", 60) + "\n", 1},
+		{"deps", testModinfo + "dep\texample.com/third\tv1.0.0\t\n", 8},
+		{"huge", bigModinfo(100000), 5},
+	} {
+		for _, strict := range []bool{false, true} {
+			var err error
+			if strict {
+				err = new(BuildInfo).UnmarshalTextStrict([]byte(tt.text))
+			} else {
+				err = new(BuildInfo).UnmarshalText([]byte(tt.text))
+			}
+			var te *TextError
+			if !errors.Is(err, ErrLimitExceeded) || !errors.As(err, &te) || te.Line != tt.line {
+				t.Errorf("%s (strict %v): error %v, want ErrLimitExceeded at line %d", tt.name, strict, err, tt.line)
+			}
+		}
+	}
+
+	// Executables with too much build information are refused before
+	// it is read.
+	if _, err := ReadBuildInfoFrom(strings.NewReader(blob(bigModinfo(1000)))); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("ReadBuildInfoFrom(big) = %v, want ErrLimitExceeded", err)
+	}
+	SetTextLimits(TextLimits{MaxDeps: 2})
+	if _, err := ReadBuildInfoFrom(bytes.NewReader([]byte(blob(bigModinfo(3))))); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("ReadBuildInfoFrom(3 deps) = %v, want ErrLimitExceeded", err)
+	}
+
+	SetTextLimits(TextLimits{})
+	if err := info.UnmarshalText([]byte(bigModinfo(1000))); err != nil {
+		t.Errorf("UnmarshalText without limits: %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"sort"
+	"strings"
+)
+
+// LockJSON returns the dependencies of bi as a JSON object in the
+// style of an npm package-lock file, for tools that consume such
+// lockfiles. The object maps each dependency's module path, in sorted
+// order, to an object with the members "version", the resolved
+// version, that of the module the dependency resolves to as
+// Module.Effective reports it; "integrity", the resolved module's h1:
+// checksum as a Subresource Integrity string "sha256-<base64>",
+// omitted if the checksum is missing or of another kind; and
+// "replaced", whether the dependency is replaced.
+func (bi *BuildInfo) LockJSON() ([]byte, error) {
+	deps := make([]*Module, 0, len(bi.Deps))
+	for _, dep := range bi.Deps {
+		if dep != nil {
+			deps = append(deps, dep)
+		}
+	}
+	sort.SliceStable(deps, func(i, j int) bool { return deps[i].Path < deps[j].Path })
+
+	b := []byte{'{'}
+	for i, dep := range deps {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		m := dep.effective()
+		b = appendJSONString(b, dep.Path)
+		b = append(b, `:{"version":`...)
+		b = appendJSONString(b, m.Version)
+		if integrity, ok := sumIntegrity(m.Sum); ok {
+			b = append(b, `,"integrity":`...)
+			b = appendJSONString(b, integrity)
+		}
+		if dep.Replace != nil {
+			b = append(b, `,"replaced":true}`...)
+		} else {
+			b = append(b, `,"replaced":false}`...)
+		}
+	}
+	b = append(b, '}')
+	return indentJSON(b, "", "  "), nil
+}
+
+// sumIntegrity converts an h1: module checksum, the standard base64
+// encoding of a SHA-256 hash, to a Subresource Integrity string.
+func sumIntegrity(sum string) (string, bool) {
+	if !strings.HasPrefix(sum, "h1:") {
+		return "", false
+	}
+	hash := sum[len("h1:"):]
+	// A 32-byte hash encodes as 43 base64 characters and one '='.
+	if len(hash) != 44 || hash[43] != '=' || strings.IndexFunc(hash[:43], func(r rune) bool {
+		return !('A' <= r && r <= 'Z' || 'a' <= r && r <= 'z' || '0' <= r && r <= '9' || r == '+' || r == '/')
+	}) >= 0 {
+		return "", false
+	}
+	return "sha256-" + hash, true
+}
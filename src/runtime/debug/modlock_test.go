@@ -0,0 +1,60 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	. "runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestLockJSON(t *testing.T) {
+	hash := sha256.Sum256([]byte("go.sum line"))
+	h1 := "h1:" + base64.StdEncoding.EncodeToString(hash[:])
+	info := &BuildInfo{
+		Deps: []*Module{
+			{Path: "rsc.io/quote", Version: "v1.5.2", Sum: "h1:beef=", Replace: &Module{Path: "rsc.io/quote", Version: "v1.0.0", Sum: h1}},
+			{Path: "golang.org/x/text", Version: "v0.3.3", Sum: h1},
+			{Path: "example.com/local", Version: "v1.0.0", Replace: &Module{Path: "../local"}},
+			{Path: "example.com/other", Version: "v1.0.0", Sum: "h2:" + h1[3:]},
+			nil,
+		},
+	}
+	data, err := info.LockJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	type entry struct {
+		Version   string `json:"version"`
+		Integrity string `json:"integrity"`
+		Replaced  bool   `json:"replaced"`
+	}
+	var got map[string]entry
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("LockJSON returned invalid JSON: %v\n%s", err, data)
+	}
+	integrity := "sha256-" + base64.StdEncoding.EncodeToString(hash[:])
+	want := map[string]entry{
+		"example.com/local": {Version: "", Replaced: true},
+		"example.com/other": {Version: "v1.0.0"},
+		"golang.org/x/text": {Version: "v0.3.3", Integrity: integrity},
+		"rsc.io/quote":      {Version: "v1.0.0", Integrity: integrity, Replaced: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LockJSON =\n%s\nwant %+v", data, want)
+	}
+	if i, j := strings.Index(string(data), "golang.org"), strings.Index(string(data), "rsc.io"); i < 0 || i > j {
+		t.Errorf("LockJSON modules not in sorted order:\n%s", data)
+	}
+	// The integrity string must decode back to the SHA-256 hash.
+	dec, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(got["golang.org/x/text"].Integrity, "sha256-"))
+	if err != nil || !reflect.DeepEqual(dec, hash[:]) {
+		t.Errorf("integrity decodes to %x, %v, want %x", dec, err, hash)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+// LogBuildInfo logs a one-line summary of the build information of
+// the running binary, giving the main module version, the VCS revision,
+// and the Go version, or "build info unavailable" if the binary was
+// built without module support. It helps record which build a test ran
+// under when debugging failures that depend on the environment.
+//
+// The argument is typically a *testing.T or *testing.B. Package
+// testing itself depends on runtime/debug, so LogBuildInfo accepts
+// any value with the Helper and Log methods of testing.TB.
+func LogBuildInfo(t interface {
+	Helper()
+	Log(args ...interface{})
+}) {
+	t.Helper()
+	info, ok := ReadBuildInfo()
+	t.Log(buildInfoSummary(info, ok))
+}
+
+// buildInfoSummary returns the summary logged by LogBuildInfo.
+func buildInfoSummary(info *BuildInfo, ok bool) string {
+	if !ok {
+		return "build info unavailable"
+	}
+	rev, ok := info.setting("vcs.revision")
+	if !ok {
+		rev = "unknown"
+	}
+	return "build info: main " + info.Main.Path + " " + orUnknown(info.Main.Version) +
+		", revision " + rev + ", " + orUnknown(info.GoVersion)
+}
+
+// LogAttrs returns the build information of bi as alternating keys
+// and values, to be passed to a structured logger that accepts its
+// attributes that way. The keys, in order, are "version" (the main
+// module version), "revision" and "vcsTime" (the vcs.revision and
+// vcs.time settings), "modified" (the vcs.modified setting),
+// "goVersion", "path" (the main package path), and "module" (the main
+// module path). Pairs with empty values are omitted. The values are
+// strings.
+func (bi *BuildInfo) LogAttrs() []interface{} {
+	var attrs []interface{}
+	add := func(key, value string) {
+		if value != "" {
+			attrs = append(attrs, key, value)
+		}
+	}
+	rev, _ := bi.setting("vcs.revision")
+	vcsTime, _ := bi.setting("vcs.time")
+	modified, _ := bi.setting("vcs.modified")
+	add("version", bi.Main.Version)
+	add("revision", rev)
+	add("vcsTime", vcsTime)
+	add("modified", modified)
+	add("goVersion", bi.GoVersion)
+	add("path", bi.Path)
+	add("module", bi.Main.Path)
+	return attrs
+}
@@ -0,0 +1,86 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"fmt"
+	"reflect"
+	. "runtime/debug"
+	"testing"
+)
+
+type testLogger struct {
+	logs []string
+}
+
+func (l *testLogger) Helper() {}
+
+func (l *testLogger) Log(args ...interface{}) {
+	l.logs = append(l.logs, fmt.Sprint(args...))
+}
+
+func TestLogBuildInfo(t *testing.T) {
+	LogBuildInfo(t)
+
+	var l testLogger
+	LogBuildInfo(&l)
+	if len(l.logs) != 1 {
+		t.Fatalf("LogBuildInfo logged %d lines, want 1", len(l.logs))
+	}
+	_, ok := ReadBuildInfo()
+	if want := BuildInfoSummary(ReadBuildInfo()); l.logs[0] != want {
+		t.Errorf("LogBuildInfo logged %q, want %q", l.logs[0], want)
+	}
+	if !ok && l.logs[0] != "build info unavailable" {
+		t.Errorf("LogBuildInfo logged %q without build info", l.logs[0])
+	}
+}
+
+func TestBuildInfoSummary(t *testing.T) {
+	info := &BuildInfo{
+		GoVersion: "go1.15",
+		Main:      Module{Path: "example.com/hello", Version: "v1.2.3"},
+		Settings:  []BuildSetting{{Key: "vcs.revision", Value: "abc123"}},
+	}
+	for _, tt := range []struct {
+		info *BuildInfo
+		ok   bool
+		want string
+	}{
+		{info, true, "build info: main example.com/hello v1.2.3, revision abc123, go1.15"},
+		{&BuildInfo{Main: Module{Path: "example.com/hello"}}, true, "build info: main example.com/hello unknown, revision unknown, unknown"},
+		{nil, false, "build info unavailable"},
+	} {
+		if got := BuildInfoSummary(tt.info, tt.ok); got != tt.want {
+			t.Errorf("BuildInfoSummary = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestLogAttrs(t *testing.T) {
+	info := &BuildInfo{
+		GoVersion: "go1.15",
+		Path:      "example.com/cmd/hello",
+		Main:      Module{Path: "example.com/hello", Version: "v1.2.3"},
+		Settings: []BuildSetting{
+			{Key: "vcs.revision", Value: "abc123"},
+			{Key: "vcs.modified", Value: "false"},
+		},
+	}
+	want := []interface{}{
+		"version", "v1.2.3",
+		"revision", "abc123",
+		"modified", "false",
+		"goVersion", "go1.15",
+		"path", "example.com/cmd/hello",
+		"module", "example.com/hello",
+	}
+	if got := info.LogAttrs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("LogAttrs() = %q, want %q", got, want)
+	}
+	if got := new(BuildInfo).LogAttrs(); len(got) != 0 {
+		t.Errorf("LogAttrs() of empty BuildInfo = %q, want none", got)
+	}
+}
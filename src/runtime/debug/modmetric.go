@@ -0,0 +1,97 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"bytes"
+	"io"
+	"runtime"
+	"strings"
+)
+
+// A GaugeSink receives the samples of constant gauges. An adapter to a
+// metrics registry, such as a Prometheus collector, implements it, so
+// that the registry need not be imported here. labels holds the names
+// and values of the sample's labels; it must not be retained.
+type GaugeSink interface {
+	Gauge(name, help string, labels map[string]string, value float64)
+}
+
+// A BuildInfoMetric is a constant gauge, go_build_info, with the value 1,
+// whose labels describe the build of the running binary:
+//
+//	path       the main package path
+//	version    the version of the main module
+//	checksum   the checksum of the main module
+//	goversion  the version of Go that built the binary, as runtime.Version
+//
+// Exported by every service, it lets queries join other metrics against
+// the versions that produced them.
+type BuildInfoMetric struct {
+	labels map[string]string
+}
+
+// buildInfoMetricName and buildInfoMetricHelp are the name and help
+// text of the metric, as the Prometheus Go client exports them.
+const (
+	buildInfoMetricName = "go_build_info"
+	buildInfoMetricHelp = "Build information about the main Go module."
+)
+
+// BuildInfoCollector returns the go_build_info metric of the running
+// binary. The labels of a binary without build information have empty
+// values, except goversion.
+func BuildInfoCollector() *BuildInfoMetric {
+	bi, _ := ReadBuildInfo()
+	if bi == nil {
+		bi = &BuildInfo{GoVersion: runtime.Version()}
+	}
+	return buildInfoMetric(bi)
+}
+
+// buildInfoMetric returns the go_build_info metric of bi.
+func buildInfoMetric(bi *BuildInfo) *BuildInfoMetric {
+	return &BuildInfoMetric{labels: map[string]string{
+		"path":      bi.Path,
+		"version":   bi.Main.Version,
+		"checksum":  bi.Main.Sum,
+		"goversion": bi.GoVersion,
+	}}
+}
+
+// Labels returns the labels of the metric, by name.
+func (m *BuildInfoMetric) Labels() map[string]string {
+	labels := make(map[string]string, len(m.labels))
+	for k, v := range m.labels {
+		labels[k] = v
+	}
+	return labels
+}
+
+// Collect passes the sample of the metric to sink.
+func (m *BuildInfoMetric) Collect(sink GaugeSink) {
+	sink.Gauge(buildInfoMetricName, buildInfoMetricHelp, m.Labels(), 1)
+}
+
+// WriteTo writes the metric to w in the Prometheus text exposition
+// format, so that it can be appended to the output of a metrics
+// endpoint. The labels are written sorted by name.
+func (m *BuildInfoMetric) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	buf.WriteString("# HELP " + buildInfoMetricName + " " + buildInfoMetricHelp + "\n")
+	buf.WriteString("# TYPE " + buildInfoMetricName + " gauge\n")
+	buf.WriteString(buildInfoMetricName + "{")
+	for i, name := range []string{"checksum", "goversion", "path", "version"} {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(name + `="` + labelEscaper.Replace(m.labels[name]) + `"`)
+	}
+	buf.WriteString("} 1\n")
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
@@ -0,0 +1,59 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"reflect"
+	"runtime"
+	. "runtime/debug"
+	"strings"
+	"testing"
+)
+
+type testGaugeSink struct {
+	name, help string
+	labels     map[string]string
+	value      float64
+	n          int
+}
+
+func (s *testGaugeSink) Gauge(name, help string, labels map[string]string, value float64) {
+	s.name, s.help, s.labels, s.value = name, help, labels, value
+	s.n++
+}
+
+func TestBuildInfoCollector(t *testing.T) {
+	m := BuildInfoCollector()
+	want := map[string]string{"path": "", "version": "", "checksum": "", "goversion": runtime.Version()}
+	if bi, ok := ReadBuildInfo(); ok {
+		want["path"], want["version"], want["checksum"] = bi.Path, bi.Main.Version, bi.Main.Sum
+	}
+	if got := m.Labels(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Labels() = %v, want %v", got, want)
+	}
+
+	var sink testGaugeSink
+	m.Collect(&sink)
+	if sink.n != 1 || sink.name != "go_build_info" || sink.help == "" || sink.value != 1 || !reflect.DeepEqual(sink.labels, want) {
+		t.Errorf("Collect reported %+v", sink)
+	}
+
+	m = BuildInfoMetricOf(&BuildInfo{
+		GoVersion: "go1.15",
+		Path:      "example.com/cmd/hello",
+		Main:      Module{Path: "example.com/hello", Version: "v1.2.3", Sum: `h1:"quoted"\=`},
+	})
+	var b strings.Builder
+	n, err := m.WriteTo(&b)
+	if err != nil || n != int64(b.Len()) {
+		t.Fatalf("WriteTo = %d, %v", n, err)
+	}
+	const text = "# HELP go_build_info Build information about the main Go module.\n" +
+		"# TYPE go_build_info gauge\n" +
+		`go_build_info{checksum="h1:\"quoted\"\\=",goversion="go1.15",path="example.com/cmd/hello",version="v1.2.3"} 1` + "\n"
+	if b.String() != text {
+		t.Errorf("WriteTo wrote:\n%s\nwant:\n%s", b.String(), text)
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import "errors"
+
+// minimalFormat is the first byte of the encoding returned by Minimal.
+const minimalFormat = 1
+
+// Minimal returns a compact binary encoding of the version of the main
+// module and the Go version of bi, for reporting the identity of a build
+// from memory-constrained devices or over constrained links. The
+// encoding is lossy: it carries nothing else from bi. Its layout is
+//
+//	byte 0:              format, currently 1
+//	byte 1:              length n of the main module version
+//	bytes 2 to 2+n:      main module version
+//	byte 2+n:            length m of the Go version
+//	bytes 3+n to 3+n+m:  Go version
+//
+// Versions longer than 255 bytes are truncated.
+// DecodeMinimal decodes the result.
+func (bi *BuildInfo) Minimal() []byte {
+	b := make([]byte, 0, 3+len(bi.Main.Version)+len(bi.GoVersion))
+	b = append(b, minimalFormat)
+	b = appendMinimalString(b, bi.Main.Version)
+	b = appendMinimalString(b, bi.GoVersion)
+	return b
+}
+
+func appendMinimalString(b []byte, s string) []byte {
+	if len(s) > 255 {
+		s = s[:255]
+	}
+	b = append(b, byte(len(s)))
+	return append(b, s...)
+}
+
+var errBadMinimal = errors.New("malformed minimal build information")
+
+// DecodeMinimal decodes the main module version and Go version
+// from data, as encoded by BuildInfo.Minimal.
+func DecodeMinimal(data []byte) (mainVersion, goVersion string, err error) {
+	if len(data) == 0 || data[0] != minimalFormat {
+		return "", "", errBadMinimal
+	}
+	data = data[1:]
+	mainVersion, data, ok := cutMinimalString(data)
+	if !ok {
+		return "", "", errBadMinimal
+	}
+	goVersion, data, ok = cutMinimalString(data)
+	if !ok || len(data) != 0 {
+		return "", "", errBadMinimal
+	}
+	return mainVersion, goVersion, nil
+}
+
+func cutMinimalString(data []byte) (s string, rest []byte, ok bool) {
+	if len(data) == 0 || int(data[0]) > len(data)-1 {
+		return "", nil, false
+	}
+	n := int(data[0])
+	return string(data[1 : 1+n]), data[1+n:], true
+}
@@ -0,0 +1,56 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	. "runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestMinimal(t *testing.T) {
+	for _, tt := range []struct {
+		mainVersion, goVersion string
+	}{
+		{"v1.2.3", "go1.15"},
+		{"(devel)", "devel +abcdef Tue Jan 1 00:00:00 2020 +0000"},
+		{"", ""},
+		{"v0.0.0-20200101000000-abcdefabcdef", ""},
+	} {
+		info := &BuildInfo{
+			GoVersion: tt.goVersion,
+			Main:      Module{Path: "example.com/hello", Version: tt.mainVersion, Sum: "h1:c0ffee="},
+			Deps:      []*Module{{Path: "golang.org/x/text", Version: "v0.3.3"}},
+		}
+		data := info.Minimal()
+		if want := 3 + len(tt.mainVersion) + len(tt.goVersion); len(data) != want {
+			t.Errorf("len(Minimal()) = %d, want %d", len(data), want)
+		}
+		mv, gv, err := DecodeMinimal(data)
+		if err != nil || mv != tt.mainVersion || gv != tt.goVersion {
+			t.Errorf("DecodeMinimal(%q) = %q, %q, %v, want %q, %q, nil", data, mv, gv, err, tt.mainVersion, tt.goVersion)
+		}
+	}
+
+	long := &BuildInfo{Main: Module{Version: "v1.0.0-" + strings.Repeat("x", 300)}}
+	if mv, _, err := DecodeMinimal(long.Minimal()); err != nil || mv != long.Main.Version[:255] {
+		t.Errorf("DecodeMinimal of long version = %q, %v, want truncation to 255 bytes", mv, err)
+	}
+}
+
+func TestDecodeMinimalErrors(t *testing.T) {
+	for _, data := range []string{
+		"",
+		"\x02\x00\x00",
+		"\x01",
+		"\x01\x06v1.2.3",
+		"\x01\x06v1.2\x00",
+		"\x01\x00\x00extra",
+	} {
+		if mv, gv, err := DecodeMinimal([]byte(data)); err == nil {
+			t.Errorf("DecodeMinimal(%q) = %q, %q, nil, want error", data, mv, gv)
+		}
+	}
+}
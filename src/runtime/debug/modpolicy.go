@@ -0,0 +1,137 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import "strings"
+
+// A Policy declares which dependencies a binary may be built with.
+// Patterns are module paths in which "..." is a wildcard, as for
+// BuildInfo.DepsMatching.
+type Policy struct {
+	// Allowed, if not empty, lists patterns of the module paths a
+	// binary may depend on; a dependency whose path matches none is
+	// a violation.
+	Allowed []string
+
+	// Denied lists patterns of the module paths a binary must not
+	// depend on. A dependency violates the policy if its path or the
+	// path of a module in its chain of replacements matches any of
+	// them, so that a banned module cannot be linked in as the
+	// replacement of another.
+	Denied []string
+
+	// MinVersions maps module paths to the lowest versions of them a
+	// binary may be built with. A dependency is checked using the
+	// module it resolves to, as Module.Effective reports it, and one
+	// lacking a valid semantic version, such as one replaced by a
+	// local directory, is a violation.
+	MinVersions map[string]string
+}
+
+// A PolicyViolation reports a dependency that violates a Policy.
+type PolicyViolation struct {
+	Dep    *Module // the dependency, as listed in BuildInfo.Deps
+	Reason string  // why the dependency violates the policy
+}
+
+// A PolicyError is returned by EnforcePolicy when dependencies of the
+// binary violate the policy.
+type PolicyError struct {
+	Violations []PolicyViolation // in the order of BuildInfo.Deps
+}
+
+func (e *PolicyError) Error() string {
+	var b strings.Builder
+	b.WriteString("module policy violated")
+	for i, v := range e.Violations {
+		if i == 0 {
+			b.WriteString(": ")
+		} else {
+			b.WriteString("; ")
+		}
+		b.WriteString(v.Dep.Path)
+		if v.Dep.Version != "" {
+			b.WriteString("@" + v.Dep.Version)
+		}
+		b.WriteString(" " + v.Reason)
+	}
+	return b.String()
+}
+
+// EnforcePolicy checks the dependencies of the running binary, as
+// ReadBuildInfo returns them, against p, and returns a *PolicyError
+// listing each dependency that violates it, or nil if none does.
+// A dependency violating p in several ways is listed once for each.
+// If the binary holds no build information, as when it was not built
+// in module mode, the error is ErrNoBuildInfo.
+//
+// EnforcePolicy is meant to be called from an init function, so that a
+// binary built with a banned or outdated dependency fails at startup:
+//
+//	func init() {
+//		if err := debug.EnforcePolicy(policy); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+func EnforcePolicy(p Policy) error {
+	bi, err := ReadBuildInfoErr()
+	if err != nil {
+		return err
+	}
+	return enforcePolicy(bi, p)
+}
+
+// enforcePolicy is EnforcePolicy with the build information bi.
+func enforcePolicy(bi *BuildInfo, p Policy) error {
+	var list []PolicyViolation
+	for _, dep := range bi.Deps {
+		if dep == nil {
+			continue
+		}
+		if len(p.Allowed) > 0 && !matchAny(p.Allowed, dep.Path) {
+			list = append(list, PolicyViolation{dep, "is not allowed"})
+		}
+		for m := dep; m != nil; m = m.Replace {
+			if matchAny(p.Denied, m.Path) {
+				reason := "is denied"
+				if m != dep {
+					reason = "is replaced by denied module " + m.Path
+				}
+				list = append(list, PolicyViolation{dep, reason})
+				break
+			}
+		}
+		m := dep.effective()
+		min, ok := p.MinVersions[m.Path]
+		if !ok {
+			min, ok = p.MinVersions[dep.Path]
+		}
+		switch {
+		case !ok:
+		case !isValidSemver(m.Version):
+			list = append(list, PolicyViolation{dep, "has no version to check against minimum " + min})
+		case compareSemver(m.Version, min) < 0:
+			reason := "is older than minimum " + min
+			if m != dep {
+				reason = "resolves to " + m.Path + "@" + m.Version + ", older than minimum " + min
+			}
+			list = append(list, PolicyViolation{dep, reason})
+		}
+	}
+	if len(list) > 0 {
+		return &PolicyError{list}
+	}
+	return nil
+}
+
+// matchAny reports whether path matches any of patterns.
+func matchAny(patterns []string, path string) bool {
+	for _, pattern := range patterns {
+		if matchPattern(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"errors"
+	. "runtime/debug"
+	"testing"
+)
+
+func TestEnforcePolicy(t *testing.T) {
+	bi := &BuildInfo{
+		Main: Module{Path: "example.com/main"},
+		Deps: []*Module{
+			{Path: "golang.org/x/text", Version: "v0.3.2"},
+			{Path: "github.com/bad/lib", Version: "v1.0.0"},
+			{Path: "example.com/a", Version: "v1.0.0", Replace: &Module{Path: "github.com/bad/fork", Version: "v1.1.0"}},
+			{Path: "example.com/b", Version: "v1.0.0", Replace: &Module{Path: "../b"}},
+			{Path: "example.com/c", Version: "v1.0.0", Replace: &Module{Path: "example.com/c", Version: "v1.5.0"}},
+		},
+	}
+	if err := EnforcePolicyOn(bi, Policy{}); err != nil {
+		t.Errorf("empty policy: %v", err)
+	}
+
+	p := Policy{
+		Allowed:     []string{"golang.org/x/...", "example.com/...", "github.com/bad/lib"},
+		Denied:      []string{"github.com/bad/..."},
+		MinVersions: map[string]string{"golang.org/x/text": "v0.3.3", "example.com/b": "v1.0.0", "example.com/c": "v1.2.0"},
+	}
+	err := EnforcePolicyOn(bi, p)
+	var pe *PolicyError
+	if !errors.As(err, &pe) {
+		t.Fatalf("EnforcePolicy = %v, want *PolicyError", err)
+	}
+	want := []struct{ path, reason string }{
+		{"golang.org/x/text", "is older than minimum v0.3.3"},
+		{"github.com/bad/lib", "is denied"},
+		{"example.com/a", "is replaced by denied module github.com/bad/fork"},
+		{"example.com/b", "has no version to check against minimum v1.0.0"},
+	}
+	if len(pe.Violations) != len(want) {
+		t.Fatalf("EnforcePolicy: %v, want %d violations", err, len(want))
+	}
+	for i, v := range pe.Violations {
+		if v.Dep.Path != want[i].path || v.Reason != want[i].reason {
+			t.Errorf("violation %d = %s %q, want %s %q", i, v.Dep.Path, v.Reason, want[i].path, want[i].reason)
+		}
+	}
+	const msg = "module policy violated: golang.org/x/text@v0.3.2 is older than minimum v0.3.3; github.com/bad/lib@v1.0.0 is denied; " +
+		"example.com/a@v1.0.0 is replaced by denied module github.com/bad/fork; example.com/b@v1.0.0 has no version to check against minimum v1.0.0"
+	if err.Error() != msg {
+		t.Errorf("Error() = %q, want %q", err, msg)
+	}
+
+	err = EnforcePolicyOn(bi, Policy{Allowed: []string{"example.com/..."}})
+	if !errors.As(err, &pe) || len(pe.Violations) != 2 || pe.Violations[1].Reason != "is not allowed" {
+		t.Errorf("Allowed policy: %v", err)
+	}
+
+	err = EnforcePolicyOn(bi, Policy{MinVersions: map[string]string{"example.com/c": "v2.0.0"}})
+	if err == nil || err.Error() != "module policy violated: example.com/c@v1.0.0 resolves to example.com/c@v1.5.0, older than minimum v2.0.0" {
+		t.Errorf("replaced minimum: %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import "strings"
+
+// PURL returns the package URL (purl) identifying m, as defined by
+// https://github.com/package-url/purl-spec for the golang type:
+// pkg:golang/ followed by the module path and, if m has a version,
+// @ and the version, such as pkg:golang/golang.org/x/text@v0.3.3.
+// Characters not allowed in a purl are percent-encoded. PURL describes
+// m itself, not its replacement.
+func (m Module) PURL() string {
+	var b strings.Builder
+	b.WriteString("pkg:golang/")
+	for i, elem := range strings.Split(m.Path, "/") {
+		if i > 0 {
+			b.WriteByte('/')
+		}
+		purlEscape(&b, elem)
+	}
+	if m.Version != "" {
+		b.WriteByte('@')
+		purlEscape(&b, m.Version)
+	}
+	return b.String()
+}
+
+// purlEscape writes s to b, percent-encoding all but the
+// unreserved characters of RFC 3986.
+func purlEscape(b *strings.Builder, s string) {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("-._~", c) >= 0 {
+			b.WriteByte(c)
+			continue
+		}
+		const upperHex = "0123456789ABCDEF"
+		b.WriteByte('%')
+		b.WriteByte(upperHex[c>>4])
+		b.WriteByte(upperHex[c&0xF])
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// ReadBuildInfoAuto reads build information from r in any of the forms
+// in which it is commonly exchanged, detecting the form from the input:
+// the JSON written by BuildInfo.MarshalJSON; the tab-separated text
+// written by BuildInfo.MarshalText, with or without the framing
+// sentinels of the blob embedded in executables; and the indented output
+// of "go version -m" for a single executable, whose first line,
+// "file: go-version", gives the Go version.
+func ReadBuildInfoAuto(r io.Reader) (*BuildInfo, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data := string(b)
+	trimmed := strings.TrimLeft(data, " \t\r\n")
+	switch {
+	case strings.HasPrefix(data, infoStart):
+		if err := validateBlob(data); err != nil {
+			return nil, err
+		}
+		return parseBuildInfo(data[len(infoStart) : len(data)-len(infoEnd)])
+	case strings.HasPrefix(trimmed, "{"):
+		return parseBuildInfoJSON(data)
+	case isTextLine(data):
+		return parseBuildInfo(data)
+	}
+	if i := strings.IndexByte(data, '\n'); i >= 0 && isTextLine(strings.TrimPrefix(data[i+1:], "\t")) {
+		// go version -m output: a "file: version" header line
+		// followed by the text form indented by a tab.
+		header, rest := data[:i], data[i+1:]
+		j := strings.LastIndex(header, ": ")
+		if j < 0 {
+			return nil, errUnknownFormat
+		}
+		lines := strings.SplitAfter(rest, "\n")
+		for k, line := range lines {
+			if line != "" && !strings.HasPrefix(line, "\t") {
+				return nil, errBadLine(strings.TrimSuffix(line, "\n"))
+			}
+			lines[k] = strings.TrimPrefix(line, "\t")
+		}
+		bi, err := parseBuildInfo(strings.Join(lines, ""))
+		if err != nil {
+			return nil, err
+		}
+		bi.GoVersion = strings.TrimSpace(header[j+len(": "):])
+		return bi, nil
+	}
+	return nil, errUnknownFormat
+}
+
+var errUnknownFormat = errors.New("cannot determine the format of the build information")
+
+// isTextLine reports whether data begins with a line of the text form.
+func isTextLine(data string) bool {
+	for _, prefix := range []string{formatLine, goLine, pathLine, modLine, depLine, repLine, buildLine} {
+		if strings.HasPrefix(data, prefix) {
+			return true
+		}
+	}
+	return false
+}
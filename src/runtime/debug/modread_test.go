@@ -0,0 +1,94 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"reflect"
+	. "runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestReadBuildInfoAuto(t *testing.T) {
+	want, ok := ReadBuildInfoData(blob(testModinfo))
+	if !ok {
+		t.Fatal("ReadBuildInfo failed")
+	}
+	want.GoVersion = "go1.15"
+	js, err := want.MarshalJSONIndent("", "\t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := want.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	versionM := "/usr/local/bin/hello: go1.15\n\t" +
+		strings.Replace(strings.TrimSuffix(testModinfo, "\n"), "\n", "\n\t", -1) + "\n"
+
+	for _, tt := range []struct {
+		name, input string
+		goVersion   string
+	}{
+		{"json", string(js), "go1.15"},
+		{"text", string(text), "go1.15"},
+		{"blob", blob(testModinfo), ""},
+		{"go version -m", versionM, "go1.15"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadBuildInfoAuto(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			w := *want
+			w.GoVersion = tt.goVersion
+			if !reflect.DeepEqual(got, &w) {
+				t.Errorf("ReadBuildInfoAuto =\n%+v\nwant\n%+v", got, &w)
+			}
+		})
+	}
+}
+
+func TestReadBuildInfoAutoJSON(t *testing.T) {
+	got, err := ReadBuildInfoAuto(strings.NewReader(`{
+		"goversion": "go1.15", "Path": "example.com/é😀", "Unknown": [1, -2.5e3, true, null, {}],
+		"Main": {"Path": "example.com/hello", "Version": "v1.0.0", "Replace": null},
+		"Deps": [{"Path": "rsc.io/quote", "Version": "v1.5.2", "Replace": {"Path": "../quote", "Version": ""}, "Extra": ["x\ty"]}],
+		"Settings": null
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &BuildInfo{
+		GoVersion: "go1.15",
+		Path:      "example.com/é\U0001F600",
+		Main:      Module{Path: "example.com/hello", Version: "v1.0.0"},
+		Deps: []*Module{
+			{Path: "rsc.io/quote", Version: "v1.5.2", Replace: &Module{Path: "../quote"}, Extra: []string{"x\ty"}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadBuildInfoAuto =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestReadBuildInfoAutoErrors(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"hello, world\n",
+		"hello: go1.15\n",
+		"hello: go1.15\n\tmod\texample.com/hello\tv1.0.0\nnot indented\n",
+		"mod\texample.com/hello\n",
+		`{"Path": "example.com/hello"`,
+		`{"Path": 1}`,
+		`{"Deps": [{"Path": "x"}], }`,
+		`["Path"]`,
+		`{"Path": "\x"}`,
+	} {
+		if info, err := ReadBuildInfoAuto(strings.NewReader(input)); err == nil {
+			t.Errorf("ReadBuildInfoAuto(%q) = %+v, want error", input, info)
+		}
+	}
+}
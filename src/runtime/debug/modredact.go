@@ -0,0 +1,52 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+// RedactOptions selects the information removed by BuildInfo.Redacted.
+type RedactOptions struct {
+	Sums       bool // drop the checksums of all modules
+	VCSTime    bool // drop the vcs.time build setting
+	LocalPaths bool // replace the paths of local directory replacements with "(local)"
+}
+
+// localPlaceholder is the path Redacted gives to directory replacements.
+const localPlaceholder = "(local)"
+
+// Redacted returns a copy of bi with the information selected by opts
+// removed. Checksums, commit times, and the directories of local
+// replacements vary with the environment a program is built in, as when
+// a module is fetched through a different proxy or built in a different
+// checkout, without changing what is built; comparing the redacted
+// forms of two builds, for example with MarshalTextCanonical, tells
+// whether they are logically identical. A local replacement remains
+// local when its path is redacted, as Module.IsLocalReplace depends
+// only on its version. bi itself is not modified.
+func (bi *BuildInfo) Redacted(opts RedactOptions) *BuildInfo {
+	c := bi.clone()
+	redact := func(m *Module) {
+		for ; m != nil; m = m.Replace {
+			if opts.Sums {
+				m.Sum = ""
+			}
+			if opts.LocalPaths && m.IsLocalReplace() {
+				m.Replace.Path = localPlaceholder
+			}
+		}
+	}
+	redact(&c.Main)
+	for _, dep := range c.Deps {
+		redact(dep)
+	}
+	if opts.VCSTime {
+		settings := c.Settings[:0]
+		for _, s := range c.Settings {
+			if s.Key != vcsTimeKey {
+				settings = append(settings, s)
+			}
+		}
+		c.Settings = settings
+	}
+	return c
+}
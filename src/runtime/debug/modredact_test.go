@@ -0,0 +1,57 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"reflect"
+	. "runtime/debug"
+	"testing"
+)
+
+func TestRedacted(t *testing.T) {
+	build := func(sum, dir, time string) *BuildInfo {
+		return &BuildInfo{
+			Path: "example.com/cmd/hello",
+			Main: Module{Path: "example.com/hello", Version: "v1.0.0", Sum: sum},
+			Deps: []*Module{
+				{Path: "rsc.io/quote", Version: "v1.5.2", Sum: sum},
+				{Path: "example.com/local", Version: "v0.0.0-00010101000000-000000000000", Replace: &Module{Path: dir}},
+				nil,
+			},
+			Settings: []BuildSetting{
+				{Key: "vcs.revision", Value: "abcdef"},
+				{Key: "vcs.time", Value: time},
+			},
+		}
+	}
+	a := build("h1:c0ffee=", "../local", "2020-01-01T00:00:00Z")
+	b := build("h1:beef=", "/home/gopher/local", "2020-02-02T00:00:00Z")
+	orig := a.Clone()
+
+	all := RedactOptions{Sums: true, VCSTime: true, LocalPaths: true}
+	ra, rb := a.Redacted(all), b.Redacted(all)
+	if !reflect.DeepEqual(ra, rb) {
+		t.Errorf("redacted builds differ:\n%s\n%s", ra, rb)
+	}
+	if !reflect.DeepEqual(a, orig) {
+		t.Errorf("Redacted modified its receiver")
+	}
+	if ra.Deps[0].Sum != "" || ra.Main.Sum != "" {
+		t.Errorf("Redacted kept sums: %s", ra)
+	}
+	if m := ra.Deps[1]; !m.IsLocalReplace() || m.Replace.Path != "(local)" {
+		t.Errorf("redacted local replacement = %+v, want local path (local)", m.Replace)
+	}
+	if _, ok := ra.Setting("vcs.time"); ok {
+		t.Errorf("Redacted kept vcs.time")
+	}
+	if v, _ := ra.Setting("vcs.revision"); v != "abcdef" {
+		t.Errorf("Redacted dropped vcs.revision")
+	}
+
+	if r := a.Redacted(RedactOptions{Sums: true}); !reflect.DeepEqual(r.Settings, a.Settings) || r.Deps[1].Replace.Path != "../local" {
+		t.Errorf("Redacted(Sums) removed more than sums: %s", r)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+// Kinds of replacement reported by Module.ReplacementKind.
+const (
+	ReplaceLocal   = "local"   // replaced by a directory
+	ReplaceVersion = "version" // replaced by another version of the same module
+	ReplaceModule  = "module"  // replaced by a different module
+)
+
+// IsLocalReplace reports whether m is replaced by a directory in the
+// local file system, which cmd/go records as a replacement without
+// a version.
+func (m *Module) IsLocalReplace() bool {
+	return m.Replace != nil && m.Replace.Version == ""
+}
+
+// ReplacementKind describes how m is replaced: ReplaceLocal for a
+// directory, ReplaceVersion for another version of the same module
+// path, or ReplaceModule for a module with a different path.
+// It returns the empty string if m is not replaced.
+func (m *Module) ReplacementKind() string {
+	switch {
+	case m.Replace == nil:
+		return ""
+	case m.IsLocalReplace():
+		return ReplaceLocal
+	case m.Replace.Path == m.Path:
+		return ReplaceVersion
+	}
+	return ReplaceModule
+}
+
+// Effective returns the module actually used for m: the last module in
+// its chain of replacements, or m itself if it is not replaced, with no
+// Replace field. Its path, version, and checksum are those of the code
+// linked into the binary. The result shares no memory with m.
+func (m Module) Effective() Module {
+	return *cloneModule(m.effective())
+}
+
+// effective returns the last module in m's chain of replacements.
+func (m *Module) effective() *Module {
+	for m.Replace != nil {
+		m = m.Replace
+	}
+	return m
+}
+
+// EffectiveDeps returns the modules actually used for the dependencies
+// of bi, as Module.Effective reports them, in the order of bi.Deps.
+// Nil entries in Deps are skipped.
+func (bi *BuildInfo) EffectiveDeps() []Module {
+	deps := make([]Module, 0, len(bi.Deps))
+	for _, dep := range bi.Deps {
+		if dep != nil {
+			deps = append(deps, dep.Effective())
+		}
+	}
+	return deps
+}
+
+// A ReplaceEntry describes one replaced module.
+type ReplaceEntry struct {
+	From  Module // the replaced module, without its Replace field
+	To    Module // the replacement
+	Kind  string // as reported by Module.ReplacementKind
+	Local bool   // as reported by Module.IsLocalReplace
+	Main  bool   // whether From is the main module
+}
+
+// ReplaceReport returns an entry for each replaced module in bi,
+// starting with the main module, if it is replaced, followed by the
+// dependencies in order.
+func (bi *BuildInfo) ReplaceReport() []ReplaceEntry {
+	var report []ReplaceEntry
+	add := func(m *Module, main bool) {
+		if m == nil || m.Replace == nil {
+			return
+		}
+		from := *m
+		from.Replace = nil
+		report = append(report, ReplaceEntry{
+			From:  from,
+			To:    *m.Replace,
+			Kind:  m.ReplacementKind(),
+			Local: m.IsLocalReplace(),
+			Main:  main,
+		})
+	}
+	add(&bi.Main, true)
+	for _, dep := range bi.Deps {
+		add(dep, false)
+	}
+	return report
+}
@@ -0,0 +1,87 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"reflect"
+	. "runtime/debug"
+	"testing"
+)
+
+func TestReplaceReport(t *testing.T) {
+	info := &BuildInfo{
+		Main: Module{Path: "example.com/hello", Version: "v1.0.0", Replace: &Module{Path: "../hello"}},
+		Deps: []*Module{
+			{Path: "golang.org/x/text", Version: "v0.3.3", Sum: "h1:cafe="},
+			{Path: "rsc.io/quote", Version: "v1.5.2", Replace: &Module{Path: "rsc.io/quote", Version: "v1.0.0", Sum: "h1:beef="}},
+			{Path: "rsc.io/sampler", Version: "v1.3.0", Replace: &Module{Path: "example.com/sampler", Version: "v1.3.1"}},
+			nil,
+			{Path: "example.com/local", Version: "v0.0.0-00010101000000-000000000000", Replace: &Module{Path: "./local"}},
+		},
+	}
+	want := []ReplaceEntry{
+		{
+			From: Module{Path: "example.com/hello", Version: "v1.0.0"},
+			To:   Module{Path: "../hello"},
+			Kind: ReplaceLocal, Local: true, Main: true,
+		},
+		{
+			From: Module{Path: "rsc.io/quote", Version: "v1.5.2"},
+			To:   Module{Path: "rsc.io/quote", Version: "v1.0.0", Sum: "h1:beef="},
+			Kind: ReplaceVersion,
+		},
+		{
+			From: Module{Path: "rsc.io/sampler", Version: "v1.3.0"},
+			To:   Module{Path: "example.com/sampler", Version: "v1.3.1"},
+			Kind: ReplaceModule,
+		},
+		{
+			From: Module{Path: "example.com/local", Version: "v0.0.0-00010101000000-000000000000"},
+			To:   Module{Path: "./local"},
+			Kind: ReplaceLocal, Local: true,
+		},
+	}
+	if got := info.ReplaceReport(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ReplaceReport() =\n%+v\nwant\n%+v", got, want)
+	}
+	if info.Main.Replace == nil || info.Deps[1].Replace == nil {
+		t.Error("ReplaceReport modified its receiver")
+	}
+	if kind := info.Deps[0].ReplacementKind(); kind != "" || info.Deps[0].IsLocalReplace() {
+		t.Errorf("unreplaced module: ReplacementKind() = %q, IsLocalReplace() = %v", kind, info.Deps[0].IsLocalReplace())
+	}
+}
+
+func TestEffective(t *testing.T) {
+	chain := Module{
+		Path: "example.com/a", Version: "v1.0.0", Sum: "h1:a=",
+		Replace: &Module{
+			Path: "example.com/b", Version: "v1.1.0", Sum: "h1:b=",
+			Replace: &Module{Path: "example.com/c", Version: "v1.2.0", Sum: "h1:c=", Extra: []string{"x"}},
+		},
+	}
+	e := chain.Effective()
+	want := Module{Path: "example.com/c", Version: "v1.2.0", Sum: "h1:c=", Extra: []string{"x"}}
+	if !reflect.DeepEqual(e, want) {
+		t.Errorf("Effective() = %+v, want %+v", e, want)
+	}
+	e.Extra[0] = "y"
+	if chain.Replace.Replace.Extra[0] != "x" {
+		t.Errorf("modifying Effective() result changed the chain")
+	}
+	plain := Module{Path: "example.com/d", Version: "v1.0.0"}
+	if got := plain.Effective(); !reflect.DeepEqual(got, plain) {
+		t.Errorf("Effective() of unreplaced module = %+v, want %+v", got, plain)
+	}
+
+	bi := &BuildInfo{Deps: []*Module{&chain, nil, &plain}}
+	if got := bi.EffectiveDeps(); !reflect.DeepEqual(got, []Module{want, plain}) {
+		t.Errorf("EffectiveDeps() = %+v", got)
+	}
+	// Consumers of the resolved module follow the whole chain.
+	if paths, versions, _ := bi.Columns(); paths[0] != "example.com/c" || versions[0] != "v1.2.0" {
+		t.Errorf("Columns() = %q, %q", paths, versions)
+	}
+}
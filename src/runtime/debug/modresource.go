@@ -0,0 +1,66 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+// resourceDepPrefix is the prefix of the resource attributes holding
+// the versions of dependencies.
+const resourceDepPrefix = "go.dependency."
+
+// ResourceAttributes returns attributes describing the build recorded
+// by bi, for stamping the telemetry of a service, such as the resource
+// of an OpenTelemetry tracer or meter provider. The keys follow the
+// OpenTelemetry semantic conventions where they define one:
+//
+//	service.version         the version of the main module
+//	process.runtime.name    "go"
+//	process.runtime.version the version of Go that built the binary
+//	vcs.ref.head.revision   the revision of the checkout it was built from
+//	go.module.path          the path of the main module
+//	go.dependency.<path>    the version of the dependency with that path
+//
+// Attributes with no value, such as the revision of a binary built
+// outside version control, are left out.
+//
+// The dependencies are the modules the main module requires directly
+// if a requirement graph has been recorded by SetModGraph, and all of
+// bi.Deps otherwise, as build information does not say which are
+// direct. The version of a replaced dependency is that of the module
+// replacing it, or, for a local directory, the required version and
+// the directory, as in "v1.2.3 => ../fork".
+func (bi *BuildInfo) ResourceAttributes() map[string]string {
+	attrs := make(map[string]string)
+	set := func(key, value string) {
+		if value != "" {
+			attrs[key] = value
+		}
+	}
+	set("service.version", bi.Main.Version)
+	set("process.runtime.name", "go")
+	set("process.runtime.version", bi.GoVersion)
+	rev, _ := bi.Revision()
+	set("vcs.ref.head.revision", rev)
+	set("go.module.path", bi.Main.Path)
+
+	var direct map[string]bool
+	if bi.graph != nil {
+		direct = make(map[string]bool)
+		for _, to := range bi.graph[bi.Main.Path] {
+			direct[nodePath(to)] = true
+		}
+	}
+	for _, dep := range bi.Deps {
+		if dep == nil || direct != nil && !direct[dep.Path] {
+			continue
+		}
+		v := dep.Version
+		if m := dep.effective(); m.Version != "" {
+			v = m.Version
+		} else if m != dep {
+			v += " => " + m.Path
+		}
+		set(resourceDepPrefix+dep.Path, v)
+	}
+	return attrs
+}
@@ -0,0 +1,51 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"reflect"
+	. "runtime/debug"
+	"testing"
+)
+
+func TestResourceAttributes(t *testing.T) {
+	bi := &BuildInfo{
+		GoVersion: "go1.15",
+		Main:      Module{Path: "example.com/hello", Version: "v1.0.0"},
+		Deps: []*Module{
+			{Path: "golang.org/x/text", Version: "v0.3.3"},
+			{Path: "rsc.io/quote", Version: "v1.5.2", Replace: &Module{Path: "example.com/quote", Version: "v1.5.3"}},
+			{Path: "rsc.io/sampler", Version: "v1.3.0", Replace: &Module{Path: "../sampler"}},
+		},
+		Settings: []BuildSetting{{Key: "vcs.revision", Value: "0123456789abcdef"}},
+	}
+	want := map[string]string{
+		"service.version":                 "v1.0.0",
+		"process.runtime.name":            "go",
+		"process.runtime.version":         "go1.15",
+		"vcs.ref.head.revision":           "0123456789abcdef",
+		"go.module.path":                  "example.com/hello",
+		"go.dependency.golang.org/x/text": "v0.3.3",
+		"go.dependency.rsc.io/quote":      "v1.5.3",
+		"go.dependency.rsc.io/sampler":    "v1.3.0 => ../sampler",
+	}
+	if got := bi.ResourceAttributes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ResourceAttributes() = %v, want %v", got, want)
+	}
+
+	// With a requirement graph, only direct dependencies are listed.
+	if err := bi.SetModGraph([]byte(testModGraph)); err != nil {
+		t.Fatal(err)
+	}
+	delete(want, "go.dependency.rsc.io/sampler")
+	if got := bi.ResourceAttributes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ResourceAttributes() with graph = %v, want %v", got, want)
+	}
+
+	got := (&BuildInfo{}).ResourceAttributes()
+	if want := map[string]string{"process.runtime.name": "go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ResourceAttributes() of empty BuildInfo = %v, want %v", got, want)
+	}
+}
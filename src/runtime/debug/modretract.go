@@ -0,0 +1,86 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+// A ModuleMetadataSource supplies what the latest go.mod file of a
+// module says about the module's versions, such as a client of a module
+// proxy supplied by the caller. This package makes no network requests
+// itself.
+type ModuleMetadataSource interface {
+	// Metadata returns the metadata of the module with the given path.
+	// A source that has none, or cannot obtain it, returns the zero
+	// ModuleMetadata.
+	Metadata(path string) ModuleMetadata
+}
+
+// ModuleMetadata is the metadata of a module that the author publishes
+// in the go.mod file of its latest version.
+type ModuleMetadata struct {
+	Retractions []Retraction // from retract directives
+	Deprecated  string       // the text of a "Deprecated:" comment, if any
+}
+
+// A Retraction is a closed interval of retracted versions, from Low to
+// High inclusive, both semantic versions, as given by a retract
+// directive; a single retracted version has Low equal to High.
+type Retraction struct {
+	Low       string
+	High      string
+	Rationale string // the comment explaining the retraction, if any
+}
+
+// A RetractedUse reports a dependency built at a retracted version or
+// from a deprecated module.
+type RetractedUse struct {
+	Dep        *Module     // the dependency, as listed in BuildInfo.Deps
+	Version    string      // the version checked, that of the resolved module
+	Retraction *Retraction // the retraction of the version, or nil
+	Deprecated string      // the module's deprecation notice, or ""
+}
+
+// CheckRetractions looks up each dependency of bi in src and returns
+// those built at a retracted version or from a deprecated module.
+// As with Scan, each dependency is looked up by the module it resolves
+// to, dependencies without a valid semantic version are skipped, and
+// retractions with invalid bounds are ignored. Each module is looked up
+// once, however many dependencies resolve to it. The uses are listed
+// in the order of bi.Deps.
+func (bi *BuildInfo) CheckRetractions(src ModuleMetadataSource) []RetractedUse {
+	cache := make(map[string]ModuleMetadata)
+	var uses []RetractedUse
+	for _, dep := range bi.Deps {
+		if dep == nil {
+			continue
+		}
+		m := dep.effective()
+		if !isValidSemver(m.Version) {
+			continue
+		}
+		md, ok := cache[m.Path]
+		if !ok {
+			md = src.Metadata(m.Path)
+			cache[m.Path] = md
+		}
+		u := RetractedUse{Dep: dep, Version: m.Version, Deprecated: md.Deprecated}
+		for i := range md.Retractions {
+			if md.Retractions[i].covers(m.Version) {
+				u.Retraction = &md.Retractions[i]
+				break
+			}
+		}
+		if u.Retraction != nil || u.Deprecated != "" {
+			uses = append(uses, u)
+		}
+	}
+	return uses
+}
+
+// covers reports whether r retracts version v.
+func (r *Retraction) covers(v string) bool {
+	if !isValidSemver(r.Low) || !isValidSemver(r.High) {
+		return false
+	}
+	return compareSemver(r.Low, v) <= 0 && compareSemver(v, r.High) <= 0
+}
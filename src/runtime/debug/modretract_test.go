@@ -0,0 +1,64 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"reflect"
+	. "runtime/debug"
+	"testing"
+)
+
+type testMetadataSource struct {
+	md      map[string]ModuleMetadata
+	lookups map[string]int
+}
+
+func (s *testMetadataSource) Metadata(path string) ModuleMetadata {
+	s.lookups[path]++
+	return s.md[path]
+}
+
+func TestCheckRetractions(t *testing.T) {
+	bi := &BuildInfo{
+		Deps: []*Module{
+			{Path: "example.com/a", Version: "v1.0.0"},
+			{Path: "example.com/b", Version: "v1.2.0"},
+			{Path: "example.com/c", Version: "v1.0.0", Replace: &Module{Path: "example.com/a", Version: "v1.5.0"}},
+			{Path: "example.com/d", Version: "v1.0.0", Replace: &Module{Path: "../d"}},
+			{Path: "example.com/e", Version: "v0.1.0"},
+			nil,
+		},
+	}
+	src := &testMetadataSource{
+		md: map[string]ModuleMetadata{
+			"example.com/a": {Retractions: []Retraction{
+				{Low: "v1.0.0", High: "v1.0.0", Rationale: "published by mistake"},
+				{Low: "v1.4.0", High: "v1.6.0"},
+			}},
+			"example.com/b": {Retractions: []Retraction{{Low: "v1.0.0", High: "v1.1.0"}}},
+			"example.com/d": {Deprecated: "use example.com/d/v2"},
+			"example.com/e": {
+				Retractions: []Retraction{{Low: "bad", High: "v1.0.0"}},
+				Deprecated:  "use example.com/f",
+			},
+		},
+		lookups: make(map[string]int),
+	}
+	uses := bi.CheckRetractions(src)
+	want := []RetractedUse{
+		{Dep: bi.Deps[0], Version: "v1.0.0", Retraction: &src.md["example.com/a"].Retractions[0]},
+		{Dep: bi.Deps[2], Version: "v1.5.0", Retraction: &src.md["example.com/a"].Retractions[1]},
+		{Dep: bi.Deps[4], Version: "v0.1.0", Deprecated: "use example.com/f"},
+	}
+	if !reflect.DeepEqual(uses, want) {
+		t.Errorf("CheckRetractions = %+v, want %+v", uses, want)
+	}
+	if n := src.lookups["example.com/a"]; n != 1 {
+		t.Errorf("example.com/a looked up %d times, want once", n)
+	}
+	if n := src.lookups["example.com/d"]; n != 0 {
+		t.Errorf("local replacement looked up")
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MarshalCycloneDX returns a software bill of materials for the
+// program described by bi, as a CycloneDX 1.4 JSON document.
+// The metadata component is the main module, an application, and
+// there is a library component for each module that bi.Deps resolve
+// to, after replacement, identified by its package URL as returned by
+// Module.PURL. Dependencies replaced by local directories are omitted,
+// having no published identity. A component's h1: checksum is
+// reported as its SHA-256 hash; that is the hash of the module's file
+// hashes that go.sum records, not of the module zip file. A recorded
+// Module.License is reported as the component's license expression. The
+// document has no timestamp or serial number, so that the same build
+// information always produces the same document.
+func (bi *BuildInfo) MarshalCycloneDX() ([]byte, error) {
+	main := bi.Main.PURL()
+	b := []byte(`{"bomFormat":"CycloneDX","specVersion":"1.4","version":1,"metadata":{"tools":[{"name":"runtime/debug","version":`)
+	b = appendJSONString(b, orUnknown(bi.GoVersion))
+	b = append(b, `}],"component":`...)
+	b = bi.Main.appendCycloneDX(b, "application")
+	b = append(b, `},"components":[`...)
+	deps := bi.sbomModules()
+	for i, m := range deps {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = m.appendCycloneDX(b, "library")
+	}
+	b = append(b, `],"dependencies":[{"ref":`...)
+	b = appendJSONString(b, main)
+	b = append(b, `,"dependsOn":[`...)
+	for i, m := range deps {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = appendJSONString(b, m.PURL())
+	}
+	b = append(b, "]}]}"...)
+	return indentJSON(b, "", "  "), nil
+}
+
+func (m *Module) appendCycloneDX(b []byte, typ string) []byte {
+	b = append(b, `{"type":`...)
+	b = appendJSONString(b, typ)
+	b = append(b, `,"bom-ref":`...)
+	b = appendJSONString(b, m.PURL())
+	b = append(b, `,"name":`...)
+	b = appendJSONString(b, m.Path)
+	if m.Version != "" {
+		b = append(b, `,"version":`...)
+		b = appendJSONString(b, m.Version)
+	}
+	b = append(b, `,"purl":`...)
+	b = appendJSONString(b, m.PURL())
+	if hash, ok := sumSHA256(m.Sum); ok {
+		b = append(b, `,"hashes":[{"alg":"SHA-256","content":`...)
+		b = appendJSONString(b, hash)
+		b = append(b, "}]"...)
+	}
+	if m.License != "" {
+		b = append(b, `,"licenses":[{"expression":`...)
+		b = appendJSONString(b, m.License)
+		b = append(b, "}]"...)
+	}
+	b = append(b, '}')
+	return b
+}
+
+// MarshalSPDX returns a software bill of materials for the program
+// described by bi, as an SPDX 2.3 JSON document. The document
+// describes a package for the main module, which depends on a package
+// for each module that bi.Deps resolve to, chosen and identified as by
+// MarshalCycloneDX, with an h1: checksum reported as a SHA256 checksum
+// and a recorded Module.License as the declared license.
+// SPDX requires each document to record its creation time and to have
+// a unique namespace, which MarshalSPDX derives from the main package
+// path and the creation time.
+func (bi *BuildInfo) MarshalSPDX() ([]byte, error) {
+	now := time.Now().UTC()
+	var ns strings.Builder
+	ns.WriteString("https://spdx.org/spdxdocs/")
+	purlEscape(&ns, strings.Replace(bi.Path, "/", "-", -1))
+	ns.WriteString("-" + strconv.FormatInt(now.UnixNano(), 10))
+
+	b := []byte(`{"spdxVersion":"SPDX-2.3","dataLicense":"CC0-1.0","SPDXID":"SPDXRef-DOCUMENT","name":`)
+	b = appendJSONString(b, orUnknown(bi.Path))
+	b = append(b, `,"documentNamespace":`...)
+	b = appendJSONString(b, ns.String())
+	b = append(b, `,"creationInfo":{"created":`...)
+	b = appendJSONString(b, now.Format(time.RFC3339))
+	b = append(b, `,"creators":[`...)
+	b = appendJSONString(b, "Tool: runtime/debug-"+orUnknown(bi.GoVersion))
+	b = append(b, `]},"packages":[`...)
+	b = bi.Main.appendSPDX(b, spdxID(0))
+	deps := bi.sbomModules()
+	for i, m := range deps {
+		b = append(b, ',')
+		b = m.appendSPDX(b, spdxID(i+1))
+	}
+	b = append(b, `],"relationships":[{"spdxElementId":"SPDXRef-DOCUMENT","relationshipType":"DESCRIBES","relatedSpdxElement":`...)
+	b = appendJSONString(b, spdxID(0))
+	b = append(b, '}')
+	for i := range deps {
+		b = append(b, `,{"spdxElementId":`...)
+		b = appendJSONString(b, spdxID(0))
+		b = append(b, `,"relationshipType":"DEPENDS_ON","relatedSpdxElement":`...)
+		b = appendJSONString(b, spdxID(i+1))
+		b = append(b, '}')
+	}
+	b = append(b, "]}"...)
+	return indentJSON(b, "", "  "), nil
+}
+
+// spdxID returns the SPDX identifier of the i'th package of a document.
+func spdxID(i int) string {
+	return "SPDXRef-Package-" + strconv.Itoa(i)
+}
+
+func (m *Module) appendSPDX(b []byte, id string) []byte {
+	b = append(b, `{"name":`...)
+	b = appendJSONString(b, m.Path)
+	b = append(b, `,"SPDXID":`...)
+	b = appendJSONString(b, id)
+	if m.Version != "" {
+		b = append(b, `,"versionInfo":`...)
+		b = appendJSONString(b, m.Version)
+	}
+	b = append(b, `,"downloadLocation":"NOASSERTION","filesAnalyzed":false`...)
+	if m.License != "" {
+		b = append(b, `,"licenseDeclared":`...)
+		b = appendJSONString(b, m.License)
+	}
+	if hash, ok := sumSHA256(m.Sum); ok {
+		b = append(b, `,"checksums":[{"algorithm":"SHA256","checksumValue":`...)
+		b = appendJSONString(b, hash)
+		b = append(b, "}]"...)
+	}
+	b = append(b, `,"externalRefs":[{"referenceCategory":"PACKAGE-MANAGER","referenceType":"purl","referenceLocator":`...)
+	b = appendJSONString(b, m.PURL())
+	b = append(b, "}]}"...)
+	return b
+}
+
+// sbomModules returns the modules that the dependencies of bi resolve
+// to, after replacement, omitting local replacements and duplicates.
+func (bi *BuildInfo) sbomModules() []*Module {
+	var mods []*Module
+	seen := make(map[string]bool)
+	for _, dep := range bi.Deps {
+		if dep == nil || dep.IsLocalReplace() {
+			continue
+		}
+		m := dep.effective()
+		if key := m.Key(); !seen[key] {
+			seen[key] = true
+			mods = append(mods, m)
+		}
+	}
+	return mods
+}
+
+// sumSHA256 returns the SHA-256 hash recorded in the h1: module
+// checksum sum, in lower-case hexadecimal.
+func sumSHA256(sum string) (string, bool) {
+	if _, ok := sumIntegrity(sum); !ok {
+		return "", false
+	}
+	const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+	enc := sum[len("h1:") : len(sum)-1]
+	var hash []byte
+	var bits, n uint
+	for i := 0; i < len(enc); i++ {
+		bits = bits<<6 | uint(strings.IndexByte(alphabet, enc[i]))
+		if n += 6; n >= 8 {
+			n -= 8
+			hash = append(hash, byte(bits>>n))
+			bits &= 1<<n - 1
+		}
+	}
+	if bits != 0 {
+		// Non-canonical encoding, with stray bits in the last character.
+		return "", false
+	}
+	out := make([]byte, 0, 2*len(hash))
+	for _, c := range hash {
+		out = append(out, hexDigits[c>>4], hexDigits[c&0xF])
+	}
+	return string(out), true
+}
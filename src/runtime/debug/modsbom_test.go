@@ -0,0 +1,116 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	. "runtime/debug"
+	"strings"
+	"testing"
+)
+
+func sbomBuildInfo() (*BuildInfo, string) {
+	hash := sha256.Sum256([]byte("x/text"))
+	return &BuildInfo{
+		GoVersion: "go1.15",
+		Path:      "example.com/cmd/hello",
+		Main:      Module{Path: "example.com/hello", Version: "v1.2.3"},
+		Deps: []*Module{
+			{Path: "golang.org/x/text", Version: "v0.3.3", Sum: "h1:" + base64.StdEncoding.EncodeToString(hash[:])},
+			{Path: "rsc.io/quote", Version: "v1.5.2", Sum: "h1:beef=", Replace: &Module{Path: "example.com/quote", Version: "v1.0.0"}},
+			{Path: "example.com/local", Version: "v0.0.0", Replace: &Module{Path: "../local"}},
+		},
+	}, hex.EncodeToString(hash[:])
+}
+
+func TestMarshalCycloneDX(t *testing.T) {
+	info, hash := sbomBuildInfo()
+	data, err := info.MarshalCycloneDX()
+	if err != nil {
+		t.Fatal(err)
+	}
+	type component struct {
+		Type, Name, Version, Purl string
+		BOMRef                    string `json:"bom-ref"`
+		Hashes                    []struct{ Alg, Content string }
+	}
+	var bom struct {
+		BOMFormat, SpecVersion string
+		Metadata               struct{ Component component }
+		Components             []component
+		Dependencies           []struct {
+			Ref       string
+			DependsOn []string
+		}
+	}
+	if err := json.Unmarshal(data, &bom); err != nil {
+		t.Fatalf("MarshalCycloneDX returned invalid JSON: %v\n%s", err, data)
+	}
+	if bom.BOMFormat != "CycloneDX" || bom.SpecVersion != "1.4" {
+		t.Errorf("bomFormat, specVersion = %q, %q", bom.BOMFormat, bom.SpecVersion)
+	}
+	if c := bom.Metadata.Component; c.Type != "application" || c.Purl != "pkg:golang/example.com/hello@v1.2.3" {
+		t.Errorf("metadata component = %+v", c)
+	}
+	if len(bom.Components) != 2 {
+		t.Fatalf("components = %+v, want x/text and the quote replacement", bom.Components)
+	}
+	text, quote := bom.Components[0], bom.Components[1]
+	if text.Purl != "pkg:golang/golang.org/x/text@v0.3.3" || text.BOMRef != text.Purl ||
+		len(text.Hashes) != 1 || text.Hashes[0].Alg != "SHA-256" || text.Hashes[0].Content != hash {
+		t.Errorf("x/text component = %+v, want SHA-256 %s", text, hash)
+	}
+	if quote.Name != "example.com/quote" || quote.Version != "v1.0.0" || len(quote.Hashes) != 0 {
+		t.Errorf("quote component = %+v", quote)
+	}
+	if len(bom.Dependencies) != 1 || len(bom.Dependencies[0].DependsOn) != 2 || bom.Dependencies[0].Ref != bom.Metadata.Component.BOMRef {
+		t.Errorf("dependencies = %+v", bom.Dependencies)
+	}
+	if again, _ := info.MarshalCycloneDX(); string(again) != string(data) {
+		t.Error("MarshalCycloneDX is not deterministic")
+	}
+}
+
+func TestMarshalSPDX(t *testing.T) {
+	info, hash := sbomBuildInfo()
+	data, err := info.MarshalSPDX()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		SPDXVersion, SPDXID, DocumentNamespace string
+		CreationInfo                           struct{ Created string }
+		Packages                               []struct {
+			Name, SPDXID, VersionInfo string
+			Checksums                 []struct{ Algorithm, ChecksumValue string }
+			ExternalRefs              []struct{ ReferenceType, ReferenceLocator string }
+		}
+		Relationships []struct{ SPDXElementID, RelationshipType, RelatedSPDXElement string }
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("MarshalSPDX returned invalid JSON: %v\n%s", err, data)
+	}
+	if doc.SPDXVersion != "SPDX-2.3" || doc.SPDXID != "SPDXRef-DOCUMENT" || doc.CreationInfo.Created == "" ||
+		!strings.HasPrefix(doc.DocumentNamespace, "https://spdx.org/spdxdocs/example.com-cmd-hello-") {
+		t.Errorf("document header = %+v", doc)
+	}
+	if len(doc.Packages) != 3 {
+		t.Fatalf("packages = %+v, want main module, x/text, and the quote replacement", doc.Packages)
+	}
+	text := doc.Packages[1]
+	if text.Name != "golang.org/x/text" || len(text.Checksums) != 1 || text.Checksums[0].Algorithm != "SHA256" || text.Checksums[0].ChecksumValue != hash {
+		t.Errorf("x/text package = %+v, want SHA256 %s", text, hash)
+	}
+	if len(text.ExternalRefs) != 1 || text.ExternalRefs[0].ReferenceType != "purl" || text.ExternalRefs[0].ReferenceLocator != "pkg:golang/golang.org/x/text@v0.3.3" {
+		t.Errorf("x/text external refs = %+v", text.ExternalRefs)
+	}
+	if len(doc.Relationships) != 3 || doc.Relationships[0].RelationshipType != "DESCRIBES" ||
+		doc.Relationships[2].RelationshipType != "DEPENDS_ON" || doc.Relationships[2].RelatedSPDXElement != doc.Packages[2].SPDXID {
+		t.Errorf("relationships = %+v", doc.Relationships)
+	}
+}
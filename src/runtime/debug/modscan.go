@@ -0,0 +1,107 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// ScanDir walks the file tree rooted at root and calls fn for each Go
+// binary found, with the binary's path and build information, so that
+// tools can audit the Go programs installed on a host. Files are
+// recognized as executables by their ELF, PE, or Mach-O magic number
+// and read concurrently by a bounded number of workers. fn is called
+// as each binary is read, in no particular order, but never
+// concurrently, so it may also report progress. Symbolic links are not
+// followed, and files and directories that cannot be read are skipped,
+// as are executables without build information.
+//
+// ScanDir stops early if ctx is done, returning ctx.Err(). Otherwise it
+// returns an error only if root itself cannot be read.
+func ScanDir(ctx context.Context, root string, fn func(path string, bi *BuildInfo)) error {
+	if _, err := os.Lstat(root); err != nil {
+		return err
+	}
+	paths := make(chan string)
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex // serializes calls to fn
+	)
+	for i := 0; i < runtime.GOMAXPROCS(0); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				if ctx.Err() != nil {
+					continue
+				}
+				if bi, ok := scanFile(path); ok {
+					mu.Lock()
+					if ctx.Err() == nil {
+						fn(path, bi)
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err != nil {
+			if fi != nil && fi.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if fi.Mode().IsRegular() {
+			select {
+			case paths <- path:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+	close(paths)
+	wg.Wait()
+	return ctx.Err()
+}
+
+// scanFile returns the build information of the file named by path,
+// if it is an executable carrying build information.
+func scanFile(path string) (*BuildInfo, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+	var magic [4]byte
+	if _, err := io.ReadFull(f, magic[:]); err != nil || !isExecutableMagic(magic[:]) {
+		return nil, false
+	}
+	bi, err := ReadBuildInfoFrom(f)
+	return bi, err == nil
+}
+
+// isExecutableMagic reports whether magic, the first four bytes of a
+// file, is that of an ELF, PE, or Mach-O file, including a universal
+// Mach-O file.
+func isExecutableMagic(magic []byte) bool {
+	switch string(magic) {
+	case "\x7fELF",
+		"\xfe\xed\xfa\xce", "\xce\xfa\xed\xfe", // 32-bit Mach-O
+		"\xfe\xed\xfa\xcf", "\xcf\xfa\xed\xfe", // 64-bit Mach-O
+		"\xca\xfe\xba\xbe": // universal Mach-O
+		return true
+	}
+	return string(magic[:2]) == "MZ" // PE, behind its MS-DOS stub
+}
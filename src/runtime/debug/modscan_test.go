@@ -0,0 +1,64 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	. "runtime/debug"
+	"sort"
+	"testing"
+)
+
+func TestScanDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scandir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(name, data string) {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, []byte(data), 0777); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("bin/elf", "\x7fELF"+blob(testModinfo))
+	write("bin/sub/macho", "\xcf\xfa\xed\xfe"+blob(testModinfo))
+	write("bin/sub/pe.exe", "MZ\x90\x00"+blob(testModinfo))
+	write("bin/c", "\x7fELF not a Go binary")
+	write("doc/modinfo.txt", "text "+blob(testModinfo))
+	write("empty", "")
+
+	var got []string
+	err = ScanDir(context.Background(), dir, func(path string, bi *BuildInfo) {
+		if bi.Main.Path != "example.com/hello" {
+			t.Errorf("%s: Main = %+v", path, bi.Main)
+		}
+		rel, _ := filepath.Rel(dir, path)
+		got = append(got, filepath.ToSlash(rel))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(got)
+	if want := []string{"bin/elf", "bin/sub/macho", "bin/sub/pe.exe"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ScanDir found %q, want %q", got, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := ScanDir(ctx, dir, func(string, *BuildInfo) { t.Error("fn called after cancellation") }); err != context.Canceled {
+		t.Errorf("ScanDir with canceled context = %v, want %v", err, context.Canceled)
+	}
+	if err := ScanDir(context.Background(), filepath.Join(dir, "missing"), nil); err == nil {
+		t.Error("ScanDir of missing root succeeded")
+	}
+}
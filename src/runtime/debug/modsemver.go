@@ -0,0 +1,70 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"errors"
+	"strconv"
+)
+
+// UpgradeSafety classifies the change from the version of m to the
+// version to by the semantic versioning rules, returning "major",
+// "minor", or "patch" for a change in the major, minor, or only the
+// patch or prerelease version. Since the versions of a v0 module make
+// no compatibility promises, a change in the minor version of a v0
+// module is classified as "major". A move to a different major version,
+// as from v1.4.0 to v2.0.0+incompatible, is "major" whether or not the
+// module path carries a major version suffix such as /v2. Downgrades
+// are classified like upgrades. UpgradeSafety returns an error if
+// either version is not a valid semantic version.
+func (m Module) UpgradeSafety(to string) (string, error) {
+	from, ok := parseSemver(m.Version)
+	if !ok {
+		return "", errors.New("module " + m.Path + " has invalid version " + strconv.Quote(m.Version))
+	}
+	p, ok := parseSemver(to)
+	if !ok {
+		return "", errors.New("invalid version " + strconv.Quote(to))
+	}
+	switch {
+	case from.major != p.major:
+		return "major", nil
+	case from.minor != p.minor:
+		if from.major == "0" {
+			return "major", nil
+		}
+		return "minor", nil
+	}
+	return "patch", nil
+}
+
+// Compare returns an integer comparing the versions of m and other by
+// semantic version precedence, as the module system orders versions:
+// 0 if they are equal, -1 if m is earlier, and +1 if m is later.
+// Build metadata such as +incompatible is ignored. An invalid version
+// is earlier than every valid one, and all invalid versions are equal.
+// The module paths are not compared.
+func (m Module) Compare(other Module) int {
+	return compareSemver(m.Version, other.Version)
+}
+
+// Major returns the major version prefix of the version of m,
+// such as "v2" for v2.1.0, or the empty string if the version
+// is not a valid semantic version.
+func (m Module) Major() string {
+	p, ok := parseSemver(m.Version)
+	if !ok {
+		return ""
+	}
+	return "v" + p.major
+}
+
+// IsPrerelease reports whether the version of m is a valid semantic
+// version with a prerelease suffix, such as v1.2.0-rc.1. Pseudo-versions
+// are prereleases.
+func (m Module) IsPrerelease() bool {
+	p, ok := parseSemver(m.Version)
+	return ok && p.prerelease != ""
+}
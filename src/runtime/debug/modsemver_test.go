@@ -0,0 +1,77 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	. "runtime/debug"
+	"testing"
+)
+
+func TestUpgradeSafety(t *testing.T) {
+	for _, tt := range []struct {
+		path, from, to string
+		want           string
+	}{
+		{"example.com/m", "v1.2.3", "v1.2.4", "patch"},
+		{"example.com/m", "v1.2.3", "v1.2.4-rc.1", "patch"},
+		{"example.com/m", "v1.2.3", "v1.2.3", "patch"},
+		{"example.com/m", "v1.2.3", "v1.3.0", "minor"},
+		{"example.com/m", "v1.2.3", "v1.3.0-0.20200101000000-abcdefabcdef", "minor"},
+		{"example.com/m", "v1.2.3", "v1.1.0", "minor"},
+		{"example.com/m", "v1.2.3", "v2.0.0+incompatible", "major"},
+		{"example.com/m", "v2.0.0+incompatible", "v2.1.0+incompatible", "minor"},
+		{"example.com/m/v2", "v2.3.0", "v2.4.0", "minor"},
+		{"example.com/m/v2", "v2.3.0", "v3.0.0", "major"},
+		{"gopkg.in/yaml.v2", "v2.2.8", "v2.3.0", "minor"},
+		{"example.com/m", "v0.1.0", "v0.1.1", "patch"},
+		{"example.com/m", "v0.1.0", "v0.2.0", "major"},
+		{"example.com/m", "v0.9.0", "v1.0.0", "major"},
+	} {
+		m := Module{Path: tt.path, Version: tt.from}
+		got, err := m.UpgradeSafety(tt.to)
+		if err != nil || got != tt.want {
+			t.Errorf("%s@%s UpgradeSafety(%s) = %q, %v, want %q", tt.path, tt.from, tt.to, got, err, tt.want)
+		}
+	}
+
+	for _, tt := range [][2]string{{"v1.0.0", "1.1.0"}, {"v1.0.0", ""}, {"(devel)", "v1.0.0"}} {
+		m := Module{Path: "example.com/m", Version: tt[0]}
+		if got, err := m.UpgradeSafety(tt[1]); err == nil {
+			t.Errorf("%s UpgradeSafety(%q) = %q, want error", tt[0], tt[1], got)
+		}
+	}
+}
+
+func TestModuleSemver(t *testing.T) {
+	for _, tt := range []struct {
+		v, w  string
+		cmp   int
+		major string
+		pre   bool
+	}{
+		{"v1.4.0", "v1.4.0", 0, "v1", false},
+		{"v1.3.9", "v1.4.0", -1, "v1", false},
+		{"v1.10.0", "v1.9.0", +1, "v1", false},
+		{"v1.4.0-rc.1", "v1.4.0", -1, "v1", true},
+		{"v0.0.0-20200101000000-abcdefabcdef", "v0.1.0", -1, "v0", true},
+		{"v2.0.0+incompatible", "v2.0.0", 0, "v2", false},
+		{"(devel)", "v0.0.1", -1, "", false},
+		{"", "bad", 0, "", false},
+	} {
+		m, other := Module{Path: "example.com/m", Version: tt.v}, Module{Path: "example.com/n", Version: tt.w}
+		if got := m.Compare(other); got != tt.cmp {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.v, tt.w, got, tt.cmp)
+		}
+		if got := other.Compare(m); got != -tt.cmp {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.w, tt.v, got, -tt.cmp)
+		}
+		if got := m.Major(); got != tt.major {
+			t.Errorf("Major(%q) = %q, want %q", tt.v, got, tt.major)
+		}
+		if got := m.IsPrerelease(); got != tt.pre {
+			t.Errorf("IsPrerelease(%q) = %v, want %v", tt.v, got, tt.pre)
+		}
+	}
+}
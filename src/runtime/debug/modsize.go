@@ -0,0 +1,550 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ModuleSizes returns the number of bytes of code and read-only data in
+// the Go executable read from exe that belong to each module it was
+// built from, keyed by module path. The sizes are those of the symbols
+// in the executable's symbol table that lie in its text and read-only
+// data sections, each attributed, by the package path at the start of
+// its name, to the main module or the dependency of its build
+// information with the longest path containing the package. Symbols of
+// the standard library, the runtime included, are counted under "std",
+// and those that cannot be attributed to a package, such as the
+// linker's string and function tables and C code linked with cgo, under
+// the empty string. The module paths are those the packages are
+// imported by, whatever the modules were replaced by.
+//
+// ModuleSizes reads ELF, PE, and Mach-O files. Executables stripped of
+// their symbol tables, as by the linker's -s flag, cannot be measured,
+// and if exe holds no build information the error is ErrNoBuildInfo.
+func ModuleSizes(exe io.ReaderAt) (map[string]int64, error) {
+	bi, err := ReadBuildInfoFrom(exe)
+	if err != nil {
+		return nil, err
+	}
+	return moduleSizes(exe, bi)
+}
+
+var (
+	errSizeFormat = errors.New("unrecognized executable format")
+	errNoSymtab   = errors.New("executable has no symbol table")
+	errBadSymtab  = errors.New("malformed executable symbol table")
+)
+
+// moduleSizes is ModuleSizes with the build information bi.
+func moduleSizes(exe io.ReaderAt, bi *BuildInfo) (map[string]int64, error) {
+	syms, err := readSizedSymbols(exe)
+	if err != nil {
+		return nil, err
+	}
+	mods := []string{bi.Main.Path}
+	for _, dep := range bi.Deps {
+		mods = append(mods, dep.Path)
+	}
+	// Longest first, so that the first module found holding a
+	// package is the innermost.
+	sort.Slice(mods, func(i, j int) bool { return len(mods[i]) > len(mods[j]) })
+	sizes := make(map[string]int64)
+	for _, s := range syms {
+		sizes[symbolModule(s.name, bi.Main.Path, mods)] += int64(s.size)
+	}
+	return sizes, nil
+}
+
+// symbolModule returns the key under which ModuleSizes counts the
+// symbol name: the first of mods, which are sorted longest first,
+// holding its package; main, for package main; "std" for the standard
+// library; or "".
+func symbolModule(name, main string, mods []string) string {
+	pkg := symbolPackage(name)
+	switch {
+	case pkg == "":
+		return ""
+	case pkg == "main":
+		return main
+	}
+	for _, mod := range mods {
+		if mod != "" && (pkg == mod || strings.HasPrefix(pkg, mod+"/")) {
+			return mod
+		}
+	}
+	elem := pkg
+	if i := strings.Index(elem, "/"); i >= 0 {
+		elem = elem[:i]
+	}
+	if !strings.Contains(elem, ".") {
+		return "std"
+	}
+	return ""
+}
+
+// symbolPackage returns the import path of the package defining the Go
+// symbol name, or "" if name is not that of a symbol of a package.
+// Type descriptors and the functions and data derived from them, such as
+// "type.*example.com/m.T" and "type..eq.example.com/m.T", are
+// attributed to the package of the type.
+func symbolPackage(name string) string {
+	switch {
+	case strings.HasPrefix(name, "type.."):
+		i := strings.Index(name[len("type.."):], ".")
+		if i < 0 {
+			return ""
+		}
+		name = name[len("type..")+i+1:]
+	case strings.HasPrefix(name, "type."):
+		name = name[len("type."):]
+	case strings.HasPrefix(name, "go.itab."):
+		name = name[len("go.itab."):]
+	case strings.HasPrefix(name, "go."):
+		return ""
+	}
+	name = strings.TrimLeft(name, "*[]0123456789")
+	// The last element of the path has its dots escaped, so the
+	// package path ends at the first dot after the last slash
+	// before any receiver, type arguments, or the like.
+	path := name
+	if i := strings.IndexAny(path, "([{ "); i >= 0 {
+		path = path[:i]
+	}
+	i := strings.LastIndex(path, "/")
+	j := strings.Index(path[i+1:], ".")
+	if j <= 0 {
+		return ""
+	}
+	return unescapeSymbol(path[:i+1+j])
+}
+
+// unescapeSymbol undoes the %xx escaping the linker applies to the
+// package paths in symbol names.
+func unescapeSymbol(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) {
+			if hi, lo := unhex(s[i+1]), unhex(s[i+2]); hi >= 0 && lo >= 0 {
+				b = append(b, byte(hi<<4|lo))
+				i += 2
+				continue
+			}
+		}
+		b = append(b, s[i])
+	}
+	return string(b)
+}
+
+func unhex(c byte) int {
+	switch {
+	case '0' <= c && c <= '9':
+		return int(c - '0')
+	case 'a' <= c && c <= 'f':
+		return int(c - 'a' + 10)
+	case 'A' <= c && c <= 'F':
+		return int(c - 'A' + 10)
+	}
+	return -1
+}
+
+// A sizedSymbol is a symbol in a text or read-only data section of an
+// executable.
+type sizedSymbol struct {
+	name string
+	addr uint64
+	size uint64
+	sect int // index of the section, per the object file format
+}
+
+// readSizedSymbols returns the symbols in the text and read-only data
+// sections of the executable read from r.
+func readSizedSymbols(r io.ReaderAt) ([]sizedSymbol, error) {
+	var ident [16]byte
+	if _, err := r.ReadAt(ident[:], 0); err != nil {
+		if err == io.EOF {
+			err = errSizeFormat
+		}
+		return nil, err
+	}
+	switch {
+	case string(ident[:4]) == "\x7fELF":
+		return readELFSymbols(r, ident)
+	case string(ident[:2]) == "MZ":
+		return readPESymbols(r)
+	}
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		switch order.Uint32(ident[:]) {
+		case 0xfeedface:
+			return readMachoSymbols(r, order, false)
+		case 0xfeedfacf:
+			return readMachoSymbols(r, order, true)
+		}
+	}
+	return nil, errSizeFormat
+}
+
+// readAt returns the n bytes of r at offset off.
+func readAt(r io.ReaderAt, off, n uint64) ([]byte, error) {
+	if n > 1<<32 || off > 1<<62 {
+		return nil, errBadSymtab
+	}
+	b := make([]byte, n)
+	if _, err := r.ReadAt(b, int64(off)); err != nil {
+		if err == io.EOF {
+			err = errBadSymtab
+		}
+		return nil, err
+	}
+	return b, nil
+}
+
+// cString returns the NUL-terminated string at the start of b.
+func cString(b []byte) string {
+	for i, c := range b {
+		if c == 0 {
+			return string(b[:i])
+		}
+	}
+	return string(b)
+}
+
+// ELF constants, from debug/elf.
+const (
+	elfSHTSymtab    = 2
+	elfSHTNobits    = 8
+	elfSHFWrite     = 0x1
+	elfSHFAlloc     = 0x2
+	elfSTTSection   = 3
+	elfSTTFile      = 4
+	elfSym32Size    = 16
+	elfSym64Size    = 24
+	elfSection32Len = 40
+	elfSection64Len = 64
+)
+
+type elfSection struct {
+	typ, link   uint32
+	flags       uint64
+	offset, len uint64
+}
+
+func readELFSymbols(r io.ReaderAt, ident [16]byte) ([]sizedSymbol, error) {
+	var order binary.ByteOrder
+	switch ident[5] {
+	case 1:
+		order = binary.LittleEndian
+	case 2:
+		order = binary.BigEndian
+	default:
+		return nil, errSizeFormat
+	}
+	is64 := ident[4] == 2
+	hdr, err := readAt(r, 0, 64)
+	if err != nil {
+		return nil, err
+	}
+	var shoff, shentsize uint64
+	var shnum uint16
+	if is64 {
+		shoff, shentsize, shnum = order.Uint64(hdr[0x28:]), uint64(order.Uint16(hdr[0x3a:])), order.Uint16(hdr[0x3c:])
+	} else {
+		shoff, shentsize, shnum = uint64(order.Uint32(hdr[0x20:])), uint64(order.Uint16(hdr[0x2e:])), order.Uint16(hdr[0x30:])
+	}
+	if is64 && shentsize < elfSection64Len || !is64 && shentsize < elfSection32Len {
+		return nil, errBadSymtab
+	}
+	shdrs, err := readAt(r, shoff, shentsize*uint64(shnum))
+	if err != nil {
+		return nil, err
+	}
+	sects := make([]elfSection, shnum)
+	var symtab *elfSection
+	for i := range sects {
+		b := shdrs[uint64(i)*shentsize:]
+		s := &sects[i]
+		s.typ = order.Uint32(b[4:])
+		if is64 {
+			s.flags, s.offset, s.len, s.link = order.Uint64(b[8:]), order.Uint64(b[24:]), order.Uint64(b[32:]), order.Uint32(b[40:])
+		} else {
+			s.flags, s.offset, s.len, s.link = uint64(order.Uint32(b[8:])), uint64(order.Uint32(b[16:])), uint64(order.Uint32(b[20:])), order.Uint32(b[24:])
+		}
+		if s.typ == elfSHTSymtab && symtab == nil {
+			symtab = s
+		}
+	}
+	if symtab == nil {
+		return nil, errNoSymtab
+	}
+	if int(symtab.link) >= len(sects) {
+		return nil, errBadSymtab
+	}
+	strtab, err := readAt(r, sects[symtab.link].offset, sects[symtab.link].len)
+	if err != nil {
+		return nil, err
+	}
+	data, err := readAt(r, symtab.offset, symtab.len)
+	if err != nil {
+		return nil, err
+	}
+	entsize := uint64(elfSym32Size)
+	if is64 {
+		entsize = elfSym64Size
+	}
+	var syms []sizedSymbol
+	for off := uint64(0); off+entsize <= uint64(len(data)); off += entsize {
+		b := data[off:]
+		var s sizedSymbol
+		var name uint32
+		var info byte
+		if is64 {
+			name, info, s.sect, s.addr, s.size = order.Uint32(b), b[4], int(order.Uint16(b[6:])), order.Uint64(b[8:]), order.Uint64(b[16:])
+		} else {
+			name, s.addr, s.size, info, s.sect = order.Uint32(b), uint64(order.Uint32(b[4:])), uint64(order.Uint32(b[8:])), b[12], int(order.Uint16(b[14:]))
+		}
+		if t := info & 0xf; t == elfSTTSection || t == elfSTTFile || s.size == 0 || s.sect == 0 || s.sect >= len(sects) {
+			continue
+		}
+		if sect := sects[s.sect]; sect.typ == elfSHTNobits || sect.flags&elfSHFAlloc == 0 || sect.flags&elfSHFWrite != 0 {
+			continue
+		}
+		if uint64(name) >= uint64(len(strtab)) {
+			return nil, errBadSymtab
+		}
+		s.name = cString(strtab[name:])
+		syms = append(syms, s)
+	}
+	return syms, nil
+}
+
+// A sizedSection is a section, in a PE or Mach-O file, whose symbols
+// are sized by the distance to the next symbol or its end.
+type sizedSection struct {
+	addr, size uint64
+}
+
+// sizeSymbols sets the size of each of syms, which have been read from
+// a symbol table that lacks sizes, to the distance to the start of the
+// next symbol in its section, or to the end of the section.
+func sizeSymbols(syms []sizedSymbol, sects map[int]sizedSection) []sizedSymbol {
+	sort.SliceStable(syms, func(i, j int) bool {
+		if syms[i].sect != syms[j].sect {
+			return syms[i].sect < syms[j].sect
+		}
+		return syms[i].addr < syms[j].addr
+	})
+	for i := range syms {
+		s := &syms[i]
+		end := sects[s.sect].addr + sects[s.sect].size
+		if i+1 < len(syms) && syms[i+1].sect == s.sect {
+			end = syms[i+1].addr
+		}
+		if end > s.addr {
+			s.size = end - s.addr
+		}
+	}
+	return syms
+}
+
+// Mach-O constants, from debug/macho.
+const (
+	machoLoadCmdSegment   = 0x1
+	machoLoadCmdSymtab    = 0x2
+	machoLoadCmdSegment64 = 0x19
+	machoVMProtWrite      = 0x2
+	machoNStab            = 0xe0
+	machoNType            = 0x0e
+	machoNSect            = 0x0e
+)
+
+func readMachoSymbols(r io.ReaderAt, order binary.ByteOrder, is64 bool) ([]sizedSymbol, error) {
+	hdr, err := readAt(r, 0, 32)
+	if err != nil {
+		return nil, err
+	}
+	ncmds, cmdsSize := order.Uint32(hdr[16:]), order.Uint32(hdr[20:])
+	hdrSize := uint64(28)
+	if is64 {
+		hdrSize = 32
+	}
+	cmds, err := readAt(r, hdrSize, uint64(cmdsSize))
+	if err != nil {
+		return nil, err
+	}
+	// Sections are numbered from 1 in the order of the load commands.
+	sects := make(map[int]sizedSection)
+	nsect := 0
+	var symoff, nsyms, stroff, strsize uint32
+	found := false
+	for i := uint32(0); i < ncmds; i++ {
+		if len(cmds) < 8 {
+			return nil, errBadSymtab
+		}
+		cmd, size := order.Uint32(cmds), order.Uint32(cmds[4:])
+		if size < 8 || uint64(size) > uint64(len(cmds)) {
+			return nil, errBadSymtab
+		}
+		b := cmds[:size]
+		cmds = cmds[size:]
+		switch cmd {
+		case machoLoadCmdSymtab:
+			if len(b) < 24 {
+				return nil, errBadSymtab
+			}
+			symoff, nsyms, stroff, strsize = order.Uint32(b[8:]), order.Uint32(b[12:]), order.Uint32(b[16:]), order.Uint32(b[20:])
+			found = true
+		case machoLoadCmdSegment, machoLoadCmdSegment64:
+			segLen, sectLen, protOff, nOff := 56, 68, 44, 48
+			if cmd == machoLoadCmdSegment64 {
+				segLen, sectLen, protOff, nOff = 72, 80, 60, 64
+			}
+			if len(b) < segLen {
+				return nil, errBadSymtab
+			}
+			readOnly := order.Uint32(b[protOff:])&machoVMProtWrite == 0
+			n := int(order.Uint32(b[nOff:]))
+			if len(b) < segLen+n*sectLen {
+				return nil, errBadSymtab
+			}
+			for j := 0; j < n; j++ {
+				nsect++
+				if !readOnly {
+					continue
+				}
+				s := b[segLen+j*sectLen:]
+				if cmd == machoLoadCmdSegment64 {
+					sects[nsect] = sizedSection{order.Uint64(s[32:]), order.Uint64(s[40:])}
+				} else {
+					sects[nsect] = sizedSection{uint64(order.Uint32(s[32:])), uint64(order.Uint32(s[36:]))}
+				}
+			}
+		}
+	}
+	if !found || nsyms == 0 {
+		return nil, errNoSymtab
+	}
+	entsize := uint64(12)
+	if is64 {
+		entsize = 16
+	}
+	data, err := readAt(r, uint64(symoff), uint64(nsyms)*entsize)
+	if err != nil {
+		return nil, err
+	}
+	strtab, err := readAt(r, uint64(stroff), uint64(strsize))
+	if err != nil {
+		return nil, err
+	}
+	var syms []sizedSymbol
+	for off := uint64(0); off < uint64(len(data)); off += entsize {
+		b := data[off:]
+		name, typ, sect := order.Uint32(b), b[4], int(b[5])
+		if typ&machoNStab != 0 || typ&machoNType != machoNSect {
+			continue
+		}
+		if _, ok := sects[sect]; !ok {
+			continue
+		}
+		if uint64(name) >= uint64(len(strtab)) {
+			return nil, errBadSymtab
+		}
+		s := sizedSymbol{name: strings.TrimPrefix(cString(strtab[name:]), "_"), sect: sect}
+		if is64 {
+			s.addr = order.Uint64(b[8:])
+		} else {
+			s.addr = uint64(order.Uint32(b[8:]))
+		}
+		syms = append(syms, s)
+	}
+	return sizeSymbols(syms, sects), nil
+}
+
+// PE constants, from debug/pe.
+const (
+	peSCNCode     = 0x00000020
+	peSCNData     = 0x00000040
+	peSCNMemWrite = 0x80000000
+	peSymbolLen   = 18
+	peSectionLen  = 40
+)
+
+func readPESymbols(r io.ReaderAt) ([]sizedSymbol, error) {
+	order := binary.LittleEndian
+	dos, err := readAt(r, 0, 64)
+	if err != nil {
+		return nil, err
+	}
+	off := uint64(order.Uint32(dos[0x3c:]))
+	hdr, err := readAt(r, off, 24)
+	if err != nil {
+		return nil, err
+	}
+	if string(hdr[:4]) != "PE\x00\x00" {
+		return nil, errSizeFormat
+	}
+	nsect := uint64(order.Uint16(hdr[6:]))
+	symPtr, nsyms := uint64(order.Uint32(hdr[12:])), uint64(order.Uint32(hdr[16:]))
+	optSize := uint64(order.Uint16(hdr[20:]))
+	shdrs, err := readAt(r, off+24+optSize, nsect*peSectionLen)
+	if err != nil {
+		return nil, err
+	}
+	// Sections are numbered from 1 in the section table, and symbol
+	// values are offsets within their sections.
+	sects := make(map[int]sizedSection)
+	for i := uint64(0); i < nsect; i++ {
+		s := shdrs[i*peSectionLen:]
+		flags := order.Uint32(s[36:])
+		if flags&(peSCNCode|peSCNData) == 0 || flags&peSCNMemWrite != 0 {
+			continue
+		}
+		size := uint64(order.Uint32(s[8:]))
+		if size == 0 {
+			size = uint64(order.Uint32(s[16:]))
+		}
+		sects[int(i)+1] = sizedSection{0, size}
+	}
+	if symPtr == 0 || nsyms == 0 {
+		return nil, errNoSymtab
+	}
+	data, err := readAt(r, symPtr, nsyms*peSymbolLen+4)
+	if err != nil {
+		return nil, err
+	}
+	strOff := nsyms * peSymbolLen
+	strtab, err := readAt(r, symPtr+strOff, uint64(order.Uint32(data[strOff:])))
+	if err != nil {
+		return nil, err
+	}
+	var syms []sizedSymbol
+	for i := uint64(0); i < nsyms; i++ {
+		b := data[i*peSymbolLen:]
+		sect := int(int16(order.Uint16(b[12:])))
+		// Skip the auxiliary records that follow.
+		i += uint64(b[17])
+		if _, ok := sects[sect]; !ok {
+			continue
+		}
+		var name string
+		if order.Uint32(b) == 0 {
+			n := uint64(order.Uint32(b[4:]))
+			if n >= uint64(len(strtab)) {
+				return nil, errBadSymtab
+			}
+			name = cString(strtab[n:])
+		} else {
+			name = cString(b[:8])
+		}
+		syms = append(syms, sizedSymbol{name: name, addr: uint64(order.Uint32(b[8:])), sect: sect})
+	}
+	return sizeSymbols(syms, sects), nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"internal/testenv"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	. "runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestSymbolModule(t *testing.T) {
+	mods := []string{"example.com/a/b", "gopkg.in/yaml.v2", "example.com/a", "example.com/main"}
+	for _, tt := range []struct {
+		sym, want string
+	}{
+		{"main.main", "example.com/main"},
+		{"type.*main.T", "example.com/main"},
+		{"example.com/main/internal/x.F", "example.com/main"},
+		{"example.com/a.F", "example.com/a"},
+		{"example.com/a/c.(*T).M", "example.com/a"},
+		{"example.com/a/b.init.0", "example.com/a/b"},
+		{"example.com/ab.F", ""},
+		{"gopkg.in/yaml%2ev2.Marshal", "gopkg.in/yaml.v2"},
+		{"type..eq.example.com/a/b/c.T", "example.com/a/b"},
+		{"go.itab.*example.com/a.T,io.Reader", "example.com/a"},
+		{"runtime.mallocgc", "std"},
+		{"vendor/golang.org/x/net/http2/hpack.(*Decoder).Write", "std"},
+		{"type.*[]encoding/json.Number", "std"},
+		{"type.int", ""},
+		{"go.string.*", ""},
+		{"runtime.pclntab", "std"},
+		{"crosscall2", ""},
+	} {
+		if got := SymbolModule(tt.sym, "example.com/main", mods); got != tt.want {
+			t.Errorf("symbolModule(%q) = %q, want %q", tt.sym, got, tt.want)
+		}
+	}
+}
+
+func TestModuleSizes(t *testing.T) {
+	testenv.MustHaveGoBuild(t)
+	switch runtime.GOOS {
+	case "aix", "plan9":
+		t.Skipf("no symbol table reader for %s executables", runtime.GOOS)
+	}
+	dir, err := ioutil.TempDir("", "modsize")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"go.mod":  "module example.com/main\n",
+		"main.go": "package main\n\nimport \"example.com/main/greet\"\n\nfunc main() { greet.Hello() }\n",
+		"greet/greet.go": "package greet\n\nimport \"fmt\"\n\ntype T struct{ A, B int }\n\n" +
+			"func Hello() { fmt.Println(\"hello\", T{1, 2}) }\n",
+	}
+	for name, text := range files {
+		name = filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, []byte(text), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	exe := filepath.Join(dir, "main.exe")
+	cmd := exec.Command(testenv.GoToolPath(t), "build", "-o", exe)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GO111MODULE=on", "GOFLAGS=", "GOPROXY=off")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go build: %v\n%s", err, out)
+	}
+
+	f, err := os.Open(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sizes, err := ModuleSizes(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sizes["example.com/main"] <= 0 || sizes["std"] <= sizes["example.com/main"] {
+		t.Errorf("ModuleSizes = %v, want sizes for example.com/main and more for std", sizes)
+	}
+	var total int64
+	for k, n := range sizes {
+		if k != "" && k != "std" && k != "example.com/main" {
+			t.Errorf("ModuleSizes has unexpected key %q", k)
+		}
+		total += n
+	}
+	if total > fi.Size() {
+		t.Errorf("ModuleSizes total %d exceeds file size %d", total, fi.Size())
+	}
+
+	if _, err := ModuleSizesOf(strings.NewReader("not an executable"), &BuildInfo{}); err == nil {
+		t.Errorf("ModuleSizes of text succeeded")
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+)
+
+// A BuildInfoDecoder reads build information in the text form written
+// by MarshalText from an input stream, one dependency at a time, so that
+// programs processing the build information of many binaries need not
+// hold every dependency list in memory at once. It enforces the limits
+// set by SetTextLimits when it is created.
+type BuildInfoDecoder struct {
+	r      *bufio.Reader
+	limits TextLimits
+	lineno int // number of the last line read
+	ndeps  int // number of dep lines read
+
+	// A line read ahead of the dependency it ends.
+	pending     string
+	pendingLine int
+	hasPending  bool
+
+	// The lines other than those of dependencies, and their numbers.
+	header      []string
+	headerLines []int
+
+	info *BuildInfo
+	err  error // sticky error, or io.EOF at the end of the input
+}
+
+// NewBuildInfoDecoder returns a decoder that reads from r.
+func NewBuildInfoDecoder(r io.Reader) *BuildInfoDecoder {
+	return &BuildInfoDecoder{
+		r:      bufio.NewReader(r),
+		limits: currentTextLimits(),
+		info:   new(BuildInfo),
+	}
+}
+
+// Next returns the next dependency, with its chain of replacements, as
+// UnmarshalText would list it in Deps. At the end of the input it
+// returns nil, io.EOF. Once Next returns an error, it returns the same
+// error on every later call.
+func (d *BuildInfoDecoder) Next() (*Module, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	var entry []string // the lines of the dependency being read
+	start := 0         // the number of its first line
+	for {
+		line, lineno, err := d.readLine()
+		if err == io.EOF {
+			if entry != nil {
+				return d.decodeDep(entry, start)
+			}
+			err = d.decodeHeader()
+			if err == nil {
+				err = io.EOF
+			}
+		}
+		if err != nil {
+			d.err = err
+			return nil, err
+		}
+		switch {
+		case strings.HasPrefix(line, depLine):
+			if entry != nil {
+				d.unreadLine(line, lineno)
+				return d.decodeDep(entry, start)
+			}
+			if d.limits.MaxDeps > 0 && d.ndeps == d.limits.MaxDeps {
+				d.err = limitError(lineno, line, d.limits, limitMsg("too many dependencies", d.limits.MaxDeps))
+				return nil, d.err
+			}
+			d.ndeps++
+			if d.ndeps == 1 {
+				// Make the lines before the dependencies,
+				// including the main module, available to Info.
+				if err := d.decodeHeader(); err != nil {
+					d.err = err
+					return nil, err
+				}
+			}
+			entry, start = []string{line}, lineno
+		case entry != nil && (strings.HasPrefix(line, repLine) ||
+			strings.HasPrefix(line, originLine) || strings.HasPrefix(line, licenseLine)):
+			entry = append(entry, line)
+		default:
+			if entry != nil {
+				d.unreadLine(line, lineno)
+				return d.decodeDep(entry, start)
+			}
+			d.header = append(d.header, line)
+			d.headerLines = append(d.headerLines, lineno)
+		}
+	}
+}
+
+// Info returns the build information read so far other than the
+// dependencies, whose Deps field is nil. The go and path lines and the
+// main module are available once Next has returned the first dependency,
+// and the settings, which follow the dependencies, once Next has
+// returned io.EOF.
+func (d *BuildInfoDecoder) Info() *BuildInfo {
+	return d.info
+}
+
+// readLine returns the next line of the input, without its newline,
+// and its line number.
+func (d *BuildInfoDecoder) readLine() (string, int, error) {
+	if d.hasPending {
+		d.hasPending = false
+		return d.pending, d.pendingLine, nil
+	}
+	var line []byte
+	for {
+		b, err := d.r.ReadSlice('\n')
+		line = append(line, b...)
+		if max := d.limits.MaxLineLength; max > 0 && len(bytes.TrimSuffix(line, []byte("\n"))) > max {
+			return "", 0, limitError(d.lineno+1, string(line[:max]), d.limits, limitMsg("line too long", max))
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err == io.EOF && len(line) > 0 {
+			// Accept a final line without a newline.
+			err = nil
+		}
+		if err != nil {
+			return "", 0, err
+		}
+		d.lineno++
+		if d.limits.MaxLines > 0 && d.lineno > d.limits.MaxLines {
+			return "", 0, limitError(d.lineno, string(line), d.limits, limitMsg("too many lines", d.limits.MaxLines))
+		}
+		return string(bytes.TrimSuffix(line, []byte("\n"))), d.lineno, nil
+	}
+}
+
+// unreadLine arranges for readLine to return line, numbered lineno, again.
+func (d *BuildInfoDecoder) unreadLine(line string, lineno int) {
+	d.pending, d.pendingLine, d.hasPending = line, lineno, true
+}
+
+// decodeDep parses the lines of a dependency, beginning at line start.
+func (d *BuildInfoDecoder) decodeDep(entry []string, start int) (*Module, error) {
+	bi, err := parseText(strings.Join(entry, "\n")+"\n", defaultFieldEncoder, false)
+	if err != nil {
+		d.err = renumber(err, func(n int) int { return start + n - 1 })
+		return nil, d.err
+	}
+	return bi.Deps[0], nil
+}
+
+// decodeHeader parses the lines other than those of dependencies
+// read so far into d.info.
+func (d *BuildInfoDecoder) decodeHeader() error {
+	var text strings.Builder
+	for _, line := range d.header {
+		text.WriteString(line)
+		text.WriteByte('\n')
+	}
+	bi, err := parseText(text.String(), defaultFieldEncoder, false)
+	if err != nil {
+		return renumber(err, func(n int) int { return d.headerLines[n-1] })
+	}
+	bi.Deps = nil
+	d.info = bi
+	return nil
+}
+
+// renumber returns err with the line number of a *TextError
+// mapped from that of the parsed text to that of the input.
+func renumber(err error, line func(int) int) error {
+	var te *TextError
+	if errors.As(err, &te) && te.Line > 0 {
+		c := *te
+		c.Line = line(te.Line)
+		return &c
+	}
+	return err
+}
+
+// A BuildInfoEncoder writes build information in the text form written
+// by MarshalText to an output stream, one dependency at a time. The
+// header must be written first, then the dependencies, then the
+// trailer; the output is then that of MarshalText for a BuildInfo
+// with the same fields.
+type BuildInfoEncoder struct {
+	w   io.Writer
+	buf bytes.Buffer
+	err error
+}
+
+// NewBuildInfoEncoder returns an encoder that writes to w.
+func NewBuildInfoEncoder(w io.Writer) *BuildInfoEncoder {
+	return &BuildInfoEncoder{w: w}
+}
+
+// EncodeHeader writes the lines that precede the dependencies, from
+// the GoVersion, Path, and Main fields of bi. It ignores bi.Deps.
+func (e *BuildInfoEncoder) EncodeHeader(bi *BuildInfo) error {
+	writeTextHeader(&e.buf, defaultFieldEncoder, bi)
+	return e.flush()
+}
+
+// EncodeDep writes the lines of the dependency m and its replacements.
+func (e *BuildInfoEncoder) EncodeDep(m *Module) error {
+	writeTextEntry(&e.buf, defaultFieldEncoder, depLine, m)
+	return e.flush()
+}
+
+// EncodeTrailer writes the lines that follow the dependencies, from
+// the Settings and Raw fields of bi.
+func (e *BuildInfoEncoder) EncodeTrailer(bi *BuildInfo) error {
+	writeTextTrailer(&e.buf, defaultFieldEncoder, bi)
+	return e.flush()
+}
+
+// flush writes the buffered lines to e.w. Once a write fails,
+// flush returns the same error on every later call.
+func (e *BuildInfoEncoder) flush() error {
+	if e.err == nil {
+		_, e.err = e.w.Write(e.buf.Bytes())
+	}
+	e.buf.Reset()
+	return e.err
+}
@@ -0,0 +1,110 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	. "runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestBuildInfoDecoder(t *testing.T) {
+	text := formatHeader +
+		"go\tgo1.15\n" +
+		testModinfo[:strings.Index(testModinfo, "dep\t")] +
+		"dep\tgolang.org/x/text\tv0.3.3\th1:cafe=\n" +
+		"license\tBSD-3-Clause\n" +
+		"dep\trsc.io/quote\tv1.5.2\n" +
+		"=>\trsc.io/quote\tv1.0.0\th1:beef=\n" +
+		"origin\tgit\thttps://github.com/rsc/quote\n" +
+		"dep\t" + strings.Repeat("x", 5000) + "\tv1.0.0\t\n" +
+		"build\t-compiler=gc\n" +
+		"future\tline"
+	var want BuildInfo
+	if err := want.UnmarshalText([]byte(text)); err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewBuildInfoDecoder(strings.NewReader(text))
+	var deps []*Module
+	for {
+		m, err := d.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(deps) == 0 && d.Info().Main.Path != "example.com/hello" {
+			t.Errorf("Info().Main = %+v after first dependency", d.Info().Main)
+		}
+		deps = append(deps, m)
+	}
+	if m, err := d.Next(); m != nil || err != io.EOF {
+		t.Errorf("Next after end = %v, %v, want nil, io.EOF", m, err)
+	}
+	got := d.Info()
+	got.Deps = deps
+	if !reflect.DeepEqual(got, &want) {
+		t.Errorf("decoded\n%+v\nwant\n%+v", got, &want)
+	}
+
+	var buf bytes.Buffer
+	e := NewBuildInfoEncoder(&buf)
+	if err := e.EncodeHeader(&want); err != nil {
+		t.Fatal(err)
+	}
+	for _, dep := range deps {
+		if err := e.EncodeDep(dep); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := e.EncodeTrailer(&want); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != want.String() {
+		t.Errorf("encoded\n%s\nwant\n%s", buf.String(), want.String())
+	}
+}
+
+func TestBuildInfoDecoderErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name, text string
+		limits     TextLimits
+		line       int
+		err        error
+	}{
+		{"bad dep", testModinfo + "dep\tx\n", TextLimits{}, 0, nil},
+		{"too many deps", bigModinfo(10), TextLimits{MaxDeps: 5}, 8, ErrLimitExceeded},
+		{"too many lines", bigModinfo(10), TextLimits{MaxLines: 5}, 6, ErrLimitExceeded},
+		{"long line", "path\t" + strings.Repeat("x", 10000) + "\n", TextLimits{MaxLineLength: 5000}, 1, ErrLimitExceeded},
+	} {
+		old := SetTextLimits(tt.limits)
+		d := NewBuildInfoDecoder(strings.NewReader(tt.text))
+		SetTextLimits(old)
+		var err error
+		for err == nil {
+			_, err = d.Next()
+		}
+		if err == io.EOF {
+			t.Errorf("%s: no error", tt.name)
+			continue
+		}
+		if tt.err != nil && !errors.Is(err, tt.err) {
+			t.Errorf("%s: error %v, want %v", tt.name, err, tt.err)
+		}
+		var te *TextError
+		if tt.line > 0 && (!errors.As(err, &te) || te.Line != tt.line) {
+			t.Errorf("%s: error %v, want error at line %d", tt.name, err, tt.line)
+		}
+		if _, again := d.Next(); again != err {
+			t.Errorf("%s: Next after error = %v, want %v", tt.name, again, err)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Validate checks that bi is structurally sound, as build information
+// written by cmd/go always is, and returns an error describing the
+// first problem found. It checks that:
+//
+//	- every module path is well formed: a sequence of non-empty
+//	  elements separated by slashes, made of ASCII letters, digits,
+//	  and the punctuation "-._~", none beginning or ending with a dot;
+//	- every dependency version is a canonical semantic version, such
+//	  as v1.2.3 or v2.0.0+incompatible, and the main module's version,
+//	  if any, is one too or "(devel)";
+//	- every checksum, if any, has the h1: form of a base64-encoded
+//	  SHA-256 hash;
+//	- no dependency path is listed twice.
+//
+// A replacement with no version is a local directory, whose path is
+// a file system path and is checked only for being non-empty.
+// Validate is meant for tools that accept build information from
+// outside sources, such as text supplied by users, before they trust it.
+func (bi *BuildInfo) Validate() error {
+	if err := checkModule(&bi.Main, true); err != nil {
+		return errors.New("main module: " + err.Error())
+	}
+	seen := make(map[string]bool, len(bi.Deps))
+	for i, dep := range bi.Deps {
+		if dep == nil {
+			return errors.New("dependency " + strconv.Itoa(i) + " is nil")
+		}
+		if err := checkModule(dep, false); err != nil {
+			return errors.New("dependency " + strconv.Quote(dep.Path) + ": " + err.Error())
+		}
+		if seen[dep.Path] {
+			return errors.New("dependency " + strconv.Quote(dep.Path) + " listed more than once")
+		}
+		seen[dep.Path] = true
+	}
+	return nil
+}
+
+// checkModule checks the path, version, and checksum of m and of each
+// module in its replacement chain. A main module may have the version
+// "(devel)" or none at all.
+func checkModule(m *Module, main bool) error {
+	for r := m; r != nil; r = r.Replace {
+		what := ""
+		if r != m {
+			what = "replacement "
+		}
+		if r != m && r.Version == "" {
+			if r.Path == "" {
+				return errors.New("empty " + what + "path")
+			}
+		} else if err := checkModulePath(r.Path); err != nil {
+			return errors.New(what + err.Error())
+		}
+		switch {
+		case r.Version == "" && (main || r != m):
+		case r.Version == "(devel)" && main && r == m:
+		case !isCanonicalSemver(r.Version):
+			return errors.New(what + "malformed version " + strconv.Quote(r.Version))
+		}
+		if r.Sum != "" {
+			if _, ok := sumIntegrity(r.Sum); !ok {
+				return errors.New(what + "malformed checksum " + strconv.Quote(r.Sum))
+			}
+		}
+	}
+	return nil
+}
+
+// checkModulePath checks the syntax of the module path p.
+func checkModulePath(p string) error {
+	if p == "" {
+		return errors.New("empty path")
+	}
+	for _, elem := range strings.Split(p, "/") {
+		if elem == "" {
+			return errors.New("malformed path " + strconv.Quote(p) + ": empty path element")
+		}
+		if elem[0] == '.' || elem[len(elem)-1] == '.' {
+			return errors.New("malformed path " + strconv.Quote(p) + ": leading or trailing dot in path element")
+		}
+		for _, c := range elem {
+			if !('0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || strings.ContainsRune("-._~", c)) {
+				return errors.New("malformed path " + strconv.Quote(p) + ": invalid char " + strconv.QuoteRune(c))
+			}
+		}
+	}
+	if p[0] == '-' {
+		return errors.New("malformed path " + strconv.Quote(p) + ": leading dash")
+	}
+	return nil
+}
+
+// isCanonicalSemver reports whether v is a semantic version in the
+// canonical form cmd/go records: with major, minor, and patch numbers
+// and no build metadata other than +incompatible.
+func isCanonicalSemver(v string) bool {
+	p, ok := parseSemver(v)
+	return ok && p.short == "" && (p.build == "" || p.build == "+incompatible")
+}
@@ -0,0 +1,79 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	. "runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	const sum = "h1:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="
+	valid := func() *BuildInfo {
+		return &BuildInfo{
+			Main: Module{Path: "example.com/cmd/hello", Version: "(devel)"},
+			Deps: []*Module{
+				{Path: "golang.org/x/text", Version: "v0.3.3", Sum: sum},
+				{Path: "example.com/old", Version: "v2.0.0+incompatible", Sum: sum},
+				{Path: "rsc.io/quote", Version: "v1.5.2",
+					Replace: &Module{Path: "rsc.io/quote", Version: "v1.0.0-pre.0.20200101120000-abcdefabcdef", Sum: sum}},
+				{Path: "example.com/local", Version: "v0.0.0-00010101000000-000000000000",
+					Replace: &Module{Path: "../local"}},
+			},
+		}
+	}
+	if err := valid().Validate(); err != nil {
+		t.Fatalf("Validate() = %v, want nil", err)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		edit   func(bi *BuildInfo)
+		errMsg string
+	}{
+		{"empty main path", func(bi *BuildInfo) { bi.Main.Path = "" }, "main module: empty path"},
+		{"bad main version", func(bi *BuildInfo) { bi.Main.Version = "1.2.3" }, `main module: malformed version "1.2.3"`},
+		{"empty element", func(bi *BuildInfo) { bi.Deps[0].Path = "golang.org//text" }, "empty path element"},
+		{"trailing dot", func(bi *BuildInfo) { bi.Deps[0].Path = "golang.org/x/text." }, "trailing dot"},
+		{"bad char", func(bi *BuildInfo) { bi.Deps[0].Path = "golang.org/x/té" }, `invalid char 'é'`},
+		{"leading dash", func(bi *BuildInfo) { bi.Deps[0].Path = "-golang.org/x/text" }, "leading dash"},
+		{"no dep version", func(bi *BuildInfo) { bi.Deps[0].Version = "" }, `"golang.org/x/text": malformed version ""`},
+		{"short version", func(bi *BuildInfo) { bi.Deps[0].Version = "v0.3" }, `malformed version "v0.3"`},
+		{"build metadata", func(bi *BuildInfo) { bi.Deps[0].Version = "v0.3.3+meta" }, `malformed version "v0.3.3+meta"`},
+		{"bad sum", func(bi *BuildInfo) { bi.Deps[0].Sum = "h1:c0ffee=" }, `malformed checksum "h1:c0ffee="`},
+		{"other hash", func(bi *BuildInfo) { bi.Deps[0].Sum = "h2:" + sum[3:] }, "malformed checksum"},
+		{"bad replacement version", func(bi *BuildInfo) { bi.Deps[2].Replace.Version = "v1" }, `"rsc.io/quote": replacement malformed version "v1"`},
+		{"bad replacement sum", func(bi *BuildInfo) { bi.Deps[2].Replace.Sum = "h1:" }, "replacement malformed checksum"},
+		{"empty directory", func(bi *BuildInfo) { bi.Deps[3].Replace.Path = "" }, "empty replacement path"},
+		{"nil dep", func(bi *BuildInfo) { bi.Deps[1] = nil }, "dependency 1 is nil"},
+		{"duplicate", func(bi *BuildInfo) { bi.Deps = append(bi.Deps, &Module{Path: "golang.org/x/text", Version: "v0.3.4"}) },
+			`dependency "golang.org/x/text" listed more than once`},
+	} {
+		bi := valid()
+		tt.edit(bi)
+		err := bi.Validate()
+		if err == nil {
+			t.Errorf("%s: Validate() = nil, want error", tt.name)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("%s: Validate() = %q, want error containing %q", tt.name, err, tt.errMsg)
+		}
+	}
+}
+
+func TestValidateParsed(t *testing.T) {
+	info := new(BuildInfo)
+	err := info.UnmarshalText([]byte("path\texample.com/cmd/hello\n" +
+		"mod\texample.com/hello\tv1.2.3\th1:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=\n" +
+		"dep\tgolang.org/x/text\tv0.3.3\th1:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := info.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import "time"
+
+// The go command stamps a binary whose main module is checked out with
+// a version control system with these build settings, in the text
+// form build lines such as "build\tvcs.revision=<hash>".
+const (
+	vcsRevisionKey = "vcs.revision" // the commit hash
+	vcsTimeKey     = "vcs.time"     // the commit time, in RFC 3339 format
+	vcsModifiedKey = "vcs.modified" // whether the working tree had uncommitted changes
+)
+
+// Revision returns the revision of the main module's checkout from
+// which the binary was built, for Git the full commit hash, as recorded
+// by the vcs.revision build setting, and reports whether it is present.
+func (bi *BuildInfo) Revision() (string, bool) {
+	return bi.setting(vcsRevisionKey)
+}
+
+// CommitTime returns the time of the revision reported by Revision,
+// as recorded by the vcs.time build setting. It reports whether the
+// setting is present and holds a valid time.
+func (bi *BuildInfo) CommitTime() (time.Time, bool) {
+	v, ok := bi.setting(vcsTimeKey)
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// Modified reports whether the binary was built from a working tree
+// with uncommitted changes, as recorded by the vcs.modified build
+// setting. A binary without the setting is reported as unmodified.
+func (bi *BuildInfo) Modified() bool {
+	v, _ := bi.setting(vcsModifiedKey)
+	return v == "true"
+}
+
+// IsReproducible reports whether bi records a build that can be
+// reproduced from its version control revision alone: one made from a
+// clean checkout, as recorded by the vcs.revision and vcs.modified build
+// settings, with -trimpath, so that the binary does not depend on where
+// the source was, and with no dependency replaced by a local directory,
+// whose contents no revision records. If the build is not reproducible,
+// IsReproducible returns the reasons, one for each problem.
+func (bi *BuildInfo) IsReproducible() (bool, []string) {
+	var reasons []string
+	if _, ok := bi.Revision(); !ok {
+		reasons = append(reasons, "no version control revision recorded")
+	} else if bi.Modified() {
+		reasons = append(reasons, "built from a working tree with uncommitted changes")
+	}
+	if !bi.trimpath() {
+		reasons = append(reasons, "built without -trimpath")
+	}
+	for _, dep := range bi.Deps {
+		if dep == nil {
+			continue
+		}
+		for m := dep; m.Replace != nil; m = m.Replace {
+			if m.IsLocalReplace() {
+				reasons = append(reasons, dep.Path+" replaced by local directory "+m.Replace.Path)
+				break
+			}
+		}
+	}
+	return len(reasons) == 0, reasons
+}
@@ -0,0 +1,77 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"reflect"
+	. "runtime/debug"
+	"testing"
+	"time"
+)
+
+func TestVCS(t *testing.T) {
+	var info BuildInfo
+	text := testModinfo +
+		"build\tvcs=git\n" +
+		"build\tvcs.revision=0123456789abcdef0123456789abcdef01234567\n" +
+		"build\tvcs.time=2020-01-02T03:04:05Z\n" +
+		"build\tvcs.modified=true\n"
+	if err := info.UnmarshalText([]byte(text)); err != nil {
+		t.Fatal(err)
+	}
+	if rev, ok := info.Revision(); !ok || rev != "0123456789abcdef0123456789abcdef01234567" {
+		t.Errorf("Revision() = %q, %v", rev, ok)
+	}
+	want := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if ct, ok := info.CommitTime(); !ok || !ct.Equal(want) {
+		t.Errorf("CommitTime() = %v, %v, want %v", ct, ok, want)
+	}
+	if !info.Modified() {
+		t.Error("Modified() = false, want true")
+	}
+	if out, _ := info.MarshalText(); string(out) != formatHeader+text {
+		t.Errorf("MarshalText:\n%s\nwant:\n%s", out, formatHeader+text)
+	}
+
+	var plain BuildInfo
+	if err := plain.UnmarshalText([]byte(testModinfo + "build\tvcs.time=yesterday\n")); err != nil {
+		t.Fatal(err)
+	}
+	if rev, ok := plain.Revision(); ok {
+		t.Errorf("Revision() without stamp = %q, true", rev)
+	}
+	if ct, ok := plain.CommitTime(); ok {
+		t.Errorf("CommitTime() with invalid time = %v, true", ct)
+	}
+	if plain.Modified() {
+		t.Error("Modified() without stamp = true")
+	}
+}
+
+func TestIsReproducible(t *testing.T) {
+	const clean = "build\t-trimpath=true\n" +
+		"build\tvcs.revision=0123456789abcdef0123456789abcdef01234567\n" +
+		"build\tvcs.modified=false\n"
+	for _, tt := range []struct {
+		text    string
+		reasons []string
+	}{
+		{testModinfo + clean, nil},
+		{testModinfo, []string{"no version control revision recorded", "built without -trimpath"}},
+		{testModinfo + "build\tvcs.revision=0123\nbuild\tvcs.modified=true\nbuild\t-trimpath=true\n",
+			[]string{"built from a working tree with uncommitted changes"}},
+		{testModinfo + "dep\texample.com/local\tv1.0.0\n=>\t../local\t\t\n" + clean,
+			[]string{"example.com/local replaced by local directory ../local"}},
+	} {
+		var info BuildInfo
+		if err := info.UnmarshalText([]byte(tt.text)); err != nil {
+			t.Fatal(err)
+		}
+		ok, reasons := info.IsReproducible()
+		if ok != (tt.reasons == nil) || !reflect.DeepEqual(reasons, tt.reasons) {
+			t.Errorf("IsReproducible() for\n%s= %v, %q, want %q", tt.text, ok, reasons, tt.reasons)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+// A SumVerifier looks up the checksums of module versions in a
+// checksum database, such as sum.golang.org or a corporate mirror
+// of it. Package runtime/debug does not implement SumVerifier;
+// a client of the checksum database, such as one built with
+// golang.org/x/mod/sumdb, can.
+type SumVerifier interface {
+	// Sum returns the h1: checksum that the database records for
+	// the contents of the module path at version.
+	Sum(path, version string) (string, error)
+}
+
+// A SumMismatch reports a module whose recorded checksum could not be
+// confirmed by a SumVerifier.
+type SumMismatch struct {
+	Module *Module // the module checked, a dependency or its replacement
+	Want   string  // the checksum reported by the verifier, if any
+	Err    error   // the error from the verifier, if any
+}
+
+// VerifySums checks the checksum recorded for each dependency of bi,
+// and for each replacement module that has a version, against the
+// checksum that v reports for the same module path and version. It
+// returns a SumMismatch for each module whose checksums differ or for
+// which v returns an error, in the order of bi.Deps; an empty result
+// means every checksum was confirmed. Modules recorded without a
+// checksum, such as those replaced by local directories, are skipped.
+// VerifySums asks v about each module path and version once.
+func (bi *BuildInfo) VerifySums(v SumVerifier) []SumMismatch {
+	type result struct {
+		sum string
+		err error
+	}
+	var mismatches []SumMismatch
+	results := make(map[string]result)
+	checked := make(map[*Module]bool)
+	check := func(m *Module) {
+		if m == nil || m.Sum == "" || m.Version == "" || checked[m] {
+			return
+		}
+		checked[m] = true
+		r, ok := results[m.Key()]
+		if !ok {
+			r.sum, r.err = v.Sum(m.Path, m.Version)
+			results[m.Key()] = r
+		}
+		if r.err != nil || r.sum != m.Sum {
+			mismatches = append(mismatches, SumMismatch{Module: m, Want: r.sum, Err: r.err})
+		}
+	}
+	for _, dep := range bi.Deps {
+		if dep == nil {
+			continue
+		}
+		check(dep)
+		check(dep.Replace)
+	}
+	return mismatches
+}
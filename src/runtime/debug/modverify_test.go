@@ -0,0 +1,59 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"errors"
+	. "runtime/debug"
+	"testing"
+)
+
+// A fakeSumDB is a SumVerifier serving checksums from a map.
+type fakeSumDB struct {
+	sums  map[string]string // keyed by path@version
+	calls int
+}
+
+func (db *fakeSumDB) Sum(path, version string) (string, error) {
+	db.calls++
+	sum, ok := db.sums[path+"@"+version]
+	if !ok {
+		return "", errors.New("not found: " + path + "@" + version)
+	}
+	return sum, nil
+}
+
+func TestVerifySums(t *testing.T) {
+	info, ok := ReadBuildInfoData(blob(testModinfo))
+	if !ok {
+		t.Fatal("ReadBuildInfo failed")
+	}
+	db := &fakeSumDB{sums: map[string]string{
+		"golang.org/x/text@v0.3.3": "h1:cafe=",
+		"rsc.io/quote@v1.0.0":      "h1:beef=",
+	}}
+	// The dependency rsc.io/quote v1.5.2 is replaced and has no checksum of its own.
+	if m := info.VerifySums(db); len(m) != 0 {
+		t.Errorf("VerifySums = %+v, want none", m)
+	}
+
+	db.sums["golang.org/x/text@v0.3.3"] = "h1:other="
+	delete(db.sums, "rsc.io/quote@v1.0.0")
+	info.Deps = append(info.Deps, info.Deps[0], &Module{Path: "golang.org/x/text", Version: "v0.3.3", Sum: "h1:other="})
+	db.calls = 0
+	m := info.VerifySums(db)
+	if len(m) != 2 {
+		t.Fatalf("VerifySums = %+v, want 2 mismatches", m)
+	}
+	if m[0].Module != info.Deps[0] || m[0].Want != "h1:other=" || m[0].Err != nil {
+		t.Errorf("VerifySums[0] = %+v, want x/text with h1:other=", m[0])
+	}
+	if m[1].Module != info.Deps[1].Replace || m[1].Err == nil {
+		t.Errorf("VerifySums[1] = %+v, want quote replacement with error", m[1])
+	}
+	if db.calls != 2 {
+		t.Errorf("VerifySums made %d lookups, want 2", db.calls)
+	}
+}
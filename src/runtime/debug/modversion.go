@@ -0,0 +1,149 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"strings"
+	"time"
+)
+
+// pseudoTimeLayout is the layout of the timestamp in a pseudo-version.
+const pseudoTimeLayout = "20060102150405"
+
+// zeroPseudoVersion is the pseudo-version cmd/go records for a
+// requirement that exists only to be replaced by a local directory.
+const zeroPseudoVersion = "v0.0.0-00010101000000-000000000000"
+
+// IsPseudoVersion reports whether m.Version is a pseudo-version,
+// such as v0.0.0-20200101120000-abcdef123456, which cmd/go records
+// for a module required at a commit that has no semantic version tag.
+func (m Module) IsPseudoVersion() bool {
+	_, _, ok := splitPseudoVersion(m.Version)
+	return ok
+}
+
+// CommitTime returns the UTC commit time recorded in the pseudo-version
+// m.Version, and reports whether m.Version is a pseudo-version.
+func (m Module) CommitTime() (time.Time, bool) {
+	return pseudoVersionTime(m.Version)
+}
+
+// CommitHash returns the revision identifier recorded in the
+// pseudo-version m.Version, for Git the 12-character prefix of the
+// commit hash, and reports whether m.Version is a pseudo-version.
+func (m Module) CommitHash() (string, bool) {
+	_, rev, ok := splitPseudoVersion(m.Version)
+	return rev, ok
+}
+
+// splitPseudoVersion reports whether v has the syntax of a
+// pseudo-version, as described in "go help modules", and if so
+// returns its timestamp and revision fields. The three accepted forms are
+//
+//	vX.0.0-yyyymmddhhmmss-abcdefabcdef
+//	vX.Y.Z-pre.0.yyyymmddhhmmss-abcdefabcdef
+//	vX.Y.(Z+1)-0.yyyymmddhhmmss-abcdefabcdef
+//
+// each optionally followed by a +build suffix such as +incompatible.
+func splitPseudoVersion(v string) (timestamp, rev string, ok bool) {
+	if i := strings.IndexByte(v, '+'); i >= 0 {
+		v = v[:i]
+	}
+	i := strings.LastIndexByte(v, '-')
+	if i < 0 || !isAlnum(v[i+1:]) {
+		return "", "", false
+	}
+	rest, rev := v[:i], v[i+1:]
+	if len(rest) < 14 || !isDigits(rest[len(rest)-14:]) {
+		return "", "", false
+	}
+	prefix, timestamp := rest[:len(rest)-14], rest[len(rest)-14:]
+	switch {
+	case strings.HasSuffix(prefix, "-"):
+		// vX.0.0-yyyymmddhhmmss-abcdefabcdef
+		major, minor, patch, ok := splitRelease(prefix[:len(prefix)-1])
+		if !ok || major == "" || minor != "0" || patch != "0" {
+			return "", "", false
+		}
+	case strings.HasSuffix(prefix, "-0.") || strings.HasSuffix(prefix, ".0."):
+		// vX.Y.Z-pre.0.yyyymmddhhmmss-abcdefabcdef or
+		// vX.Y.(Z+1)-0.yyyymmddhhmmss-abcdefabcdef
+		j := strings.IndexByte(prefix, '-')
+		if j < 0 {
+			return "", "", false
+		}
+		if pre := prefix[j+1:]; pre != "0." && len(pre) <= len(".0.") {
+			return "", "", false
+		}
+		if _, _, _, ok := splitRelease(prefix[:j]); !ok {
+			return "", "", false
+		}
+	default:
+		return "", "", false
+	}
+	return timestamp, rev, true
+}
+
+// pseudoVersionTime returns the commit time recorded in the
+// pseudo-version v.
+func pseudoVersionTime(v string) (time.Time, bool) {
+	timestamp, _, ok := splitPseudoVersion(v)
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(pseudoTimeLayout, timestamp)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// splitRelease splits a release version vX.Y.Z into its three
+// numeric components, reporting whether v has that form.
+func splitRelease(v string) (major, minor, patch string, ok bool) {
+	if !strings.HasPrefix(v, "v") {
+		return "", "", "", false
+	}
+	parts := strings.Split(v[1:], ".")
+	if len(parts) != 3 {
+		return "", "", "", false
+	}
+	for _, p := range parts {
+		if !isNum(p) {
+			return "", "", "", false
+		}
+	}
+	return parts[0], parts[1], parts[2], true
+}
+
+// isNum reports whether s is a decimal number without leading zeros.
+func isNum(s string) bool {
+	return isDigits(s) && (s == "0" || s[0] != '0')
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || '9' < s[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlnum(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
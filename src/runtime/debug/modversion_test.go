@@ -0,0 +1,43 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	. "runtime/debug"
+	"testing"
+	"time"
+)
+
+func TestPseudoVersion(t *testing.T) {
+	for _, tt := range []struct {
+		version string
+		pseudo  bool
+		time    string
+		hash    string
+	}{
+		{"v0.0.0-20230101120000-abcdef123456", true, "2023-01-01T12:00:00Z", "abcdef123456"},
+		{"v1.2.4-0.20200102030405-0123456789ab", true, "2020-01-02T03:04:05Z", "0123456789ab"},
+		{"v1.2.3-pre.0.20200102030405-0123456789ab", true, "2020-01-02T03:04:05Z", "0123456789ab"},
+		{"v2.0.0-20200102030405-0123456789ab+incompatible", true, "2020-01-02T03:04:05Z", "0123456789ab"},
+		{"v1.2.3", false, "", ""},
+		{"v1.2.3-rc.1", false, "", ""},
+		{"v1.2.3-20200102030405-0123456789ab", false, "", ""},
+		{"(devel)", false, "", ""},
+		{"", false, "", ""},
+	} {
+		m := Module{Path: "example.com/m", Version: tt.version}
+		if got := m.IsPseudoVersion(); got != tt.pseudo {
+			t.Errorf("IsPseudoVersion(%q) = %v, want %v", tt.version, got, tt.pseudo)
+		}
+		ct, ok := m.CommitTime()
+		if ok != tt.pseudo || ok && ct.Format(time.RFC3339) != tt.time {
+			t.Errorf("CommitTime(%q) = %v, %v, want %s", tt.version, ct, ok, tt.time)
+		}
+		hash, ok := m.CommitHash()
+		if ok != tt.pseudo || hash != tt.hash {
+			t.Errorf("CommitHash(%q) = %q, %v, want %q", tt.version, hash, ok, tt.hash)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// A ModulePath is a module version, such as one in a chain of
+// requirements returned by BuildInfo.Why or in an AuditFinding.
+type ModulePath struct {
+	Path    string
+	Version string
+}
+
+// SetModGraph records in bi the requirement graph of its build, for
+// Why to explain. The graph is in the form printed by go mod graph,
+// one requirement to a line: the requiring module, a space, and the
+// module required, each as path@version, except the main module,
+// which has no version. A binary does not record its requirement
+// graph, so it must be supplied separately, as go mod graph prints it
+// for the main module at the revision from which the binary was built.
+// SetModGraph returns an error if graph is malformed, leaving bi
+// unchanged.
+func (bi *BuildInfo) SetModGraph(graph []byte) error {
+	g := make(map[string][]string)
+	for i, line := range strings.Split(string(graph), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if line == "" {
+			continue
+		}
+		f := strings.Fields(line)
+		if len(f) != 2 || f[0] == "" || f[1] == "" || strings.HasPrefix(f[0], "@") || strings.HasPrefix(f[1], "@") {
+			return errors.New("invalid module graph line " + strconv.Itoa(i+1) + ": " + strconv.Quote(line))
+		}
+		g[f[0]] = append(g[f[0]], f[1])
+	}
+	bi.graph = g
+	return nil
+}
+
+// Why returns a shortest chain of requirements by which the main module
+// of bi requires the module with the given path, as go mod why -m
+// explains it: the main module first, each module required by the one
+// before it, and the named module last, each at the version used in
+// the build, as listed in bi.Deps, rather than the version required.
+// Why returns nil if no requirement graph has been recorded by
+// SetModGraph, or if the graph does not lead to the module.
+func (bi *BuildInfo) Why(path string) []ModulePath {
+	if bi.graph == nil {
+		return nil
+	}
+	// The node of a module in the graph is that of its selected version.
+	selected := make(map[string]string, len(bi.Deps))
+	for _, dep := range bi.Deps {
+		if dep != nil {
+			selected[dep.Path] = dep.Version
+		}
+	}
+	node := func(path string) string {
+		if path == bi.Main.Path {
+			return path
+		}
+		return path + "@" + selected[path]
+	}
+	// Search breadth first from the main module, by module path.
+	prev := map[string]string{bi.Main.Path: ""}
+	queue := []string{bi.Main.Path}
+	for len(queue) > 0 && path != queue[0] {
+		from := queue[0]
+		queue = queue[1:]
+		for _, to := range bi.graph[node(from)] {
+			p := nodePath(to)
+			if _, ok := prev[p]; ok {
+				continue
+			}
+			if _, ok := selected[p]; !ok {
+				// Not in the build.
+				continue
+			}
+			prev[p] = from
+			queue = append(queue, p)
+		}
+	}
+	if _, ok := prev[path]; !ok {
+		return nil
+	}
+	var chain []ModulePath
+	for p := path; p != ""; p = prev[p] {
+		v := bi.Main.Version
+		if p != bi.Main.Path {
+			v = selected[p]
+		}
+		chain = append(chain, ModulePath{Path: p, Version: v})
+	}
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain
+}
+
+// nodePath returns the module path of the node n of a requirement
+// graph, which is a path@version or, for the main module, a path.
+func nodePath(n string) string {
+	if i := strings.LastIndexByte(n, '@'); i > 0 {
+		return n[:i]
+	}
+	return n
+}
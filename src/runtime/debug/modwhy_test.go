@@ -0,0 +1,73 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"reflect"
+	. "runtime/debug"
+	"testing"
+)
+
+const testModGraph = `example.com/hello golang.org/x/text@v0.3.0
+example.com/hello rsc.io/quote@v1.5.2
+rsc.io/quote@v1.5.2 rsc.io/sampler@v1.3.0
+rsc.io/sampler@v1.3.0 golang.org/x/text@v0.0.0-20170915032832-14c0d48ead0c
+rsc.io/sampler@v1.3.0 example.com/unused@v1.0.0
+rsc.io/sampler@v1.99.99 example.com/later@v1.0.0
+`
+
+func TestWhy(t *testing.T) {
+	bi := &BuildInfo{
+		Main: Module{Path: "example.com/hello", Version: "(devel)"},
+		Deps: []*Module{
+			{Path: "golang.org/x/text", Version: "v0.3.3"},
+			{Path: "rsc.io/quote", Version: "v1.5.2"},
+			{Path: "rsc.io/sampler", Version: "v1.3.0"},
+			{Path: "example.com/later", Version: "v1.0.0"},
+		},
+	}
+	if got := bi.Why("rsc.io/sampler"); got != nil {
+		t.Errorf("Why without graph = %v, want nil", got)
+	}
+	if err := bi.SetModGraph([]byte(testModGraph)); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		path string
+		want []ModulePath
+	}{
+		{"rsc.io/sampler", []ModulePath{
+			{"example.com/hello", "(devel)"},
+			{"rsc.io/quote", "v1.5.2"},
+			{"rsc.io/sampler", "v1.3.0"},
+		}},
+		{"golang.org/x/text", []ModulePath{
+			{"example.com/hello", "(devel)"},
+			{"golang.org/x/text", "v0.3.3"},
+		}},
+		{"example.com/hello", []ModulePath{{"example.com/hello", "(devel)"}}},
+		// Not in the build.
+		{"example.com/unused", nil},
+		// Required only by a version not selected.
+		{"example.com/later", nil},
+		{"example.com/missing", nil},
+	} {
+		if got := bi.Why(tt.path); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Why(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+	if got := bi.Clone().Why("rsc.io/sampler"); len(got) != 3 {
+		t.Errorf("Why on clone = %v", got)
+	}
+
+	for _, bad := range []string{"example.com/hello\n", "a b c\n", "a @v1.0.0\n"} {
+		if err := bi.SetModGraph([]byte(bad)); err == nil {
+			t.Errorf("SetModGraph(%q) succeeded", bad)
+		}
+	}
+	if got := bi.Why("rsc.io/sampler"); len(got) != 3 {
+		t.Errorf("failed SetModGraph changed the graph: Why = %v", got)
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// WriteBuildInfo returns a copy of the Go executable binary with the
+// build information embedded in it replaced by bi, in the text form
+// returned by bi.String, so that tools can stamp a binary with
+// version control or release metadata after it has been linked.
+//
+// The build information is stored in the binary as a string whose
+// length is fixed at link time, so the new text must fit in the space
+// taken by the old, between the sentinels that frame it; any space left
+// over is filled with newlines, which readers of build information
+// skip. WriteBuildInfo returns an error if binary holds no build
+// information or if the text of bi does not fit. It does not update
+// code signatures or other checksums over the contents of the binary,
+// which must be recomputed afterward where the platform requires them.
+func WriteBuildInfo(binary []byte, bi *BuildInfo) ([]byte, error) {
+	off, old, err := locateModinfo(bytes.NewReader(binary), int64(len(binary)))
+	if err != nil {
+		return nil, err
+	}
+	if bi.Path == "" && bi.Main.Path == "" && bi.Main.Version == "" {
+		return nil, errors.New("build information has neither path nor main module")
+	}
+	text := bi.String()
+	room := len(old) - len(infoStart) - len(infoEnd)
+	if len(text) > room {
+		return nil, errors.New("build information needs " + strconv.Itoa(len(text)) +
+			" bytes, but the binary has room for " + strconv.Itoa(room))
+	}
+	out := append([]byte(nil), binary...)
+	blob := infoStart + text + strings.Repeat("\n", room-len(text)) + infoEnd
+	copy(out[off:], blob)
+	return out, nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"bytes"
+	"reflect"
+	. "runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestWriteBuildInfo(t *testing.T) {
+	binary := []byte("\x7fELF fake executable\x00" + infoStart + "decoy" + blob(testModinfo) + "\x00trailer")
+	info, err := ReadBuildInfoFrom(bytes.NewReader(binary))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info.Main.Version = "v1.2.4"
+	info.Settings = info.Settings[:1]
+	out, err := WriteBuildInfo(binary, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != len(binary) {
+		t.Fatalf("WriteBuildInfo changed the binary's size from %d to %d", len(binary), len(out))
+	}
+	if !bytes.HasPrefix(out, binary[:30]) || !bytes.HasSuffix(out, []byte("\x00trailer")) {
+		t.Errorf("WriteBuildInfo changed data outside the build information")
+	}
+	got, err := ReadBuildInfoFrom(bytes.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, info) {
+		t.Errorf("build information after WriteBuildInfo:\n%s\nwant:\n%s", got, info)
+	}
+	if strings.Contains(string(binary), "v1.2.4") {
+		t.Errorf("WriteBuildInfo modified its argument")
+	}
+}
+
+func TestWriteBuildInfoErrors(t *testing.T) {
+	binary := []byte("junk" + blob(testModinfo) + "junk")
+	info, _ := ReadBuildInfoFrom(bytes.NewReader(binary))
+	info.Deps = append(info.Deps, &Module{Path: "example.com/new", Version: "v1.0.0"})
+	if _, err := WriteBuildInfo(binary, info); err == nil || !strings.Contains(err.Error(), "has room for") {
+		t.Errorf("WriteBuildInfo with larger build information = %v, want error about room", err)
+	}
+	if _, err := WriteBuildInfo(binary, &BuildInfo{}); err == nil {
+		t.Errorf("WriteBuildInfo with empty build information succeeded")
+	}
+	if _, err := WriteBuildInfo([]byte("no build information here"), info); err == nil {
+		t.Errorf("WriteBuildInfo on a binary without build information succeeded")
+	}
+}
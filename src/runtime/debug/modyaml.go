@@ -0,0 +1,121 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// YAML returns a YAML document describing bi, without requiring a
+// YAML library. The document is a mapping with the keys path,
+// goVersion, main (a mapping with the keys path, version, sum,
+// replace, origin, and license), deps (a sequence of such mappings),
+// and settings (a mapping from setting key to value). An origin is a
+// mapping with the keys vcs, url, ref, and hash. Empty sums, licenses,
+// refs, and hashes and nil replacements and origins are omitted.
+// A scalar is written plain only when YAML cannot mistake it for
+// anything but a string; all others are double-quoted.
+func (bi *BuildInfo) YAML() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("path: " + yamlScalar(bi.Path) + "\n")
+	buf.WriteString("goVersion: " + yamlScalar(bi.GoVersion) + "\n")
+	buf.WriteString("main:\n")
+	writeYAMLModule(&buf, "  ", "  ", &bi.Main)
+
+	n := 0
+	for _, dep := range bi.Deps {
+		if dep == nil {
+			continue
+		}
+		if n == 0 {
+			buf.WriteString("deps:\n")
+		}
+		n++
+		writeYAMLModule(&buf, "  - ", "    ", dep)
+	}
+	if n == 0 {
+		buf.WriteString("deps: []\n")
+	}
+
+	// A key may appear only once in a YAML mapping,
+	// so write only the last setting for each key.
+	last := make(map[string]int)
+	for i, s := range bi.Settings {
+		last[s.Key] = i
+	}
+	if len(last) == 0 {
+		buf.WriteString("settings: {}\n")
+	} else {
+		buf.WriteString("settings:\n")
+		for i, s := range bi.Settings {
+			if last[s.Key] == i {
+				buf.WriteString("  " + yamlScalar(s.Key) + ": " + yamlScalar(s.Value) + "\n")
+			}
+		}
+	}
+	return buf.Bytes()
+}
+
+// writeYAMLModule writes m as a block mapping. The first line begins
+// with first and the following lines with indent.
+func writeYAMLModule(buf *bytes.Buffer, first, indent string, m *Module) {
+	buf.WriteString(first + "path: " + yamlScalar(m.Path) + "\n")
+	buf.WriteString(indent + "version: " + yamlScalar(m.Version) + "\n")
+	if m.Sum != "" {
+		buf.WriteString(indent + "sum: " + yamlScalar(m.Sum) + "\n")
+	}
+	if m.Replace != nil {
+		buf.WriteString(indent + "replace:\n")
+		writeYAMLModule(buf, indent+"  ", indent+"  ", m.Replace)
+	}
+	if o := m.Origin; o != nil {
+		buf.WriteString(indent + "origin:\n")
+		buf.WriteString(indent + "  vcs: " + yamlScalar(o.VCS) + "\n")
+		buf.WriteString(indent + "  url: " + yamlScalar(o.URL) + "\n")
+		if o.Ref != "" {
+			buf.WriteString(indent + "  ref: " + yamlScalar(o.Ref) + "\n")
+		}
+		if o.Hash != "" {
+			buf.WriteString(indent + "  hash: " + yamlScalar(o.Hash) + "\n")
+		}
+	}
+	if m.License != "" {
+		buf.WriteString(indent + "license: " + yamlScalar(m.License) + "\n")
+	}
+}
+
+// yamlScalar returns s as a YAML scalar. The result of strconv.Quote
+// uses only escapes that YAML double-quoted scalars also define.
+func yamlScalar(s string) string {
+	if isPlainYAML(s) {
+		return s
+	}
+	return strconv.Quote(s)
+}
+
+// isPlainYAML reports whether s can be written as a plain YAML scalar
+// and still be read back as the same string. It accepts only strings
+// beginning with a letter and made of characters that never have
+// special meaning in YAML, excluding words that YAML 1.1 resolves to
+// booleans or null.
+func isPlainYAML(s string) bool {
+	if s == "" || !('a' <= s[0] && s[0] <= 'z' || 'A' <= s[0] && s[0] <= 'Z') {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+			strings.IndexByte("._/+()~-", c) >= 0) {
+			return false
+		}
+	}
+	switch strings.ToLower(s) {
+	case "y", "n", "yes", "no", "true", "false", "on", "off", "null":
+		return false
+	}
+	return true
+}
@@ -0,0 +1,176 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"fmt"
+	"reflect"
+	. "runtime/debug"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// parseYAML decodes the block-style subset of YAML produced by
+// BuildInfo.YAML: nested mappings, sequences of mappings,
+// plain and double-quoted scalars, and the empty flow collections.
+func parseYAML(data string) (interface{}, error) {
+	type line struct {
+		indent int
+		text   string
+	}
+	var lines []line
+	for _, l := range strings.Split(strings.TrimSuffix(data, "\n"), "\n") {
+		t := strings.TrimLeft(l, " ")
+		lines = append(lines, line{len(l) - len(t), t})
+	}
+	scalar := func(s string) (string, error) {
+		if strings.HasPrefix(s, `"`) {
+			return strconv.Unquote(s)
+		}
+		return s, nil
+	}
+	// splitKey splits a "key: value" or "key:" entry.
+	splitKey := func(t string) (key, value string, err error) {
+		if strings.HasPrefix(t, `"`) {
+			for i := 1; i < len(t); i++ {
+				if t[i] == '\\' {
+					i++
+				} else if t[i] == '"' {
+					key, t = t[:i+1], t[i+1:]
+					break
+				}
+			}
+		} else if i := strings.Index(t, ":"); i >= 0 {
+			key, t = t[:i], t[i:]
+		}
+		if !strings.HasPrefix(t, ":") {
+			return "", "", fmt.Errorf("missing colon in %q", t)
+		}
+		key, err = scalar(key)
+		return key, strings.TrimPrefix(t[1:], " "), err
+	}
+
+	var parse func(i, indent int) (interface{}, int, error)
+	parse = func(i, indent int) (interface{}, int, error) {
+		if strings.HasPrefix(lines[i].text, "- ") {
+			var seq []interface{}
+			for i < len(lines) && lines[i].indent == indent && strings.HasPrefix(lines[i].text, "- ") {
+				// Treat the item as a mapping indented past the dash.
+				lines[i].indent += 2
+				lines[i].text = lines[i].text[2:]
+				v, next, err := parse(i, indent+2)
+				if err != nil {
+					return nil, 0, err
+				}
+				seq = append(seq, v)
+				i = next
+			}
+			return seq, i, nil
+		}
+		m := make(map[string]interface{})
+		for i < len(lines) && lines[i].indent == indent {
+			key, value, err := splitKey(lines[i].text)
+			if err != nil {
+				return nil, 0, err
+			}
+			if _, dup := m[key]; dup {
+				return nil, 0, fmt.Errorf("duplicate key %q", key)
+			}
+			i++
+			switch value {
+			case "":
+				if i >= len(lines) || lines[i].indent < indent || lines[i].indent == indent && !strings.HasPrefix(lines[i].text, "- ") {
+					return nil, 0, fmt.Errorf("empty value for %q", key)
+				}
+				m[key], i, err = parse(i, lines[i].indent)
+			case "[]":
+				m[key] = []interface{}(nil)
+			case "{}":
+				m[key] = map[string]interface{}{}
+			default:
+				m[key], err = scalar(value)
+			}
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+		return m, i, nil
+	}
+	v, i, err := parse(0, 0)
+	if err == nil && i != len(lines) {
+		err = fmt.Errorf("unexpected line %q", lines[i].text)
+	}
+	return v, err
+}
+
+func yamlModule(m *Module) map[string]interface{} {
+	y := map[string]interface{}{"path": m.Path, "version": m.Version}
+	if m.Sum != "" {
+		y["sum"] = m.Sum
+	}
+	if m.Replace != nil {
+		y["replace"] = yamlModule(m.Replace)
+	}
+	if o := m.Origin; o != nil {
+		origin := map[string]interface{}{"vcs": o.VCS, "url": o.URL}
+		if o.Ref != "" {
+			origin["ref"] = o.Ref
+		}
+		if o.Hash != "" {
+			origin["hash"] = o.Hash
+		}
+		y["origin"] = origin
+	}
+	return y
+}
+
+func TestYAML(t *testing.T) {
+	info, ok := ReadBuildInfoData(blob(testModinfo))
+	if !ok {
+		t.Fatal("ReadBuildInfo failed")
+	}
+	info.GoVersion = "go1.15"
+	info.Deps[0].Version = "true"
+	info.Deps[1].Origin = &Origin{VCS: "git", URL: "https://github.com/rsc/quote", Ref: "refs/tags/v1.5.2"}
+	info.Settings = append(info.Settings,
+		BuildSetting{Key: "-ldflags", Value: `-X "main.msg=a: b # c"`},
+		BuildSetting{Key: "CGO_ENABLED", Value: "0"},
+		BuildSetting{Key: "empty", Value: ""})
+
+	out := info.YAML()
+	got, err := parseYAML(string(out))
+	if err != nil {
+		t.Fatalf("parsing YAML: %v\n%s", err, out)
+	}
+	want := map[string]interface{}{
+		"path":      info.Path,
+		"goVersion": info.GoVersion,
+		"main":      yamlModule(&info.Main),
+		"deps":      []interface{}{yamlModule(info.Deps[0]), yamlModule(info.Deps[1])},
+		"settings": map[string]interface{}{
+			"-compiler":   "gc",
+			"CGO_ENABLED": "0",
+			"-ldflags":    `-X "main.msg=a: b # c"`,
+			"empty":       "",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("YAML decoded to\n%v\nwant\n%v\nYAML:\n%s", got, want, out)
+	}
+	for _, plain := range []string{"path: example.com/cmd/hello\n", "  version: v1.2.3\n"} {
+		if !strings.Contains(string(out), plain) {
+			t.Errorf("YAML does not contain %q:\n%s", plain, out)
+		}
+	}
+
+	empty, err := parseYAML(string((&BuildInfo{}).YAML()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deps := empty.(map[string]interface{})["deps"]; deps != nil && len(deps.([]interface{})) != 0 {
+		t.Errorf("empty BuildInfo has deps %v", deps)
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"internal/race"
+	"os"
+	"runtime"
+	"strconv"
+)
+
+// A RunEnvironment describes the environment a program is running in:
+// the runtime settings and limits that, together with the build
+// information of the binary, bug reports most often need. Its fields
+// marshal to JSON as those of BuildInfo do.
+type RunEnvironment struct {
+	GOOS       string // runtime.GOOS
+	GOARCH     string // runtime.GOARCH
+	NumCPU     int    // runtime.NumCPU
+	GOMAXPROCS int    // runtime.GOMAXPROCS(0)
+
+	// GCPercent and MemoryLimit are the current settings of
+	// SetGCPercent and SetMemoryLimit, whatever set them.
+	GCPercent   int
+	MemoryLimit int64
+
+	// GODEBUG holds the current value of each GODEBUG setting the
+	// runtime reads, by name, including those left at their defaults.
+	GODEBUG map[string]int
+
+	// Env holds the values of the environment variables GOGC,
+	// GOMEMLIMIT, GODEBUG, GOMAXPROCS, and GOTRACEBACK, by name, as
+	// they were set as the program started, when this package was
+	// initialized; unset variables are left out. GODEBUG may hold
+	// settings read by packages other than the runtime.
+	Env map[string]string
+
+	// CgroupCPULimit and CgroupMemoryLimit are the limits on the CPU
+	// time, in CPUs, and memory, in bytes, of the control group the
+	// process runs in, as configured for a container, or 0 if none is
+	// set or found. Only Linux has control groups.
+	CgroupCPULimit    float64
+	CgroupMemoryLimit int64
+
+	Race bool // whether the race detector is enabled, as with -race
+	MSan bool // whether the memory sanitizer is enabled, as with -msan
+}
+
+// runEnvVars are the environment variables recorded in RunEnvironment.Env.
+var runEnvVars = []string{"GOGC", "GOMEMLIMIT", "GODEBUG", "GOMAXPROCS", "GOTRACEBACK"}
+
+// startEnv holds the values of runEnvVars when the package was
+// initialized, before the program could change them.
+var startEnv = func() map[string]string {
+	env := make(map[string]string)
+	for _, name := range runEnvVars {
+		if v, ok := os.LookupEnv(name); ok {
+			env[name] = v
+		}
+	}
+	return env
+}()
+
+// ReadRunEnvironment returns the environment the running program is
+// running in.
+func ReadRunEnvironment() RunEnvironment {
+	env := RunEnvironment{
+		GOOS:        runtime.GOOS,
+		GOARCH:      runtime.GOARCH,
+		NumCPU:      runtime.NumCPU(),
+		GOMAXPROCS:  runtime.GOMAXPROCS(0),
+		GCPercent:   GCPercent(),
+		MemoryLimit: SetMemoryLimit(-1),
+		GODEBUG:     make(map[string]int),
+		Env:         make(map[string]string, len(startEnv)),
+		Race:        race.Enabled,
+		MSan:        msanEnabled,
+	}
+	names, values := godebugSettings()
+	for i, name := range names {
+		env.GODEBUG[name] = int(values[i])
+	}
+	// The runtime keeps memprofilerate in runtime.MemProfileRate.
+	env.GODEBUG["memprofilerate"] = runtime.MemProfileRate
+	for k, v := range startEnv {
+		env.Env[k] = v
+	}
+	env.CgroupCPULimit, env.CgroupMemoryLimit = cgroupLimits()
+	return env
+}
+
+// parseCgroupInt parses the integer in the contents of a control group
+// file, returning 0 for "max", or a value of 1<<62 or more, as cgroup
+// v1 reports the absence of a memory limit, or for an invalid value.
+func parseCgroupInt(s string) int64 {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 || n >= 1<<62 {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,76 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"io/ioutil"
+	"strings"
+)
+
+// cgroupRoot is where the control group file systems are mounted.
+const cgroupRoot = "/sys/fs/cgroup"
+
+// cgroupLimits returns the CPU and memory limits of the control group
+// of the process, as listed in /proc/self/cgroup, in either version of
+// the control group file system, or 0 for each limit not set.
+func cgroupLimits() (cpu float64, mem int64) {
+	data, err := ioutil.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return 0, 0
+	}
+	// Each line is hierarchy-ID:controllers:path. Version 2 has a
+	// single hierarchy, with ID 0 and no controllers listed.
+	for _, line := range strings.Split(string(data), "\n") {
+		f := strings.SplitN(line, ":", 3)
+		if len(f) != 3 {
+			continue
+		}
+		if f[0] == "0" && f[1] == "" {
+			if max, ok := readCgroupFile("", f[2], "cpu.max"); ok {
+				if q := strings.Fields(max); len(q) == 2 {
+					if quota, period := parseCgroupInt(q[0]), parseCgroupInt(q[1]); quota > 0 && period > 0 {
+						cpu = float64(quota) / float64(period)
+					}
+				}
+			}
+			if max, ok := readCgroupFile("", f[2], "memory.max"); ok {
+				mem = parseCgroupInt(max)
+			}
+			continue
+		}
+		for _, c := range strings.Split(f[1], ",") {
+			switch c {
+			case "cpu":
+				q, ok1 := readCgroupFile("cpu", f[2], "cpu.cfs_quota_us")
+				p, ok2 := readCgroupFile("cpu", f[2], "cpu.cfs_period_us")
+				if quota, period := parseCgroupInt(q), parseCgroupInt(p); ok1 && ok2 && quota > 0 && period > 0 {
+					cpu = float64(quota) / float64(period)
+				}
+			case "memory":
+				if limit, ok := readCgroupFile("memory", f[2], "memory.limit_in_bytes"); ok {
+					mem = parseCgroupInt(limit)
+				}
+			}
+		}
+	}
+	return cpu, mem
+}
+
+// readCgroupFile returns the trimmed contents of the file of the control
+// group at path in the hierarchy mounted at controller below cgroupRoot.
+// A container commonly has the group of its process mounted as the root
+// of the hierarchy, so the file is also looked for there.
+func readCgroupFile(controller, path, file string) (string, bool) {
+	dir := cgroupRoot
+	if controller != "" {
+		dir += "/" + controller
+	}
+	for _, name := range []string{dir + path + "/" + file, dir + "/" + file} {
+		if data, err := ioutil.ReadFile(name); err == nil {
+			return strings.TrimSpace(string(data)), true
+		}
+	}
+	return "", false
+}
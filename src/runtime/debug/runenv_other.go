@@ -0,0 +1,12 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !linux
+
+package debug
+
+// cgroupLimits returns 0, 0, as only Linux has control groups.
+func cgroupLimits() (cpu float64, mem int64) {
+	return 0, 0
+}
@@ -0,0 +1,67 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"encoding/json"
+	"os"
+	"runtime"
+	. "runtime/debug"
+	"testing"
+)
+
+func TestReadRunEnvironment(t *testing.T) {
+	old := SetGCPercent(123)
+	defer SetGCPercent(old)
+
+	env := ReadRunEnvironment()
+	if env.GOOS != runtime.GOOS || env.GOARCH != runtime.GOARCH || env.NumCPU != runtime.NumCPU() || env.GOMAXPROCS != runtime.GOMAXPROCS(0) {
+		t.Errorf("ReadRunEnvironment() = %+v, want values of package runtime", env)
+	}
+	if env.GCPercent != 123 || env.MemoryLimit != SetMemoryLimit(-1) {
+		t.Errorf("GCPercent, MemoryLimit = %d, %d, want 123, %d", env.GCPercent, env.MemoryLimit, SetMemoryLimit(-1))
+	}
+	if _, ok := env.GODEBUG["gctrace"]; !ok {
+		t.Errorf("GODEBUG lacks gctrace: %v", env.GODEBUG)
+	}
+	if env.GODEBUG["memprofilerate"] != runtime.MemProfileRate {
+		t.Errorf("GODEBUG[memprofilerate] = %d, want %d", env.GODEBUG["memprofilerate"], runtime.MemProfileRate)
+	}
+	for _, name := range []string{"GOGC", "GODEBUG"} {
+		v, ok := os.LookupEnv(name)
+		if got, gotOK := env.Env[name]; got != v || gotOK != ok {
+			t.Errorf("Env[%s] = %q, %v, want %q, %v", name, got, gotOK, v, ok)
+		}
+	}
+	if env.CgroupCPULimit < 0 || env.CgroupMemoryLimit < 0 {
+		t.Errorf("cgroup limits %v, %d, want non-negative", env.CgroupCPULimit, env.CgroupMemoryLimit)
+	}
+
+	data, err := json.Marshal(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back RunEnvironment
+	if err := json.Unmarshal(data, &back); err != nil || back.GCPercent != 123 || back.GOOS != runtime.GOOS {
+		t.Errorf("JSON round trip = %+v, %v", back, err)
+	}
+}
+
+func TestParseCgroupInt(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want int64
+	}{
+		{"1073741824", 1 << 30},
+		{"max", 0},
+		{"-1", 0},
+		{"9223372036854771712", 0},
+		{"", 0},
+	} {
+		if got := ParseCgroupInt(tt.in); got != tt.want {
+			t.Errorf("parseCgroupInt(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
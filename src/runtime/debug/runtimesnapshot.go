@@ -0,0 +1,151 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"runtime"
+	"time"
+)
+
+// A RuntimeSnapshot holds the runtime statistics most often wanted for
+// reporting the health of a program, read at a single moment.
+type RuntimeSnapshot struct {
+	Time time.Time // when the snapshot was read
+
+	// Heap statistics, as in runtime.MemStats.
+	HeapAlloc    uint64 // bytes of allocated heap objects
+	HeapObjects  uint64 // number of allocated heap objects
+	HeapSys      uint64 // bytes of heap memory obtained from the OS
+	HeapIdle     uint64 // bytes in idle spans
+	HeapReleased uint64 // bytes of physical memory returned to the OS
+	HeapGoal     uint64 // heap size at which the next GC completes
+	TotalAlloc   uint64 // cumulative bytes allocated for heap objects
+	Sys          uint64 // total bytes of memory obtained from the OS
+
+	// Garbage collection statistics.
+	NumGC         uint32        // number of completed GC cycles
+	LastGC        time.Time     // when the last GC cycle completed
+	PauseTotal    time.Duration // total stop-the-world pause time
+	GCCPUFraction float64       // fraction of CPU time used by the GC
+
+	// Scheduler statistics. The goroutine counts exclude those
+	// the runtime starts for itself.
+	GOMAXPROCS         int
+	Threads            int // number of OS threads created
+	Goroutines         int // number of goroutines
+	GoroutinesRunning  int // of the goroutines, those running
+	GoroutinesRunnable int // those waiting for a thread to run on
+	GoroutinesWaiting  int // those blocked, as on a channel or lock
+	GoroutinesSyscall  int // those in a system call
+
+	// SchedLatency is the distribution of the time goroutines have
+	// spent runnable before running, since the program started,
+	// sampled for a fraction of the times they have become runnable.
+	SchedLatency LatencyHistogram
+}
+
+// A LatencyHistogram is a distribution of durations.
+// Counts[i] is the number of durations in the range
+// [Buckets[i], Buckets[i+1]), or at least Buckets[i] if i is the last.
+type LatencyHistogram struct {
+	Counts  []uint64
+	Buckets []time.Duration
+}
+
+// Total returns the number of durations in h.
+func (h LatencyHistogram) Total() uint64 {
+	var n uint64
+	for _, c := range h.Counts {
+		n += c
+	}
+	return n
+}
+
+// Quantile returns an upper bound on the q-quantile of the durations
+// in h, for q between 0 and 1: the end of the bucket in which it lies,
+// or, for the last bucket, the start. It returns 0 if h is empty.
+func (h LatencyHistogram) Quantile(q float64) time.Duration {
+	total := h.Total()
+	if total == 0 {
+		return 0
+	}
+	rank := uint64(q * float64(total))
+	if rank >= total {
+		rank = total - 1
+	}
+	var n uint64
+	for i, c := range h.Counts {
+		n += c
+		if n > rank {
+			if i+1 < len(h.Buckets) {
+				return h.Buckets[i+1]
+			}
+			return h.Buckets[i]
+		}
+	}
+	return h.Buckets[len(h.Buckets)-1]
+}
+
+// schedLatencyMinShift and schedLatencyNumBuckets describe the
+// runtime's buckets, and must match those in package runtime.
+const (
+	schedLatencyMinShift   = 10
+	schedLatencyNumBuckets = 22
+)
+
+// A runtimeSnapshot is the runtime's snapshot of its statistics.
+// It must match runtime.runtimeSnapshot.
+type runtimeSnapshot struct {
+	now          int64
+	mem          runtime.MemStats
+	running      int64
+	runnable     int64
+	waiting      int64
+	syscall      int64
+	threads      int64
+	gomaxprocs   int64
+	schedLatency [schedLatencyNumBuckets]uint64
+}
+
+// ReadRuntimeSnapshot reads the statistics in a RuntimeSnapshot.
+// They are consistent with one another, read with the other goroutines
+// stopped, as ReadMemStats reads its statistics, and so ReadRuntimeSnapshot
+// stops the program briefly.
+func ReadRuntimeSnapshot() RuntimeSnapshot {
+	var s runtimeSnapshot
+	readRuntimeSnapshot(&s)
+	snap := RuntimeSnapshot{
+		Time:               time.Unix(0, s.now),
+		HeapAlloc:          s.mem.HeapAlloc,
+		HeapObjects:        s.mem.HeapObjects,
+		HeapSys:            s.mem.HeapSys,
+		HeapIdle:           s.mem.HeapIdle,
+		HeapReleased:       s.mem.HeapReleased,
+		HeapGoal:           s.mem.NextGC,
+		TotalAlloc:         s.mem.TotalAlloc,
+		Sys:                s.mem.Sys,
+		NumGC:              s.mem.NumGC,
+		PauseTotal:         time.Duration(s.mem.PauseTotalNs),
+		GCCPUFraction:      s.mem.GCCPUFraction,
+		GOMAXPROCS:         int(s.gomaxprocs),
+		Threads:            int(s.threads),
+		Goroutines:         int(s.running + s.runnable + s.waiting + s.syscall),
+		GoroutinesRunning:  int(s.running),
+		GoroutinesRunnable: int(s.runnable),
+		GoroutinesWaiting:  int(s.waiting),
+		GoroutinesSyscall:  int(s.syscall),
+		SchedLatency: LatencyHistogram{
+			Counts:  s.schedLatency[:],
+			Buckets: make([]time.Duration, schedLatencyNumBuckets),
+		},
+	}
+	if s.mem.LastGC != 0 {
+		snap.LastGC = time.Unix(0, int64(s.mem.LastGC))
+	}
+	for i := 1; i < schedLatencyNumBuckets; i++ {
+		snap.SchedLatency.Buckets[i] = time.Duration(1) << (schedLatencyMinShift + i - 1)
+	}
+	return snap
+}
@@ -0,0 +1,83 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"runtime"
+	. "runtime/debug"
+	"testing"
+	"time"
+)
+
+func TestReadRuntimeSnapshot(t *testing.T) {
+	const n = 10
+	c := make(chan int)
+	defer close(c)
+	for i := 0; i < n; i++ {
+		go blockForStacks(c)
+	}
+	// Let the goroutines block, and give the scheduler
+	// latencies to sample.
+	for i := 0; i < 100; i++ {
+		runtime.Gosched()
+	}
+
+	before := time.Now()
+	s := ReadRuntimeSnapshot()
+	if s.Time.Before(before) || s.Time.After(time.Now()) {
+		t.Errorf("Time = %v, want between %v and now", s.Time, before)
+	}
+	if s.HeapAlloc == 0 || s.HeapSys < s.HeapAlloc || s.Sys < s.HeapSys || s.TotalAlloc < s.HeapAlloc {
+		t.Errorf("inconsistent heap statistics: %+v", s)
+	}
+	if s.GOMAXPROCS != runtime.GOMAXPROCS(0) {
+		t.Errorf("GOMAXPROCS = %d, want %d", s.GOMAXPROCS, runtime.GOMAXPROCS(0))
+	}
+	if s.Threads < 1 {
+		t.Errorf("Threads = %d, want at least 1", s.Threads)
+	}
+	if s.GoroutinesRunning < 1 || s.GoroutinesWaiting < n {
+		t.Errorf("GoroutinesRunning = %d, GoroutinesWaiting = %d, want at least 1 and %d", s.GoroutinesRunning, s.GoroutinesWaiting, n)
+	}
+	if sum := s.GoroutinesRunning + s.GoroutinesRunnable + s.GoroutinesWaiting + s.GoroutinesSyscall; s.Goroutines != sum {
+		t.Errorf("Goroutines = %d, want the sum of the counts by state, %d", s.Goroutines, sum)
+	}
+	if s.SchedLatency.Total() == 0 {
+		t.Errorf("no scheduling latencies sampled")
+	}
+	if len(s.SchedLatency.Counts) != len(s.SchedLatency.Buckets) {
+		t.Errorf("%d counts for %d buckets", len(s.SchedLatency.Counts), len(s.SchedLatency.Buckets))
+	}
+
+	runtime.GC()
+	s2 := ReadRuntimeSnapshot()
+	if s2.NumGC <= s.NumGC || s2.LastGC.Before(s.Time) {
+		t.Errorf("after GC, NumGC = %d, LastGC = %v; before, NumGC = %d at %v", s2.NumGC, s2.LastGC, s.NumGC, s.Time)
+	}
+}
+
+func TestLatencyHistogramQuantile(t *testing.T) {
+	h := LatencyHistogram{
+		Counts:  []uint64{1, 0, 8, 1},
+		Buckets: []time.Duration{0, 1, 2, 4},
+	}
+	for _, tt := range []struct {
+		q    float64
+		want time.Duration
+	}{
+		{0, 1},
+		{0.1, 4},
+		{0.5, 4},
+		{0.95, 4},
+		{1, 4},
+	} {
+		if got := h.Quantile(tt.q); got != tt.want {
+			t.Errorf("Quantile(%v) = %v, want %v", tt.q, got, tt.want)
+		}
+	}
+	if got := (LatencyHistogram{}).Quantile(0.5); got != 0 {
+		t.Errorf("Quantile of empty histogram = %v, want 0", got)
+	}
+}
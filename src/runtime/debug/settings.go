@@ -0,0 +1,101 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"strings"
+)
+
+// BuildFlags returns the build settings recording command-line flags,
+// those whose keys begin with '-' such as -ldflags or -trimpath,
+// keyed by flag name. It excludes environment settings such as
+// CGO_ENABLED. A flag recorded without a value maps to the empty string.
+// If a flag is recorded more than once, the last value wins.
+func (bi *BuildInfo) BuildFlags() map[string]string {
+	flags := make(map[string]string)
+	for _, s := range bi.Settings {
+		if strings.HasPrefix(s.Key, "-") {
+			flags[s.Key] = s.Value
+		}
+	}
+	return flags
+}
+
+// BuildMode returns the build mode recorded by the -buildmode setting,
+// such as "exe", "pie", "c-shared", or "plugin", and reports whether
+// the setting is present.
+func (bi *BuildInfo) BuildMode() (string, bool) {
+	return bi.setting("-buildmode")
+}
+
+// Setting returns the value of the build setting with the given key
+// and reports whether bi records it. If the key is recorded more than
+// once, the last value wins. Besides the settings recorded by the go
+// command, such as -compiler or vcs.revision, the key may be one of
+// the product's own, such as a build number or CI pipeline ID, added
+// to the text form by custom release tooling as a build line
+// "build\tkey=value"; MarshalText and UnmarshalText preserve the
+// build lines of any key.
+func (bi *BuildInfo) Setting(key string) (string, bool) {
+	return bi.setting(key)
+}
+
+// setting returns the value of the last build setting with the given key.
+func (bi *BuildInfo) setting(key string) (string, bool) {
+	for i := len(bi.Settings) - 1; i >= 0; i-- {
+		if bi.Settings[i].Key == key {
+			return bi.Settings[i].Value, true
+		}
+	}
+	return "", false
+}
+
+// settingsMap returns the build settings as a map,
+// with later settings overriding earlier ones.
+func (bi *BuildInfo) settingsMap() map[string]string {
+	m := make(map[string]string, len(bi.Settings))
+	for _, s := range bi.Settings {
+		m[s.Key] = s.Value
+	}
+	return m
+}
+
+// Tags returns the build tags given to the build with the -tags flag,
+// as recorded by the -tags build setting, or nil if there were none.
+// The go command records them separated by commas; the space-separated
+// form of older tools is accepted too. Tags implied by the build
+// environment, such as the GOOS, GOARCH, and cgo tags, are not included;
+// the GOOS, GOARCH, and CGO_ENABLED settings record those.
+func (bi *BuildInfo) Tags() []string {
+	v, _ := bi.setting("-tags")
+	tags := strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' })
+	if len(tags) == 0 {
+		return nil
+	}
+	return tags
+}
+
+// Trimpath reports whether the binary was built with -trimpath, which
+// removes file system paths from the binary, as recorded by the
+// -trimpath build setting.
+func (bi *BuildInfo) Trimpath() bool {
+	return bi.trimpath()
+}
+
+// Vendored reports whether the binary was built with -mod=vendor, as
+// recorded by the -mod build setting, loading its dependencies from the
+// main module's vendor directory rather than from the module cache. The
+// go command does not verify the contents of a vendor directory against
+// go.sum, and records no checksums for vendored dependencies.
+func (bi *BuildInfo) Vendored() bool {
+	v, _ := bi.setting("-mod")
+	return v == "vendor"
+}
+
+// trimpath reports whether bi was built with -trimpath.
+func (bi *BuildInfo) trimpath() bool {
+	v, ok := bi.setting("-trimpath")
+	return ok && v != "false"
+}
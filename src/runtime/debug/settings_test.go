@@ -0,0 +1,118 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"reflect"
+	. "runtime/debug"
+	"testing"
+)
+
+func TestBuildFlags(t *testing.T) {
+	info, ok := ReadBuildInfoData(blob("path\texample.com/cmd/hello\n" +
+		"mod\texample.com/hello\tv1.2.3\n" +
+		"build\t-compiler=gc\n" +
+		"build\tCGO_ENABLED=1\n" +
+		"build\t-ldflags=-s -X main.version=1\n" +
+		"build\t-trimpath\n" +
+		"build\tGOOS=linux\n"))
+	if !ok {
+		t.Fatal("ReadBuildInfo failed")
+	}
+	want := map[string]string{
+		"-compiler": "gc",
+		"-ldflags":  "-s -X main.version=1",
+		"-trimpath": "",
+	}
+	if got := info.BuildFlags(); !reflect.DeepEqual(got, want) {
+		t.Errorf("BuildFlags() = %v, want %v", got, want)
+	}
+	if n := len(info.Settings); n != 5 {
+		t.Errorf("len(Settings) = %d, want 5", n)
+	}
+}
+
+func TestBuildMode(t *testing.T) {
+	info := &BuildInfo{Settings: []BuildSetting{
+		{Key: "-compiler", Value: "gc"},
+		{Key: "-buildmode", Value: "pie"},
+	}}
+	if mode, ok := info.BuildMode(); mode != "pie" || !ok {
+		t.Errorf("BuildMode() = %q, %v, want %q, true", mode, ok, "pie")
+	}
+	info.Settings = info.Settings[:1]
+	if mode, ok := info.BuildMode(); mode != "" || ok {
+		t.Errorf("BuildMode() without -buildmode = %q, %v, want \"\", false", mode, ok)
+	}
+}
+
+func TestTags(t *testing.T) {
+	for _, tt := range []struct {
+		tags string
+		want []string
+	}{
+		{"", nil},
+		{"netgo", []string{"netgo"}},
+		{"netgo,osusergo", []string{"netgo", "osusergo"}},
+		{"netgo osusergo", []string{"netgo", "osusergo"}},
+		{",", nil},
+	} {
+		info := &BuildInfo{Settings: []BuildSetting{{Key: "-tags", Value: tt.tags}}}
+		if got := info.Tags(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tags() with -tags=%q = %q, want %q", tt.tags, got, tt.want)
+		}
+	}
+	if got := new(BuildInfo).Tags(); got != nil {
+		t.Errorf("Tags() without -tags = %q, want nil", got)
+	}
+}
+
+func TestVendoredTrimpath(t *testing.T) {
+	for _, tt := range []struct {
+		settings           []BuildSetting
+		vendored, trimpath bool
+	}{
+		{nil, false, false},
+		{[]BuildSetting{{Key: "-mod", Value: "vendor"}, {Key: "-trimpath", Value: "true"}}, true, true},
+		{[]BuildSetting{{Key: "-mod", Value: "mod"}}, false, false},
+		{[]BuildSetting{{Key: "-mod", Value: "readonly"}, {Key: "-trimpath"}}, false, true},
+		{[]BuildSetting{{Key: "-trimpath", Value: "false"}}, false, false},
+	} {
+		info := &BuildInfo{Settings: tt.settings}
+		if got := info.Vendored(); got != tt.vendored {
+			t.Errorf("Vendored() with settings %v = %v, want %v", tt.settings, got, tt.vendored)
+		}
+		if got := info.Trimpath(); got != tt.trimpath {
+			t.Errorf("Trimpath() with settings %v = %v, want %v", tt.settings, got, tt.trimpath)
+		}
+	}
+}
+
+func TestSetting(t *testing.T) {
+	text := testModinfo +
+		"build\tcom.example.build=1234\n" +
+		"build\tcom.example.artifact=https://ci.example.com/a?b=c\n" +
+		"build\tcom.example.build=1235\n"
+	info, ok := ReadBuildInfoData(blob(text))
+	if !ok {
+		t.Fatal("ReadBuildInfo failed")
+	}
+	for _, tt := range []struct {
+		key, want string
+		ok        bool
+	}{
+		{"com.example.build", "1235", true},
+		{"com.example.artifact", "https://ci.example.com/a?b=c", true},
+		{"-compiler", "gc", true},
+		{"com.example.missing", "", false},
+	} {
+		if v, ok := info.Setting(tt.key); v != tt.want || ok != tt.ok {
+			t.Errorf("Setting(%q) = %q, %v, want %q, %v", tt.key, v, ok, tt.want, tt.ok)
+		}
+	}
+	if out, _ := info.MarshalText(); string(out) != formatHeader+text {
+		t.Errorf("MarshalText:\n%s\nwant:\n%s", out, formatHeader+text)
+	}
+}
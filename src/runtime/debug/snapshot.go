@@ -0,0 +1,95 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"sort"
+	"time"
+)
+
+// A Snapshot records the goroutines of a program at a point in time,
+// grouped by the go statement that created them, so that a later
+// snapshot may show which have grown in number: the usual sign of a
+// goroutine leak.
+type Snapshot struct {
+	Time   time.Time
+	Groups []GoroutineGroup // in decreasing order of Count
+}
+
+// A GoroutineGroup is a set of goroutines created by the same go statement.
+type GoroutineGroup struct {
+	CreatedBy StackFrame // as in GoroutineStack, with no PC
+	IDs       []int64    // the IDs of the goroutines, in increasing order
+}
+
+// GoroutineSnapshot returns a snapshot of the goroutines of the program,
+// including the calling goroutine. The other goroutines are stopped
+// while they are recorded, but their stacks are not.
+func GoroutineSnapshot() *Snapshot {
+	recs, _ := goroutineRecords(0, 0)
+	s := &Snapshot{Time: time.Now()}
+	index := make(map[StackFrame]int)
+	for _, r := range recs {
+		site := createdBy(r.gopc)
+		// The PCs of a go statement inlined into
+		// different functions differ.
+		site.PC = 0
+		i, ok := index[site]
+		if !ok {
+			i = len(s.Groups)
+			index[site] = i
+			s.Groups = append(s.Groups, GoroutineGroup{CreatedBy: site})
+		}
+		s.Groups[i].IDs = append(s.Groups[i].IDs, r.id)
+	}
+	for _, g := range s.Groups {
+		sort.Slice(g.IDs, func(i, j int) bool { return g.IDs[i] < g.IDs[j] })
+	}
+	sort.SliceStable(s.Groups, func(i, j int) bool {
+		return len(s.Groups[i].IDs) > len(s.Groups[j].IDs)
+	})
+	return s
+}
+
+// A GoroutineGrowth reports the growth of a group of goroutines
+// between two snapshots.
+type GoroutineGrowth struct {
+	CreatedBy     StackFrame
+	Before, After int     // the numbers of goroutines in the two snapshots
+	New           []int64 // the IDs of the goroutines not in the earlier snapshot
+}
+
+// Diff compares s with other, a later snapshot, and returns the groups
+// of goroutines that have more goroutines in other than in s, in
+// decreasing order of growth. Goroutines that exit and are replaced by
+// as many new ones do not count as growth.
+func (s *Snapshot) Diff(other *Snapshot) []GoroutineGrowth {
+	before := make(map[StackFrame]map[int64]bool)
+	for _, g := range s.Groups {
+		ids := make(map[int64]bool)
+		for _, id := range g.IDs {
+			ids[id] = true
+		}
+		before[g.CreatedBy] = ids
+	}
+	var growth []GoroutineGrowth
+	for _, g := range other.Groups {
+		old := before[g.CreatedBy]
+		if len(g.IDs) <= len(old) {
+			continue
+		}
+		gr := GoroutineGrowth{CreatedBy: g.CreatedBy, Before: len(old), After: len(g.IDs)}
+		for _, id := range g.IDs {
+			if !old[id] {
+				gr.New = append(gr.New, id)
+			}
+		}
+		growth = append(growth, gr)
+	}
+	sort.SliceStable(growth, func(i, j int) bool {
+		return growth[i].After-growth[i].Before > growth[j].After-growth[j].Before
+	})
+	return growth
+}
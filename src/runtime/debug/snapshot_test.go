@@ -0,0 +1,57 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	. "runtime/debug"
+	"strings"
+	"testing"
+	"time"
+)
+
+func leak(c chan int, n int) {
+	for i := 0; i < n; i++ {
+		go func() { <-c }()
+	}
+}
+
+func TestGoroutineSnapshot(t *testing.T) {
+	before := GoroutineSnapshot()
+	if len(before.Groups) == 0 {
+		t.Fatal("GoroutineSnapshot found no goroutines")
+	}
+	for i := 1; i < len(before.Groups); i++ {
+		if len(before.Groups[i].IDs) > len(before.Groups[i-1].IDs) {
+			t.Errorf("groups not in decreasing order of size")
+		}
+	}
+
+	c := make(chan int)
+	defer close(c)
+	leak(c, 5)
+
+	after := GoroutineSnapshot()
+	growth := before.Diff(after)
+	if len(growth) != 1 {
+		t.Fatalf("Diff reported %d groups grown, want 1: %+v", len(growth), growth)
+	}
+	g := growth[0]
+	if g.CreatedBy.Func != "runtime/debug_test.leak" || !strings.HasSuffix(g.CreatedBy.File, "snapshot_test.go") {
+		t.Errorf("growth created by %+v, want leak in snapshot_test.go", g.CreatedBy)
+	}
+	if g.Before != 0 || g.After != 5 || len(g.New) != 5 {
+		t.Errorf("growth from %d to %d with %d new IDs, want from 0 to 5 with 5", g.Before, g.After, len(g.New))
+	}
+
+	if growth := after.Diff(after); len(growth) != 0 {
+		t.Errorf("snapshot differs from itself: %+v", growth)
+	}
+	if growth := after.Diff(before); len(growth) != 0 {
+		t.Errorf("Diff of an earlier snapshot reported growth: %+v", growth)
+	}
+	if after.Time.Before(before.Time) || time.Since(after.Time) > time.Minute {
+		t.Errorf("snapshot times %v and %v out of order", before.Time, after.Time)
+	}
+}
@@ -7,8 +7,15 @@
 package debug
 
 import (
+	"bufio"
+	"io"
 	"os"
 	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unsafe"
 )
 
 // PrintStack prints to standard error the stack trace returned by runtime.Stack.
@@ -16,15 +23,542 @@ func PrintStack() {
 	os.Stderr.Write(Stack())
 }
 
+// FprintStack writes to w a stack trace of the goroutine that calls it,
+// beginning with the caller of FprintStack. The trace is in the form
+// printed by PrintStack, except that arguments are elided, as for
+// inlined calls, and the runtime's own frames are left out, as is the
+// goroutine's creator. FprintStack returns any error writing to w.
+func FprintStack(w io.Writer) error {
+	return fprintStack(w, 0, 2)
+}
+
+// FprintStackDepth is like FprintStack but writes at most depth frames,
+// then notes that the rest were elided, as tracebacks cut off by the
+// runtime do. If depth is zero or negative, all frames are written.
+func FprintStackDepth(w io.Writer, depth int) error {
+	return fprintStack(w, depth, 2)
+}
+
+// fprintStack implements FprintStack and FprintStackDepth. The trace
+// begins skip frames above the caller of fprintStack.
+func fprintStack(w io.Writer, depth, skip int) error {
+	b := bufio.NewWriter(w)
+	b.WriteString("goroutine ")
+	b.WriteString(strconv.FormatInt(goroutineID(), 10))
+	b.WriteString(" [running]:\n")
+	n := 0
+	for _, f := range stackFrames(callers(skip), 0) {
+		if strings.HasPrefix(f.Func, "runtime.") {
+			continue
+		}
+		if depth > 0 && n == depth {
+			b.WriteString("...additional frames elided...\n")
+			break
+		}
+		writeFrame(b, f)
+		n++
+	}
+	return b.Flush()
+}
+
+// writeFrame writes f to b in the form printed by fprintStack.
+func writeFrame(b *bufio.Writer, f StackFrame) {
+	b.WriteString(f.Func)
+	b.WriteString("(...)\n\t")
+	if opts, _ := stackFormat.Load().(StackFormatOptions); opts.TrimPathPrefix != "" {
+		b.WriteString(strings.TrimPrefix(f.File, opts.TrimPathPrefix))
+	} else {
+		b.WriteString(f.File)
+	}
+	b.WriteByte(':')
+	b.WriteString(strconv.Itoa(f.Line))
+	b.WriteByte('\n')
+}
+
 // Stack returns a formatted stack trace of the goroutine that calls it.
 // It calls runtime.Stack with a large enough buffer to capture the entire trace.
+// The trace leaves out the frames rejected by the filter set by
+// SetTracebackFilter. If a Symbolizer is set by SetSymbolizer, the
+// trace is instead in the form written by FprintStack, still beginning
+// with Stack itself, so that the Symbolizer names its frames.
 func Stack() []byte {
-	buf := make([]byte, 1024)
+	buf, ok := symbolizedStack()
+	if ok {
+		if filter, _ := tracebackFilter.Load().(func(StackFrame) bool); filter != nil {
+			return filterTrace(buf, filter)
+		}
+		return buf
+	}
+	buf = make([]byte, 1024)
 	for {
 		n := runtime.Stack(buf, false)
 		if n < len(buf) {
+			if filter, _ := tracebackFilter.Load().(func(StackFrame) bool); filter != nil {
+				return filterTrace(buf[:n], filter)
+			}
 			return buf[:n]
 		}
 		buf = make([]byte, 2*len(buf))
 	}
 }
+
+// tracebackFilter holds the filter set by SetTracebackFilter.
+var tracebackFilter atomic.Value
+
+// SetTracebackFilter sets a filter for the frames of the traces
+// returned by Stack and RecoverWithStack, so that frameworks may hide
+// their own frames and show those of their users first: the traces
+// leave out the frames for which filter returns false. The frames
+// passed to filter by Stack, which are parsed from its text, have no
+// PC. The goroutine's creator, shown by Stack, is never left out. If
+// filter is nil, as initially, the traces are not filtered.
+//
+// The tracebacks printed by the runtime when the program crashes are
+// not filtered, as it is not safe to run the program's code then.
+func SetTracebackFilter(filter func(frame StackFrame) bool) {
+	tracebackFilter.Store(filter)
+}
+
+// filterTrace returns trace, as formatted by runtime.Stack, without the
+// frames for which filter returns false.
+func filterTrace(trace []byte, filter func(StackFrame) bool) []byte {
+	lines := strings.SplitAfter(string(trace), "\n")
+	out := make([]byte, 0, len(trace))
+	var id int64
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.HasPrefix(line, "goroutine ") {
+			if sp := strings.IndexByte(line[len("goroutine "):], ' '); sp >= 0 {
+				id, _ = strconv.ParseInt(line[len("goroutine "):len("goroutine ")+sp], 10, 64)
+			}
+		}
+		if i+1 == len(lines) || !strings.HasPrefix(lines[i+1], "\t") || strings.HasPrefix(line, "created by ") {
+			out = append(out, line...)
+			continue
+		}
+		// A frame: a function and its arguments,
+		// then a tab and the file and line number.
+		f := StackFrame{GoroutineID: id}
+		if paren := strings.LastIndexByte(line, '('); paren >= 0 {
+			f.Func = line[:paren]
+		}
+		if f.Func == "panic" {
+			f.Func = "runtime.gopanic"
+		}
+		pos := strings.TrimSuffix(lines[i+1][1:], "\n")
+		if sp := strings.IndexByte(pos, ' '); sp >= 0 {
+			pos = pos[:sp]
+		}
+		if colon := strings.LastIndexByte(pos, ':'); colon >= 0 {
+			f.File = pos[:colon]
+			f.Line, _ = strconv.Atoi(pos[colon+1:])
+		}
+		if filter(f) {
+			out = append(out, line...)
+			out = append(out, lines[i+1]...)
+		}
+		i++
+	}
+	return out
+}
+
+// StackFormatOptions says which parts of the frames of stack traces
+// to leave out or shorten, so that traces may be shipped to third
+// parties without revealing the values of pointers or the layout of
+// the machine that built the program. The zero StackFormatOptions
+// leaves traces as they are.
+type StackFormatOptions struct {
+	// HideArgs prints "..." in place of the arguments of each call,
+	// which may include pointers, as for inlined calls.
+	HideArgs bool
+
+	// HideAddresses omits the offsets of the program counters
+	// within their functions, such as "+0x1d", and the frame
+	// addresses printed by crashing programs.
+	HideAddresses bool
+
+	// TrimPathPrefix, if not empty, is removed from the start of
+	// the file names that begin with it.
+	TrimPathPrefix string
+}
+
+// stackFormat holds the StackFormatOptions set by SetStackFormat.
+var stackFormat atomic.Value
+
+// SetStackFormat sets the format of the stack traces printed by the
+// runtime, as returned by Stack and runtime.Stack and printed when the
+// program crashes or at the request of GOTRACEBACK. FprintStack and
+// the dumps written by EnableSignalDump, which never print arguments
+// or addresses, also follow opts.TrimPathPrefix. The setting does not
+// change the frames returned by CapturedStack and the like, nor those
+// of runtime.Callers.
+func SetStackFormat(opts StackFormatOptions) {
+	stackFormat.Store(opts)
+	setStackFormat(opts.HideArgs, opts.HideAddresses, opts.TrimPathPrefix)
+}
+
+// CrashInfo describes the crash of a program.
+type CrashInfo struct {
+	// Value is the value passed to panic, for an unrecovered panic.
+	Value interface{}
+
+	// Error is the message of a fatal error, such as
+	// "concurrent map writes", for a fatal error.
+	Error string
+
+	// Signal is the number of the signal that caused the crash, as for
+	// a nil pointer dereference, or 0. For a fault, SigCode is the
+	// signal's code and SigAddr the faulting address.
+	Signal  int
+	SigCode uintptr
+	SigAddr uintptr
+
+	// NumGoroutine is the number of goroutines, as runtime.NumGoroutine
+	// would report.
+	NumGoroutine int
+}
+
+// SetCrashHandler arranges for f to be called when the program crashes
+// with an unrecovered panic or a fatal error, such as concurrent map
+// writes or a deadlock, before the runtime prints the traceback, so
+// that crash reporters need not wrap the main function. A nil f
+// removes the handler. f cannot recover the panic, nor prevent the crash.
+//
+// For a panic, f runs on the panicking goroutine, after its deferred
+// calls, so CapturedStack reports the stack of the panic.
+// For a fatal error, the call is best effort: f runs on a goroutine of
+// its own, while the goroutine that failed waits for at most a second,
+// holding its processor; with GOMAXPROCS set to 1, f does not run. Nor
+// does it run for fatal errors in the runtime's own critical sections,
+// where even starting a goroutine could fail.
+// f runs at most once, and should not panic itself.
+func SetCrashHandler(f func(CrashInfo)) {
+	if f == nil {
+		setCrashHandler(nil)
+		return
+	}
+	setCrashHandler(func() {
+		defer fatalHandlerDone()
+		value, msg, sig, code, addr, n := readCrashEvent()
+		f(CrashInfo{
+			Value:        value,
+			Error:        msg,
+			Signal:       int(sig),
+			SigCode:      code,
+			SigAddr:      addr,
+			NumGoroutine: n,
+		})
+	})
+}
+
+// RecoverWithStack is recover for error reporting. Called directly by a
+// deferred function, it recovers the panic, if any, as recover would,
+// and returns the panic value together with the stack of the panicking
+// goroutine at the panic site, beginning with the function that called
+// panic, or in which a run-time error such as a nil pointer dereference
+// occurred. It reports whether there was a panic to recover; if not, or
+// when not called directly by a deferred function, it returns nil, nil,
+// false and the panic, if any, continues. The stack leaves out the
+// frames rejected by the filter set by SetTracebackFilter.
+func RecoverWithStack() (val interface{}, stack []StackFrame, ok bool) {
+	val, ok = recoverCaller()
+	if !ok {
+		return nil, nil, false
+	}
+	frames := stackFrames(callers(1), goroutineID())
+	for i, f := range frames {
+		if f.Func == "runtime.gopanic" {
+			// Leave out the runtime's frames between
+			// the panic and the panic site.
+			for frames = frames[i+1:]; len(frames) > 0 && strings.HasPrefix(frames[0].Func, "runtime."); {
+				frames = frames[1:]
+			}
+			break
+		}
+	}
+	if filter, _ := tracebackFilter.Load().(func(StackFrame) bool); filter != nil {
+		kept := frames[:0]
+		for _, f := range frames {
+			if filter(f) {
+				kept = append(kept, f)
+			}
+		}
+		frames = kept
+	}
+	return val, frames, true
+}
+
+// A StackFrame is a frame of a goroutine's stack, as printed by Stack.
+type StackFrame struct {
+	Func        string  // package path-qualified function name, as runtime.Frame.Function
+	File        string  // file name
+	Line        int     // line number
+	PC          uintptr // program counter, as runtime.Frame.PC
+	GoroutineID int64   // ID of the goroutine, as printed by Stack
+}
+
+// CapturedStack returns the stack of the goroutine that calls it,
+// beginning with the caller of CapturedStack, as Stack would format it.
+// Inlined calls appear as frames of their own, as in Stack.
+func CapturedStack() []StackFrame {
+	return stackFrames(callers(1), goroutineID())
+}
+
+// callers returns the return program counters of the stack of the
+// calling goroutine, beginning skip frames above the caller of callers.
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, 64)
+	for {
+		n := runtime.Callers(skip+2, pcs)
+		if n < len(pcs) {
+			return pcs[:n]
+		}
+		pcs = make([]uintptr, 2*len(pcs))
+	}
+}
+
+// The depth to which CapturedStacks and AllStacks record each stack.
+// It matches that of the tracebacks printed by the runtime.
+const capturedStackDepth = 100
+
+// CapturedStacks returns the stacks of all goroutines, as Stack would
+// format them with runtime.Stack(buf, true): one slice of frames per
+// goroutine, that of the calling goroutine first. Each stack is cut off
+// after its first 100 frames. The other goroutines are stopped while
+// their stacks are recorded.
+func CapturedStacks() [][]StackFrame {
+	var stacks [][]StackFrame
+	for _, g := range allStacks(StackOptions{}) {
+		stacks = append(stacks, g.Frames)
+	}
+	return stacks
+}
+
+// A GoroutineStack describes a goroutine and its stack.
+type GoroutineStack struct {
+	ID     int64
+	State  string // as printed by Stack, such as "running" or "chan receive"
+	Frames []StackFrame
+
+	// Blocked reports whether the goroutine is waiting, as on a
+	// channel, lock, timer, or network I/O, rather than running,
+	// runnable, or in a system call.
+	Blocked bool
+
+	// WaitDuration is roughly how long the goroutine has been blocked
+	// or in a system call. The runtime notes when a goroutine blocked
+	// only during garbage collection, so WaitDuration is 0 for a
+	// goroutine blocked since the last collection began.
+	WaitDuration time.Duration
+
+	// Labels holds the goroutine's profiler labels,
+	// as set by runtime/pprof.SetGoroutineLabels.
+	Labels map[string]string
+
+	// CreatedBy is the go statement that created the goroutine,
+	// as Stack prints it. Its Func is empty for the goroutines
+	// the runtime starts, such as the main goroutine.
+	CreatedBy StackFrame
+}
+
+// A GoroutineState selects goroutines by whether they are blocked.
+type GoroutineState int
+
+const (
+	AnyGoroutine     GoroutineState = iota // all goroutines
+	GoroutineRunning                       // goroutines running, runnable, or in a system call
+	GoroutineBlocked                       // goroutines blocked, as GoroutineStack.Blocked reports
+)
+
+// StackOptions selects the goroutines whose stacks AllStacks returns.
+// The zero StackOptions selects every goroutine.
+type StackOptions struct {
+	// Labels, if not empty, selects goroutines with every one of
+	// these profiler labels. An empty value matches only an empty label.
+	Labels map[string]string
+
+	// State selects goroutines by state.
+	State GoroutineState
+
+	// MinWait, if positive, selects goroutines whose WaitDuration is
+	// at least MinWait.
+	MinWait time.Duration
+}
+
+// AllStacks returns the stacks of the goroutines selected by opts, that
+// of the calling goroutine first if it is selected, then the others in
+// the order printed by runtime.Stack(buf, true). Each stack is cut off
+// after its first 100 frames. The other goroutines are stopped while
+// their stacks are recorded, but the stacks of goroutines not selected
+// are not symbolized.
+func AllStacks(opts StackOptions) []GoroutineStack {
+	return allStacks(opts)
+}
+
+// A goroutineRecord describes a goroutine, as recorded by the runtime.
+// It must match runtime.goroutineRecord.
+type goroutineRecord struct {
+	id      int64
+	status  string
+	blocked bool
+	waited  int64
+	labels  unsafe.Pointer // *runtime/pprof.labelMap
+	gopc    uintptr
+}
+
+// goroutineRecords returns the runtime's records of the goroutines,
+// that of the calling goroutine first, and their stacks, up to depth
+// frames each, as goroutineStacks records them. The stack of the
+// calling goroutine begins skip frames above the caller of
+// goroutineRecords.
+func goroutineRecords(depth, skip int) ([]goroutineRecord, []uintptr) {
+	for n := runtime.NumGoroutine(); ; {
+		// Allocate a little more than needed in case
+		// goroutines are created in the meantime.
+		recs := make([]goroutineRecord, n+10)
+		stk := make([]uintptr, len(recs)*depth)
+		var ok bool
+		if n, ok = goroutineStacks(recs, stk, skip+2); ok {
+			return recs[:n], stk
+		}
+	}
+}
+
+// allStacks implements AllStacks. It is called by the exported
+// functions directly, so that the calling goroutine's stack
+// begins with their callers.
+func allStacks(opts StackOptions) []GoroutineStack {
+	recs, stk := goroutineRecords(capturedStackDepth, 1)
+	var stacks []GoroutineStack
+	for i, r := range recs {
+		g := GoroutineStack{
+			ID:           r.id,
+			State:        r.status,
+			Blocked:      r.blocked,
+			WaitDuration: time.Duration(r.waited),
+			CreatedBy:    createdBy(r.gopc),
+		}
+		if r.labels != nil {
+			g.Labels = make(map[string]string)
+			for k, v := range *(*map[string]string)(r.labels) {
+				g.Labels[k] = v
+			}
+		}
+		if !opts.selects(&g) {
+			continue
+		}
+		pcs := stk[i*capturedStackDepth : (i+1)*capturedStackDepth]
+		for j, pc := range pcs {
+			if pc == 0 {
+				pcs = pcs[:j]
+				break
+			}
+		}
+		g.Frames = stackFrames(pcs, r.id)
+		stacks = append(stacks, g)
+	}
+	return stacks
+}
+
+// selects reports whether opts selects the goroutine g.
+func (opts *StackOptions) selects(g *GoroutineStack) bool {
+	switch opts.State {
+	case GoroutineRunning:
+		if g.Blocked {
+			return false
+		}
+	case GoroutineBlocked:
+		if !g.Blocked {
+			return false
+		}
+	}
+	if opts.MinWait > 0 && g.WaitDuration < opts.MinWait {
+		return false
+	}
+	for k, v := range opts.Labels {
+		if have, ok := g.Labels[k]; !ok || have != v {
+			return false
+		}
+	}
+	return true
+}
+
+// createdBy returns the frame of the go statement at gopc, with no
+// goroutine ID, or the zero StackFrame if the runtime started the
+// goroutine.
+func createdBy(gopc uintptr) StackFrame {
+	if gopc == 0 {
+		return StackFrame{}
+	}
+	f, _ := runtime.CallersFrames([]uintptr{gopc}).Next()
+	if f.Function == "" || strings.HasPrefix(f.Function, "runtime.") {
+		// Like Stack, do not report the runtime's own go statements.
+		return StackFrame{}
+	}
+	return symbolize(StackFrame{Func: f.Function, File: f.File, Line: f.Line, PC: f.PC})
+}
+
+// stackFrames returns the frames of the stack of goroutine id
+// at the return program counters pcs, leaving out runtime.goexit,
+// which Stack does not print, as named by the Symbolizer, if any.
+func stackFrames(pcs []uintptr, id int64) []StackFrame {
+	var frames []StackFrame
+	if len(pcs) == 0 {
+		return frames
+	}
+	iter := runtime.CallersFrames(pcs)
+	for {
+		f, more := iter.Next()
+		if f.Function != "runtime.goexit" {
+			frames = append(frames, symbolize(StackFrame{
+				Func:        f.Function,
+				File:        f.File,
+				Line:        f.Line,
+				PC:          f.PC,
+				GoroutineID: id,
+			}))
+		}
+		if !more {
+			return frames
+		}
+	}
+}
+
+// CrashOptions provides options that control the
+// formatting of the fatal crash message.
+type CrashOptions struct {
+	/* for future expansion */
+}
+
+// SetCrashOutput configures a single additional file where unhandled
+// panics and other fatal errors are printed, in addition to standard error.
+// There is only one additional file: calling SetCrashOutput again overrides
+// any earlier call. SetCrashOutput duplicates f's file descriptor, so the
+// caller may safely close f as soon as SetCrashOutput returns.
+// To disable this additional crash output, call SetCrashOutput(nil).
+// If called concurrently with a crash, some in-progress output may be
+// written to the old file even after an overriding SetCrashOutput
+// returns.
+func SetCrashOutput(f *os.File, opts CrashOptions) error {
+	fd := ^uintptr(0)
+	if f != nil {
+		// The runtime writes to the file descriptor from low-level
+		// routines during a crash, so it must remain valid however
+		// the caller uses f afterward. A private duplicate cannot be
+		// closed by the caller, nor reused for another file, and is
+		// close-on-exec so that a crash monitor run as a child
+		// process sees EOF when this process dies.
+		fd2, err := dupCrashFile(f)
+		if err != nil {
+			return err
+		}
+		runtime.KeepAlive(f) // prevent finalization before the dup
+		fd = fd2
+	}
+	if prev := setCrashFD(fd); prev != ^uintptr(0) {
+		// os.File.Close is portable, unlike syscall.Close,
+		// whose parameter type varies.
+		os.NewFile(prev, "").Close() // ignore error
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"os"
+	"syscall"
+)
+
+// dupCrashFile returns a duplicate of f's file descriptor.
+func dupCrashFile(f *os.File) (uintptr, error) {
+	fd, err := syscall.Dup(int(f.Fd()), -1)
+	if err != nil {
+		return 0, os.NewSyscallError("dup", err)
+	}
+	return uintptr(fd), nil
+}
@@ -5,9 +5,23 @@
 package debug_test
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"internal/testenv"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
 	. "runtime/debug"
+	"runtime/pprof"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 type T int
@@ -58,8 +72,596 @@ func TestStack(t *testing.T) {
 	frame("src/testing/testing.go", "")
 }
 
+func TestCapturedStack(t *testing.T) {
+	frames := T(0).capture()
+	if len(frames) < 3 {
+		t.Fatalf("CapturedStack returned %d frames, want at least 3", len(frames))
+	}
+	for i, fn := range []string{
+		"runtime/debug_test.T.capture",
+		"runtime/debug_test.TestCapturedStack",
+		"testing.tRunner",
+	} {
+		if frames[i].Func != fn {
+			t.Errorf("frame %d: Func = %q, want %q", i, frames[i].Func, fn)
+		}
+	}
+	if !strings.HasSuffix(frames[0].File, "src/runtime/debug/stack_test.go") || frames[0].Line == 0 || frames[0].PC == 0 {
+		t.Errorf("frame 0 = %+v, want a position in stack_test.go", frames[0])
+	}
+	id := frames[0].GoroutineID
+	if id <= 0 {
+		t.Errorf("GoroutineID = %d, want > 0", id)
+	}
+	for _, f := range frames {
+		if f.GoroutineID != id {
+			t.Errorf("frame %+v has a different goroutine ID than frame 0", f)
+		}
+		if f.Func == "runtime.goexit" {
+			t.Errorf("stack includes runtime.goexit")
+		}
+	}
+	if !strings.Contains(string(Stack()), "goroutine "+strconv.FormatInt(id, 10)+" ") {
+		t.Errorf("Stack does not print goroutine ID %d", id)
+	}
+}
+
+func (T) capture() []StackFrame {
+	return CapturedStack()
+}
+
+func blockForStacks(c chan int) {
+	<-c
+}
+
+func TestCapturedStacks(t *testing.T) {
+	c := make(chan int)
+	defer close(c)
+	started := make(chan bool)
+	go func() {
+		started <- true
+		blockForStacks(c)
+	}()
+	<-started
+
+	var blocked []StackFrame
+	for tries := 0; blocked == nil && tries < 100; tries++ {
+		stacks := CapturedStacks()
+		if len(stacks) < 2 {
+			t.Fatalf("CapturedStacks returned %d stacks, want at least 2", len(stacks))
+		}
+		if f := stacks[0][0]; f.Func != "runtime/debug_test.TestCapturedStacks" {
+			t.Fatalf("first stack begins with %s, want the caller of CapturedStacks", f.Func)
+		}
+		for _, frames := range stacks[1:] {
+			for _, f := range frames {
+				if f.Func == "runtime/debug_test.blockForStacks" {
+					blocked = frames
+				}
+			}
+		}
+		if blocked == nil {
+			// The goroutine has not yet blocked.
+			time.Sleep(time.Millisecond)
+		}
+	}
+	if blocked == nil {
+		t.Fatal("no stack includes blockForStacks")
+	}
+	if blocked[0].GoroutineID == CapturedStack()[0].GoroutineID {
+		t.Errorf("blocked goroutine has the ID of the calling goroutine")
+	}
+}
+
+func TestAllStacks(t *testing.T) {
+	c := make(chan int)
+	defer close(c)
+	go pprof.Do(context.Background(), pprof.Labels("test", "TestAllStacks"), func(context.Context) {
+		blockForStacks(c)
+	})
+	labels := map[string]string{"test": "TestAllStacks"}
+
+	var stacks []GoroutineStack
+	for tries := 0; tries < 100; tries++ {
+		stacks = AllStacks(StackOptions{Labels: labels, State: GoroutineBlocked})
+		if len(stacks) > 0 {
+			break
+		}
+		// The goroutine has not yet blocked.
+		time.Sleep(time.Millisecond)
+	}
+	if len(stacks) != 1 {
+		t.Fatalf("AllStacks selected %d blocked goroutines labeled %v, want 1", len(stacks), labels)
+	}
+	g := stacks[0]
+	if !g.Blocked || g.State != "chan receive" || g.Labels["test"] != "TestAllStacks" {
+		t.Errorf("AllStacks = {State: %q, Blocked: %v, Labels: %v}, want a blocked goroutine labeled %v", g.State, g.Blocked, g.Labels, labels)
+	}
+	found := false
+	for _, f := range g.Frames {
+		if f.GoroutineID != g.ID {
+			t.Errorf("frame %+v has a goroutine ID other than %d", f, g.ID)
+		}
+		found = found || f.Func == "runtime/debug_test.blockForStacks"
+	}
+	if !found {
+		t.Errorf("stack does not include blockForStacks")
+	}
+
+	if stacks := AllStacks(StackOptions{Labels: labels, State: GoroutineRunning}); len(stacks) != 0 {
+		t.Errorf("AllStacks selected %d running goroutines labeled %v, want 0", len(stacks), labels)
+	}
+	if stacks := AllStacks(StackOptions{Labels: labels, MinWait: time.Hour}); len(stacks) != 0 {
+		t.Errorf("AllStacks selected %d goroutines blocked for an hour, want 0", len(stacks))
+	}
+	stacks = AllStacks(StackOptions{State: GoroutineRunning})
+	if len(stacks) == 0 || stacks[0].State != "running" || stacks[0].Frames[0].Func != "runtime/debug_test.TestAllStacks" {
+		t.Errorf("AllStacks(GoroutineRunning) does not begin with the calling goroutine")
+	}
+	for _, g := range stacks {
+		if g.Blocked {
+			t.Errorf("AllStacks(GoroutineRunning) selected goroutine %d in state %q", g.ID, g.State)
+		}
+	}
+}
+
+// TestAllStacksLabelsRace reads the labels of goroutines while they
+// set them. Run with -race, it checks that AllStacks synchronizes with
+// the writers of the labels.
+func TestAllStacksLabelsRace(t *testing.T) {
+	done := make(chan bool)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for n := 0; ; n++ {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				ctx := pprof.WithLabels(context.Background(), pprof.Labels("test", "TestAllStacksLabelsRace", "n", strconv.Itoa(n)))
+				pprof.SetGoroutineLabels(ctx)
+				runtime.Gosched()
+			}
+		}(i)
+	}
+	labels := map[string]string{"test": "TestAllStacksLabelsRace"}
+	seen := 0
+	for i := 0; i < 100 || seen == 0 && i < 10000; i++ {
+		for _, g := range AllStacks(StackOptions{Labels: labels}) {
+			if g.Labels["n"] == "" {
+				t.Errorf("goroutine %d has labels %v, want an n label", g.ID, g.Labels)
+			}
+			seen++
+		}
+		// Let the goroutines run, without synchronizing with them.
+		time.Sleep(10 * time.Microsecond)
+	}
+	if seen == 0 {
+		t.Errorf("AllStacks found no labeled goroutines")
+	}
+	close(done)
+	wg.Wait()
+}
+
+func TestFprintStack(t *testing.T) {
+	var buf bytes.Buffer
+	if err := T(0).fprint(&buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	if len(lines) < 6 {
+		t.Fatalf("too few lines:\n%s", buf.String())
+	}
+	id := strconv.FormatInt(CapturedStack()[0].GoroutineID, 10)
+	check(t, lines[0], "goroutine "+id+" [running]:")
+	n := 1
+	frame := func(line, code string) {
+		check(t, lines[n], code)
+		n++
+		check(t, lines[n], line)
+		n++
+	}
+	frame("src/runtime/debug/stack_test.go:", "runtime/debug_test.T.fprint(...)")
+	frame("src/runtime/debug/stack_test.go:", "runtime/debug_test.TestFprintStack(...)")
+	frame("src/testing/testing.go:", "testing.tRunner(...)")
+	if strings.Contains(buf.String(), "runtime.") {
+		t.Errorf("trace includes runtime frames:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := T(0).fprint(&buf, 1); err != nil {
+		t.Fatal(err)
+	}
+	lines = strings.Split(buf.String(), "\n")
+	if len(lines) != 5 || lines[3] != "...additional frames elided..." {
+		t.Errorf("FprintStackDepth(w, 1) wrote:\n%s", buf.String())
+	}
+}
+
+func TestSetStackFormat(t *testing.T) {
+	_, file, _, _ := runtime.Caller(0)
+	dir := filepath.ToSlash(filepath.Dir(file)) + "/"
+	SetStackFormat(StackFormatOptions{HideArgs: true, HideAddresses: true, TrimPathPrefix: dir})
+	defer SetStackFormat(StackFormatOptions{})
+
+	b := T(0).method()
+	lines := strings.Split(string(b), "\n")
+	if len(lines) < 6 {
+		t.Fatalf("too few lines:\n%s", b)
+	}
+	check(t, lines[3], "runtime/debug_test.(*T).ptrmethod(...)")
+	if !strings.HasPrefix(lines[4], "\tstack_test.go:") {
+		t.Errorf("path not trimmed: %q", lines[4])
+	}
+	if strings.Contains(string(b), "+0x") || strings.Contains(string(b), "(0x") {
+		t.Errorf("trace includes arguments or addresses:\n%s", b)
+	}
+
+	var buf bytes.Buffer
+	if err := FprintStack(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lines = strings.Split(buf.String(), "\n")
+	if len(lines) < 3 || !strings.HasPrefix(lines[2], "\tstack_test.go:") {
+		t.Errorf("FprintStack did not trim path:\n%s", buf.String())
+	}
+
+	SetStackFormat(StackFormatOptions{})
+	if b := Stack(); !strings.Contains(string(b), "+0x") || !strings.Contains(string(b), dir) {
+		t.Errorf("default format not restored:\n%s", b)
+	}
+}
+
+func TestSetTracebackFilter(t *testing.T) {
+	var seen []StackFrame
+	SetTracebackFilter(func(f StackFrame) bool {
+		seen = append(seen, f)
+		return !strings.HasSuffix(f.Func, ".(*T).ptrmethod") && !strings.HasSuffix(f.Func, ".(*T).panic")
+	})
+	defer SetTracebackFilter(nil)
+
+	b := string(T(0).method())
+	if strings.Contains(b, "ptrmethod") {
+		t.Errorf("Stack includes filtered frame:\n%s", b)
+	}
+	if !strings.Contains(b, "runtime/debug_test.T.method(") || !strings.Contains(b, "\ncreated by ") {
+		t.Errorf("Stack lacks unfiltered frames:\n%s", b)
+	}
+	if len(seen) < 3 || seen[0].Func != "runtime/debug.Stack" || !strings.HasSuffix(seen[0].File, "stack.go") || seen[0].Line == 0 {
+		t.Errorf("filter saw %+v", seen)
+	}
+	id := CapturedStack()[0].GoroutineID
+	for _, f := range seen {
+		if f.GoroutineID != id {
+			t.Errorf("filter saw frame of goroutine %d, want %d: %+v", f.GoroutineID, id, f)
+		}
+	}
+
+	var stack []StackFrame
+	func() {
+		defer func() { _, stack, _ = RecoverWithStack() }()
+		new(T).panic()
+	}()
+	if len(stack) == 0 || !strings.HasSuffix(stack[0].Func, "TestSetTracebackFilter.func2") {
+		t.Errorf("RecoverWithStack did not filter frames: %+v", stack)
+	}
+
+	SetTracebackFilter(nil)
+	if b := string(T(0).method()); !strings.Contains(b, "ptrmethod") {
+		t.Errorf("Stack filtered after SetTracebackFilter(nil):\n%s", b)
+	}
+}
+
+func (t *T) panic() {
+	panic("boom")
+}
+
+func (T) fprint(w io.Writer, depth int) error {
+	if depth > 0 {
+		return FprintStackDepth(w, depth)
+	}
+	return FprintStack(w)
+}
+
+func TestRecoverWithStack(t *testing.T) {
+	val, stack, ok := recoverPanic(func() { panicker("oops") })
+	if !ok || val != "oops" {
+		t.Fatalf("RecoverWithStack = %v, _, %v, want oops, _, true", val, ok)
+	}
+	if len(stack) < 2 || stack[0].Func != "runtime/debug_test.panicker" || stack[1].Func != "runtime/debug_test.TestRecoverWithStack.func1" {
+		t.Errorf("stack does not begin at the panic site: %+v", stack)
+	}
+
+	// A run-time error's stack begins where it occurred.
+	val, stack, ok = recoverPanic(func() { nilDeref(nil) })
+	if _, isErr := val.(runtime.Error); !ok || !isErr {
+		t.Fatalf("RecoverWithStack = %v, _, %v, want a runtime.Error", val, ok)
+	}
+	if len(stack) == 0 || stack[0].Func != "runtime/debug_test.nilDeref" {
+		t.Errorf("stack does not begin at the nil dereference: %+v", stack)
+	}
+
+	// Without a panic, there is nothing to recover.
+	val, stack, ok = recoverPanic(func() {})
+	if ok || val != nil || stack != nil {
+		t.Errorf("RecoverWithStack without a panic = %v, %v, %v, want nil, nil, false", val, stack, ok)
+	}
+
+	// Called other than directly by the deferred function,
+	// RecoverWithStack does not stop the panic.
+	func() {
+		defer func() {
+			if v := recover(); v != "again" {
+				t.Errorf("recover = %v, want again", v)
+			}
+		}()
+		defer func() {
+			if _, _, ok := indirectRecover(); ok {
+				t.Errorf("indirect RecoverWithStack recovered the panic")
+			}
+		}()
+		panic("again")
+	}()
+}
+
+// recoverPanic calls f and returns the result of RecoverWithStack.
+func recoverPanic(f func()) (val interface{}, stack []StackFrame, ok bool) {
+	defer func() {
+		val, stack, ok = RecoverWithStack()
+	}()
+	f()
+	return
+}
+
+//go:noinline
+func panicker(v interface{}) {
+	panic(v)
+}
+
+//go:noinline
+func nilDeref(p *int) int {
+	return *p
+}
+
+//go:noinline
+func indirectRecover() (interface{}, []StackFrame, bool) {
+	return RecoverWithStack()
+}
+
 func check(t *testing.T, line, has string) {
 	if !strings.Contains(line, has) {
 		t.Errorf("expected %q in %q", has, line)
 	}
 }
+
+func TestSetCrashOutput(t *testing.T) {
+	if out := os.Getenv("GO_RUNTIME_DEBUG_CRASH_OUTPUT"); out != "" {
+		// In the child: crash with the output copied to the file.
+		f, err := os.Create(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := SetCrashOutput(f, CrashOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		f.Close() // SetCrashOutput holds its own descriptor
+		panic("oops")
+	}
+	testenv.MustHaveExec(t)
+
+	dir, err := ioutil.TempDir("", "crash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	out := filepath.Join(dir, "crash.txt")
+	cmd := exec.Command(os.Args[0], "-test.run=^TestSetCrashOutput$")
+	cmd.Env = append(os.Environ(), "GO_RUNTIME_DEBUG_CRASH_OUTPUT="+out)
+	stderr, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("child did not crash; output:\n%s", stderr)
+	}
+	crash, err := ioutil.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"panic: oops", "goroutine ", "TestSetCrashOutput"} {
+		if !strings.Contains(string(crash), want) {
+			t.Errorf("crash output does not contain %q:\n%s", want, crash)
+		}
+	}
+	if !strings.Contains(string(stderr), "panic: oops") {
+		t.Errorf("standard error does not contain the panic:\n%s", stderr)
+	}
+
+	// Setting no file stops the copying.
+	if err := SetCrashOutput(nil, CrashOptions{}); err != nil {
+		t.Errorf("SetCrashOutput(nil) = %v", err)
+	}
+}
+
+func TestSetCrashHandler(t *testing.T) {
+	if mode := os.Getenv("GO_RUNTIME_DEBUG_CRASH_HANDLER"); mode != "" {
+		// In the child: crash as mode says.
+		runtime.GOMAXPROCS(2)
+		SetCrashHandler(func(c CrashInfo) {
+			msg := "handler:"
+			if s, ok := c.Value.(string); ok {
+				msg += " value " + s
+			} else if c.Value != nil {
+				msg += " error value"
+			}
+			if c.Error != "" {
+				msg += " error " + c.Error
+			}
+			if c.Signal != 0 {
+				msg += " signal"
+			}
+			if c.NumGoroutine > 0 {
+				msg += " goroutines"
+			}
+			os.Stderr.WriteString(msg + "\n")
+		})
+		switch mode {
+		case "panic":
+			panic("oops")
+		case "nil":
+			var p *T
+			_ = *p
+		case "fatal":
+			var mu sync.Mutex
+			mu.Unlock()
+		}
+		return
+	}
+	testenv.MustHaveExec(t)
+
+	for _, tt := range []struct {
+		mode, handler, crash string
+	}{
+		{"panic", "handler: value oops goroutines", "panic: oops"},
+		{"nil", "handler: error value signal goroutines", "invalid memory address or nil pointer dereference"},
+		{"fatal", "handler: error sync: unlock of unlocked mutex goroutines", "fatal error: sync: unlock of unlocked mutex"},
+	} {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestSetCrashHandler$")
+		cmd.Env = append(os.Environ(), "GO_RUNTIME_DEBUG_CRASH_HANDLER="+tt.mode)
+		out, err := cmd.CombinedOutput()
+		if err == nil {
+			t.Errorf("%s: child did not crash; output:\n%s", tt.mode, out)
+			continue
+		}
+		i := strings.Index(string(out), tt.handler+"\n")
+		j := strings.Index(string(out), "goroutine ")
+		if i < 0 || j >= 0 && j < i || !strings.Contains(string(out), tt.crash) {
+			t.Errorf("%s: want %q before the traceback and %q; output:\n%s", tt.mode, tt.handler, tt.crash, out)
+		}
+	}
+}
+
+func TestSetTracebackEncoding(t *testing.T) {
+	if mode := os.Getenv("GO_RUNTIME_DEBUG_TRACEBACK_JSON"); mode != "" {
+		// In the child: crash as mode says.
+		SetTracebackEncoding(TracebackJSON)
+		switch mode {
+		case "panic":
+			panic("boom \"quoted\"\n")
+		case "nil":
+			var p *T
+			_ = *p
+		case "fatal":
+			var mu sync.Mutex
+			mu.Unlock()
+		}
+		return
+	}
+	testenv.MustHaveExec(t)
+
+	for _, tt := range []struct {
+		mode, typ, field, want string
+	}{
+		{"panic", "panic", "value", "boom \"quoted\"\n"},
+		{"nil", "panic", "value", "runtime error: invalid memory address or nil pointer dereference"},
+		{"fatal", "fatal error", "error", "sync: unlock of unlocked mutex"},
+	} {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestSetTracebackEncoding$")
+		cmd.Env = append(os.Environ(), "GO_RUNTIME_DEBUG_TRACEBACK_JSON="+tt.mode, "GOTRACEBACK=all")
+		out, err := cmd.CombinedOutput()
+		if err == nil {
+			t.Errorf("%s: child did not crash; output:\n%s", tt.mode, out)
+			continue
+		}
+		var objs []map[string]interface{}
+		for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+			if !strings.HasPrefix(line, "{") {
+				// The testing package reports the panic in text.
+				for _, text := range []string{"panic: ", "fatal error: ", "goroutine ", "[signal "} {
+					if strings.HasPrefix(line, text) {
+						t.Errorf("%s: text line %q; output:\n%s", tt.mode, line, out)
+					}
+				}
+				continue
+			}
+			var obj map[string]interface{}
+			if err := json.Unmarshal([]byte(line), &obj); err != nil {
+				t.Fatalf("%s: line %q is not JSON: %v; output:\n%s", tt.mode, line, err, out)
+			}
+			objs = append(objs, obj)
+		}
+		if objs[0]["type"] != tt.typ || objs[0][tt.field] != tt.want {
+			t.Errorf("%s: first object is %v, want %s with %s %q", tt.mode, objs[0], tt.typ, tt.field, tt.want)
+		}
+		found, others, signal := false, 0, false
+		for _, obj := range objs {
+			switch obj["type"] {
+			case "signal":
+				signal = true
+			case "goroutine":
+				frames, _ := obj["frames"].([]interface{})
+				if len(frames) == 0 {
+					t.Errorf("%s: goroutine without frames: %v", tt.mode, obj)
+				}
+				for _, f := range frames {
+					f := f.(map[string]interface{})
+					if f["func"] == "runtime/debug_test.TestSetTracebackEncoding" {
+						file, _ := f["file"].(string)
+						if !strings.HasSuffix(file, "stack_test.go") || f["line"] == nil {
+							t.Errorf("%s: frame %v lacks position", tt.mode, f)
+						}
+						if _, ok := obj["created_by"].(map[string]interface{}); !ok {
+							t.Errorf("%s: goroutine %v lacks creator", tt.mode, obj["goroutine"])
+						}
+						found = true
+					}
+				}
+				others++
+			}
+		}
+		if !found || others < 2 {
+			t.Errorf("%s: found test frame %v in %d goroutines; output:\n%s", tt.mode, found, others, out)
+		}
+		if signal != (tt.mode == "nil") {
+			t.Errorf("%s: signal object %v; output:\n%s", tt.mode, signal, out)
+		}
+	}
+}
+
+// renamer is a Symbolizer that moves the frames of this package's test
+// functions to another package and file.
+type renamer struct{}
+
+func (renamer) Symbolize(f StackFrame) (StackFrame, bool) {
+	if !strings.HasPrefix(f.Func, "runtime/debug_test.") {
+		return f, false
+	}
+	f.Func = "example.com/real." + strings.TrimPrefix(f.Func, "runtime/debug_test.")
+	f.File = "/src/real/real.go"
+	f.Line = 7
+	return f, true
+}
+
+func TestSetSymbolizer(t *testing.T) {
+	SetSymbolizer(renamer{})
+	defer SetSymbolizer(nil)
+
+	b := string(T(0).method())
+	if !strings.HasPrefix(b, "goroutine ") || !strings.Contains(b, "\nruntime/debug.Stack(...)\n") ||
+		!strings.Contains(b, "\nexample.com/real.(*T).ptrmethod(...)\n\t/src/real/real.go:7\n") ||
+		strings.Contains(b, "runtime/debug_test.") {
+		t.Errorf("Stack did not use Symbolizer:\n%s", b)
+	}
+	if f := CapturedStack()[0]; f.Func != "example.com/real.TestSetSymbolizer" || f.File != "/src/real/real.go" || f.Line != 7 || f.PC == 0 {
+		t.Errorf("CapturedStack()[0] = %+v", f)
+	}
+	var buf bytes.Buffer
+	if err := FprintStack(&buf); err != nil || !strings.Contains(buf.String(), "\nexample.com/real.TestSetSymbolizer(...)\n") {
+		t.Errorf("FprintStack = %v:\n%s", err, buf.String())
+	}
+
+	SetSymbolizer(nil)
+	if f := CapturedStack()[0]; f.Func != "runtime/debug_test.TestSetSymbolizer" {
+		t.Errorf("CapturedStack()[0] after SetSymbolizer(nil) = %+v", f)
+	}
+}
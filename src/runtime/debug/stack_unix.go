@@ -0,0 +1,23 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build aix darwin dragonfly freebsd js,wasm linux netbsd openbsd solaris
+
+package debug
+
+import (
+	"internal/poll"
+	"os"
+)
+
+// dupCrashFile returns a close-on-exec duplicate of f's file descriptor.
+// Calling f.Fd also puts f in blocking mode, so that a crash report
+// written to a full pipe is not lost.
+func dupCrashFile(f *os.File) (uintptr, error) {
+	fd, op, err := poll.DupCloseOnExec(int(f.Fd()))
+	if err != nil {
+		return 0, os.NewSyscallError(op, err)
+	}
+	return uintptr(fd), nil
+}
@@ -0,0 +1,24 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"os"
+	"syscall"
+)
+
+// dupCrashFile returns a non-inheritable duplicate of f's handle.
+func dupCrashFile(f *os.File) (uintptr, error) {
+	p, err := syscall.GetCurrentProcess()
+	if err != nil {
+		return 0, os.NewSyscallError("GetCurrentProcess", err)
+	}
+	var h syscall.Handle
+	err = syscall.DuplicateHandle(p, syscall.Handle(f.Fd()), p, &h, 0, false, syscall.DUPLICATE_SAME_ACCESS)
+	if err != nil {
+		return 0, os.NewSyscallError("DuplicateHandle", err)
+	}
+	return uintptr(h), nil
+}
@@ -11,7 +11,29 @@ import (
 // Implemented in package runtime.
 func readGCStats(*[]time.Duration)
 func freeOSMemory()
+func freeOSMemoryTo(target uint64, max uintptr, restart bool) (released uintptr, retained uint64)
 func setMaxStack(int) int
+func getMaxStack() int
 func setGCPercent(int32) int32
+func getGCPercent() int32
+func setMemoryLimit(int64) int64
+func setHeapTarget(uint64) uint64
 func setPanicOnFault(bool) bool
+func setInheritFault(bool) bool
+func readRuntimeSnapshot(*runtimeSnapshot)
 func setMaxThreads(int) int
+func getMaxThreads() int
+func setCrashFD(uintptr) uintptr
+func setStackFormat(hideArgs, hideAddrs bool, trimPrefix string)
+func setTracebackEncoding(uint32)
+func godebugSettings() (names []string, values []int32)
+func goroutineStacks(recs []goroutineRecord, stk []uintptr, skip int) (n int, ok bool)
+func goroutineID() int64
+func readGCCycles(n uint32, recs []gcCycleRecord) (last uint32, copied int)
+func waitGCCycle(n uint32)
+func setLimitHandler(enabled bool)
+func waitLimitEvent() (kind int32, limit, value uint64, goid int64)
+func fatalHandlerDone()
+func recoverCaller() (v interface{}, ok bool)
+func setCrashHandler(func())
+func readCrashEvent() (value interface{}, msg string, sig uint32, code, addr uintptr, ngoroutine int)
@@ -0,0 +1,73 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"bytes"
+	"sync/atomic"
+)
+
+// A Symbolizer names the locations of program counters from a source
+// other than the tables of the running binary, such as a symbol file
+// kept apart from a binary whose names were obfuscated, or one mapping
+// the file names of a binary built with -trimpath back to its sources.
+type Symbolizer interface {
+	// Symbolize returns frame with its Func, File, and Line replaced
+	// by those recorded for frame.PC, and reports whether any are
+	// recorded. The frame passed to Symbolize holds what the binary's
+	// own tables say about frame.PC, which is the program counter
+	// of the frame's call instruction, or of the faulting
+	// instruction, as runtime.Frame.PC is. Each call inlined at
+	// frame.PC gives a frame of its own with the same PC.
+	Symbolize(frame StackFrame) (StackFrame, bool)
+}
+
+// symbolizer holds a symbolizerValue with the Symbolizer set by
+// SetSymbolizer.
+var symbolizer atomic.Value
+
+// A symbolizerValue holds a Symbolizer, as an atomic.Value must hold
+// values of a single concrete type.
+type symbolizerValue struct{ s Symbolizer }
+
+// SetSymbolizer sets s as the source of the names of the frames
+// reported by this package: those of Stack, FprintStack, CapturedStack,
+// CapturedStacks, AllStacks, RecoverWithStack, and the dumps written by
+// EnableSignalDump. When s is set, Stack writes its trace as
+// FprintStack does. If s is nil, as initially, the frames are named by
+// the binary's own tables alone.
+//
+// The linker's -s and -w flags remove the binary's symbol table and
+// DWARF debugging information but keep the tables the runtime uses for
+// tracebacks, so frames are named even without a Symbolizer.
+// The tracebacks printed by the runtime when the program crashes do
+// not consult s, as it is not safe to run the program's code then;
+// a handler set by SetCrashHandler may call CapturedStack or AllStacks
+// instead, whose frames s names.
+func SetSymbolizer(s Symbolizer) {
+	symbolizer.Store(symbolizerValue{s})
+}
+
+// symbolize returns f as named by the Symbolizer set by SetSymbolizer,
+// if it knows f.PC.
+func symbolize(f StackFrame) StackFrame {
+	if v, _ := symbolizer.Load().(symbolizerValue); v.s != nil {
+		if sf, ok := v.s.Symbolize(f); ok {
+			return sf
+		}
+	}
+	return f
+}
+
+// symbolizedStack returns the trace of Stack when a Symbolizer is set:
+// that written by FprintStack, beginning with Stack.
+func symbolizedStack() ([]byte, bool) {
+	if v, _ := symbolizer.Load().(symbolizerValue); v.s == nil {
+		return nil, false
+	}
+	var b bytes.Buffer
+	fprintStack(&b, 0, 2)
+	return b.Bytes(), true
+}
@@ -0,0 +1,71 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// A FieldEncoder encodes the fields of the text form of build
+// information, such as module paths, versions, and setting values,
+// so that they can hold characters that the format otherwise reserves,
+// such as the tabs separating columns and the newlines ending lines.
+// Decode must reverse Encode. An encoded build setting key must not
+// contain '='.
+type FieldEncoder interface {
+	Encode(string) string
+	Decode(string) (string, error)
+}
+
+// DefaultFieldEncoder is the FieldEncoder used by MarshalText and
+// UnmarshalText. It leaves a field unchanged unless the field
+// contains a character that is not printable, as defined by
+// strconv.IsPrint, such as a tab or newline, or a byte that is not
+// valid UTF-8, or the field begins with a double quote; such a field
+// is written as a double-quoted Go string literal, as by
+// strconv.Quote, which escapes all such characters and bytes. The
+// fields recorded by cmd/go never need quoting, so the encoding of
+// build information without such characters matches the text cmd/go
+// embeds in binaries byte for byte.
+var DefaultFieldEncoder FieldEncoder = defaultFieldEncoder
+
+var defaultFieldEncoder = quoteFieldEncoder{}
+
+type quoteFieldEncoder struct{}
+
+func (quoteFieldEncoder) Encode(s string) string {
+	if needsQuote(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// needsQuote reports whether the field s must be quoted
+// to survive the text format unchanged.
+func needsQuote(s string) bool {
+	if strings.HasPrefix(s, `"`) {
+		return true
+	}
+	for i, r := range s {
+		if r == utf8.RuneError {
+			if _, n := utf8.DecodeRuneInString(s[i:]); n == 1 {
+				return true
+			}
+		}
+		if !strconv.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func (quoteFieldEncoder) Decode(s string) (string, error) {
+	if strings.HasPrefix(s, `"`) {
+		return strconv.Unquote(s)
+	}
+	return s, nil
+}
@@ -0,0 +1,144 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"errors"
+	"reflect"
+	. "runtime/debug"
+	"strings"
+	"testing"
+)
+
+// percentEncoder is a FieldEncoder that percent-encodes
+// the characters the text form reserves.
+type percentEncoder struct{}
+
+var percentReplacer = strings.NewReplacer("%", "%25", "\t", "%09", "\n", "%0A", "\r", "%0D")
+
+func (percentEncoder) Encode(s string) string { return percentReplacer.Replace(s) }
+
+func (percentEncoder) Decode(s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+2 >= len(s) {
+			return "", errors.New("truncated escape")
+		}
+		switch s[i+1 : i+3] {
+		case "25":
+			b.WriteByte('%')
+		case "09":
+			b.WriteByte('\t')
+		case "0A":
+			b.WriteByte('\n')
+		case "0D":
+			b.WriteByte('\r')
+		default:
+			return "", errors.New("bad escape")
+		}
+		i += 2
+	}
+	return b.String(), nil
+}
+
+func awkwardBuildInfo() *BuildInfo {
+	return &BuildInfo{
+		Path: "example.com/cmd/hello",
+		Main: Module{Path: "example.com/hello", Version: "v1.0.0", Sum: `"quoted"`},
+		Deps: []*Module{
+			{Path: "example.com/a", Version: "v1.0.0", Replace: &Module{Path: `C:\src\a 100%`}},
+			{Path: "example.com/b", Version: "v1.0.0", Extra: []string{"line1\nline2", "col\tumn"}},
+		},
+		Settings: []BuildSetting{
+			{Key: "-ldflags", Value: "-X \"main.msg=a\tb\""},
+			{Key: "CGO_CFLAGS", Value: "-O2\r\n-g"},
+		},
+	}
+}
+
+func TestDefaultFieldEncoder(t *testing.T) {
+	info := awkwardBuildInfo()
+	text, err := info.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := formatHeader +
+		"path\texample.com/cmd/hello\n" +
+		"mod\texample.com/hello\tv1.0.0\t\"\\\"quoted\\\"\"\n" +
+		"dep\texample.com/a\tv1.0.0\n" +
+		"=>\tC:\\src\\a 100%\t\t\n" +
+		"dep\texample.com/b\tv1.0.0\t\t\"line1\\nline2\"\t\"col\\tumn\"\n" +
+		"build\t-ldflags=\"-X \\\"main.msg=a\\tb\\\"\"\n" +
+		"build\tCGO_CFLAGS=\"-O2\\r\\n-g\"\n"
+	if string(text) != want {
+		t.Errorf("MarshalText =\n%s\nwant:\n%s", text, want)
+	}
+	var back BuildInfo
+	if err := back.UnmarshalText(text); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&back, info) {
+		t.Errorf("UnmarshalText(MarshalText()) =\n%+v\nwant\n%+v", &back, info)
+	}
+
+	for _, s := range []string{"plain", "", `C:\dir`, "tab\there", `"`, "\"x\"\n", "nul\x00", "bad\xffutf8", "sep\u2028", "héllo"} {
+		enc := DefaultFieldEncoder.Encode(s)
+		if strings.ContainsAny(enc, "\t\n\r") {
+			t.Errorf("Encode(%q) = %q contains reserved characters", s, enc)
+		}
+		if dec, err := DefaultFieldEncoder.Decode(enc); err != nil || dec != s {
+			t.Errorf("Decode(Encode(%q)) = %q, %v", s, dec, err)
+		}
+	}
+	if enc := DefaultFieldEncoder.Encode("héllo"); enc != "héllo" {
+		t.Errorf("Encode(%q) = %q, want it unchanged", "héllo", enc)
+	}
+
+	odd := &BuildInfo{
+		Main: Module{Path: "example.com/\xff\xfe", Version: "v1.0.0\x00"},
+		Deps: []*Module{{Path: "example.com/\x1b[31mred", Version: "v1.0.0", Replace: &Module{Path: "../\u00a0dir"}}},
+	}
+	text, err = odd.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var oddBack BuildInfo
+	if err := oddBack.UnmarshalTextStrict(text); err != nil {
+		t.Fatalf("UnmarshalTextStrict(%q): %v", text, err)
+	}
+	if !reflect.DeepEqual(&oddBack, odd) {
+		t.Errorf("UnmarshalText(MarshalText()) =\n%+v\nwant\n%+v", &oddBack, odd)
+	}
+
+	if err := new(BuildInfo).UnmarshalText([]byte("mod\t\"unterminated\tv1.0.0\n")); err == nil {
+		t.Error("UnmarshalText with bad quoting succeeded, want error")
+	}
+}
+
+func TestCustomFieldEncoder(t *testing.T) {
+	info := awkwardBuildInfo()
+	text, err := info.MarshalTextWith(percentEncoder{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(text), "=>\tC:\\src\\a 100%25\t\t\n") ||
+		!strings.Contains(string(text), "\tline1%0Aline2\tcol%09umn\n") {
+		t.Errorf("MarshalTextWith(percentEncoder) =\n%s", text)
+	}
+	var back BuildInfo
+	if err := back.UnmarshalTextWith(text, percentEncoder{}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&back, info) {
+		t.Errorf("UnmarshalTextWith(MarshalTextWith()) =\n%+v\nwant\n%+v", &back, info)
+	}
+	if err := back.UnmarshalTextWith([]byte("path\tbad%zz\n"), percentEncoder{}); err == nil {
+		t.Error("UnmarshalTextWith with bad escape succeeded, want error")
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"errors"
+	"strconv"
+)
+
+var (
+	// ErrSyntax reports a malformed line in the text form of build information.
+	ErrSyntax = errors.New("syntax error")
+
+	// ErrTruncated reports text build information that ends partway
+	// through a line.
+	ErrTruncated = errors.New("unexpected end of input")
+)
+
+// A TextError describes a problem found by UnmarshalTextStrict in the
+// text form of build information, or a limit set by SetTextLimits that
+// the text exceeds.
+type TextError struct {
+	Line   int    // line number, starting at 1
+	Column int    // byte column where the problem starts, starting at 1
+	Text   string // text of the line, without its newline
+	Msg    string // description of the problem
+	Err    error  // ErrSyntax, ErrTruncated, or ErrLimitExceeded
+}
+
+func (e *TextError) Error() string {
+	return "build information:" + strconv.Itoa(e.Line) + ":" + strconv.Itoa(e.Column) + ": " + e.Msg + ": " + strconv.Quote(e.Text)
+}
+
+func (e *TextError) Unwrap() error { return e.Err }
@@ -0,0 +1,62 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"errors"
+	"reflect"
+	. "runtime/debug"
+	"testing"
+)
+
+func TestUnmarshalTextStrict(t *testing.T) {
+	var strict, lax BuildInfo
+	if err := strict.UnmarshalTextStrict([]byte(testModinfo)); err != nil {
+		t.Fatal(err)
+	}
+	if err := lax.UnmarshalText([]byte(testModinfo)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&strict, &lax) {
+		t.Errorf("UnmarshalTextStrict = %+v, want %+v", &strict, &lax)
+	}
+
+	for _, tt := range []struct {
+		text      string
+		err       error
+		line, col int
+	}{
+		{"path\tx\nfoo\tbar\n", ErrSyntax, 2, 1},
+		{"path\tx\n\n", ErrSyntax, 2, 1},
+		{"mod\ta\tv1.0.0\t\nmod\tb\tv1.0.0\t\n", ErrSyntax, 2, 1},
+		{"path\tx\npath\ty\n", ErrSyntax, 2, 1},
+		{"go\tgo1.15\ngo\tgo1.16\npath\tx\n", ErrSyntax, 2, 1},
+		{"mod\ta\n", ErrSyntax, 1, 5},
+		{"=>\ta\tv1.0.0\t\n", ErrSyntax, 1, 1},
+		{"dep\ta\tv1.0.0\th1:x\textra\n", ErrSyntax, 1, 19},
+		{"mod\ta\tv1.0.0\t\tx\n", ErrSyntax, 1, 15},
+		{"dep\ta\tv1.0.0\t\n=>\tb\tv1.0.0\th1:y\tx\ty\n", ErrSyntax, 2, 18},
+		{"mod\ta\tv1.0.0\t\ndep\tb\tv1", ErrTruncated, 2, 9},
+	} {
+		var bi BuildInfo
+		err := bi.UnmarshalTextStrict([]byte(tt.text))
+		var te *TextError
+		if !errors.As(err, &te) || !errors.Is(err, tt.err) {
+			t.Errorf("UnmarshalTextStrict(%q) error = %v, want *TextError wrapping %v", tt.text, err, tt.err)
+			continue
+		}
+		if te.Line != tt.line || te.Column != tt.col {
+			t.Errorf("UnmarshalTextStrict(%q) error at %d:%d, want %d:%d", tt.text, te.Line, te.Column, tt.line, tt.col)
+		}
+	}
+
+	// UnmarshalText still accepts the input that is only suspicious.
+	for _, text := range []string{"foo\tbar\n", "mod\ta\tv1.0.0\t\nmod\tb\tv1.0.0\t\n", "path\tx", "dep\ta\tv1.0.0\th1:x\textra\n"} {
+		var bi BuildInfo
+		if err := bi.UnmarshalText([]byte(text)); err != nil {
+			t.Errorf("UnmarshalText(%q): %v", text, err)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+// A VulnEntry describes a vulnerability affecting a range of versions
+// of the module with the given path: the versions starting at
+// IntroducedVersion and preceding FixedVersion, both semantic versions.
+// An empty IntroducedVersion means the vulnerability affects all versions
+// before FixedVersion, and an empty FixedVersion means no fixed version
+// exists.
+type VulnEntry struct {
+	Path              string
+	IntroducedVersion string
+	FixedVersion      string
+}
+
+// A VulnMatch reports a dependency affected by a vulnerability.
+type VulnMatch struct {
+	Dep   *Module   // the dependency, as listed in BuildInfo.Deps
+	Entry VulnEntry // the matching vulnerability entry
+}
+
+// CheckVulns returns the dependencies of bi affected by the
+// vulnerabilities described in db, a database supplied by the caller,
+// for example loaded from OSV data. Each dependency is checked using
+// the module it resolves to: the last in its chain of replacements,
+// as Module.Effective reports it.
+// Dependencies without a valid semantic version, such as those replaced
+// by local directories, and entries with invalid version bounds are
+// skipped. The matches are listed in the order of bi.Deps, and then
+// in the order of db.
+func (bi *BuildInfo) CheckVulns(db []VulnEntry) []VulnMatch {
+	byPath := make(map[string][]VulnEntry)
+	for _, e := range db {
+		if e.IntroducedVersion != "" && !isValidSemver(e.IntroducedVersion) ||
+			e.FixedVersion != "" && !isValidSemver(e.FixedVersion) {
+			continue
+		}
+		byPath[e.Path] = append(byPath[e.Path], e)
+	}
+	var matches []VulnMatch
+	for _, dep := range bi.Deps {
+		if dep == nil {
+			continue
+		}
+		m := dep.effective()
+		if !isValidSemver(m.Version) {
+			continue
+		}
+		for _, e := range byPath[m.Path] {
+			if versionInRange(m.Version, e.IntroducedVersion, e.FixedVersion) {
+				matches = append(matches, VulnMatch{Dep: dep, Entry: e})
+			}
+		}
+	}
+	return matches
+}
+
+// versionInRange reports whether v is at least introduced and less than
+// fixed. An empty bound does not restrict the range.
+func versionInRange(v, introduced, fixed string) bool {
+	return (introduced == "" || compareSemver(v, introduced) >= 0) &&
+		(fixed == "" || compareSemver(v, fixed) < 0)
+}
+
+// A VulnDB is a source of advisories, such as an OSV database loaded
+// by the caller. This package holds no vulnerability data itself.
+type VulnDB interface {
+	// Lookup returns the advisories concerning the module with the
+	// given path. It may return advisories for other versions than
+	// the one given; Scan checks each advisory's affected ranges.
+	Lookup(path, version string) []Advisory
+}
+
+// An Advisory describes a vulnerability and the versions it affects.
+type Advisory struct {
+	ID       string         // identifier, such as GO-2020-0001
+	Aliases  []string       // other identifiers, such as CVE IDs
+	Summary  string         // one-line description
+	Affected []VersionRange // affected versions; empty means all versions
+}
+
+// A VersionRange is the half-open range of semantic versions starting
+// at Introduced and preceding Fixed. An empty bound does not restrict
+// the range.
+type VersionRange struct {
+	Introduced string
+	Fixed      string
+}
+
+// A Finding reports a dependency affected by an advisory.
+type Finding struct {
+	Dep      *Module // the dependency, as listed in BuildInfo.Deps
+	Version  string  // the version checked, that of the resolved module
+	Advisory Advisory
+}
+
+// Scan looks up each dependency of bi in db and returns the dependencies
+// affected by the advisories found. As with CheckVulns, each dependency
+// is looked up by the module it resolves to, dependencies without a valid
+// semantic version are skipped, and ranges with invalid bounds are
+// ignored. The findings are listed in the order of bi.Deps, and then in
+// the order returned by db.
+func (bi *BuildInfo) Scan(db VulnDB) []Finding {
+	var findings []Finding
+	for _, dep := range bi.Deps {
+		if dep == nil {
+			continue
+		}
+		m := dep.effective()
+		if !isValidSemver(m.Version) {
+			continue
+		}
+		for _, a := range db.Lookup(m.Path, m.Version) {
+			if a.affects(m.Version) {
+				findings = append(findings, Finding{Dep: dep, Version: m.Version, Advisory: a})
+			}
+		}
+	}
+	return findings
+}
+
+// affects reports whether version v lies in one of a's affected ranges.
+func (a *Advisory) affects(v string) bool {
+	if len(a.Affected) == 0 {
+		return true
+	}
+	for _, r := range a.Affected {
+		if r.Introduced != "" && !isValidSemver(r.Introduced) ||
+			r.Fixed != "" && !isValidSemver(r.Fixed) {
+			continue
+		}
+		if versionInRange(v, r.Introduced, r.Fixed) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	. "runtime/debug"
+	"testing"
+)
+
+func TestCheckVulns(t *testing.T) {
+	info := &BuildInfo{
+		Deps: []*Module{
+			{Path: "example.com/vulnerable", Version: "v1.2.0"},
+			{Path: "example.com/fixed", Version: "v1.3.0"},
+			{Path: "example.com/before", Version: "v0.9.0"},
+			{Path: "example.com/pseudo", Version: "v1.2.1-0.20200101000000-abcdefabcdef"},
+			{Path: "example.com/replaced", Version: "v1.0.0", Replace: &Module{Path: "example.com/fork", Version: "v2.0.0"}},
+			{Path: "example.com/local", Version: "v0.0.0-00010101000000-000000000000", Replace: &Module{Path: "../local"}},
+		},
+	}
+	db := []VulnEntry{
+		{Path: "example.com/vulnerable", IntroducedVersion: "v1.1.0", FixedVersion: "v1.2.1"},
+		{Path: "example.com/fixed", IntroducedVersion: "v1.1.0", FixedVersion: "v1.2.1"},
+		{Path: "example.com/before", IntroducedVersion: "v1.0.0", FixedVersion: "v1.2.1"},
+		{Path: "example.com/pseudo", FixedVersion: "v1.2.1"},
+		{Path: "example.com/pseudo", IntroducedVersion: "v1.2.0"},
+		{Path: "example.com/replaced", FixedVersion: "v9.0.0"},
+		{Path: "example.com/fork", IntroducedVersion: "v2.0.0"},
+		{Path: "../local", FixedVersion: "v1.0.0"},
+		{Path: "example.com/vulnerable", FixedVersion: "bogus"},
+	}
+	want := []struct{ dep, introduced, fixed string }{
+		{"example.com/vulnerable", "v1.1.0", "v1.2.1"},
+		{"example.com/pseudo", "", "v1.2.1"},
+		{"example.com/pseudo", "v1.2.0", ""},
+		{"example.com/replaced", "v2.0.0", ""},
+	}
+	got := info.CheckVulns(db)
+	if len(got) != len(want) {
+		t.Fatalf("CheckVulns returned %d matches, want %d: %+v", len(got), len(want), got)
+	}
+	for i, m := range got {
+		w := want[i]
+		if m.Dep.Path != w.dep || m.Entry.IntroducedVersion != w.introduced || m.Entry.FixedVersion != w.fixed {
+			t.Errorf("match %d = %s %+v, want %s [%s, %s)", i, m.Dep.Path, m.Entry, w.dep, w.introduced, w.fixed)
+		}
+	}
+}
+
+// mapDB is a VulnDB holding advisories by module path.
+type mapDB map[string][]Advisory
+
+func (db mapDB) Lookup(path, version string) []Advisory { return db[path] }
+
+func TestScan(t *testing.T) {
+	info := &BuildInfo{
+		Deps: []*Module{
+			{Path: "example.com/vulnerable", Version: "v1.2.0"},
+			{Path: "example.com/fixed", Version: "v1.3.0"},
+			{Path: "example.com/replaced", Version: "v1.0.0", Replace: &Module{Path: "example.com/fork", Version: "v2.0.0"}},
+			{Path: "example.com/local", Version: "v0.0.0-00010101000000-000000000000", Replace: &Module{Path: "../local"}},
+		},
+	}
+	db := mapDB{
+		"example.com/vulnerable": {
+			{ID: "GO-1", Affected: []VersionRange{{Introduced: "v0.1.0", Fixed: "v0.2.0"}, {Introduced: "v1.1.0", Fixed: "v1.2.1"}}},
+			{ID: "GO-2", Affected: []VersionRange{{Fixed: "bogus"}}},
+			{ID: "GO-3"},
+		},
+		"example.com/fixed":    {{ID: "GO-4", Affected: []VersionRange{{Fixed: "v1.2.1"}}}},
+		"example.com/replaced": {{ID: "GO-5"}},
+		"example.com/fork":     {{ID: "GO-6", Affected: []VersionRange{{Introduced: "v2.0.0"}}}},
+		"../local":             {{ID: "GO-7"}},
+	}
+	want := []struct{ dep, version, id string }{
+		{"example.com/vulnerable", "v1.2.0", "GO-1"},
+		{"example.com/vulnerable", "v1.2.0", "GO-3"},
+		{"example.com/replaced", "v2.0.0", "GO-6"},
+	}
+	got := info.Scan(db)
+	if len(got) != len(want) {
+		t.Fatalf("Scan returned %d findings, want %d: %+v", len(got), len(want), got)
+	}
+	for i, f := range got {
+		w := want[i]
+		if f.Dep.Path != w.dep || f.Version != w.version || f.Advisory.ID != w.id {
+			t.Errorf("finding %d = %s@%s %s, want %s@%s %s", i, f.Dep.Path, f.Version, f.Advisory.ID, w.dep, w.version, w.id)
+		}
+	}
+}
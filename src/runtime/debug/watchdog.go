@@ -0,0 +1,116 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"sync"
+	"time"
+)
+
+// WatchdogOptions configures a Watchdog.
+type WatchdogOptions struct {
+	// Labels, if not empty, selects the goroutines watched, as in
+	// StackOptions: those with every one of these profiler labels,
+	// as set by runtime/pprof.Do. Otherwise every goroutine is watched.
+	Labels map[string]string
+
+	// Deadline is how long a goroutine may stay blocked
+	// before it is reported. It must be positive.
+	Deadline time.Duration
+
+	// Interval is how often the goroutines are sampled.
+	// If it is zero or negative, it is a quarter of Deadline,
+	// but at least a nanosecond.
+	Interval time.Duration
+
+	// OnStuck is called with the goroutines that have stayed blocked
+	// beyond Deadline, each with its stack and a WaitDuration of how
+	// long it has been blocked. It is called on the watchdog's own
+	// goroutine, so a slow OnStuck delays the next sample.
+	OnStuck func(stuck []GoroutineStack)
+}
+
+// A Watchdog watches for goroutines that stay blocked, as on a channel
+// or lock, for too long, such as the handlers of requests that have
+// deadlocked. It samples the states of the goroutines periodically,
+// as AllStacks does, so a goroutine that runs briefly between samples
+// may be taken for one that has not. It reports each goroutine once
+// for each spell of being blocked in the same state.
+type Watchdog struct {
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// A watchedGoroutine records when a goroutine was first seen blocked.
+type watchedGoroutine struct {
+	state    string
+	since    time.Time
+	reported bool
+}
+
+// NewWatchdog starts a watchdog configured by opts. It panics if
+// opts.Deadline is not positive or opts.OnStuck is nil.
+func NewWatchdog(opts WatchdogOptions) *Watchdog {
+	if opts.Deadline <= 0 {
+		panic("debug: non-positive deadline for NewWatchdog")
+	}
+	if opts.OnStuck == nil {
+		panic("debug: NewWatchdog with nil OnStuck")
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = opts.Deadline / 4
+		if opts.Interval <= 0 {
+			opts.Interval = 1 // time.NewTicker panics on zero
+		}
+	}
+	w := &Watchdog{stop: make(chan struct{})}
+	go w.run(opts)
+	return w
+}
+
+// Stop stops the watchdog. A call of OnStuck in progress completes,
+// but OnStuck is not called again. Stop may be called more than once.
+func (w *Watchdog) Stop() {
+	w.stopOnce.Do(func() { close(w.stop) })
+}
+
+func (w *Watchdog) run(opts WatchdogOptions) {
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+	watched := make(map[int64]*watchedGoroutine)
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+		}
+		now := time.Now()
+		var stuck []GoroutineStack
+		next := make(map[int64]*watchedGoroutine)
+		for _, g := range allStacks(StackOptions{Labels: opts.Labels, State: GoroutineBlocked}) {
+			wg := watched[g.ID]
+			if wg == nil || wg.state != g.State {
+				// The runtime may know that the goroutine
+				// blocked before the watchdog saw it.
+				wg = &watchedGoroutine{state: g.State, since: now.Add(-g.WaitDuration)}
+			}
+			next[g.ID] = wg
+			if d := now.Sub(wg.since); d >= opts.Deadline && !wg.reported {
+				wg.reported = true
+				g.WaitDuration = d
+				stuck = append(stuck, g)
+			}
+		}
+		watched = next
+		if len(stuck) > 0 {
+			select {
+			case <-w.stop:
+				return
+			default:
+			}
+			opts.OnStuck(stuck)
+		}
+	}
+}
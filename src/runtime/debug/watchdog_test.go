@@ -0,0 +1,69 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"context"
+	. "runtime/debug"
+	"runtime/pprof"
+	"testing"
+	"time"
+)
+
+func TestWatchdog(t *testing.T) {
+	c := make(chan int)
+	defer close(c)
+	go pprof.Do(context.Background(), pprof.Labels("test", "TestWatchdog"), func(context.Context) {
+		blockForStacks(c)
+	})
+
+	reports := make(chan []GoroutineStack, 10)
+	w := NewWatchdog(WatchdogOptions{
+		Labels:   map[string]string{"test": "TestWatchdog"},
+		Deadline: 50 * time.Millisecond,
+		Interval: 5 * time.Millisecond,
+		OnStuck:  func(stuck []GoroutineStack) { reports <- stuck },
+	})
+	defer w.Stop()
+
+	var stuck []GoroutineStack
+	select {
+	case stuck = <-reports:
+	case <-time.After(time.Minute):
+		t.Fatal("watchdog did not report the blocked goroutine")
+	}
+	if len(stuck) != 1 {
+		t.Fatalf("watchdog reported %d goroutines, want 1", len(stuck))
+	}
+	g := stuck[0]
+	if g.State != "chan receive" || g.Labels["test"] != "TestWatchdog" || g.WaitDuration < 50*time.Millisecond {
+		t.Errorf("watchdog reported {State: %q, Labels: %v, WaitDuration: %v}", g.State, g.Labels, g.WaitDuration)
+	}
+	found := false
+	for _, f := range g.Frames {
+		found = found || f.Func == "runtime/debug_test.blockForStacks"
+	}
+	if !found {
+		t.Errorf("stack does not include blockForStacks")
+	}
+
+	// The goroutine is not reported again while it stays blocked.
+	select {
+	case stuck := <-reports:
+		t.Errorf("watchdog reported goroutine %d again", stuck[0].ID)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWatchdogShortDeadline(t *testing.T) {
+	// A quarter of a deadline under 4ns is zero, which must not
+	// become the interval.
+	w := NewWatchdog(WatchdogOptions{
+		Deadline: 3 * time.Nanosecond,
+		OnStuck:  func([]GoroutineStack) {},
+	})
+	time.Sleep(time.Millisecond)
+	w.Stop()
+}
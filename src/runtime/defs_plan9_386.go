@@ -42,19 +42,19 @@ func (c *sigctxt) setlr(x uintptr) {}
 func (c *sigctxt) savelr(x uintptr) {}
 
 func dumpregs(u *ureg) {
-	print("ax    ", hex(u.ax), "\n")
-	print("bx    ", hex(u.bx), "\n")
-	print("cx    ", hex(u.cx), "\n")
-	print("dx    ", hex(u.dx), "\n")
-	print("di    ", hex(u.di), "\n")
-	print("si    ", hex(u.si), "\n")
-	print("bp    ", hex(u.bp), "\n")
-	print("sp    ", hex(u.sp), "\n")
-	print("pc    ", hex(u.pc), "\n")
-	print("flags ", hex(u.flags), "\n")
-	print("cs    ", hex(u.cs), "\n")
-	print("fs    ", hex(u.fs), "\n")
-	print("gs    ", hex(u.gs), "\n")
+	printreg("ax    ", hex(u.ax), "\n")
+	printreg("bx    ", hex(u.bx), "\n")
+	printreg("cx    ", hex(u.cx), "\n")
+	printreg("dx    ", hex(u.dx), "\n")
+	printreg("di    ", hex(u.di), "\n")
+	printreg("si    ", hex(u.si), "\n")
+	printreg("bp    ", hex(u.bp), "\n")
+	printreg("sp    ", hex(u.sp), "\n")
+	printreg("pc    ", hex(u.pc), "\n")
+	printreg("flags ", hex(u.flags), "\n")
+	printreg("cs    ", hex(u.cs), "\n")
+	printreg("fs    ", hex(u.fs), "\n")
+	printreg("gs    ", hex(u.gs), "\n")
 }
 
 func sigpanictramp() {}
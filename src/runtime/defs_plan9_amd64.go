@@ -51,27 +51,27 @@ func (c *sigctxt) setlr(x uintptr) {}
 func (c *sigctxt) savelr(x uintptr) {}
 
 func dumpregs(u *ureg) {
-	print("ax    ", hex(u.ax), "\n")
-	print("bx    ", hex(u.bx), "\n")
-	print("cx    ", hex(u.cx), "\n")
-	print("dx    ", hex(u.dx), "\n")
-	print("di    ", hex(u.di), "\n")
-	print("si    ", hex(u.si), "\n")
-	print("bp    ", hex(u.bp), "\n")
-	print("sp    ", hex(u.sp), "\n")
-	print("r8    ", hex(u.r8), "\n")
-	print("r9    ", hex(u.r9), "\n")
-	print("r10   ", hex(u.r10), "\n")
-	print("r11   ", hex(u.r11), "\n")
-	print("r12   ", hex(u.r12), "\n")
-	print("r13   ", hex(u.r13), "\n")
-	print("r14   ", hex(u.r14), "\n")
-	print("r15   ", hex(u.r15), "\n")
-	print("ip    ", hex(u.ip), "\n")
-	print("flags ", hex(u.flags), "\n")
-	print("cs    ", hex(u.cs), "\n")
-	print("fs    ", hex(u.fs), "\n")
-	print("gs    ", hex(u.gs), "\n")
+	printreg("ax    ", hex(u.ax), "\n")
+	printreg("bx    ", hex(u.bx), "\n")
+	printreg("cx    ", hex(u.cx), "\n")
+	printreg("dx    ", hex(u.dx), "\n")
+	printreg("di    ", hex(u.di), "\n")
+	printreg("si    ", hex(u.si), "\n")
+	printreg("bp    ", hex(u.bp), "\n")
+	printreg("sp    ", hex(u.sp), "\n")
+	printreg("r8    ", hex(u.r8), "\n")
+	printreg("r9    ", hex(u.r9), "\n")
+	printreg("r10   ", hex(u.r10), "\n")
+	printreg("r11   ", hex(u.r11), "\n")
+	printreg("r12   ", hex(u.r12), "\n")
+	printreg("r13   ", hex(u.r13), "\n")
+	printreg("r14   ", hex(u.r14), "\n")
+	printreg("r15   ", hex(u.r15), "\n")
+	printreg("ip    ", hex(u.ip), "\n")
+	printreg("flags ", hex(u.flags), "\n")
+	printreg("cs    ", hex(u.cs), "\n")
+	printreg("fs    ", hex(u.fs), "\n")
+	printreg("gs    ", hex(u.gs), "\n")
 }
 
 func sigpanictramp() {}
@@ -44,23 +44,23 @@ func (c *sigctxt) setlr(x uintptr)  { c.u.link = uint32(x) }
 func (c *sigctxt) savelr(x uintptr) { c.u.r0 = uint32(x) }
 
 func dumpregs(u *ureg) {
-	print("r0    ", hex(u.r0), "\n")
-	print("r1    ", hex(u.r1), "\n")
-	print("r2    ", hex(u.r2), "\n")
-	print("r3    ", hex(u.r3), "\n")
-	print("r4    ", hex(u.r4), "\n")
-	print("r5    ", hex(u.r5), "\n")
-	print("r6    ", hex(u.r6), "\n")
-	print("r7    ", hex(u.r7), "\n")
-	print("r8    ", hex(u.r8), "\n")
-	print("r9    ", hex(u.r9), "\n")
-	print("r10   ", hex(u.r10), "\n")
-	print("r11   ", hex(u.r11), "\n")
-	print("r12   ", hex(u.r12), "\n")
-	print("sp    ", hex(u.sp), "\n")
-	print("link  ", hex(u.link), "\n")
-	print("pc    ", hex(u.pc), "\n")
-	print("psr   ", hex(u.psr), "\n")
+	printreg("r0    ", hex(u.r0), "\n")
+	printreg("r1    ", hex(u.r1), "\n")
+	printreg("r2    ", hex(u.r2), "\n")
+	printreg("r3    ", hex(u.r3), "\n")
+	printreg("r4    ", hex(u.r4), "\n")
+	printreg("r5    ", hex(u.r5), "\n")
+	printreg("r6    ", hex(u.r6), "\n")
+	printreg("r7    ", hex(u.r7), "\n")
+	printreg("r8    ", hex(u.r8), "\n")
+	printreg("r9    ", hex(u.r9), "\n")
+	printreg("r10   ", hex(u.r10), "\n")
+	printreg("r11   ", hex(u.r11), "\n")
+	printreg("r12   ", hex(u.r12), "\n")
+	printreg("sp    ", hex(u.sp), "\n")
+	printreg("link  ", hex(u.link), "\n")
+	printreg("pc    ", hex(u.pc), "\n")
+	printreg("psr   ", hex(u.psr), "\n")
 }
 
 func sigpanictramp()
@@ -116,19 +116,19 @@ func (c *context) set_ip(x uintptr) { c.eip = uint32(x) }
 func (c *context) set_sp(x uintptr) { c.esp = uint32(x) }
 
 func dumpregs(r *context) {
-	print("eax     ", hex(r.eax), "\n")
-	print("ebx     ", hex(r.ebx), "\n")
-	print("ecx     ", hex(r.ecx), "\n")
-	print("edx     ", hex(r.edx), "\n")
-	print("edi     ", hex(r.edi), "\n")
-	print("esi     ", hex(r.esi), "\n")
-	print("ebp     ", hex(r.ebp), "\n")
-	print("esp     ", hex(r.esp), "\n")
-	print("eip     ", hex(r.eip), "\n")
-	print("eflags  ", hex(r.eflags), "\n")
-	print("cs      ", hex(r.segcs), "\n")
-	print("fs      ", hex(r.segfs), "\n")
-	print("gs      ", hex(r.seggs), "\n")
+	printreg("eax     ", hex(r.eax), "\n")
+	printreg("ebx     ", hex(r.ebx), "\n")
+	printreg("ecx     ", hex(r.ecx), "\n")
+	printreg("edx     ", hex(r.edx), "\n")
+	printreg("edi     ", hex(r.edi), "\n")
+	printreg("esi     ", hex(r.esi), "\n")
+	printreg("ebp     ", hex(r.ebp), "\n")
+	printreg("esp     ", hex(r.esp), "\n")
+	printreg("eip     ", hex(r.eip), "\n")
+	printreg("eflags  ", hex(r.eflags), "\n")
+	printreg("cs      ", hex(r.segcs), "\n")
+	printreg("fs      ", hex(r.segfs), "\n")
+	printreg("gs      ", hex(r.seggs), "\n")
 }
 
 type overlapped struct {
@@ -131,26 +131,26 @@ func (c *context) set_ip(x uintptr) { c.rip = uint64(x) }
 func (c *context) set_sp(x uintptr) { c.rsp = uint64(x) }
 
 func dumpregs(r *context) {
-	print("rax     ", hex(r.rax), "\n")
-	print("rbx     ", hex(r.rbx), "\n")
-	print("rcx     ", hex(r.rcx), "\n")
-	print("rdi     ", hex(r.rdi), "\n")
-	print("rsi     ", hex(r.rsi), "\n")
-	print("rbp     ", hex(r.rbp), "\n")
-	print("rsp     ", hex(r.rsp), "\n")
-	print("r8      ", hex(r.r8), "\n")
-	print("r9      ", hex(r.r9), "\n")
-	print("r10     ", hex(r.r10), "\n")
-	print("r11     ", hex(r.r11), "\n")
-	print("r12     ", hex(r.r12), "\n")
-	print("r13     ", hex(r.r13), "\n")
-	print("r14     ", hex(r.r14), "\n")
-	print("r15     ", hex(r.r15), "\n")
-	print("rip     ", hex(r.rip), "\n")
-	print("rflags  ", hex(r.eflags), "\n")
-	print("cs      ", hex(r.segcs), "\n")
-	print("fs      ", hex(r.segfs), "\n")
-	print("gs      ", hex(r.seggs), "\n")
+	printreg("rax     ", hex(r.rax), "\n")
+	printreg("rbx     ", hex(r.rbx), "\n")
+	printreg("rcx     ", hex(r.rcx), "\n")
+	printreg("rdi     ", hex(r.rdi), "\n")
+	printreg("rsi     ", hex(r.rsi), "\n")
+	printreg("rbp     ", hex(r.rbp), "\n")
+	printreg("rsp     ", hex(r.rsp), "\n")
+	printreg("r8      ", hex(r.r8), "\n")
+	printreg("r9      ", hex(r.r9), "\n")
+	printreg("r10     ", hex(r.r10), "\n")
+	printreg("r11     ", hex(r.r11), "\n")
+	printreg("r12     ", hex(r.r12), "\n")
+	printreg("r13     ", hex(r.r13), "\n")
+	printreg("r14     ", hex(r.r14), "\n")
+	printreg("r15     ", hex(r.r15), "\n")
+	printreg("rip     ", hex(r.rip), "\n")
+	printreg("rflags  ", hex(r.eflags), "\n")
+	printreg("cs      ", hex(r.segcs), "\n")
+	printreg("fs      ", hex(r.segfs), "\n")
+	printreg("gs      ", hex(r.seggs), "\n")
 }
 
 type overlapped struct {
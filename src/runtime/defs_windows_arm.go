@@ -113,23 +113,23 @@ func (c *context) set_sp(x uintptr) { c.spr = uint32(x) }
 func (c *context) set_lr(x uintptr) { c.lrr = uint32(x) }
 
 func dumpregs(r *context) {
-	print("r0   ", hex(r.r0), "\n")
-	print("r1   ", hex(r.r1), "\n")
-	print("r2   ", hex(r.r2), "\n")
-	print("r3   ", hex(r.r3), "\n")
-	print("r4   ", hex(r.r4), "\n")
-	print("r5   ", hex(r.r5), "\n")
-	print("r6   ", hex(r.r6), "\n")
-	print("r7   ", hex(r.r7), "\n")
-	print("r8   ", hex(r.r8), "\n")
-	print("r9   ", hex(r.r9), "\n")
-	print("r10  ", hex(r.r10), "\n")
-	print("r11  ", hex(r.r11), "\n")
-	print("r12  ", hex(r.r12), "\n")
-	print("sp   ", hex(r.spr), "\n")
-	print("lr   ", hex(r.lrr), "\n")
-	print("pc   ", hex(r.pc), "\n")
-	print("cpsr ", hex(r.cpsr), "\n")
+	printreg("r0   ", hex(r.r0), "\n")
+	printreg("r1   ", hex(r.r1), "\n")
+	printreg("r2   ", hex(r.r2), "\n")
+	printreg("r3   ", hex(r.r3), "\n")
+	printreg("r4   ", hex(r.r4), "\n")
+	printreg("r5   ", hex(r.r5), "\n")
+	printreg("r6   ", hex(r.r6), "\n")
+	printreg("r7   ", hex(r.r7), "\n")
+	printreg("r8   ", hex(r.r8), "\n")
+	printreg("r9   ", hex(r.r9), "\n")
+	printreg("r10  ", hex(r.r10), "\n")
+	printreg("r11  ", hex(r.r11), "\n")
+	printreg("r12  ", hex(r.r12), "\n")
+	printreg("sp   ", hex(r.spr), "\n")
+	printreg("lr   ", hex(r.lrr), "\n")
+	printreg("pc   ", hex(r.pc), "\n")
+	printreg("cpsr ", hex(r.cpsr), "\n")
 }
 
 type overlapped struct {
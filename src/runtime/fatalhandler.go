@@ -0,0 +1,237 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package runtime
+
+import (
+	"runtime/internal/atomic"
+	"unsafe"
+)
+
+// A fatalHandler is a function set through runtime/debug to run when
+// the program is about to die. Each runs at most once. Unless the
+// dying goroutine can run it itself, it runs on a goroutine of its own,
+// and the runtime waits a while for it before going on to abort the
+// program. That goroutine runs only if another M can run it: the M
+// that starts it keeps its P while it waits.
+type fatalHandler struct {
+	fn  *funcval // set atomically
+	ran uint32   // set once the handler has started
+}
+
+// fatalHandlerState is 1 while a fatal handler runs,
+// 2 once it has returned, and otherwise 0.
+var fatalHandlerState uint32
+
+// fatalHandlerWait is how long start waits for a fatal
+// handler to return, in microseconds.
+const fatalHandlerWait = 1e6
+
+// set sets the handler's function to f, which may be nil.
+func (h *fatalHandler) set(f func()) {
+	atomicstorep(unsafe.Pointer(&h.fn), unsafe.Pointer(*(**funcval)(unsafe.Pointer(&f))))
+}
+
+// claim returns the handler's function if it is set and no fatal
+// handler has run or is running, and nil otherwise. If it returns
+// non-nil, the caller must run the function and then call release.
+func (h *fatalHandler) claim() *funcval {
+	fn := (*funcval)(atomic.Loadp(unsafe.Pointer(&h.fn)))
+	if fn == nil || atomic.Load(&h.ran) != 0 || !atomic.Cas(&fatalHandlerState, 0, 1) {
+		return nil
+	}
+	if !atomic.Cas(&h.ran, 0, 1) {
+		atomic.Store(&fatalHandlerState, 0)
+		return nil
+	}
+	return fn
+}
+
+// release lets other fatal handlers run.
+func (h *fatalHandler) release() {
+	atomic.Store(&fatalHandlerState, 0)
+}
+
+// call runs fn, as claimed from a fatalHandler,
+// on the calling goroutine.
+func (fn *funcval) call() {
+	var f func()
+	*(**funcval)(unsafe.Pointer(&f)) = fn
+	f()
+}
+
+// start runs fn, as claimed from a fatalHandler, on a new goroutine,
+// and waits until it returns, or for fatalHandlerWait.
+// sched.lock must not be held.
+func (fn *funcval) start() {
+	pc := getcallerpc()
+	systemstack(func() {
+		_g_ := getg()
+		callergp := _g_.m.curg
+		if callergp == nil {
+			callergp = _g_.m.g0
+		}
+		newg := newproc1(fn, nil, 0, callergp, pc)
+		lock(&sched.lock)
+		globrunqput(newg)
+		unlock(&sched.lock)
+		wakep()
+	})
+	waitFatalHandler()
+}
+
+// waitFatalHandler waits until the running fatal handler returns,
+// or for fatalHandlerWait.
+func waitFatalHandler() {
+	for i := 0; i < fatalHandlerWait/1000 && atomic.Load(&fatalHandlerState) != 2; i++ {
+		usleep(1000)
+	}
+}
+
+//go:linkname fatalHandlerDone runtime/debug.fatalHandlerDone
+func fatalHandlerDone() {
+	atomic.Cas(&fatalHandlerState, 1, 2)
+}
+
+// The kinds of limit reported to the handler set by
+// debug.SetLimitExceededHandler.
+// They must match runtime/debug.StackLimit and ThreadLimit.
+const (
+	limitStack   = 1
+	limitThreads = 2
+)
+
+var (
+	// limitHandler is the state of the handler set by
+	// debug.SetLimitExceededHandler. The handler runs on a goroutine
+	// that runtime/debug starts and that waits in waitLimitEvent, as
+	// os/signal's does in signal_recv, so that the M exceeding a limit
+	// need not start one.
+	limitHandler struct {
+		enabled uint32 // whether a handler is set
+		ran     uint32 // set once the handler has been woken
+		note    note   // woken when a limit is exceeded
+	}
+
+	// limitEvent describes the limit exceeded to limitHandler.
+	limitEvent struct {
+		kind         int32
+		limit, value uint64
+		goid         int64
+	}
+)
+
+//go:linkname setLimitHandler runtime/debug.setLimitHandler
+func setLimitHandler(enabled bool) {
+	v := uint32(0)
+	if enabled {
+		v = 1
+	}
+	atomic.Store(&limitHandler.enabled, v)
+}
+
+// waitLimitEvent blocks until a limit is exceeded and returns the
+// event, for the goroutine running the handler. It returns at most once.
+//
+//go:linkname waitLimitEvent runtime/debug.waitLimitEvent
+func waitLimitEvent() (kind int32, limit, value uint64, goid int64) {
+	notetsleepg(&limitHandler.note, -1)
+	return limitEvent.kind, limitEvent.limit, limitEvent.value, limitEvent.goid
+}
+
+// notifyLimitHandler records that value exceeds the limit of the given
+// kind on goroutine goid, and wakes the goroutine waiting to run the
+// handler set by debug.SetLimitExceededHandler, if one is set and no
+// fatal handler has run or is running. If it reports true, the caller
+// must call waitLimitHandler before the program aborts.
+// It is called by newstack and, holding sched.lock, by checkmcount,
+// so it must not have write barriers or take locks.
+//
+//go:nowritebarrierrec
+func notifyLimitHandler(kind int32, limit, value uint64, goid int64) bool {
+	if atomic.Load(&limitHandler.enabled) == 0 || atomic.Load(&limitHandler.ran) != 0 || !atomic.Cas(&fatalHandlerState, 0, 1) {
+		return false
+	}
+	if !atomic.Cas(&limitHandler.ran, 0, 1) {
+		atomic.Store(&fatalHandlerState, 0)
+		return false
+	}
+	limitEvent.kind, limitEvent.limit, limitEvent.value, limitEvent.goid = kind, limit, value, goid
+	notewakeup(&limitHandler.note)
+	return true
+}
+
+// waitLimitHandler waits for the handler woken by notifyLimitHandler,
+// as waitFatalHandler does, and then lets other fatal handlers run.
+// sched.lock must not be held, as the handler goroutine needs it to
+// acquire a P.
+//
+//go:nowritebarrierrec
+func waitLimitHandler() {
+	waitFatalHandler()
+	atomic.Store(&fatalHandlerState, 0)
+}
+
+var (
+	// crashHandler is the handler set by debug.SetCrashHandler.
+	crashHandler fatalHandler
+
+	// crashEvent describes the crash to crashHandler.
+	crashEvent struct {
+		value      interface{} // the panic value, for a panic
+		msg        string      // the error, for a fatal error
+		sig        uint32
+		code, addr uintptr
+		ngoroutine int32
+	}
+)
+
+//go:linkname setCrashHandler runtime/debug.setCrashHandler
+func setCrashHandler(f func()) {
+	crashHandler.set(f)
+}
+
+//go:linkname readCrashEvent runtime/debug.readCrashEvent
+func readCrashEvent() (value interface{}, msg string, sig uint32, code, addr uintptr, ngoroutine int) {
+	e := &crashEvent
+	return e.value, e.msg, e.sig, e.code, e.addr, int(e.ngoroutine)
+}
+
+// crashPanic runs crashHandler, if set, on the calling goroutine, which
+// is about to die of the panic p.
+func crashPanic(gp *g, p *_panic) {
+	fn := crashHandler.claim()
+	if fn == nil {
+		return
+	}
+	crashEvent.value = p.arg
+	crashEvent.sig, crashEvent.code, crashEvent.addr = gp.sig, gp.sigcode0, gp.sigcode1
+	crashEvent.ngoroutine = gcount()
+	fn.call()
+	crashHandler.release()
+}
+
+// crashThrow runs crashHandler, if set, before the program dies of the
+// fatal error s, unless the M is in a state in which starting the
+// handler goroutine could fail, such as holding a runtime lock, or the
+// goroutine could not run, as with the world stopped. It runs on the
+// system stack.
+//
+//go:yeswritebarrierrec
+func crashThrow(s string) {
+	mp := getg().m
+	if mp.locks > 0 || mp.mallocing != 0 || mp.p == 0 || mp.curg == nil || mp.dying != 0 || atomic.Load(&sched.gcwaiting) != 0 {
+		return
+	}
+	fn := crashHandler.claim()
+	if fn == nil {
+		return
+	}
+	crashEvent.msg = s
+	gp := mp.curg
+	crashEvent.sig, crashEvent.code, crashEvent.addr = gp.sig, gp.sigcode0, gp.sigcode1
+	crashEvent.ngoroutine = gcount()
+	fn.start()
+	crashHandler.release()
+}
@@ -221,6 +221,17 @@ func gcenable() {
 	memstats.enablegc = true // now that runtime is initialized, GC is okay
 }
 
+//go:linkname getGCPercent runtime/debug.getGCPercent
+func getGCPercent() (out int32) {
+	// Run on the system stack since we grab the heap lock.
+	systemstack(func() {
+		lock(&mheap_.lock)
+		out = gcpercent
+		unlock(&mheap_.lock)
+	})
+	return out
+}
+
 //go:linkname setGCPercent runtime/debug.setGCPercent
 func setGCPercent(in int32) (out int32) {
 	// Run on the system stack since we grab the heap lock.
@@ -246,6 +257,51 @@ func setGCPercent(in int32) (out int32) {
 	return out
 }
 
+// memoryLimit is the soft limit on the memory used by the runtime, in
+// bytes, set by debug.SetMemoryLimit. The maximum int64 means no limit.
+// It is protected by mheap_.lock or read with the world stopped.
+var memoryLimit int64 = 1<<63 - 1
+
+// memoryLimitMinHeapDistance is how far the heap goal always lies above
+// the marked heap, however low the memory limit, so that a limit below
+// the live heap slows the program rather than running GC continuously.
+const memoryLimitMinHeapDistance = 4 << 20
+
+//go:linkname setMemoryLimit runtime/debug.setMemoryLimit
+func setMemoryLimit(in int64) (out int64) {
+	// Run on the system stack since we grab the heap lock.
+	systemstack(func() {
+		lock(&mheap_.lock)
+		out = memoryLimit
+		if in >= 0 {
+			memoryLimit = in
+			// Update pacing in response to the limit change.
+			gcSetTriggerRatio(memstats.triggerRatio)
+		}
+		unlock(&mheap_.lock)
+	})
+	return out
+}
+
+// heapTarget is the minimum heap goal, in bytes, set by
+// debug.SetHeapTarget. Zero means no minimum beyond heapminimum.
+// It is protected by mheap_.lock or read with the world stopped.
+var heapTarget uint64
+
+//go:linkname setHeapTarget runtime/debug.setHeapTarget
+func setHeapTarget(in uint64) (out uint64) {
+	// Run on the system stack since we grab the heap lock.
+	systemstack(func() {
+		lock(&mheap_.lock)
+		out = heapTarget
+		heapTarget = in
+		// Update pacing in response to the target change.
+		gcSetTriggerRatio(memstats.triggerRatio)
+		unlock(&mheap_.lock)
+	})
+	return out
+}
+
 // Garbage collector phase.
 // Indicates to write barrier and synchronization task to perform.
 var gcphase uint32
@@ -750,6 +806,26 @@ func pollFractionalWorkerExit() bool {
 	return float64(selfTime)/float64(delta) > 1.2*gcController.fractionalUtilizationGoal
 }
 
+// memoryLimitHeapGoal returns the heap goal that keeps the memory used
+// by the runtime within memoryLimit: the limit less the memory mapped
+// for other than the heap. It never returns less than
+// memoryLimitMinHeapDistance above the marked heap.
+//
+// mheap_.lock must be held or the world must be stopped.
+func memoryLimitHeapGoal() uint64 {
+	if memoryLimit == 1<<63-1 {
+		return ^uint64(0)
+	}
+	nonHeap := atomic.Load64(&memstats.stacks_inuse) + memstats.stacks_sys +
+		memstats.mspan_sys + memstats.mcache_sys + memstats.buckhash_sys +
+		atomic.Load64(&memstats.gc_sys) + atomic.Load64(&memstats.other_sys)
+	min := memstats.heap_marked + memoryLimitMinHeapDistance
+	if limit := uint64(memoryLimit); limit > nonHeap && limit-nonHeap > min {
+		return limit - nonHeap
+	}
+	return min
+}
+
 // gcSetTriggerRatio sets the trigger ratio and updates everything
 // derived from it: the absolute trigger, the heap goal, mark pacing,
 // and sweep pacing.
@@ -840,6 +916,32 @@ func gcSetTriggerRatio(triggerRatio float64) {
 			// Push up the goal, too.
 			goal = trigger
 		}
+
+		// Raise the goal to the heap target, if it is higher,
+		// keeping the trigger the same fraction of the way from
+		// the marked heap to the goal as GOGC would place it.
+		if goal < heapTarget {
+			goal = heapTarget
+			frac := 0.95
+			if gcpercent > 0 {
+				frac = triggerRatio * 100 / float64(gcpercent)
+			}
+			targetTrigger := memstats.heap_marked + uint64(frac*float64(goal-memstats.heap_marked))
+			if targetTrigger > trigger {
+				trigger = targetTrigger
+			}
+		}
+	}
+
+	// Lower the goal, and the trigger with it, if the heap would
+	// otherwise grow past the memory limit. This applies even with
+	// GOGC=off, so that the limit alone can drive collection.
+	if limitGoal := memoryLimitHeapGoal(); limitGoal < goal {
+		goal = limitGoal
+		limitTrigger := memstats.heap_marked + uint64(0.95*float64(goal-memstats.heap_marked))
+		if limitTrigger < trigger {
+			trigger = limitTrigger
+		}
 	}
 
 	// Commit to the trigger and goal.
@@ -1158,6 +1260,65 @@ func gcWaitOnMark(n uint32) {
 	}
 }
 
+// A gcCycleRecord holds statistics about a completed GC cycle
+// for runtime/debug. It must match runtime/debug.gcCycleRecord.
+type gcCycleRecord struct {
+	end            int64   // Unix time in nanoseconds at which the cycle ended
+	pause          int64   // total stop-the-world pause in nanoseconds
+	sweepTermPause int64   // sweep termination pause in nanoseconds
+	markTermPause  int64   // mark termination pause in nanoseconds
+	assistTime     int64   // nanoseconds spent in mutator assists
+	cpuFraction    float64 // fraction of CPU used by GC during the cycle
+	heapLive       uint64  // bytes of heap marked live
+	heapGoal       uint64  // heap goal for the next cycle
+}
+
+// gcCycleRecords holds the records of the most recent GC cycles.
+// The record of the cycle that brought memstats.numgc to n is at
+// index (n-1)%len(gcCycleRecords). It is written with the world
+// stopped.
+var gcCycleRecords [256]gcCycleRecord
+
+// readGCCycles copies into recs the records of the cycles after
+// cycle n, oldest first, as far as recs and gcCycleRecords hold them,
+// and returns the number of the last cycle copied, or of the last
+// cycle, if no records are copied.
+//go:linkname readGCCycles runtime/debug.readGCCycles
+func readGCCycles(n uint32, recs []gcCycleRecord) (last uint32, copied int) {
+	systemstack(func() {
+		numgc := memstats.numgc
+		if numgc-n > uint32(len(gcCycleRecords)) {
+			// The records of the intervening cycles are gone.
+			n = numgc - uint32(len(gcCycleRecords))
+		}
+		for n < numgc && copied < len(recs) {
+			recs[copied] = gcCycleRecords[n%uint32(len(gcCycleRecords))]
+			copied++
+			n++
+		}
+		last = n
+		if copied == 0 {
+			last = numgc
+		}
+	})
+	return last, copied
+}
+
+// waitGCCycle blocks until GC completes cycle n. If it already has,
+// waitGCCycle returns immediately.
+//go:linkname waitGCCycle runtime/debug.waitGCCycle
+func waitGCCycle(n uint32) {
+	for {
+		lock(&work.sweepWaiters.lock)
+		if memstats.numgc >= n {
+			unlock(&work.sweepWaiters.lock)
+			return
+		}
+		work.sweepWaiters.list.push(getg())
+		goparkunlock(&work.sweepWaiters.lock, waitReasonWaitForGCCycle, traceEvGoBlock, 1)
+	}
+}
+
 // gcMode indicates how concurrent a GC cycle should be.
 type gcMode int
 
@@ -1726,6 +1887,17 @@ func gcMarkTermination(nextTriggerRatio float64) {
 	cycleCpu := sweepTermCpu + markCpu + markTermCpu
 	work.totaltime += cycleCpu
 
+	gcCycleRecords[memstats.numgc%uint32(len(gcCycleRecords))] = gcCycleRecord{
+		end:            unixNow,
+		pause:          work.pauseNS,
+		sweepTermPause: work.tMark - work.tSweepTerm,
+		markTermPause:  work.tEnd - work.tMarkTerm,
+		assistTime:     gcController.assistTime,
+		cpuFraction:    float64(cycleCpu) / float64((work.tEnd-work.tSweepTerm)*int64(gomaxprocs)),
+		heapLive:       memstats.heap_marked,
+		heapGoal:       memstats.next_gc,
+	}
+
 	// Compute overall GC CPU utilization.
 	totalCpu := sched.totaltime + (now-sched.procresizetime)*int64(gomaxprocs)
 	memstats.gc_cpu_fraction = float64(work.totaltime) / float64(totalCpu)
@@ -1508,6 +1508,43 @@ func runtime_debug_freeOSMemory() {
 	systemstack(func() { mheap_.scavengeAll() })
 }
 
+// scavengeTo releases free pages to the OS until the heap retains at
+// most target bytes, or max bytes have been released, and returns
+// the number of bytes released and the number of bytes retained.
+// If restart is set, it first starts a new scavenge generation, so
+// that the whole heap may be released; otherwise it continues from
+// where the last call left off, and it releases nothing once the
+// generation's search of the heap is exhausted.
+func (h *mheap) scavengeTo(target uint64, max uintptr, restart bool) (released uintptr, retained uint64) {
+	// Disallow malloc or panic while holding the heap lock,
+	// as in scavengeAll.
+	gp := getg()
+	gp.m.mallocing++
+	lock(&h.lock)
+	if restart {
+		h.pages.scavengeStartGen()
+	}
+	if r := heapRetained(); r > target {
+		n := max
+		if r-target < uint64(n) {
+			n = uintptr(r - target)
+		}
+		released = h.pages.scavenge(n, false)
+	}
+	retained = heapRetained()
+	unlock(&h.lock)
+	gp.m.mallocing--
+	return released, retained
+}
+
+//go:linkname runtime_debug_freeOSMemoryTo runtime/debug.freeOSMemoryTo
+func runtime_debug_freeOSMemoryTo(target uint64, max uintptr, restart bool) (released uintptr, retained uint64) {
+	systemstack(func() {
+		released, retained = mheap_.scavengeTo(target, max, restart)
+	})
+	return released, retained
+}
+
 // Initialize a new span with the given start and npages.
 func (span *mspan) init(base uintptr, npages uintptr) {
 	// span is *not* zeroed.
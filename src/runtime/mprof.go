@@ -789,6 +789,98 @@ func GoroutineProfile(p []StackRecord) (n int, ok bool) {
 	return goroutineProfileWithLabels(p, nil)
 }
 
+// A goroutineRecord describes a goroutine to runtime/debug.
+// It must match runtime/debug.goroutineRecord.
+type goroutineRecord struct {
+	id      int64
+	status  string         // as printed in tracebacks
+	blocked bool           // whether in _Gwaiting
+	waited  int64          // approximate nanoseconds blocked, or 0
+	labels  unsafe.Pointer // profiler labels, a *runtime/pprof.labelMap
+	gopc    uintptr        // pc of the go statement that created it
+}
+
+// goroutineStacks is like GoroutineProfile but for runtime/debug.
+// It describes each goroutine in recs and records its stack, up to
+// len(stk)/len(recs) frames, in the corresponding part of stk,
+// terminated by a 0 if the stack is shorter. The calling goroutine
+// comes first, with the stack beginning skip frames above its caller.
+//go:linkname goroutineStacks runtime/debug.goroutineStacks
+func goroutineStacks(recs []goroutineRecord, stk []uintptr, skip int) (n int, ok bool) {
+	gp := getg()
+
+	isOK := func(gp1 *g) bool {
+		return gp1 != gp && readgstatus(gp1) != _Gdead && !isSystemGoroutine(gp1, false)
+	}
+
+	stopTheWorld("stack trace")
+
+	n = 1
+	for _, gp1 := range allgs {
+		if isOK(gp1) {
+			n++
+		}
+	}
+
+	if n <= len(recs) {
+		ok = true
+		depth := len(stk) / len(recs)
+
+		// Save current goroutine.
+		sp := getcallersp()
+		pc := getcallerpc()
+		systemstack(func() {
+			savegstack(pc, sp, gp, skip, stk[:depth])
+		})
+		recs[0] = goroutineRecord{id: gp.goid, status: "running", labels: gp.labels, gopc: gp.gopc}
+
+		// Save other goroutines.
+		i := 1
+		for _, gp1 := range allgs {
+			if isOK(gp1) {
+				if i == len(recs) {
+					break
+				}
+				savegstack(^uintptr(0), ^uintptr(0), gp1, 0, stk[i*depth:(i+1)*depth])
+				status := readgstatus(gp1) &^ _Gscan
+				recs[i] = goroutineRecord{
+					id:      gp1.goid,
+					status:  gstatusString(gp1, status),
+					blocked: status == _Gwaiting,
+					waited:  gwaitTime(gp1, status),
+					labels:  gp1.labels,
+					gopc:    gp1.gopc,
+				}
+				i++
+			}
+		}
+	}
+
+	startTheWorld()
+	if ok && raceenabled {
+		// The caller reads the label maps, which were written
+		// before runtime_setProfLabel's release of labelSync.
+		raceacquire(unsafe.Pointer(&labelSync))
+	}
+	return n, ok
+}
+
+// goroutineID returns the ID of the calling goroutine, for runtime/debug.
+//go:linkname goroutineID runtime/debug.goroutineID
+func goroutineID() int64 {
+	return getg().goid
+}
+
+func savegstack(pc, sp uintptr, gp *g, skip int, stk []uintptr) {
+	if len(stk) == 0 {
+		return
+	}
+	n := gentraceback(pc, sp, 0, gp, skip, &stk[0], len(stk), nil, nil, 0)
+	if n < len(stk) {
+		stk[n] = 0
+	}
+}
+
 func saveg(pc, sp uintptr, gp *g, r *StackRecord) {
 	n := gentraceback(pc, sp, 0, gp, 0, &r.Stack0[0], len(r.Stack0), nil, nil, 0)
 	if n < len(r.Stack0) {
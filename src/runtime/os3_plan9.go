@@ -126,16 +126,29 @@ Throw:
 	_g_.m.throwing = 1
 	_g_.m.caughtsig.set(gp)
 	startpanic_m()
-	print(notestr, "\n")
-	print("PC=", hex(c.pc()), "\n")
-	print("\n")
+	if crashJSON() {
+		print(`{"type":"signal","signal":`)
+		printJSONString(notestr)
+		// Notes have no code or address.
+		print(`,"code":"0x0","addr":"0x0","pc":"`, hex(c.pc()), `","m":`, _g_.m.id, "}\n")
+	} else {
+		print(notestr, "\n")
+		print("PC=", hex(c.pc()), "\n")
+		print("\n")
+	}
 	level, _, docrash = gotraceback()
 	if level > 0 {
 		goroutineheader(gp)
 		tracebacktrap(c.pc(), c.sp(), c.lr(), gp)
 		tracebackothers(gp)
-		print("\n")
-		dumpregs(_ureg)
+		if crashJSON() {
+			print(`{"type":"registers","m":`, _g_.m.id)
+			dumpregs(_ureg)
+			print("}\n")
+		} else {
+			print("\n")
+			dumpregs(_ureg)
+		}
 	}
 	if docrash {
 		crash()
@@ -679,6 +679,10 @@ func preprintpanics(p *_panic) {
 // Print all currently active panics. Used when crashing.
 // Should only be called after preprintpanics.
 func printpanics(p *_panic) {
+	if crashJSON() {
+		printpanicsJSON(p)
+		return
+	}
 	if p.link != nil {
 		printpanics(p.link)
 		if !p.link.goexit {
@@ -1059,6 +1063,7 @@ func gopanic(e interface{}) {
 	// Because it is unsafe to call arbitrary user code after freezing
 	// the world, we call preprintpanics to invoke all necessary Error
 	// and String methods to prepare the panic strings before startpanic.
+	crashPanic(gp, gp._panic)
 	preprintpanics(gp._panic)
 
 	fatalpanic(gp._panic) // should not return
@@ -1097,6 +1102,30 @@ func gorecover(argp uintptr) interface{} {
 	return nil
 }
 
+// recoverCaller is recover for runtime/debug.RecoverWithStack: it
+// recovers the panic, as recover would if called by the caller of
+// its caller, and reports whether there was a panic to recover.
+//go:linkname recoverCaller runtime/debug.recoverCaller
+func recoverCaller() (v interface{}, ok bool) {
+	gp := getg()
+	pc, sp := getcallerpc(), getcallersp()
+	var argp uintptr
+	n := 0
+	systemstack(func() {
+		// The frames are those of the caller and its caller.
+		n = gentraceback(pc, sp, 0, gp, 0, nil, 2, func(frame *stkframe, _ unsafe.Pointer) bool {
+			argp = frame.argp
+			return true
+		}, nil, 0)
+	})
+	p := gp._panic
+	if n == 2 && p != nil && !p.goexit && !p.recovered && argp == uintptr(p.argp) {
+		p.recovered = true
+		return p.arg, true
+	}
+	return nil, false
+}
+
 //go:linkname sync_throw sync.throw
 func sync_throw(s string) {
 	throw(s)
@@ -1106,13 +1135,20 @@ func sync_throw(s string) {
 func throw(s string) {
 	// Everything throw does should be recursively nosplit so it
 	// can be called even when it's unsafe to grow the stack.
-	systemstack(func() {
-		print("fatal error: ", s, "\n")
-	})
 	gp := getg()
 	if gp.m.throwing == 0 {
 		gp.m.throwing = 1
 	}
+	systemstack(func() {
+		if crashJSON() {
+			print(`{"type":"fatal error","error":`)
+			printJSONString(s)
+			print("}\n")
+		} else {
+			print("fatal error: ", s, "\n")
+		}
+		crashThrow(s)
+	})
 	fatalthrow()
 	*(*int)(nil) = 0 // not reached
 }
@@ -1293,14 +1329,25 @@ var didothers bool
 var deadlock mutex
 
 func dopanic_m(gp *g, pc, sp uintptr) bool {
+	json := crashJSON()
 	if gp.sig != 0 {
 		signame := signame(gp.sig)
-		if signame != "" {
-			print("[signal ", signame)
+		if json {
+			print(`{"type":"signal","signal":"`)
+			if signame != "" {
+				print(signame)
+			} else {
+				print(hex(gp.sig))
+			}
+			print(`","code":"`, hex(gp.sigcode0), `","addr":"`, hex(gp.sigcode1), `","pc":"`, hex(gp.sigpc), `","m":`, getg().m.id, "}\n")
 		} else {
-			print("[signal ", hex(gp.sig))
+			if signame != "" {
+				print("[signal ", signame)
+			} else {
+				print("[signal ", hex(gp.sig))
+			}
+			print(" code=", hex(gp.sigcode0), " addr=", hex(gp.sigcode1), " pc=", hex(gp.sigpc), "]\n")
 		}
-		print(" code=", hex(gp.sigcode0), " addr=", hex(gp.sigcode1), " pc=", hex(gp.sigpc), "]\n")
 	}
 
 	level, all, docrash := gotraceback()
@@ -1310,11 +1357,17 @@ func dopanic_m(gp *g, pc, sp uintptr) bool {
 			all = true
 		}
 		if gp != gp.m.g0 {
-			print("\n")
+			if !json {
+				print("\n")
+			}
 			goroutineheader(gp)
 			traceback(pc, sp, 0, gp)
 		} else if level >= 2 || _g_.m.throwing > 0 {
-			print("\nruntime stack:\n")
+			if json {
+				print(`{"type":"runtime stack"`)
+			} else {
+				print("\nruntime stack:\n")
+			}
 			traceback(pc, sp, 0, gp)
 		}
 		if !didothers && all {
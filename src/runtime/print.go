@@ -81,14 +81,40 @@ func printunlock() {
 	}
 }
 
+// crashFD is the file descriptor set by debug.SetCrashOutput, to which
+// the output of fatal panics and throws is copied, or ^uintptr(0).
+var crashFD = ^uintptr(0)
+
+//go:linkname setCrashFD runtime/debug.setCrashFD
+func setCrashFD(fd uintptr) uintptr {
+	return atomic.Xchguintptr(&crashFD, fd)
+}
+
+// writeCrash copies b to crashFD if the program is crashing, that is,
+// if the M is dying or throwing, as the signal and exception handlers
+// mark it before printing.
+//go:nosplit
+func writeCrash(gp *g, b []byte) {
+	if gp != nil && gp.m.dying == 0 && gp.m.throwing == 0 || gp == nil && atomic.Load(&panicking) == 0 {
+		return
+	}
+	if fd := atomic.Loaduintptr(&crashFD); fd != ^uintptr(0) {
+		write(fd, unsafe.Pointer(&b[0]), int32(len(b)))
+	}
+}
+
 // write to goroutine-local buffer if diverting output,
 // or else standard error.
 func gwrite(b []byte) {
 	if len(b) == 0 {
 		return
 	}
-	recordForPanic(b)
 	gp := getg()
+	if gp != nil && gp.m != nil && gp.m.printjson {
+		gwriteJSON(b)
+		return
+	}
+	recordForPanic(b)
 	// Don't use the writebuf if gp.m is dying. We want anything
 	// written through gwrite to appear in the terminal rather
 	// than be written to in some buffer, if we're in a panicking state.
@@ -96,6 +122,7 @@ func gwrite(b []byte) {
 	// because a panic isn't allowed to have any write barriers.
 	if gp == nil || gp.writebuf == nil || gp.m.dying > 0 {
 		writeErr(b)
+		writeCrash(gp, b)
 		return
 	}
 
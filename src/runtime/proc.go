@@ -615,12 +615,32 @@ func dumpgstatus(gp *g) {
 	print("runtime:  g:  g=", _g_, ", goid=", _g_.goid, ",  g->atomicstatus=", readgstatus(_g_), "\n")
 }
 
-func checkmcount() {
+// checkmcount throws if the program exceeds the thread limit, unless
+// it wakes the handler set by debug.SetLimitExceededHandler, in which
+// case it reports true and the caller must call limitThreadsExceeded
+// once it has released sched.lock.
+func checkmcount() bool {
 	// sched lock is held
 	if mcount() > sched.maxmcount {
 		print("runtime: program exceeds ", sched.maxmcount, "-thread limit\n")
+		// Only an M with a P waits for the handler: one without,
+		// as allocm(nil) creates for cgo callbacks, may be in no
+		// state to sleep.
+		if getg().m.p != 0 && notifyLimitHandler(limitThreads, uint64(sched.maxmcount), uint64(mcount()), 0) {
+			// Allow the handler a few more threads to run on.
+			sched.maxmcount += 5
+			return true
+		}
 		throw("thread exhaustion")
 	}
+	return false
+}
+
+// limitThreadsExceeded waits for the handler woken by checkmcount and
+// throws. sched.lock must not be held.
+func limitThreadsExceeded() {
+	waitLimitHandler()
+	throw("thread exhaustion")
 }
 
 func mcommoninit(mp *m) {
@@ -637,7 +657,7 @@ func mcommoninit(mp *m) {
 	}
 	mp.id = sched.mnext
 	sched.mnext++
-	checkmcount()
+	exceeded := checkmcount()
 
 	mp.fastrand[0] = uint32(int64Hash(uint64(mp.id), fastrandseed))
 	mp.fastrand[1] = uint32(int64Hash(uint64(cputicks()), ^fastrandseed))
@@ -658,6 +678,9 @@ func mcommoninit(mp *m) {
 	// so we need to publish it safely.
 	atomicstorep(unsafe.Pointer(&allm), unsafe.Pointer(mp))
 	unlock(&sched.lock)
+	if exceeded {
+		limitThreadsExceeded()
+	}
 
 	// Allocate memory to hold a cgo traceback if the cgo call crashes.
 	if iscgo || GOOS == "solaris" || GOOS == "illumos" || GOOS == "windows" {
@@ -824,6 +847,22 @@ func casgstatus(gp *g, oldval, newval uint32) {
 			nextYield = nanotime() + yieldDelay/2
 		}
 	}
+
+	// Sample the time from runnable to running.
+	switch newval {
+	case _Grunnable:
+		gp.trackingSeq++
+		if gp.trackingSeq%schedLatencySampleRate == 0 {
+			gp.runnableSince = nanotime()
+		} else {
+			gp.runnableSince = 0
+		}
+	case _Grunning:
+		if gp.runnableSince != 0 {
+			recordSchedLatency(nanotime() - gp.runnableSince)
+			gp.runnableSince = 0
+		}
+	}
 }
 
 // casgstatus(gp, oldstatus, Gcopystack), assuming oldstatus is Gwaiting or Grunnable.
@@ -2916,6 +2955,7 @@ func goexit0(gp *g) {
 	_g_.m.lockedg = 0
 	gp.preemptStop = false
 	gp.paniconfault = false
+	gp.inheritfault = false
 	gp._defer = nil // should be true already but just in case.
 	gp._panic = nil // non-nil for Goexit during panic. points at stack-allocated data.
 	gp.writebuf = nil
@@ -3587,6 +3627,10 @@ func newproc1(fn *funcval, argp unsafe.Pointer, narg int32, callergp *g, callerp
 	if _g_.m.curg != nil {
 		newg.labels = _g_.m.curg.labels
 	}
+	if callergp.inheritfault {
+		newg.paniconfault = callergp.paniconfault
+		newg.inheritfault = true
+	}
 	if isSystemGoroutine(newg, false) {
 		atomic.Xadd(&sched.ngsys, +1)
 	}
@@ -5413,11 +5457,22 @@ func setMaxThreads(in int) (out int) {
 	} else {
 		sched.maxmcount = int32(in)
 	}
-	checkmcount()
+	exceeded := checkmcount()
 	unlock(&sched.lock)
+	if exceeded {
+		limitThreadsExceeded()
+	}
 	return
 }
 
+//go:linkname getMaxThreads runtime/debug.getMaxThreads
+func getMaxThreads() int {
+	lock(&sched.lock)
+	out := int(sched.maxmcount)
+	unlock(&sched.lock)
+	return out
+}
+
 func haveexperiment(name string) bool {
 	if name == "framepointer" {
 		return framepointer_enabled // set by linker
@@ -4,13 +4,19 @@
 
 package runtime
 
-import _ "unsafe" // for go:linkname
+import (
+	"runtime/internal/atomic"
+	_ "unsafe" // for go:linkname
+)
 
 //go:linkname setMaxStack runtime/debug.setMaxStack
 func setMaxStack(in int) (out int) {
-	out = int(maxstacksize)
-	maxstacksize = uintptr(in)
-	return out
+	return int(atomic.Xchguintptr(&maxstacksize, uintptr(in)))
+}
+
+//go:linkname getMaxStack runtime/debug.getMaxStack
+func getMaxStack() int {
+	return int(atomic.Loaduintptr(&maxstacksize))
 }
 
 //go:linkname setPanicOnFault runtime/debug.setPanicOnFault
@@ -20,3 +26,11 @@ func setPanicOnFault(new bool) (old bool) {
 	_g_.paniconfault = new
 	return old
 }
+
+//go:linkname setInheritFault runtime/debug.setInheritFault
+func setInheritFault(new bool) (old bool) {
+	_g_ := getg()
+	old = _g_.inheritfault
+	_g_.inheritfault = new
+	return old
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package runtime
+
+import (
+	"runtime/internal/atomic"
+	"runtime/internal/sys"
+	_ "unsafe" // for go:linkname
+)
+
+// Scheduling latency is the time a goroutine spends runnable before it
+// runs. casgstatus samples it for one in schedLatencySampleRate of the
+// transitions to runnable, to keep nanotime off the hot path, and
+// recordSchedLatency counts the samples in schedLatencyBuckets.
+//
+// Bucket 0 counts latencies below 1<<schedLatencyMinShift nanoseconds
+// (about 1µs), bucket i counts those in [1<<(schedLatencyMinShift+i-1),
+// 1<<(schedLatencyMinShift+i)), and the last bucket counts the rest,
+// of about 1s and more.
+const (
+	schedLatencySampleRate = 8
+	schedLatencyMinShift   = 10
+	schedLatencyNumBuckets = 22
+)
+
+// schedLatencyBuckets holds the counts of sampled scheduling latencies.
+// It is accessed atomically.
+var schedLatencyBuckets [schedLatencyNumBuckets]uint64
+
+// recordSchedLatency counts a sampled scheduling latency of d nanoseconds.
+//go:nosplit
+func recordSchedLatency(d int64) {
+	i := 0
+	if d >= 1<<schedLatencyMinShift {
+		i = sys.Len64(uint64(d)) - schedLatencyMinShift
+		if i >= schedLatencyNumBuckets {
+			i = schedLatencyNumBuckets - 1
+		}
+	}
+	atomic.Xadd64(&schedLatencyBuckets[i], 1)
+}
+
+// A runtimeSnapshot holds the statistics read at once by
+// runtime/debug.ReadRuntimeSnapshot. It must match
+// runtime/debug.runtimeSnapshot.
+type runtimeSnapshot struct {
+	now          int64 // Unix time in nanoseconds
+	mem          MemStats
+	running      int64 // user goroutines by status
+	runnable     int64
+	waiting      int64
+	syscall      int64
+	threads      int64
+	gomaxprocs   int64
+	schedLatency [schedLatencyNumBuckets]uint64
+}
+
+//go:linkname readRuntimeSnapshot runtime/debug.readRuntimeSnapshot
+func readRuntimeSnapshot(s *runtimeSnapshot) {
+	stopTheWorld("read runtime snapshot")
+
+	systemstack(func() {
+		sec, nsec, _ := time_now()
+		s.now = sec*1e9 + int64(nsec)
+		readmemstats_m(&s.mem)
+		lock(&allglock)
+		for _, gp := range allgs {
+			if isSystemGoroutine(gp, false) {
+				continue
+			}
+			switch readgstatus(gp) &^ _Gscan {
+			case _Grunning:
+				s.running++
+			case _Grunnable:
+				s.runnable++
+			case _Gwaiting, _Gpreempted:
+				s.waiting++
+			case _Gsyscall:
+				s.syscall++
+			}
+		}
+		unlock(&allglock)
+		s.threads = int64(mcount())
+		s.gomaxprocs = int64(gomaxprocs)
+		for i := range s.schedLatency {
+			s.schedLatency[i] = atomic.Load64(&schedLatencyBuckets[i])
+		}
+	})
+
+	startTheWorld()
+}
@@ -381,6 +381,17 @@ func parsedebugvars() {
 	traceback_env = traceback_cache
 }
 
+// godebugSettings returns the names of the GODEBUG settings the runtime
+// reads and their current values, defaults included.
+//go:linkname godebugSettings runtime/debug.godebugSettings
+func godebugSettings() (names []string, values []int32) {
+	for _, v := range dbgvars {
+		names = append(names, v.name)
+		values = append(values, *v.value)
+	}
+	return
+}
+
 //go:linkname setTraceback runtime/debug.SetTraceback
 func setTraceback(level string) {
 	var t uint32
@@ -440,6 +440,7 @@ type g struct {
 	asyncSafePoint bool
 
 	paniconfault bool // panic (instead of crash) on unexpected fault address
+	inheritfault bool // goroutines started by this one inherit paniconfault and inheritfault
 	gcscandone   bool // g has scanned stack; protected by _Gscan bit in status
 	throwsplit   bool // must not split stack
 	// activeStackChans indicates that there are unlocked channels
@@ -449,8 +450,10 @@ type g struct {
 	activeStackChans bool
 
 	raceignore     int8     // ignore race detection events
+	trackingSeq    uint8    // transitions to runnable, for sampling runnableSince
 	sysblocktraced bool     // StartTrace has emitted EvGoInSyscall about this goroutine
 	sysexitticks   int64    // cputicks when syscall has returned (for tracing)
+	runnableSince  int64    // nanotime when the g became runnable, if sampling its scheduling latency
 	traceseq       uint64   // trace event sequencer
 	tracelastp     puintptr // last P emitted an event for this goroutine
 	lockedm        muintptr
@@ -509,6 +512,8 @@ type m struct {
 	blocked       bool // m is blocked on a note
 	newSigstack   bool // minit on C thread called sigaltstack
 	printlock     int8
+	printjson     bool   // print output is escaped as JSON string contents; see crashjson.go
+	printjsonsep  bool   // the JSON array being printed needs a comma before its next element
 	incgo         bool   // m is executing a cgo call
 	freeWait      uint32 // if == 0, safe to free g0 and delete m (atomic)
 	fastrand      [2]uint32
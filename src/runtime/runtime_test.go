@@ -192,6 +192,37 @@ func TestSetPanicOnFault(t *testing.T) {
 	}
 }
 
+func TestInheritPanicOnFault(t *testing.T) {
+	old := debug.SetPanicOnFault(true)
+	defer debug.SetPanicOnFault(old)
+	oldInherit := debug.InheritPanicOnFault(true)
+	defer debug.InheritPanicOnFault(oldInherit)
+
+	// The setting passes to children and grandchildren.
+	done := make(chan bool)
+	go func() {
+		child := debug.SetPanicOnFault(true)
+		go func() {
+			nfault := 0
+			for _, addr := range faultAddrs {
+				testSetPanicOnFault(t, uintptr(addr), &nfault)
+			}
+			done <- child && debug.SetPanicOnFault(true) && nfault > 0
+		}()
+	}()
+	if !<-done {
+		t.Errorf("goroutines did not inherit SetPanicOnFault(true)")
+	}
+
+	debug.InheritPanicOnFault(false)
+	go func() {
+		done <- debug.SetPanicOnFault(false)
+	}()
+	if <-done {
+		t.Errorf("goroutine inherited SetPanicOnFault(true) after InheritPanicOnFault(false)")
+	}
+}
+
 // testSetPanicOnFault tests one potentially faulting address.
 // It deliberately constructs and uses an invalid pointer,
 // so mark it as nocheckptr.
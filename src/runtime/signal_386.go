@@ -12,19 +12,19 @@ import (
 )
 
 func dumpregs(c *sigctxt) {
-	print("eax    ", hex(c.eax()), "\n")
-	print("ebx    ", hex(c.ebx()), "\n")
-	print("ecx    ", hex(c.ecx()), "\n")
-	print("edx    ", hex(c.edx()), "\n")
-	print("edi    ", hex(c.edi()), "\n")
-	print("esi    ", hex(c.esi()), "\n")
-	print("ebp    ", hex(c.ebp()), "\n")
-	print("esp    ", hex(c.esp()), "\n")
-	print("eip    ", hex(c.eip()), "\n")
-	print("eflags ", hex(c.eflags()), "\n")
-	print("cs     ", hex(c.cs()), "\n")
-	print("fs     ", hex(c.fs()), "\n")
-	print("gs     ", hex(c.gs()), "\n")
+	printreg("eax    ", hex(c.eax()), "\n")
+	printreg("ebx    ", hex(c.ebx()), "\n")
+	printreg("ecx    ", hex(c.ecx()), "\n")
+	printreg("edx    ", hex(c.edx()), "\n")
+	printreg("edi    ", hex(c.edi()), "\n")
+	printreg("esi    ", hex(c.esi()), "\n")
+	printreg("ebp    ", hex(c.ebp()), "\n")
+	printreg("esp    ", hex(c.esp()), "\n")
+	printreg("eip    ", hex(c.eip()), "\n")
+	printreg("eflags ", hex(c.eflags()), "\n")
+	printreg("cs     ", hex(c.cs()), "\n")
+	printreg("fs     ", hex(c.fs()), "\n")
+	printreg("gs     ", hex(c.gs()), "\n")
 }
 
 //go:nosplit
@@ -13,27 +13,27 @@ import (
 )
 
 func dumpregs(c *sigctxt) {
-	print("rax    ", hex(c.rax()), "\n")
-	print("rbx    ", hex(c.rbx()), "\n")
-	print("rcx    ", hex(c.rcx()), "\n")
-	print("rdx    ", hex(c.rdx()), "\n")
-	print("rdi    ", hex(c.rdi()), "\n")
-	print("rsi    ", hex(c.rsi()), "\n")
-	print("rbp    ", hex(c.rbp()), "\n")
-	print("rsp    ", hex(c.rsp()), "\n")
-	print("r8     ", hex(c.r8()), "\n")
-	print("r9     ", hex(c.r9()), "\n")
-	print("r10    ", hex(c.r10()), "\n")
-	print("r11    ", hex(c.r11()), "\n")
-	print("r12    ", hex(c.r12()), "\n")
-	print("r13    ", hex(c.r13()), "\n")
-	print("r14    ", hex(c.r14()), "\n")
-	print("r15    ", hex(c.r15()), "\n")
-	print("rip    ", hex(c.rip()), "\n")
-	print("rflags ", hex(c.rflags()), "\n")
-	print("cs     ", hex(c.cs()), "\n")
-	print("fs     ", hex(c.fs()), "\n")
-	print("gs     ", hex(c.gs()), "\n")
+	printreg("rax    ", hex(c.rax()), "\n")
+	printreg("rbx    ", hex(c.rbx()), "\n")
+	printreg("rcx    ", hex(c.rcx()), "\n")
+	printreg("rdx    ", hex(c.rdx()), "\n")
+	printreg("rdi    ", hex(c.rdi()), "\n")
+	printreg("rsi    ", hex(c.rsi()), "\n")
+	printreg("rbp    ", hex(c.rbp()), "\n")
+	printreg("rsp    ", hex(c.rsp()), "\n")
+	printreg("r8     ", hex(c.r8()), "\n")
+	printreg("r9     ", hex(c.r9()), "\n")
+	printreg("r10    ", hex(c.r10()), "\n")
+	printreg("r11    ", hex(c.r11()), "\n")
+	printreg("r12    ", hex(c.r12()), "\n")
+	printreg("r13    ", hex(c.r13()), "\n")
+	printreg("r14    ", hex(c.r14()), "\n")
+	printreg("r15    ", hex(c.r15()), "\n")
+	printreg("rip    ", hex(c.rip()), "\n")
+	printreg("rflags ", hex(c.rflags()), "\n")
+	printreg("cs     ", hex(c.cs()), "\n")
+	printreg("fs     ", hex(c.fs()), "\n")
+	printreg("gs     ", hex(c.gs()), "\n")
 }
 
 //go:nosplit
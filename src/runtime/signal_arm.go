@@ -9,27 +9,27 @@ package runtime
 import "unsafe"
 
 func dumpregs(c *sigctxt) {
-	print("trap    ", hex(c.trap()), "\n")
-	print("error   ", hex(c.error()), "\n")
-	print("oldmask ", hex(c.oldmask()), "\n")
-	print("r0      ", hex(c.r0()), "\n")
-	print("r1      ", hex(c.r1()), "\n")
-	print("r2      ", hex(c.r2()), "\n")
-	print("r3      ", hex(c.r3()), "\n")
-	print("r4      ", hex(c.r4()), "\n")
-	print("r5      ", hex(c.r5()), "\n")
-	print("r6      ", hex(c.r6()), "\n")
-	print("r7      ", hex(c.r7()), "\n")
-	print("r8      ", hex(c.r8()), "\n")
-	print("r9      ", hex(c.r9()), "\n")
-	print("r10     ", hex(c.r10()), "\n")
-	print("fp      ", hex(c.fp()), "\n")
-	print("ip      ", hex(c.ip()), "\n")
-	print("sp      ", hex(c.sp()), "\n")
-	print("lr      ", hex(c.lr()), "\n")
-	print("pc      ", hex(c.pc()), "\n")
-	print("cpsr    ", hex(c.cpsr()), "\n")
-	print("fault   ", hex(c.fault()), "\n")
+	printreg("trap    ", hex(c.trap()), "\n")
+	printreg("error   ", hex(c.error()), "\n")
+	printreg("oldmask ", hex(c.oldmask()), "\n")
+	printreg("r0      ", hex(c.r0()), "\n")
+	printreg("r1      ", hex(c.r1()), "\n")
+	printreg("r2      ", hex(c.r2()), "\n")
+	printreg("r3      ", hex(c.r3()), "\n")
+	printreg("r4      ", hex(c.r4()), "\n")
+	printreg("r5      ", hex(c.r5()), "\n")
+	printreg("r6      ", hex(c.r6()), "\n")
+	printreg("r7      ", hex(c.r7()), "\n")
+	printreg("r8      ", hex(c.r8()), "\n")
+	printreg("r9      ", hex(c.r9()), "\n")
+	printreg("r10     ", hex(c.r10()), "\n")
+	printreg("fp      ", hex(c.fp()), "\n")
+	printreg("ip      ", hex(c.ip()), "\n")
+	printreg("sp      ", hex(c.sp()), "\n")
+	printreg("lr      ", hex(c.lr()), "\n")
+	printreg("pc      ", hex(c.pc()), "\n")
+	printreg("cpsr    ", hex(c.cpsr()), "\n")
+	printreg("fault   ", hex(c.fault()), "\n")
 }
 
 //go:nosplit
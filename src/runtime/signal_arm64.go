@@ -12,40 +12,40 @@ import (
 )
 
 func dumpregs(c *sigctxt) {
-	print("r0      ", hex(c.r0()), "\n")
-	print("r1      ", hex(c.r1()), "\n")
-	print("r2      ", hex(c.r2()), "\n")
-	print("r3      ", hex(c.r3()), "\n")
-	print("r4      ", hex(c.r4()), "\n")
-	print("r5      ", hex(c.r5()), "\n")
-	print("r6      ", hex(c.r6()), "\n")
-	print("r7      ", hex(c.r7()), "\n")
-	print("r8      ", hex(c.r8()), "\n")
-	print("r9      ", hex(c.r9()), "\n")
-	print("r10     ", hex(c.r10()), "\n")
-	print("r11     ", hex(c.r11()), "\n")
-	print("r12     ", hex(c.r12()), "\n")
-	print("r13     ", hex(c.r13()), "\n")
-	print("r14     ", hex(c.r14()), "\n")
-	print("r15     ", hex(c.r15()), "\n")
-	print("r16     ", hex(c.r16()), "\n")
-	print("r17     ", hex(c.r17()), "\n")
-	print("r18     ", hex(c.r18()), "\n")
-	print("r19     ", hex(c.r19()), "\n")
-	print("r20     ", hex(c.r20()), "\n")
-	print("r21     ", hex(c.r21()), "\n")
-	print("r22     ", hex(c.r22()), "\n")
-	print("r23     ", hex(c.r23()), "\n")
-	print("r24     ", hex(c.r24()), "\n")
-	print("r25     ", hex(c.r25()), "\n")
-	print("r26     ", hex(c.r26()), "\n")
-	print("r27     ", hex(c.r27()), "\n")
-	print("r28     ", hex(c.r28()), "\n")
-	print("r29     ", hex(c.r29()), "\n")
-	print("lr      ", hex(c.lr()), "\n")
-	print("sp      ", hex(c.sp()), "\n")
-	print("pc      ", hex(c.pc()), "\n")
-	print("fault   ", hex(c.fault()), "\n")
+	printreg("r0      ", hex(c.r0()), "\n")
+	printreg("r1      ", hex(c.r1()), "\n")
+	printreg("r2      ", hex(c.r2()), "\n")
+	printreg("r3      ", hex(c.r3()), "\n")
+	printreg("r4      ", hex(c.r4()), "\n")
+	printreg("r5      ", hex(c.r5()), "\n")
+	printreg("r6      ", hex(c.r6()), "\n")
+	printreg("r7      ", hex(c.r7()), "\n")
+	printreg("r8      ", hex(c.r8()), "\n")
+	printreg("r9      ", hex(c.r9()), "\n")
+	printreg("r10     ", hex(c.r10()), "\n")
+	printreg("r11     ", hex(c.r11()), "\n")
+	printreg("r12     ", hex(c.r12()), "\n")
+	printreg("r13     ", hex(c.r13()), "\n")
+	printreg("r14     ", hex(c.r14()), "\n")
+	printreg("r15     ", hex(c.r15()), "\n")
+	printreg("r16     ", hex(c.r16()), "\n")
+	printreg("r17     ", hex(c.r17()), "\n")
+	printreg("r18     ", hex(c.r18()), "\n")
+	printreg("r19     ", hex(c.r19()), "\n")
+	printreg("r20     ", hex(c.r20()), "\n")
+	printreg("r21     ", hex(c.r21()), "\n")
+	printreg("r22     ", hex(c.r22()), "\n")
+	printreg("r23     ", hex(c.r23()), "\n")
+	printreg("r24     ", hex(c.r24()), "\n")
+	printreg("r25     ", hex(c.r25()), "\n")
+	printreg("r26     ", hex(c.r26()), "\n")
+	printreg("r27     ", hex(c.r27()), "\n")
+	printreg("r28     ", hex(c.r28()), "\n")
+	printreg("r29     ", hex(c.r29()), "\n")
+	printreg("lr      ", hex(c.lr()), "\n")
+	printreg("sp      ", hex(c.sp()), "\n")
+	printreg("pc      ", hex(c.pc()), "\n")
+	printreg("fault   ", hex(c.fault()), "\n")
 }
 
 //go:nosplit
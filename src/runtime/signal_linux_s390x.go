@@ -57,24 +57,24 @@ func (c *sigctxt) set_sigaddr(x uint64) {
 }
 
 func dumpregs(c *sigctxt) {
-	print("r0   ", hex(c.r0()), "\t")
-	print("r1   ", hex(c.r1()), "\n")
-	print("r2   ", hex(c.r2()), "\t")
-	print("r3   ", hex(c.r3()), "\n")
-	print("r4   ", hex(c.r4()), "\t")
-	print("r5   ", hex(c.r5()), "\n")
-	print("r6   ", hex(c.r6()), "\t")
-	print("r7   ", hex(c.r7()), "\n")
-	print("r8   ", hex(c.r8()), "\t")
-	print("r9   ", hex(c.r9()), "\n")
-	print("r10  ", hex(c.r10()), "\t")
-	print("r11  ", hex(c.r11()), "\n")
-	print("r12  ", hex(c.r12()), "\t")
-	print("r13  ", hex(c.r13()), "\n")
-	print("r14  ", hex(c.r14()), "\t")
-	print("r15  ", hex(c.r15()), "\n")
-	print("pc   ", hex(c.pc()), "\t")
-	print("link ", hex(c.link()), "\n")
+	printreg("r0   ", hex(c.r0()), "\t")
+	printreg("r1   ", hex(c.r1()), "\n")
+	printreg("r2   ", hex(c.r2()), "\t")
+	printreg("r3   ", hex(c.r3()), "\n")
+	printreg("r4   ", hex(c.r4()), "\t")
+	printreg("r5   ", hex(c.r5()), "\n")
+	printreg("r6   ", hex(c.r6()), "\t")
+	printreg("r7   ", hex(c.r7()), "\n")
+	printreg("r8   ", hex(c.r8()), "\t")
+	printreg("r9   ", hex(c.r9()), "\n")
+	printreg("r10  ", hex(c.r10()), "\t")
+	printreg("r11  ", hex(c.r11()), "\n")
+	printreg("r12  ", hex(c.r12()), "\t")
+	printreg("r13  ", hex(c.r13()), "\n")
+	printreg("r14  ", hex(c.r14()), "\t")
+	printreg("r15  ", hex(c.r15()), "\n")
+	printreg("pc   ", hex(c.pc()), "\t")
+	printreg("link ", hex(c.link()), "\n")
 }
 
 //go:nosplit
@@ -13,42 +13,42 @@ import (
 )
 
 func dumpregs(c *sigctxt) {
-	print("r0   ", hex(c.r0()), "\t")
-	print("r1   ", hex(c.r1()), "\n")
-	print("r2   ", hex(c.r2()), "\t")
-	print("r3   ", hex(c.r3()), "\n")
-	print("r4   ", hex(c.r4()), "\t")
-	print("r5   ", hex(c.r5()), "\n")
-	print("r6   ", hex(c.r6()), "\t")
-	print("r7   ", hex(c.r7()), "\n")
-	print("r8   ", hex(c.r8()), "\t")
-	print("r9   ", hex(c.r9()), "\n")
-	print("r10  ", hex(c.r10()), "\t")
-	print("r11  ", hex(c.r11()), "\n")
-	print("r12  ", hex(c.r12()), "\t")
-	print("r13  ", hex(c.r13()), "\n")
-	print("r14  ", hex(c.r14()), "\t")
-	print("r15  ", hex(c.r15()), "\n")
-	print("r16  ", hex(c.r16()), "\t")
-	print("r17  ", hex(c.r17()), "\n")
-	print("r18  ", hex(c.r18()), "\t")
-	print("r19  ", hex(c.r19()), "\n")
-	print("r20  ", hex(c.r20()), "\t")
-	print("r21  ", hex(c.r21()), "\n")
-	print("r22  ", hex(c.r22()), "\t")
-	print("r23  ", hex(c.r23()), "\n")
-	print("r24  ", hex(c.r24()), "\t")
-	print("r25  ", hex(c.r25()), "\n")
-	print("r26  ", hex(c.r26()), "\t")
-	print("r27  ", hex(c.r27()), "\n")
-	print("r28  ", hex(c.r28()), "\t")
-	print("r29  ", hex(c.r29()), "\n")
-	print("r30  ", hex(c.r30()), "\t")
-	print("r31  ", hex(c.r31()), "\n")
-	print("pc   ", hex(c.pc()), "\t")
-	print("link ", hex(c.link()), "\n")
-	print("lo   ", hex(c.lo()), "\t")
-	print("hi   ", hex(c.hi()), "\n")
+	printreg("r0   ", hex(c.r0()), "\t")
+	printreg("r1   ", hex(c.r1()), "\n")
+	printreg("r2   ", hex(c.r2()), "\t")
+	printreg("r3   ", hex(c.r3()), "\n")
+	printreg("r4   ", hex(c.r4()), "\t")
+	printreg("r5   ", hex(c.r5()), "\n")
+	printreg("r6   ", hex(c.r6()), "\t")
+	printreg("r7   ", hex(c.r7()), "\n")
+	printreg("r8   ", hex(c.r8()), "\t")
+	printreg("r9   ", hex(c.r9()), "\n")
+	printreg("r10  ", hex(c.r10()), "\t")
+	printreg("r11  ", hex(c.r11()), "\n")
+	printreg("r12  ", hex(c.r12()), "\t")
+	printreg("r13  ", hex(c.r13()), "\n")
+	printreg("r14  ", hex(c.r14()), "\t")
+	printreg("r15  ", hex(c.r15()), "\n")
+	printreg("r16  ", hex(c.r16()), "\t")
+	printreg("r17  ", hex(c.r17()), "\n")
+	printreg("r18  ", hex(c.r18()), "\t")
+	printreg("r19  ", hex(c.r19()), "\n")
+	printreg("r20  ", hex(c.r20()), "\t")
+	printreg("r21  ", hex(c.r21()), "\n")
+	printreg("r22  ", hex(c.r22()), "\t")
+	printreg("r23  ", hex(c.r23()), "\n")
+	printreg("r24  ", hex(c.r24()), "\t")
+	printreg("r25  ", hex(c.r25()), "\n")
+	printreg("r26  ", hex(c.r26()), "\t")
+	printreg("r27  ", hex(c.r27()), "\n")
+	printreg("r28  ", hex(c.r28()), "\t")
+	printreg("r29  ", hex(c.r29()), "\n")
+	printreg("r30  ", hex(c.r30()), "\t")
+	printreg("r31  ", hex(c.r31()), "\n")
+	printreg("pc   ", hex(c.pc()), "\t")
+	printreg("link ", hex(c.link()), "\n")
+	printreg("lo   ", hex(c.lo()), "\t")
+	printreg("hi   ", hex(c.hi()), "\n")
 }
 
 //go:nosplit
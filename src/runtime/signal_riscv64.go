@@ -12,38 +12,38 @@ import (
 )
 
 func dumpregs(c *sigctxt) {
-	print("ra  ", hex(c.ra()), "\t")
-	print("sp  ", hex(c.sp()), "\n")
-	print("gp  ", hex(c.gp()), "\t")
-	print("tp  ", hex(c.tp()), "\n")
-	print("t0  ", hex(c.t0()), "\t")
-	print("t1  ", hex(c.t1()), "\n")
-	print("t2  ", hex(c.t2()), "\t")
-	print("s0  ", hex(c.s0()), "\n")
-	print("s1  ", hex(c.s1()), "\t")
-	print("a0  ", hex(c.a0()), "\n")
-	print("a1  ", hex(c.a1()), "\t")
-	print("a2  ", hex(c.a2()), "\n")
-	print("a3  ", hex(c.a3()), "\t")
-	print("a4  ", hex(c.a4()), "\n")
-	print("a5  ", hex(c.a5()), "\t")
-	print("a6  ", hex(c.a6()), "\n")
-	print("a7  ", hex(c.a7()), "\t")
-	print("s2  ", hex(c.s2()), "\n")
-	print("s3  ", hex(c.s3()), "\t")
-	print("s4  ", hex(c.s4()), "\n")
-	print("s5  ", hex(c.s5()), "\t")
-	print("s6  ", hex(c.s6()), "\n")
-	print("s7  ", hex(c.s7()), "\t")
-	print("s8  ", hex(c.s8()), "\n")
-	print("s9  ", hex(c.s9()), "\t")
-	print("s10 ", hex(c.s10()), "\n")
-	print("s11 ", hex(c.s11()), "\t")
-	print("t3  ", hex(c.t3()), "\n")
-	print("t4  ", hex(c.t4()), "\t")
-	print("t5  ", hex(c.t5()), "\n")
-	print("t6  ", hex(c.t6()), "\t")
-	print("pc  ", hex(c.pc()), "\n")
+	printreg("ra  ", hex(c.ra()), "\t")
+	printreg("sp  ", hex(c.sp()), "\n")
+	printreg("gp  ", hex(c.gp()), "\t")
+	printreg("tp  ", hex(c.tp()), "\n")
+	printreg("t0  ", hex(c.t0()), "\t")
+	printreg("t1  ", hex(c.t1()), "\n")
+	printreg("t2  ", hex(c.t2()), "\t")
+	printreg("s0  ", hex(c.s0()), "\n")
+	printreg("s1  ", hex(c.s1()), "\t")
+	printreg("a0  ", hex(c.a0()), "\n")
+	printreg("a1  ", hex(c.a1()), "\t")
+	printreg("a2  ", hex(c.a2()), "\n")
+	printreg("a3  ", hex(c.a3()), "\t")
+	printreg("a4  ", hex(c.a4()), "\n")
+	printreg("a5  ", hex(c.a5()), "\t")
+	printreg("a6  ", hex(c.a6()), "\n")
+	printreg("a7  ", hex(c.a7()), "\t")
+	printreg("s2  ", hex(c.s2()), "\n")
+	printreg("s3  ", hex(c.s3()), "\t")
+	printreg("s4  ", hex(c.s4()), "\n")
+	printreg("s5  ", hex(c.s5()), "\t")
+	printreg("s6  ", hex(c.s6()), "\n")
+	printreg("s7  ", hex(c.s7()), "\t")
+	printreg("s8  ", hex(c.s8()), "\n")
+	printreg("s9  ", hex(c.s9()), "\t")
+	printreg("s10 ", hex(c.s10()), "\n")
+	printreg("s11 ", hex(c.s11()), "\t")
+	printreg("t3  ", hex(c.t3()), "\n")
+	printreg("t4  ", hex(c.t4()), "\t")
+	printreg("t5  ", hex(c.t5()), "\n")
+	printreg("t6  ", hex(c.t6()), "\t")
+	printreg("pc  ", hex(c.pc()), "\n")
 }
 
 //go:nosplit
@@ -605,15 +605,27 @@ func sighandler(sig uint32, info *siginfo, ctxt unsafe.Pointer, gp *g) {
 		startpanic_m()
 	}
 
+	json := crashJSON()
+	if json {
+		print(`{"type":"signal","signal":"`)
+	}
 	if sig < uint32(len(sigtable)) {
-		print(sigtable[sig].name, "\n")
+		print(sigtable[sig].name)
 	} else {
-		print("Signal ", sig, "\n")
+		print("Signal ", sig)
 	}
 
-	print("PC=", hex(c.sigpc()), " m=", _g_.m.id, " sigcode=", c.sigcode(), "\n")
+	if json {
+		print(`","code":"`, hex(c.sigcode()), `","addr":"`, hex(c.fault()), `","pc":"`, hex(c.sigpc()), `","m":`, _g_.m.id)
+	} else {
+		print("\nPC=", hex(c.sigpc()), " m=", _g_.m.id, " sigcode=", c.sigcode(), "\n")
+	}
 	if _g_.m.lockedg != 0 && _g_.m.ncgo > 0 && gp == _g_.m.g0 {
-		print("signal arrived during cgo execution\n")
+		if json {
+			print(`,"cgo":true`)
+		} else {
+			print("signal arrived during cgo execution\n")
+		}
 		gp = _g_.m.lockedg.ptr()
 	}
 	if sig == _SIGILL {
@@ -633,12 +645,26 @@ func sighandler(sig uint32, info *siginfo, ctxt unsafe.Pointer, gp *g) {
 		if n > physPageSize-pc%physPageSize {
 			n = physPageSize - pc%physPageSize
 		}
-		print("instruction bytes:")
 		b := (*[maxN]byte)(unsafe.Pointer(pc))
-		for i := uintptr(0); i < n; i++ {
-			print(" ", hex(b[i]))
+		if json {
+			print(`,"instruction_bytes":[`)
+			for i := uintptr(0); i < n; i++ {
+				if i > 0 {
+					print(",")
+				}
+				print(`"`, hex(b[i]), `"`)
+			}
+			print("]")
+		} else {
+			print("instruction bytes:")
+			for i := uintptr(0); i < n; i++ {
+				print(" ", hex(b[i]))
+			}
+			println()
 		}
-		println()
+	}
+	if json {
+		print("}")
 	}
 	print("\n")
 
@@ -652,9 +678,17 @@ func sighandler(sig uint32, info *siginfo, ctxt unsafe.Pointer, gp *g) {
 			traceback(^uintptr(0), ^uintptr(0), 0, _g_.m.curg)
 		} else if crashing == 0 {
 			tracebackothers(gp)
-			print("\n")
+			if !json {
+				print("\n")
+			}
+		}
+		if json {
+			print(`{"type":"registers","m":`, _g_.m.id)
+			dumpregs(c)
+			print("}\n")
+		} else {
+			dumpregs(c)
 		}
-		dumpregs(c)
 	}
 
 	if docrash {
@@ -670,7 +704,9 @@ func sighandler(sig uint32, info *siginfo, ctxt unsafe.Pointer, gp *g) {
 			// In expected operation, the last m has received the SIGQUIT and run
 			// crash/exit and the process is gone, all long before any of the
 			// 5-second sleeps have finished.
-			print("\n-----\n\n")
+			if !json {
+				print("\n-----\n\n")
+			}
 			raiseproc(_SIGQUIT)
 			usleep(5 * 1000 * 1000)
 		}
@@ -202,28 +202,48 @@ func lastcontinuehandler(info *exceptionrecord, r *context, gp *g) int32 {
 	_g_.stackguard0 = _g_.stack.lo + _StackGuard
 	_g_.stackguard1 = _g_.stackguard0
 
-	print("Exception ", hex(info.exceptioncode), " ", hex(info.exceptioninformation[0]), " ", hex(info.exceptioninformation[1]), " ", hex(r.ip()), "\n")
+	// Mark the M as throwing, as sighandler does, so that the output
+	// is printed as JSON if selected and copied to the crash output.
+	_g_.m.throwing = 1
 
-	print("PC=", hex(r.ip()), "\n")
+	json := crashJSON()
+	if json {
+		print(`{"type":"signal","signal":"Exception `, hex(info.exceptioncode), `","code":"`, hex(info.exceptioninformation[0]), `","addr":"`, hex(info.exceptioninformation[1]), `","pc":"`, hex(r.ip()), `","m":`, _g_.m.id)
+	} else {
+		print("Exception ", hex(info.exceptioncode), " ", hex(info.exceptioninformation[0]), " ", hex(info.exceptioninformation[1]), " ", hex(r.ip()), "\n")
+		print("PC=", hex(r.ip()), "\n")
+	}
 	if _g_.m.lockedg != 0 && _g_.m.ncgo > 0 && gp == _g_.m.g0 {
 		if iscgo {
-			print("signal arrived during external code execution\n")
+			if json {
+				print(`,"cgo":true`)
+			} else {
+				print("signal arrived during external code execution\n")
+			}
 		}
 		gp = _g_.m.lockedg.ptr()
 	}
-	print("\n")
-
-	// TODO(jordanrh1): This may be needed for 386/AMD64 as well.
-	if GOARCH == "arm" {
-		_g_.m.throwing = 1
-		_g_.m.caughtsig.set(gp)
+	if json {
+		print("}")
 	}
+	print("\n")
+	_g_.m.caughtsig.set(gp)
 
 	level, _, docrash := gotraceback()
 	if level > 0 {
+		if json {
+			// Unlike text, JSON needs the goroutine's header.
+			goroutineheader(gp)
+		}
 		tracebacktrap(r.ip(), r.sp(), r.lr(), gp)
 		tracebackothers(gp)
-		dumpregs(r)
+		if json {
+			print(`{"type":"registers","m":`, _g_.m.id)
+			dumpregs(r)
+			print("}\n")
+		} else {
+			dumpregs(r)
+		}
 	}
 
 	if docrash {
@@ -1057,6 +1057,9 @@ func newstack() {
 	if newsize > maxstacksize {
 		print("runtime: goroutine stack exceeds ", maxstacksize, "-byte limit\n")
 		print("runtime: sp=", hex(sp), " stack=[", hex(gp.stack.lo), ", ", hex(gp.stack.hi), "]\n")
+		if notifyLimitHandler(limitStack, uint64(maxstacksize), uint64(newsize), gp.goid) {
+			waitLimitHandler()
+		}
 		throw("stack overflow")
 	}
 
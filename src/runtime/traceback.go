@@ -148,6 +148,10 @@ func gentraceback(pc0, sp0, lr0 uintptr, gp *g, skip int, pcbuf *uintptr, max in
 	waspanic := false
 	cgoCtxt := gp.cgoCtxt
 	printing := pcbuf == nil && callback == nil
+	var tf tracebackFormat
+	if printing {
+		tf = getTracebackFormat()
+	}
 
 	// If the PC is zero, it's likely a nil function call.
 	// Start in the caller's frame.
@@ -416,8 +420,13 @@ func gentraceback(pc0, sp0, lr0 uintptr, gp *g, skip int, pcbuf *uintptr, max in
 					if (flags&_TraceRuntimeFrames) != 0 || showframe(f, gp, nprint == 0, inltree[ix].funcID, lastFuncID) {
 						name := funcnameFromNameoff(f, inltree[ix].func_)
 						file, line := funcline(f, tracepc)
-						print(name, "(...)\n")
-						print("\t", file, ":", line, "\n")
+						if tf.json {
+							printFrameJSON(name, tf.file(file), int(line))
+							print(`,"inlined":true}`)
+						} else {
+							print(name, "(...)\n")
+							print("\t", tf.file(file), ":", line, "\n")
+						}
 						nprint++
 					}
 					lastFuncID = inltree[ix].funcID
@@ -435,27 +444,56 @@ func gentraceback(pc0, sp0, lr0 uintptr, gp *g, skip int, pcbuf *uintptr, max in
 				if name == "runtime.gopanic" {
 					name = "panic"
 				}
-				print(name, "(")
-				argp := (*[100]uintptr)(unsafe.Pointer(frame.argp))
-				for i := uintptr(0); i < frame.arglen/sys.PtrSize; i++ {
-					if i >= 10 {
-						print(", ...")
-						break
+				if tf.json {
+					printFrameJSON(name, tf.file(file), int(line))
+					if !tf.hideArgs {
+						print(`,"args":[`)
+						argp := (*[100]uintptr)(unsafe.Pointer(frame.argp))
+						for i := uintptr(0); i < frame.arglen/sys.PtrSize; i++ {
+							if i != 0 {
+								print(",")
+							}
+							if i >= 10 {
+								print(`"..."`)
+								break
+							}
+							print(`"`, hex(argp[i]), `"`)
+						}
+						print("]")
 					}
-					if i != 0 {
-						print(", ")
+					if frame.pc > f.entry && !tf.hideAddrs {
+						print(`,"offset":"`, hex(frame.pc-f.entry), `"`)
 					}
-					print(hex(argp[i]))
-				}
-				print(")\n")
-				print("\t", file, ":", line)
-				if frame.pc > f.entry {
-					print(" +", hex(frame.pc-f.entry))
-				}
-				if gp.m != nil && gp.m.throwing > 0 && gp == gp.m.curg || level >= 2 {
-					print(" fp=", hex(frame.fp), " sp=", hex(frame.sp), " pc=", hex(frame.pc))
+					if (gp.m != nil && gp.m.throwing > 0 && gp == gp.m.curg || level >= 2) && !tf.hideAddrs {
+						print(`,"fp":"`, hex(frame.fp), `","sp":"`, hex(frame.sp), `","pc":"`, hex(frame.pc), `"`)
+					}
+					print("}")
+				} else {
+					print(name, "(")
+					argp := (*[100]uintptr)(unsafe.Pointer(frame.argp))
+					for i := uintptr(0); i < frame.arglen/sys.PtrSize; i++ {
+						if i >= 10 || tf.hideArgs {
+							if i != 0 {
+								print(", ")
+							}
+							print("...")
+							break
+						}
+						if i != 0 {
+							print(", ")
+						}
+						print(hex(argp[i]))
+					}
+					print(")\n")
+					print("\t", tf.file(file), ":", line)
+					if frame.pc > f.entry && !tf.hideAddrs {
+						print(" +", hex(frame.pc-f.entry))
+					}
+					if (gp.m != nil && gp.m.throwing > 0 && gp == gp.m.curg || level >= 2) && !tf.hideAddrs {
+						print(" fp=", hex(frame.fp), " sp=", hex(frame.sp), " pc=", hex(frame.pc))
+					}
+					print("\n")
 				}
-				print("\n")
 				nprint++
 			}
 			lastFuncID = f.funcID
@@ -647,7 +685,7 @@ func tracebackCgoContext(pcbuf *uintptr, printing bool, ctxt uintptr, n, max int
 		}
 		if printing {
 			if cgoSymbolizer == nil {
-				print("non-Go function at pc=", hex(pc), "\n")
+				printNonGoFrame(pc)
 			} else {
 				c := printOneCgoTraceback(pc, max-n, &arg)
 				n += c - 1 // +1 a few lines down
@@ -673,19 +711,70 @@ func printcreatedby(gp *g) {
 }
 
 func printcreatedby1(f funcInfo, pc uintptr) {
-	print("created by ", funcname(f), "\n")
+	tf := getTracebackFormat()
+	if !tf.json {
+		print("created by ", funcname(f), "\n")
+	}
 	tracepc := pc // back up to CALL instruction for funcline.
 	if pc > f.entry {
 		tracepc -= sys.PCQuantum
 	}
 	file, line := funcline(f, tracepc)
-	print("\t", file, ":", line)
-	if pc > f.entry {
+	if tf.json {
+		print(`,"created_by":{"func":`)
+		printJSONString(funcname(f))
+		print(`,"file":`)
+		printJSONString(tf.file(file))
+		print(`,"line":`, line)
+		if pc > f.entry && !tf.hideAddrs {
+			print(`,"offset":"`, hex(pc-f.entry), `"`)
+		}
+		print("}")
+		return
+	}
+	print("\t", tf.file(file), ":", line)
+	if pc > f.entry && !tf.hideAddrs {
 		print(" +", hex(pc-f.entry))
 	}
 	print("\n")
 }
 
+// A tracebackFormat says which parts of the frames
+// of printed tracebacks to leave out or shorten.
+type tracebackFormat struct {
+	hideArgs   bool   // print "..." for the arguments
+	hideAddrs  bool   // omit PC offsets and frame addresses
+	trimPrefix string // removed from the start of file names
+	json       bool   // print frames as JSON; see crashjson.go
+}
+
+// tracebackFmt is the format set by runtime/debug.SetStackFormat,
+// or nil for the default. It is accessed atomically.
+var tracebackFmt *tracebackFormat
+
+//go:linkname setStackFormat runtime/debug.setStackFormat
+func setStackFormat(hideArgs, hideAddrs bool, trimPrefix string) {
+	atomicstorep(unsafe.Pointer(&tracebackFmt), unsafe.Pointer(&tracebackFormat{hideArgs: hideArgs, hideAddrs: hideAddrs, trimPrefix: trimPrefix}))
+}
+
+// getTracebackFormat returns the format in which to print tracebacks.
+func getTracebackFormat() tracebackFormat {
+	var tf tracebackFormat
+	if p := (*tracebackFormat)(atomic.Loadp(unsafe.Pointer(&tracebackFmt))); p != nil {
+		tf = *p
+	}
+	tf.json = crashJSON()
+	return tf
+}
+
+// file returns the file name to print for file.
+func (tf *tracebackFormat) file(file string) string {
+	if tf.trimPrefix != "" && hasPrefix(file, tf.trimPrefix) {
+		return file[len(tf.trimPrefix):]
+	}
+	return file
+}
+
 func traceback(pc, sp, lr uintptr, gp *g) {
 	traceback1(pc, sp, lr, gp, 0)
 }
@@ -708,6 +797,9 @@ func tracebacktrap(pc, sp, lr uintptr, gp *g) {
 }
 
 func traceback1(pc, sp, lr uintptr, gp *g, flags uint) {
+	// In JSON, the caller has begun the object
+	// of the goroutine, which traceback1 ends.
+	json := crashJSON()
 	// If the goroutine is in cgo, and we have a cgo traceback, print that.
 	if iscgo && gp.m != nil && gp.m.ncgo > 0 && gp.syscallsp != 0 && gp.m.cgoCallers != nil && gp.m.cgoCallers[0] != 0 {
 		// Lock cgoCallers so that a signal handler won't
@@ -721,7 +813,14 @@ func traceback1(pc, sp, lr uintptr, gp *g, flags uint) {
 		gp.m.cgoCallers[0] = 0
 		atomic.Store(&gp.m.cgoCallersUse, 0)
 
+		if json {
+			print(`,"cgo_frames":[`)
+			getg().m.printjsonsep = false
+		}
 		printCgoTraceback(&cgoCallers)
+		if json {
+			print("]")
+		}
 	}
 
 	var n int
@@ -733,41 +832,79 @@ func traceback1(pc, sp, lr uintptr, gp *g, flags uint) {
 	}
 	// Print traceback. By default, omits runtime frames.
 	// If that means we print nothing at all, repeat forcing all frames printed.
+	if json {
+		printFramesJSON()
+	}
 	n = gentraceback(pc, sp, lr, gp, 0, nil, _TracebackMaxFrames, nil, nil, flags)
 	if n == 0 && (flags&_TraceRuntimeFrames) == 0 {
 		n = gentraceback(pc, sp, lr, gp, 0, nil, _TracebackMaxFrames, nil, nil, flags|_TraceRuntimeFrames)
 	}
+	if json {
+		print("]")
+	}
 	if n == _TracebackMaxFrames {
-		print("...additional frames elided...\n")
+		printElided(json)
 	}
 	printcreatedby(gp)
 
-	if gp.ancestors == nil {
-		return
+	if gp.ancestors != nil {
+		if json {
+			print(`,"ancestors":[`)
+		}
+		for i, ancestor := range *gp.ancestors {
+			if json && i > 0 {
+				print(",")
+			}
+			printAncestorTraceback(ancestor)
+		}
+		if json {
+			print("]")
+		}
+	}
+	if json {
+		print("}\n")
 	}
-	for _, ancestor := range *gp.ancestors {
-		printAncestorTraceback(ancestor)
+}
+
+// printElided notes that a traceback was cut short.
+func printElided(json bool) {
+	if json {
+		print(`,"elided":true`)
+	} else {
+		print("...additional frames elided...\n")
 	}
 }
 
 // printAncestorTraceback prints the traceback of the given ancestor.
 // TODO: Unify this with gentraceback and CallersFrames.
 func printAncestorTraceback(ancestor ancestorInfo) {
-	print("[originating from goroutine ", ancestor.goid, "]:\n")
+	json := crashJSON()
+	if json {
+		print(`{"goroutine":`, ancestor.goid)
+		printFramesJSON()
+	} else {
+		print("[originating from goroutine ", ancestor.goid, "]:\n")
+	}
 	for fidx, pc := range ancestor.pcs {
 		f := findfunc(pc) // f previously validated
 		if showfuncinfo(f, fidx == 0, funcID_normal, funcID_normal) {
 			printAncestorTracebackFuncInfo(f, pc)
 		}
 	}
+	if json {
+		print("]")
+	}
 	if len(ancestor.pcs) == _TracebackMaxFrames {
-		print("...additional frames elided...\n")
+		printElided(json)
 	}
 	// Show what created goroutine, except main goroutine (goid 1).
 	f := findfunc(ancestor.gopc)
 	if f.valid() && showfuncinfo(f, false, funcID_normal, funcID_normal) && ancestor.goid != 1 {
 		printcreatedby1(f, ancestor.gopc)
 	}
+	if json {
+		print("}")
+	}
 }
 
 // printAncestorTraceback prints the given function info at a given pc
@@ -787,9 +924,18 @@ func printAncestorTracebackFuncInfo(f funcInfo, pc uintptr) {
 	if name == "runtime.gopanic" {
 		name = "panic"
 	}
+	tf := getTracebackFormat()
+	if tf.json {
+		printFrameJSON(name, tf.file(file), int(line))
+		if pc > f.entry && !tf.hideAddrs {
+			print(`,"offset":"`, hex(pc-f.entry), `"`)
+		}
+		print("}")
+		return
+	}
 	print(name, "(...)\n")
-	print("\t", file, ":", line)
-	if pc > f.entry {
+	print("\t", tf.file(file), ":", line)
+	if pc > f.entry && !tf.hideAddrs {
 		print(" +", hex(pc-f.entry))
 	}
 	print("\n")
@@ -877,29 +1023,54 @@ var gStatusStrings = [...]string{
 	_Gpreempted: "preempted",
 }
 
-func goroutineheader(gp *g) {
-	gpstatus := readgstatus(gp)
-
-	isScan := gpstatus&_Gscan != 0
-	gpstatus &^= _Gscan // drop the scan bit
+// gstatusString returns the status of gp, whose status without the
+// scan bit is gpstatus, as printed in tracebacks.
+func gstatusString(gp *g, gpstatus uint32) string {
+	// Override.
+	if gpstatus == _Gwaiting && gp.waitreason != waitReasonZero {
+		return gp.waitreason.String()
+	}
 
 	// Basic string status
-	var status string
 	if 0 <= gpstatus && gpstatus < uint32(len(gStatusStrings)) {
-		status = gStatusStrings[gpstatus]
-	} else {
-		status = "???"
+		return gStatusStrings[gpstatus]
 	}
+	return "???"
+}
 
-	// Override.
-	if gpstatus == _Gwaiting && gp.waitreason != waitReasonZero {
-		status = gp.waitreason.String()
+// gwaitTime returns the approximate time in nanoseconds gp, whose
+// status without the scan bit is gpstatus, has been blocked, or 0.
+func gwaitTime(gp *g, gpstatus uint32) int64 {
+	if (gpstatus == _Gwaiting || gpstatus == _Gsyscall) && gp.waitsince != 0 {
+		return nanotime() - gp.waitsince
 	}
+	return 0
+}
+
+func goroutineheader(gp *g) {
+	gpstatus := readgstatus(gp)
+
+	isScan := gpstatus&_Gscan != 0
+	gpstatus &^= _Gscan // drop the scan bit
+
+	status := gstatusString(gp, gpstatus)
 
 	// approx time the G is blocked, in minutes
-	var waitfor int64
-	if (gpstatus == _Gwaiting || gpstatus == _Gsyscall) && gp.waitsince != 0 {
-		waitfor = (nanotime() - gp.waitsince) / 60e9
+	waitfor := gwaitTime(gp, gpstatus) / 60e9
+	if crashJSON() {
+		// Begin the object of the goroutine, for traceback to end.
+		print(`{"type":"goroutine","goroutine":`, gp.goid, `,"state":`)
+		printJSONString(status)
+		if isScan {
+			print(`,"scan":true`)
+		}
+		if waitfor >= 1 {
+			print(`,"wait_minutes":`, waitfor)
+		}
+		if gp.lockedm != 0 {
+			print(`,"locked_to_thread":true`)
+		}
+		return
 	}
 	print("goroutine ", gp.goid, " [", status)
 	if isScan {
@@ -916,12 +1087,15 @@ func goroutineheader(gp *g) {
 
 func tracebackothers(me *g) {
 	level, _, _ := gotraceback()
+	json := crashJSON()
 
 	// Show the current goroutine first, if we haven't already.
 	g := getg()
 	gp := g.m.curg
 	if gp != nil && gp != me {
-		print("\n")
+		if !json {
+			print("\n")
+		}
 		goroutineheader(gp)
 		traceback(^uintptr(0), ^uintptr(0), 0, gp)
 	}
@@ -931,15 +1105,23 @@ func tracebackothers(me *g) {
 		if gp == me || gp == g.m.curg || readgstatus(gp) == _Gdead || isSystemGoroutine(gp, false) && level < 2 {
 			continue
 		}
-		print("\n")
+		if !json {
+			print("\n")
+		}
 		goroutineheader(gp)
 		// Note: gp.m == g.m occurs when tracebackothers is
 		// called from a signal handler initiated during a
 		// systemstack call. The original G is still in the
 		// running state, and we want to print its stack.
 		if gp.m != g.m && readgstatus(gp)&^_Gscan == _Grunning {
-			print("\tgoroutine running on other thread; stack unavailable\n")
-			printcreatedby(gp)
+			if json {
+				print(`,"stack_unavailable":true`)
+				printcreatedby(gp)
+				print("}\n")
+			} else {
+				print("\tgoroutine running on other thread; stack unavailable\n")
+				printcreatedby(gp)
+			}
 		} else {
 			traceback(^uintptr(0), ^uintptr(0), 0, gp)
 		}
@@ -1251,6 +1433,17 @@ type cgoSymbolizerArg struct {
 	data     uintptr
 }
 
+// printNonGoFrame prints the frame of a non-Go function
+// for which there is no symbolizer.
+func printNonGoFrame(pc uintptr) {
+	if crashJSON() {
+		printFrameJSON("non-Go function", "", 0)
+		print(`,"pc":"`, hex(pc), `"}`)
+		return
+	}
+	print("non-Go function at pc=", hex(pc), "\n")
+}
+
 // cgoTraceback prints a traceback of callers.
 func printCgoTraceback(callers *cgoCallers) {
 	if cgoSymbolizer == nil {
@@ -1258,7 +1451,7 @@ func printCgoTraceback(callers *cgoCallers) {
 			if c == 0 {
 				break
 			}
-			print("non-Go function at pc=", hex(c), "\n")
+			printNonGoFrame(c)
 		}
 		return
 	}
@@ -1280,9 +1473,21 @@ func printCgoTraceback(callers *cgoCallers) {
 func printOneCgoTraceback(pc uintptr, max int, arg *cgoSymbolizerArg) int {
 	c := 0
 	arg.pc = pc
+	json := crashJSON()
 	for c <= max {
 		callCgoSymbolizer(arg)
-		if arg.funcName != nil {
+		if json {
+			name := "non-Go function"
+			if arg.funcName != nil {
+				name = gostringnocopy(arg.funcName)
+			}
+			file := ""
+			if arg.file != nil {
+				file = gostringnocopy(arg.file)
+			}
+			printFrameJSON(name, file, int(arg.lineno))
+			print(`,"pc":"`, hex(pc), `"}`)
+		} else if arg.funcName != nil {
 			// Note that we don't print any argument
 			// information here, not even parentheses.
 			// The symbolizer must add that if appropriate.
@@ -1290,11 +1495,13 @@ func printOneCgoTraceback(pc uintptr, max int, arg *cgoSymbolizerArg) int {
 		} else {
 			println("non-Go function")
 		}
-		print("\t")
-		if arg.file != nil {
-			print(gostringnocopy(arg.file), ":", arg.lineno, " ")
+		if !json {
+			print("\t")
+			if arg.file != nil {
+				print(gostringnocopy(arg.file), ":", arg.lineno, " ")
+			}
+			print("pc=", hex(pc), "\n")
 		}
-		print("pc=", hex(pc), "\n")
 		c++
 		if arg.more == 0 {
 			break